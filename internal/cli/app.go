@@ -2,20 +2,28 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
+	"syscall"
 	"time"
 
 	flag "github.com/spf13/pflag"
 
 	"github.com/cjp2600/stepwise/internal/ai"
 	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/jsondiff"
 	"github.com/cjp2600/stepwise/internal/logger"
 	"github.com/cjp2600/stepwise/internal/mcp"
+	"github.com/cjp2600/stepwise/internal/openapi"
 	"github.com/cjp2600/stepwise/internal/report"
+	"github.com/cjp2600/stepwise/internal/secrets"
 	"github.com/cjp2600/stepwise/internal/workflow"
 )
 
@@ -82,6 +90,8 @@ func (a *App) Run(args []string) error {
 		return a.handleGenerate(commandArgs)
 	case "codex":
 		return a.handleCodex(commandArgs)
+	case "secrets":
+		return a.handleSecrets(commandArgs)
 	case "help":
 		return a.showHelp()
 	case "version":
@@ -180,6 +190,14 @@ func (a *App) handleRun(args []string) error {
 	failFast := fs.BoolP("fail-fast", "f", false, "Stop execution on first test failure")
 	htmlReportEnabled := fs.Bool("html-report", false, "Generate HTML report (default: test-report_TIMESTAMP.html)")
 	htmlReportPath := fs.String("html-report-path", "", "Path for HTML report file (used with --html-report)")
+	timeout := fs.Duration("timeout", 0, "Deadline for the whole run, e.g. 5m (0 means no limit)")
+	dataFile := fs.String("data", "", "Data file (CSV, JSON, YAML or JSONL); runs each workflow once per row")
+	environment := fs.StringP("env", "e", a.config.Environment, "Environment whose variables are layered over the workflow variables (default: $STEPWISE_ENV)")
+	varFile := fs.String("var-file", "", "YAML or JSON file with variables overriding workflow and environment variables")
+	varPairs := fs.StringArray("var", nil, "Variable override as key=value; may be repeated, wins over --var-file")
+	envFile := fs.String("env-file", "", ".env file for {{env.*}}, loaded on top of the .env next to each workflow")
+	seed := fs.Int64("seed", 0, "Seed of {{faker.*}} data, to repeat a run (default: faker_seed or random)")
+	updateSnapshots := fs.Bool("update-snapshots", false, "Rewrite snapshot files that differ from the responses instead of failing")
 	_ = fs.Parse(args)
 
 	// Find the first non-flag argument as the path
@@ -219,10 +237,45 @@ func (a *App) handleRun(args []string) error {
 		a.logger.SetMuteMode(true)
 	}
 
+	// Ctrl-C / SIGTERM cancel in-flight requests; results collected so far are still reported
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	// The data file is given relative to the working directory, not the workflow file
+	if *dataFile != "" {
+		absPath, err := filepath.Abs(*dataFile)
+		if err != nil {
+			return fmt.Errorf("invalid data file path: %w", err)
+		}
+		*dataFile = absPath
+	}
+	if *envFile != "" {
+		if _, err := os.Stat(*envFile); err != nil {
+			return fmt.Errorf("invalid env file: %w", err)
+		}
+	}
+
+	overrides, err := loadVariableOverrides(*varFile, *varPairs)
+	if err != nil {
+		return err
+	}
+
 	if info.IsDir() {
 		runner := NewWorkflowRunner(a.config, a.logger)
 		runner.SetFailFast(*failFast)
-		err := runner.RunWorkflows(path, *parallelism, *recursive, *htmlReportEnabled, *htmlReportPath)
+		runner.SetDataFile(*dataFile)
+		runner.SetEnvironment(*environment, overrides)
+		runner.SetEnvFile(*envFile)
+		runner.SetUpdateSnapshots(*updateSnapshots)
+		if fs.Changed("seed") {
+			runner.SetSeed(*seed)
+		}
+		err := runner.RunWorkflows(ctx, path, *parallelism, *recursive, *htmlReportEnabled, *htmlReportPath)
 
 		// Generate HTML report if requested (for directory runs, report is generated inside RunWorkflows)
 		if *htmlReportEnabled && err == nil {
@@ -255,6 +308,14 @@ func (a *App) handleRun(args []string) error {
 
 		// Set fail-fast mode
 		executor.SetFailFast(*failFast)
+		executor.SetDataFile(*dataFile)
+		executor.SetEnvironment(*environment)
+		executor.SetOverrides(overrides)
+		executor.SetEnvFile(*envFile)
+		executor.SetUpdateSnapshots(*updateSnapshots)
+		if fs.Changed("seed") {
+			executor.SetSeed(*seed)
+		}
 
 		// Set MCP mode if enabled
 		if a.mcpMode {
@@ -313,28 +374,40 @@ func (a *App) handleRun(args []string) error {
 			})
 		}
 
-		results, err := executor.Execute(wf)
+		results, execErr := executor.Execute(ctx, wf)
 
 		// Stop and complete progress reporter
 		if progressReporter != nil {
 			progressReporter.Complete()
 		}
 
-		if err != nil {
+		if execErr != nil {
 			if !*verbose && !a.mcpMode {
-				fmt.Printf("✗ Workflow execution failed: %v\n", err)
+				fmt.Printf("✗ Workflow execution failed: %v\n", execErr)
 			} else if a.mcpMode {
 				// In MCP mode, output errors to stderr
-				fmt.Fprintf(os.Stderr, "✗ Workflow execution failed: %v\n", err)
+				fmt.Fprintf(os.Stderr, "✗ Workflow execution failed: %v\n", execErr)
+			}
+			// Nothing ran, nothing to report
+			if len(results) == 0 {
+				return fmt.Errorf("workflow execution failed: %w", execErr)
 			}
-			return fmt.Errorf("workflow execution failed: %w", err)
 		}
 
 		// Workflow completion is now shown by progress reporter
-		hasFailures := a.printResults(results)
+		hasFailures := a.printResults(results, *environment, executor.Seed())
+		if !a.mcpMode {
+			printCaptures(a.colors, "", executor.Redactor().Map(executor.Captured()))
+			printCoverage(a.colors, executor.OpenAPICoverage())
+		}
 
 		// Send results to MCP if in MCP mode
 		if a.mcpMode && a.mcpOutput != nil {
+			for _, result := range results {
+				for _, warning := range result.Warnings {
+					a.mcpOutput.SendLog("warning", "Validation warning", map[string]interface{}{"step": result.DisplayName(), "warning": warning})
+				}
+			}
 			a.mcpOutput.SendResult(results)
 		}
 
@@ -347,7 +420,7 @@ func (a *App) handleRun(args []string) error {
 				workflowName = filepath.Base(path)
 			}
 
-			if err := a.generateHTMLReport(results, workflowName, path, reportPath); err != nil {
+			if err := a.generateHTMLReport(results, executor.Redactor().Map(executor.Captured()), workflowName, path, *environment, executor.Seed(), reportPath); err != nil {
 				if a.mcpMode && a.mcpOutput != nil {
 					a.mcpOutput.SendLog("warning", "Failed to generate HTML report", map[string]interface{}{"error": err.Error()})
 				} else {
@@ -366,6 +439,9 @@ func (a *App) handleRun(args []string) error {
 			}
 		}
 
+		if execErr != nil {
+			return fmt.Errorf("workflow execution failed: %w", execErr)
+		}
 		if hasFailures {
 			return fmt.Errorf("workflow execution completed with failures")
 		}
@@ -506,6 +582,67 @@ func (a *App) handleCodex(args []string) error {
 	return chat.Start(ctx)
 }
 
+// defaultKeystorePath is the keystore used by the secrets command without --keystore
+const defaultKeystorePath = ".stepwise/keystore.yml"
+
+// handleSecrets handles the secrets command. Values are read from stdin so they do
+// not end up in the shell history.
+func (a *App) handleSecrets(args []string) error {
+	fs := flag.NewFlagSet("secrets", flag.ContinueOnError)
+	keystorePath := fs.StringP("keystore", "k", defaultKeystorePath, "Path of the keystore file")
+	_ = fs.Parse(args)
+
+	rest := fs.Args()
+	if len(rest) == 0 {
+		return fmt.Errorf("usage: stepwise secrets <set|delete|list> [name] [--keystore path]")
+	}
+	action := rest[0]
+	if (action == "set" || action == "delete") && len(rest) < 2 {
+		return fmt.Errorf("secret name is required: stepwise secrets %s <name>", action)
+	}
+
+	// Only set creates a new keystore
+	if action != "set" {
+		if _, err := os.Stat(*keystorePath); err != nil {
+			return fmt.Errorf("failed to open keystore: %w", err)
+		}
+	}
+	ks, err := secrets.OpenKeystore(*keystorePath, os.Getenv(secrets.PassphraseEnv))
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "set":
+		value, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read secret value: %w", err)
+		}
+		if err := ks.Set(rest[1], strings.TrimRight(string(value), "\r\n")); err != nil {
+			return err
+		}
+		if err := ks.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("%s Stored secret %s in %s\n", a.colors.Green("✓"), a.colors.Cyan(rest[1]), *keystorePath)
+	case "delete":
+		if !ks.Delete(rest[1]) {
+			return fmt.Errorf("secret %s not found in %s", rest[1], *keystorePath)
+		}
+		if err := ks.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("%s Deleted secret %s from %s\n", a.colors.Green("✓"), a.colors.Cyan(rest[1]), *keystorePath)
+	case "list":
+		for _, name := range ks.Names() {
+			fmt.Println(name)
+		}
+	default:
+		return fmt.Errorf("unknown secrets action: %s", action)
+	}
+	return nil
+}
+
 // showHelp shows the help message
 func (a *App) showHelp() error {
 	fmt.Printf("%s\n", a.colors.Bold("Stepwise - API Testing Framework"))
@@ -521,6 +658,7 @@ func (a *App) showHelp() error {
 	fmt.Printf("  %s    %s\n", a.colors.Green("init"), "Initialize a new project")
 	fmt.Printf("  %s    %s\n", a.colors.Green("generate"), "Generate test data")
 	fmt.Printf("  %s    %s\n", a.colors.Green("codex"), "AI assistant for creating workflows (requires codex CLI)")
+	fmt.Printf("  %s    %s\n", a.colors.Green("secrets"), "Manage the encrypted secrets keystore (set, delete, list)")
 	fmt.Printf("  %s    %s\n", a.colors.Green("help"), "Show this help message")
 	fmt.Printf("  %s    %s\n", a.colors.Green("version"), "Show version information")
 
@@ -528,8 +666,10 @@ func (a *App) showHelp() error {
 	fmt.Printf("  %s\n", "stepwise run workflow.yml")
 	fmt.Printf("  %s\n", "stepwise run --verbose workflow.yml")
 	fmt.Printf("  %s\n", "stepwise run --parallel 4 --recursive ./tests")
+	fmt.Printf("  %s\n", "stepwise run --env staging --var user_id=42 workflow.yml")
 	fmt.Printf("  %s\n", "stepwise validate workflow.yml")
 	fmt.Printf("  %s\n", "stepwise info workflow.yml")
+	fmt.Printf("  %s\n", "echo -n $TOKEN | stepwise secrets set api_token")
 	fmt.Printf("  %s\n", "stepwise codex ./examples")
 	fmt.Printf("  %s\n", "stepwise codex --model gpt-4o .")
 
@@ -544,6 +684,14 @@ func (a *App) showHelp() error {
 	fmt.Printf("  %s    %s\n", a.colors.Cyan("--fail-fast, -f"), "Stop execution on first test failure")
 	fmt.Printf("  %s    %s\n", a.colors.Cyan("--html-report"), "Generate HTML report (default: test-report_TIMESTAMP.html)")
 	fmt.Printf("  %s    %s\n", a.colors.Cyan("--html-report-path"), "Path for HTML report file (used with --html-report)")
+	fmt.Printf("  %s    %s\n", a.colors.Cyan("--timeout"), "Deadline for the whole run, e.g. 5m (0 means no limit)")
+	fmt.Printf("  %s    %s\n", a.colors.Cyan("--data"), "Data file (CSV, JSON, YAML or JSONL); runs each workflow once per row")
+	fmt.Printf("  %s    %s\n", a.colors.Cyan("--env, -e"), "Environment to run against (default: $STEPWISE_ENV)")
+	fmt.Printf("  %s    %s\n", a.colors.Cyan("--var-file"), "YAML or JSON file with variable overrides")
+	fmt.Printf("  %s    %s\n", a.colors.Cyan("--var"), "Variable override as key=value (repeatable)")
+	fmt.Printf("  %s    %s\n", a.colors.Cyan("--env-file"), ".env file for {{env.*}}, on top of the .env next to each workflow")
+	fmt.Printf("  %s    %s\n", a.colors.Cyan("--seed"), "Seed of {{faker.*}} data, printed after every run")
+	fmt.Printf("  %s    %s\n", a.colors.Cyan("--update-snapshots"), "Rewrite snapshot files that differ from the responses")
 
 	fmt.Printf("\n%s\n", a.colors.Bold("WORKFLOW FILES:"))
 	fmt.Printf("  Stepwise supports YAML workflow files with the following features:\n")
@@ -554,6 +702,10 @@ func (a *App) showHelp() error {
 	fmt.Printf("  • Retry logic\n")
 	fmt.Printf("  • Parallel execution\n")
 	fmt.Printf("  • Component imports\n")
+	fmt.Printf("  • Data-driven runs from CSV, JSON, YAML and JSONL files\n")
+	fmt.Printf("  • Environment profiles and variable overrides\n")
+	fmt.Printf("  • .env files and {{secret.*}} from files, keystores and commands\n")
+	fmt.Printf("  • Reproducible fake data with --seed and faker locales\n")
 
 	return nil
 }
@@ -565,7 +717,7 @@ func (a *App) showVersion() error {
 }
 
 // printResults prints test results and returns true if there were failures
-func (a *App) printResults(results []workflow.TestResult) bool {
+func (a *App) printResults(results []workflow.TestResult, environment string, seed *int64) bool {
 	if !a.mcpMode {
 		fmt.Println("\n" + a.colors.Bold("Test Results:"))
 		fmt.Println(a.colors.Dim("============="))
@@ -573,25 +725,42 @@ func (a *App) printResults(results []workflow.TestResult) bool {
 
 	passed := 0
 	failed := 0
+	skipped := 0
+	warnings := 0
 	totalDuration := 0
 
+	row := ""
 	for _, result := range results {
 		duration := int(result.Duration.Milliseconds())
 		totalDuration += duration
+		warnings += len(result.Warnings)
 
 		if !a.mcpMode {
+			// Data-driven workflows: results are grouped per data row
+			if result.Row != row {
+				row = result.Row
+				fmt.Printf("\n%s %s\n", a.colors.Cyan("---"), a.colors.Magenta(row))
+			}
 			if result.Status == "passed" {
 				fmt.Printf("%s %s (%dms)\n",
 					a.colors.Green("✓"),
-					a.colors.Cyan(a.colors.Bold(result.Name)),
+					a.colors.Cyan(a.colors.Bold(result.DisplayName())),
 					duration)
 				if result.PrintText != "" {
 					fmt.Printf("  %s\n", a.colors.Dim(result.PrintText))
 				}
+			} else if result.Status == "skipped" {
+				fmt.Printf("%s %s",
+					a.colors.Yellow("○"),
+					a.colors.Cyan(a.colors.Bold(result.DisplayName())))
+				if result.Error != "" {
+					fmt.Printf(" - %s", a.colors.Yellow(result.Error))
+				}
+				fmt.Println()
 			} else {
 				fmt.Printf("%s %s (%dms) - %s\n",
 					a.colors.Red("✗"),
-					a.colors.Cyan(a.colors.Bold(result.Name)),
+					a.colors.Cyan(a.colors.Bold(result.DisplayName())),
 					duration,
 					a.colors.Red(result.Error))
 				if result.PrintText != "" {
@@ -606,15 +775,22 @@ func (a *App) printResults(results []workflow.TestResult) bool {
 			for _, v := range result.Validations {
 				icon := a.colors.Green("✓")
 				lineColor := a.colors.Green
-				if !v.Passed {
+				if v.Warning() {
+					icon = a.colors.Yellow("!")
+					lineColor = a.colors.Yellow
+				} else if !v.Passed {
 					icon = a.colors.Red("✗")
 					lineColor = a.colors.Red
 				}
 				msg := fmt.Sprintf("    %s %s: expected %v, got %v", icon, v.Type, v.Expected, v.Actual)
-				if v.Error != "" && !v.Passed {
+				if len(v.Diff) > 0 {
+					// The differences are printed below instead of the whole values
+					msg = fmt.Sprintf("    %s %s: %s", icon, v.Type, countDifferences(v.Diff))
+				} else if v.Error != "" && !v.Passed {
 					msg += " (" + v.Error + ")"
 				}
 				fmt.Println(lineColor(msg))
+				printDiff(a.colors, "      ", v.Diff)
 			}
 		}
 
@@ -625,7 +801,11 @@ func (a *App) printResults(results []workflow.TestResult) bool {
 				if repeatResult.Status != "passed" {
 					icon = a.colors.Red("✗")
 				}
-				fmt.Printf("  %s Iteration %d (%dms)\n", icon, i+1, int(repeatResult.Duration.Milliseconds()))
+				if repeatResult.Item != "" {
+					fmt.Printf("  %s Iteration %d %s (%dms)\n", icon, i+1, a.colors.Dim(repeatResult.Item), int(repeatResult.Duration.Milliseconds()))
+				} else {
+					fmt.Printf("  %s Iteration %d (%dms)\n", icon, i+1, int(repeatResult.Duration.Milliseconds()))
+				}
 				if repeatResult.Error != "" {
 					fmt.Printf("    %s %s\n", a.colors.Red("Error:"), a.colors.Red(repeatResult.Error))
 				}
@@ -634,21 +814,29 @@ func (a *App) printResults(results []workflow.TestResult) bool {
 					for _, v := range repeatResult.Validations {
 						icon := a.colors.Green("✓")
 						lineColor := a.colors.Green
-						if !v.Passed {
+						if v.Warning() {
+							icon = a.colors.Yellow("!")
+							lineColor = a.colors.Yellow
+						} else if !v.Passed {
 							icon = a.colors.Red("✗")
 							lineColor = a.colors.Red
 						}
 						msg := fmt.Sprintf("      %s %s: expected %v, got %v", icon, v.Type, v.Expected, v.Actual)
-						if v.Error != "" && !v.Passed {
+						if len(v.Diff) > 0 {
+							msg = fmt.Sprintf("      %s %s: %s", icon, v.Type, countDifferences(v.Diff))
+						} else if v.Error != "" && !v.Passed {
 							msg += " (" + v.Error + ")"
 						}
 						fmt.Println(lineColor(msg))
+						printDiff(a.colors, "        ", v.Diff)
 					}
 				}
 			}
 		}
 		if result.Status == "passed" {
 			passed++
+		} else if result.Status == "skipped" {
+			skipped++
 		} else {
 			failed++
 		}
@@ -656,9 +844,21 @@ func (a *App) printResults(results []workflow.TestResult) bool {
 
 	if !a.mcpMode {
 		fmt.Printf("\n%s\n", a.colors.Bold("Summary:"))
+		if environment != "" {
+			fmt.Printf("- Environment: %s\n", a.colors.Magenta(environment))
+		}
+		if seed != nil {
+			fmt.Printf("- Seed: %d\n", *seed)
+		}
 		fmt.Printf("- Total: %d tests\n", len(results))
 		fmt.Printf("- Passed: %s\n", a.colors.Green(fmt.Sprintf("%d", passed)))
 		fmt.Printf("- Failed: %s\n", a.colors.Red(fmt.Sprintf("%d", failed)))
+		if skipped > 0 {
+			fmt.Printf("- Skipped: %s\n", a.colors.Yellow(fmt.Sprintf("%d", skipped)))
+		}
+		if warnings > 0 {
+			fmt.Printf("- Warnings: %s\n", a.colors.Yellow(fmt.Sprintf("%d", warnings)))
+		}
 		fmt.Printf("- Duration: %dms\n", totalDuration)
 	}
 
@@ -683,6 +883,121 @@ func (a *App) printWorkflowInfo(wf *workflow.Workflow) {
 }
 
 // generateHTMLReport generates an HTML report from test results
-func (a *App) generateHTMLReport(results []workflow.TestResult, workflowName string, workflowFile string, outputPath string) error {
-	return report.GenerateHTMLReport(results, workflowName, workflowFile, outputPath)
+func (a *App) generateHTMLReport(results []workflow.TestResult, captures map[string]interface{}, workflowName string, workflowFile string, environment string, seed *int64, outputPath string) error {
+	return report.GenerateHTMLReport(results, captures, workflowName, workflowFile, environment, seed, outputPath)
+}
+
+// loadVariableOverrides merges the variables of --var-file and --var, --var winning
+func loadVariableOverrides(varFile string, pairs []string) (map[string]interface{}, error) {
+	overrides := make(map[string]interface{})
+	if varFile != "" {
+		fileVars, err := workflow.LoadVariablesFile(varFile)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range fileVars {
+			overrides[key] = value
+		}
+	}
+
+	pairVars, err := workflow.ParseVariableOverrides(pairs)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range pairVars {
+		overrides[key] = value
+	}
+	return overrides, nil
+}
+
+// printCaptures prints the values of workflow captures, sorted by name
+func printCaptures(c *Colors, indent string, captures map[string]interface{}) {
+	if len(captures) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(captures))
+	for name := range captures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("%s%s\n", indent, c.Bold("Captured:"))
+	for _, name := range names {
+		value := fmt.Sprintf("%v", captures[name])
+		if _, isString := captures[name].(string); !isString {
+			if data, err := json.Marshal(captures[name]); err == nil {
+				value = string(data)
+			}
+		}
+		fmt.Printf("%s- %s: %s\n", indent, c.Cyan(name), value)
+	}
+}
+
+// printDiff prints the differences of a validation from its expected value, one per line
+func printDiff(c *Colors, indent string, diff []jsondiff.Difference) {
+	for _, d := range diff {
+		switch d.Kind {
+		case jsondiff.Missing:
+			fmt.Printf("%s%s %s\n", indent, c.Red("-"), d)
+		case jsondiff.Extra:
+			fmt.Printf("%s%s %s\n", indent, c.Green("+"), d)
+		default:
+			fmt.Printf("%s%s %s\n", indent, c.Yellow("~"), d)
+		}
+	}
+}
+
+// countDifferences formats the number of differences, such as "1 difference"
+func countDifferences(diff []jsondiff.Difference) string {
+	if len(diff) == 1 {
+		return "1 difference"
+	}
+	return fmt.Sprintf("%d differences", len(diff))
+}
+
+// printCoverage prints the calls made to each operation of an OpenAPI description
+func printCoverage(c *Colors, coverage *openapi.Coverage) {
+	if coverage == nil {
+		return
+	}
+
+	fmt.Printf("\n%s\n", c.Bold(fmt.Sprintf("OpenAPI coverage (%s): %d/%d operations",
+		filepath.Base(coverage.Spec), coverage.Covered(), len(coverage.Operations))))
+	for _, op := range coverage.Operations {
+		if op.Calls == 0 {
+			fmt.Printf("%s %s %s\n", c.Red("✗"), op.Operation, c.Dim("- not called"))
+			continue
+		}
+
+		icon := c.Green("✓")
+		calls := fmt.Sprintf("%d calls", op.Calls)
+		if op.Calls == 1 {
+			calls = "1 call"
+		}
+		if op.Failed > 0 {
+			icon = c.Yellow("!")
+			calls += c.Red(fmt.Sprintf(", %d failed", op.Failed))
+		}
+		statuses := make([]string, 0, len(op.Statuses))
+		for _, status := range op.Seen() {
+			if count := op.Statuses[status]; count > 1 {
+				statuses = append(statuses, fmt.Sprintf("%d ×%d", status, count))
+			} else {
+				statuses = append(statuses, fmt.Sprintf("%d", status))
+			}
+		}
+		line := fmt.Sprintf("%s %s - %s - status %s", icon, op.Operation, calls, strings.Join(statuses, ", "))
+		if missing := op.Missing(); len(missing) > 0 {
+			line += c.Dim(" - not returned: " + strings.Join(missing, ", "))
+		}
+		fmt.Println(line)
+	}
+	if coverage.Undocumented > 0 {
+		calls := fmt.Sprintf("%d calls", coverage.Undocumented)
+		if coverage.Undocumented == 1 {
+			calls = "1 call"
+		}
+		fmt.Printf("%s %s\n", c.Red("✗"), c.Red(calls+" to undocumented endpoints"))
+	}
 }
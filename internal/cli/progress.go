@@ -160,7 +160,7 @@ func (p *LiveProgressReporter) render() {
 		p.totalSteps)
 
 	// Statistics
-	fmt.Printf("%s %s  %s %s  %s %d\n",
+	fmt.Printf("%s %s  %s  %s\n",
 		p.colors.Bold("Results:"),
 		p.colors.Green(fmt.Sprintf("✓ %d passed", p.passed)),
 		p.colors.Red(fmt.Sprintf("✗ %d failed", p.failed)),
@@ -11,6 +11,7 @@ import (
 
 	"github.com/cjp2600/stepwise/internal/config"
 	"github.com/cjp2600/stepwise/internal/logger"
+	"github.com/cjp2600/stepwise/internal/openapi"
 	"github.com/cjp2600/stepwise/internal/report"
 	"github.com/cjp2600/stepwise/internal/workflow"
 )
@@ -23,6 +24,14 @@ type WorkflowRunner struct {
 	spinner  *Spinner
 	verbose  bool
 	failFast bool
+	dataFile string
+
+	environment string                 // Selected environment, passed to every executor
+	overrides   map[string]interface{} // Variables from --var-file and --var
+	envFile     string                 // .env file given with --env-file
+	seed        *int64                 // Faker seed given with --seed
+
+	updateSnapshots bool // Rewrite snapshots that differ, with --update-snapshots
 }
 
 // NewWorkflowRunner creates a new workflow runner
@@ -47,8 +56,35 @@ func (r *WorkflowRunner) SetFailFast(failFast bool) {
 	r.failFast = failFast
 }
 
-// RunWorkflows runs all workflow files in the given path
-func (r *WorkflowRunner) RunWorkflows(path string, parallelism int, recursive bool, htmlReportEnabled bool, htmlReportPath string) error {
+// SetDataFile sets a data file every workflow is run against once per row
+func (r *WorkflowRunner) SetDataFile(path string) {
+	r.dataFile = path
+}
+
+// SetEnvironment sets the environment and variable overrides every workflow is run with
+func (r *WorkflowRunner) SetEnvironment(environment string, overrides map[string]interface{}) {
+	r.environment = environment
+	r.overrides = overrides
+}
+
+// SetEnvFile sets a .env file loaded for every workflow
+func (r *WorkflowRunner) SetEnvFile(path string) {
+	r.envFile = path
+}
+
+// SetSeed sets the faker seed every workflow is run with
+func (r *WorkflowRunner) SetSeed(seed int64) {
+	r.seed = &seed
+}
+
+// SetUpdateSnapshots makes every workflow rewrite the snapshots that differ
+func (r *WorkflowRunner) SetUpdateSnapshots(update bool) {
+	r.updateSnapshots = update
+}
+
+// RunWorkflows runs all workflow files in the given path.
+// Cancelling ctx stops in-flight workflows; results collected so far are still reported.
+func (r *WorkflowRunner) RunWorkflows(ctx context.Context, path string, parallelism int, recursive bool, htmlReportEnabled bool, htmlReportPath string) error {
 	if !r.verbose {
 		fmt.Println("Loading workflow...")
 	} else {
@@ -82,14 +118,23 @@ func (r *WorkflowRunner) RunWorkflows(path string, parallelism int, recursive bo
 		file         string
 		workflowName string
 		results      []workflow.TestResult
+		captures     map[string]interface{}
+		seed         *int64
+		coverage     *openapi.Coverage
 		err          error
 	}
 
 	resultsCh := make(chan wfResult, len(workflowFiles))
 
 	if parallelism <= 1 {
+		// Values of workflow captures are passed on to the workflows that run later
+		exported := make(map[string]interface{})
+
 		// Sequential (old behavior)
 		for i, file := range workflowFiles {
+			if ctx.Err() != nil {
+				break
+			}
 			if r.verbose {
 				r.logger.Info("Running workflow", "file", file, "progress", fmt.Sprintf("%d/%d", i+1, len(workflowFiles)))
 			}
@@ -113,6 +158,15 @@ func (r *WorkflowRunner) RunWorkflows(path string, parallelism int, recursive bo
 
 			executor := workflow.NewExecutor(r.config, r.logger)
 			executor.SetFailFast(r.failFast)
+			executor.SetDataFile(r.dataFile)
+			executor.SetEnvironment(r.environment)
+			executor.SetOverrides(r.overrides)
+			executor.SetEnvFile(r.envFile)
+			executor.SetUpdateSnapshots(r.updateSnapshots)
+			if r.seed != nil {
+				executor.SetSeed(*r.seed)
+			}
+			executor.SetVariables(exported)
 			// Note: MCP mode is not set in runner - it's only for direct CLI execution
 
 			// Setup live progress reporter if not in verbose mode
@@ -147,7 +201,11 @@ func (r *WorkflowRunner) RunWorkflows(path string, parallelism int, recursive bo
 				}
 			}
 
-			res, err := executor.Execute(wf)
+			res, err := executor.Execute(ctx, wf)
+			captures := executor.Captured()
+			for name, value := range captures {
+				exported[name] = value
+			}
 
 			// Stop and complete progress reporter
 			if progressReporter != nil {
@@ -184,7 +242,7 @@ func (r *WorkflowRunner) RunWorkflows(path string, parallelism int, recursive bo
 				}
 			}
 
-			resultsCh <- wfResult{file: file, workflowName: wf.Name, results: res, err: err}
+			resultsCh <- wfResult{file: file, workflowName: wf.Name, results: res, captures: executor.Redactor().Map(captures), seed: executor.Seed(), coverage: executor.OpenAPICoverage(), err: err}
 
 			// Check fail-fast mode in sequential execution
 			if r.failFast && err != nil {
@@ -202,7 +260,7 @@ func (r *WorkflowRunner) RunWorkflows(path string, parallelism int, recursive bo
 		}
 
 		// Create context for fail-fast cancellation
-		ctx, cancel := context.WithCancel(context.Background())
+		failFastCtx, cancel := context.WithCancel(ctx)
 		defer cancel()
 
 		fileCh := make(chan string, len(workflowFiles))
@@ -223,7 +281,7 @@ func (r *WorkflowRunner) RunWorkflows(path string, parallelism int, recursive bo
 				for file := range fileCh {
 					// Check if context is cancelled (fail-fast triggered)
 					select {
-					case <-ctx.Done():
+					case <-failFastCtx.Done():
 						if r.verbose {
 							r.logger.Info("Skipping workflow due to fail-fast", "file", file)
 						}
@@ -262,9 +320,17 @@ func (r *WorkflowRunner) RunWorkflows(path string, parallelism int, recursive bo
 
 					executor := workflow.NewExecutor(r.config, workflowLogger)
 					executor.SetFailFast(r.failFast)
+					executor.SetDataFile(r.dataFile)
+					executor.SetEnvironment(r.environment)
+					executor.SetOverrides(r.overrides)
+					executor.SetEnvFile(r.envFile)
+					executor.SetUpdateSnapshots(r.updateSnapshots)
+					if r.seed != nil {
+						executor.SetSeed(*r.seed)
+					}
 					// Note: MCP mode is not set in runner - it's only for direct CLI execution
-					res, err := executor.Execute(wf)
-					resultsCh <- wfResult{file: file, workflowName: wf.Name, results: res, err: err}
+					res, err := executor.Execute(ctx, wf)
+					resultsCh <- wfResult{file: file, workflowName: wf.Name, results: res, captures: executor.Redactor().Map(executor.Captured()), seed: executor.Seed(), coverage: executor.OpenAPICoverage(), err: err}
 
 					// Check fail-fast mode after workflow execution
 					if r.failFast && err != nil {
@@ -305,6 +371,9 @@ func (r *WorkflowRunner) RunWorkflows(path string, parallelism int, recursive bo
 	totalFailed := 0
 	totalDuration := 0
 	workflowGroups := make([]report.WorkflowGroup, 0)
+	// Coverage of each OpenAPI description, summed over the workflows that use it
+	var coverages []*openapi.Coverage
+	coverageBySpec := make(map[string]*openapi.Coverage)
 
 	for rres := range resultsCh {
 		// Even if there was an error loading the workflow, we still want to include
@@ -315,9 +384,20 @@ func (r *WorkflowRunner) RunWorkflows(path string, parallelism int, recursive bo
 			continue
 		}
 
+		if rres.coverage != nil {
+			spec, _ := filepath.Abs(rres.coverage.Spec)
+			if total, ok := coverageBySpec[spec]; ok {
+				total.Merge(rres.coverage)
+			} else {
+				coverageBySpec[spec] = rres.coverage
+				coverages = append(coverages, rres.coverage)
+			}
+		}
+
 		// Print results for this workflow (if any)
 		if len(rres.results) > 0 {
-			r.printWorkflowResults(rres.file, rres.workflowName, rres.results)
+			r.printWorkflowResults(rres.file, rres.workflowName, rres.seed, rres.results)
+			printCaptures(r.colors, "  ", rres.captures)
 		}
 
 		// Add all results to totalResults (for HTML report)
@@ -326,7 +406,7 @@ func (r *WorkflowRunner) RunWorkflows(path string, parallelism int, recursive bo
 			totalResults = append(totalResults, result)
 			if result.Status == "passed" {
 				totalPassed++
-			} else {
+			} else if result.Status != "skipped" {
 				totalFailed++
 			}
 			totalDuration += int(result.Duration.Milliseconds())
@@ -338,6 +418,8 @@ func (r *WorkflowRunner) RunWorkflows(path string, parallelism int, recursive bo
 				FileName:     filepath.Base(rres.file),
 				WorkflowName: rres.workflowName,
 				Results:      rres.results,
+				Captures:     rres.captures,
+				Seed:         rres.seed,
 			})
 		}
 	}
@@ -348,6 +430,13 @@ func (r *WorkflowRunner) RunWorkflows(path string, parallelism int, recursive bo
 	}
 
 	r.printSummary(len(workflowFiles), totalPassed, totalFailed, totalDuration)
+	for _, coverage := range coverages {
+		printCoverage(r.colors, coverage)
+	}
+
+	if ctx.Err() != nil {
+		fmt.Printf("%s %s\n", r.colors.Yellow("[WARNING]"), r.colors.Yellow(fmt.Sprintf("Run stopped early (%v): results are partial", ctx.Err())))
+	}
 
 	// Generate HTML report if requested
 	if htmlReportEnabled {
@@ -359,13 +448,16 @@ func (r *WorkflowRunner) RunWorkflows(path string, parallelism int, recursive bo
 
 		workflowName := fmt.Sprintf("Multiple Workflows (%d files)", len(workflowFiles))
 		// Use grouped report for multiple files
-		if err := report.GenerateHTMLReportFromGroups(workflowGroups, workflowName, reportPath); err != nil {
+		if err := report.GenerateHTMLReportFromGroups(workflowGroups, workflowName, r.environment, reportPath); err != nil {
 			fmt.Printf("%s %s: %v\n", r.colors.Yellow("[WARNING]"), r.colors.Yellow("Failed to generate HTML report"), err)
 		} else {
 			fmt.Printf("%s %s\n", r.colors.Green("[INFO]"), r.colors.Green(fmt.Sprintf("HTML report generated: %s", reportPath)))
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("workflow execution stopped: %w", err)
+	}
 	if totalFailed > 0 {
 		return fmt.Errorf("workflow execution completed with %d failures", totalFailed)
 	}
@@ -459,7 +551,7 @@ func (r *WorkflowRunner) isWorkflowFile(filePath string) bool {
 }
 
 // printWorkflowResults prints results for a single workflow
-func (r *WorkflowRunner) printWorkflowResults(filePath string, workflowName string, results []workflow.TestResult) {
+func (r *WorkflowRunner) printWorkflowResults(filePath string, workflowName string, seed *int64, results []workflow.TestResult) {
 	// Format display name with workflow name and file
 	var workflowDisplayName string
 	if workflowName != "" {
@@ -475,14 +567,25 @@ func (r *WorkflowRunner) printWorkflowResults(filePath string, workflowName stri
 		r.colors.Cyan("==="),
 		workflowDisplayName,
 		r.colors.Cyan("==="))
+	if seed != nil {
+		fmt.Printf("%s\n", r.colors.Dim(fmt.Sprintf("Seed: %d", *seed)))
+	}
 
 	passed := 0
 	failed := 0
+	skipped := 0
 	duration := 0
 
+	row := ""
 	for _, result := range results {
 		duration += int(result.Duration.Milliseconds())
 
+		// Data-driven workflows: results are grouped per data row
+		if result.Row != row {
+			row = result.Row
+			fmt.Printf("%s %s\n", r.colors.Cyan("---"), r.colors.Magenta(row))
+		}
+
 		// Handle repeat results
 		if result.RepeatCount > 0 {
 			r.printRepeatResults(result)
@@ -491,12 +594,19 @@ func (r *WorkflowRunner) printWorkflowResults(filePath string, workflowName stri
 			} else {
 				failed++
 			}
+		} else if result.Status == "skipped" {
+			fmt.Printf("%s %s", r.colors.Yellow("○"), r.colors.Bold(result.DisplayName()))
+			if result.Error != "" {
+				fmt.Printf(" - %s", r.colors.Yellow(result.Error))
+			}
+			fmt.Println()
+			skipped++
 		} else {
 			// Regular step result
 			if result.Status == "passed" {
 				fmt.Printf("%s %s (%dms)\n",
 					r.colors.Green("✓"),
-					r.colors.Bold(result.Name),
+					r.colors.Bold(result.DisplayName()),
 					int(result.Duration.Milliseconds()))
 				// Выводим print-текст отдельным блоком, как валидации
 				if result.PrintText != "" {
@@ -504,22 +614,27 @@ func (r *WorkflowRunner) printWorkflowResults(filePath string, workflowName stri
 				}
 				for _, v := range result.Validations {
 					var valDesc string
-					if v.Error != "" {
+					if len(v.Diff) > 0 {
+						valDesc = fmt.Sprintf("%s: %s", v.Type, countDifferences(v.Diff))
+					} else if v.Error != "" {
 						valDesc = v.Error
 					} else {
 						valDesc = fmt.Sprintf("%s: expected %v, got %v", v.Type, v.Expected, v.Actual)
 					}
 					if v.Passed {
 						fmt.Printf("    %s %s\n", r.colors.Green("✓"), r.colors.Dim(valDesc))
+					} else if v.Warning() {
+						fmt.Printf("    %s %s\n", r.colors.Yellow("!"), r.colors.Dim(valDesc))
 					} else {
 						fmt.Printf("    %s %s\n", r.colors.Red("✗"), r.colors.Dim(valDesc))
 					}
+					printDiff(r.colors, "      ", v.Diff)
 				}
 				passed++
 			} else {
 				fmt.Printf("%s %s (%dms) - %s\n",
 					r.colors.Red("✗"),
-					r.colors.Bold(result.Name),
+					r.colors.Bold(result.DisplayName()),
 					int(result.Duration.Milliseconds()),
 					r.colors.Red(result.Error))
 				if result.PrintText != "" {
@@ -527,26 +642,36 @@ func (r *WorkflowRunner) printWorkflowResults(filePath string, workflowName stri
 				}
 				for _, v := range result.Validations {
 					var valDesc string
-					if v.Error != "" {
+					if len(v.Diff) > 0 {
+						valDesc = fmt.Sprintf("%s: %s", v.Type, countDifferences(v.Diff))
+					} else if v.Error != "" {
 						valDesc = v.Error
 					} else {
 						valDesc = fmt.Sprintf("%s: expected %v, got %v", v.Type, v.Expected, v.Actual)
 					}
 					if v.Passed {
 						fmt.Printf("    %s %s\n", r.colors.Green("✓"), r.colors.Dim(valDesc))
+					} else if v.Warning() {
+						fmt.Printf("    %s %s\n", r.colors.Yellow("!"), r.colors.Dim(valDesc))
 					} else {
 						fmt.Printf("    %s %s\n", r.colors.Red("✗"), r.colors.Dim(valDesc))
 					}
+					printDiff(r.colors, "      ", v.Diff)
 				}
 				failed++
 			}
 		}
 	}
 
-	fmt.Printf("  %s: %s passed, %s failed, %dms\n",
+	skippedInfo := ""
+	if skipped > 0 {
+		skippedInfo = fmt.Sprintf(", %s skipped", r.colors.Yellow(fmt.Sprintf("%d", skipped)))
+	}
+	fmt.Printf("  %s: %s passed, %s failed%s, %dms\n",
 		r.colors.Dim("Summary"),
 		r.colors.Green(fmt.Sprintf("%d", passed)),
 		r.colors.Red(fmt.Sprintf("%d", failed)),
+		skippedInfo,
 		duration)
 }
 
@@ -577,7 +702,7 @@ func (r *WorkflowRunner) printPollSummary(attempts int, success bool) {
 func (r *WorkflowRunner) printRepeatResults(result workflow.TestResult) {
 	fmt.Printf("%s %s (repeat: %d iterations)\n",
 		r.colors.Blue("🔄"),
-		r.colors.Bold(result.Name),
+		r.colors.Bold(result.DisplayName()),
 		result.RepeatCount)
 
 	repeatPassed := 0
@@ -597,6 +722,9 @@ func (r *WorkflowRunner) printRepeatResults(result workflow.TestResult) {
 			r.colors.Dim(repeatResult.Name),
 			i+1,
 			int(repeatResult.Duration.Milliseconds()))
+		if repeatResult.Item != "" {
+			fmt.Printf("    %s %s\n", r.colors.Dim("Item:"), repeatResult.Item)
+		}
 
 		if repeatResult.Error != "" {
 			fmt.Printf("    %s %s\n",
@@ -625,6 +753,12 @@ func (r *WorkflowRunner) printSummary(workflowCount, passed, failed, duration in
 	fmt.Printf("%s\n", r.colors.Bold("OVERALL SUMMARY"))
 	fmt.Printf("%s\n", r.colors.Dim("==============="))
 	fmt.Printf("Workflows: %d\n", workflowCount)
+	if r.environment != "" {
+		fmt.Printf("Environment: %s\n", r.colors.Magenta(r.environment))
+	}
+	if r.seed != nil {
+		fmt.Printf("Seed: %d\n", *r.seed)
+	}
 	fmt.Printf("Tests Passed: %s\n", r.colors.Green(fmt.Sprintf("%d", passed)))
 	fmt.Printf("Tests Failed: %s\n", r.colors.Red(fmt.Sprintf("%d", failed)))
 	fmt.Printf("Total Duration: %dms\n", duration)
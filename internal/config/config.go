@@ -11,7 +11,7 @@ type Config struct {
 	LogLevel    string
 	Timeout     time.Duration
 	Parallel    int
-	Environment string
+	Environment string // Environment selected when run is given no --env
 	Output      string
 	Verbose     bool
 	Quiet       bool
@@ -24,7 +24,7 @@ func Load() (*Config, error) {
 		LogLevel:    getEnv("STEPWISE_LOG_LEVEL", "info"),
 		Timeout:     getEnvDuration("STEPWISE_TIMEOUT", 10*time.Second),
 		Parallel:    getEnvInt("STEPWISE_PARALLEL", 1),
-		Environment: getEnv("STEPWISE_ENV", "development"),
+		Environment: getEnv("STEPWISE_ENV", ""),
 		Output:      getEnv("STEPWISE_OUTPUT", "console"),
 		Verbose:     getEnvBool("STEPWISE_VERBOSE", false),
 		Quiet:       getEnvBool("STEPWISE_QUIET", false),
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -65,12 +66,12 @@ func (p *PostgresProvider) Connect(config *Config) error {
 }
 
 // ExecuteQuery executes a SQL query and returns results as JSON
-func (p *PostgresProvider) ExecuteQuery(query string) (interface{}, error) {
+func (p *PostgresProvider) ExecuteQuery(ctx context.Context, query string) (interface{}, error) {
 	if p.db == nil {
 		return nil, fmt.Errorf("database connection is not established")
 	}
 
-	rows, err := p.db.Query(query)
+	rows, err := p.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("query execution failed: %w", err)
 	}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"time"
 
 	"github.com/cjp2600/stepwise/internal/logger"
@@ -12,7 +13,7 @@ type Provider interface {
 	Connect(config *Config) error
 
 	// ExecuteQuery executes a SQL query and returns results as JSON
-	ExecuteQuery(query string) (interface{}, error)
+	ExecuteQuery(ctx context.Context, query string) (interface{}, error)
 
 	// Close closes the database connection
 	Close() error
@@ -74,8 +75,8 @@ func NewClient(config *Config, log *logger.Logger) (*Client, error) {
 	return client, nil
 }
 
-// Execute executes a database query
-func (c *Client) Execute(query string) (*Response, error) {
+// Execute executes a database query. The query is cancelled when ctx is done.
+func (c *Client) Execute(ctx context.Context, query string) (*Response, error) {
 	start := time.Now()
 
 	c.logger.Debug("Executing database query",
@@ -83,7 +84,7 @@ func (c *Client) Execute(query string) (*Response, error) {
 		"database", c.config.Database,
 		"query", query)
 
-	data, err := c.provider.ExecuteQuery(query)
+	data, err := c.provider.ExecuteQuery(ctx, query)
 	if err != nil {
 		return &Response{
 			Data:     nil,
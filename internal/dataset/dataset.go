@@ -0,0 +1,186 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported data formats
+const (
+	FormatCSV   = "csv"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatJSONL = "jsonl"
+)
+
+// Load reads the rows of a data file. The format is detected from the file
+// extension when it is not given explicitly.
+func Load(path string, format string) ([]map[string]interface{}, error) {
+	if format == "" {
+		detected, err := DetectFormat(path)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
+	}
+
+	rows, err := Parse(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data file %s: %w", path, err)
+	}
+	return rows, nil
+}
+
+// DetectFormat returns the data format for a file extension
+func DetectFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".yml", ".yaml":
+		return FormatYAML, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("cannot detect data format of %s: use a .csv, .json, .yaml or .jsonl file or set format", path)
+	}
+}
+
+// NormalizeFormat returns the canonical name of a supported data format
+func NormalizeFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatYAML, "yml":
+		return FormatYAML, nil
+	case FormatJSONL, "ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("unsupported data format: %s", format)
+	}
+}
+
+// Parse parses rows in the given format
+func Parse(data []byte, format string) ([]map[string]interface{}, error) {
+	format, err := NormalizeFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatCSV:
+		return parseCSV(data)
+	case FormatJSON:
+		return parseJSON(data)
+	case FormatYAML:
+		return parseYAML(data)
+	default:
+		return parseJSONL(data)
+	}
+}
+
+// parseCSV parses CSV with a header row. All values are strings.
+func parseCSV(data []byte) ([]map[string]interface{}, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	for i, column := range header {
+		header[i] = strings.TrimSpace(column)
+		if header[i] == "" {
+			return nil, fmt.Errorf("column %d has an empty name", i+1)
+		}
+	}
+
+	rows := make([]map[string]interface{}, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseJSON parses a JSON array of objects
+func parseJSON(data []byte) ([]map[string]interface{}, error) {
+	var items []interface{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("expected an array of objects: %w", err)
+	}
+	return toRows(items)
+}
+
+// parseYAML parses a YAML list of mappings
+func parseYAML(data []byte) ([]map[string]interface{}, error) {
+	var items []interface{}
+	if err := yaml.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("expected a list of mappings: %w", err)
+	}
+	return toRows(items)
+}
+
+// parseJSONL parses one JSON object per line, skipping blank lines
+func parseJSONL(data []byte) ([]map[string]interface{}, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	var rows []map[string]interface{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var row map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			return nil, fmt.Errorf("line %d: expected a JSON object: %w", line, err)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// toRows checks that every item of a decoded list is an object
+func toRows(items []interface{}) ([]map[string]interface{}, error) {
+	rows := make([]map[string]interface{}, 0, len(items))
+	for i, item := range items {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("row %d is not an object", i+1)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
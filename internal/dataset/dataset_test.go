@@ -0,0 +1,83 @@
+package dataset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"csv", "users.csv", "\ufeffname, role\nalice,admin\nbob,viewer\n"},
+		{"json", "users.json", `[{"name": "alice", "role": "admin"}, {"name": "bob", "role": "viewer"}]`},
+		{"yaml", "users.yml", "- name: alice\n  role: admin\n- name: bob\n  role: viewer\n"},
+		{"jsonl", "users.jsonl", "{\"name\": \"alice\", \"role\": \"admin\"}\n\n{\"name\": \"bob\", \"role\": \"viewer\"}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Load(writeFile(t, tt.file, tt.content), "")
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if len(rows) != 2 {
+				t.Fatalf("Expected 2 rows, got %d", len(rows))
+			}
+			if rows[0]["name"] != "alice" || rows[0]["role"] != "admin" {
+				t.Errorf("Unexpected first row: %v", rows[0])
+			}
+			if rows[1]["name"] != "bob" || rows[1]["role"] != "viewer" {
+				t.Errorf("Unexpected second row: %v", rows[1])
+			}
+		})
+	}
+}
+
+func TestLoadExplicitFormat(t *testing.T) {
+	rows, err := Load(writeFile(t, "users.txt", "id\n1\n2\n"), FormatCSV)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(rows) != 2 || rows[1]["id"] != "2" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"unknown extension", "users.txt", "id\n1\n"},
+		{"csv row length", "users.csv", "id,name\n1\n"},
+		{"json not a list", "users.json", `{"id": 1}`},
+		{"json item not an object", "users.json", `[1, 2]`},
+		{"jsonl bad line", "users.jsonl", "{\"id\": 1}\nnot json\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(writeFile(t, tt.file, tt.content), ""); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.csv"), ""); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
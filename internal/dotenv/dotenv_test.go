@@ -0,0 +1,75 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	values, err := Parse(`
+# Comment
+API_URL=https://api.example.com
+export TOKEN=abc123
+EMPTY=
+SPACED = value with spaces   # trailing comment
+SINGLE='literal \n $value # not a comment'
+DOUBLE="line1\nline2 \"quoted\""
+HASH=abc#def
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"API_URL": "https://api.example.com",
+		"TOKEN":   "abc123",
+		"EMPTY":   "",
+		"SPACED":  "value with spaces",
+		"SINGLE":  `literal \n $value # not a comment`,
+		"DOUBLE":  "line1\nline2 \"quoted\"",
+		"HASH":    "abc#def",
+	}
+	if len(values) != len(want) {
+		t.Errorf("Expected %d values, got %v", len(want), values)
+	}
+	for key, expected := range want {
+		if values[key] != expected {
+			t.Errorf("Expected %s = %q, got %q", key, expected, values[key])
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"missing equals":    "API_URL",
+		"space in key":      "API URL=x",
+		"unterminated":      `TOKEN="abc`,
+		"text after quotes": `TOKEN="abc" def`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse(content); err == nil {
+				t.Errorf("Expected an error for %q", content)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("USER=alice\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	values, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if values["USER"] != "alice" {
+		t.Errorf("Expected USER = alice, got %v", values)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.env")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,458 @@
+package expression
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Env supplies variables to an expression
+type Env interface {
+	// Lookup returns the value of a {{...}} reference
+	Lookup(ref string) (interface{}, bool)
+	// Substitute expands {{...}} references inside string literals
+	Substitute(input string) (string, error)
+}
+
+// Evaluate evaluates the expression and returns its value
+func (x *Expression) Evaluate(env Env) (interface{}, error) {
+	return x.root.eval(env)
+}
+
+// EvaluateBool evaluates the expression and returns whether the result is truthy
+func (x *Expression) EvaluateBool(env Env) (bool, error) {
+	value, err := x.Evaluate(env)
+	if err != nil {
+		return false, err
+	}
+	return Truthy(value), nil
+}
+
+// node is a node of the expression tree
+type node interface {
+	eval(env Env) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(env Env) (interface{}, error) {
+	// String literals may embed variables, e.g. "{{first}} {{last}}"
+	if s, ok := n.value.(string); ok && strings.Contains(s, "{{") {
+		return env.Substitute(s)
+	}
+	return n.value, nil
+}
+
+type variableNode struct {
+	ref string
+}
+
+func (n *variableNode) eval(env Env) (interface{}, error) {
+	value, exists := env.Lookup(n.ref)
+	if !exists {
+		return nil, nil
+	}
+	return normalize(value), nil
+}
+
+type listNode struct {
+	items []node
+}
+
+func (n *listNode) eval(env Env) (interface{}, error) {
+	list := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		value, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) eval(env Env) (interface{}, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return !Truthy(value), nil
+}
+
+type negateNode struct {
+	operand node
+}
+
+func (n *negateNode) eval(env Env) (interface{}, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	number, ok := toNumber(value)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %s", describe(value))
+	}
+	return -number, nil
+}
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n *logicalNode) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	// Short-circuit: the right side is not evaluated when the left side decides
+	if n.op == "&&" && !Truthy(left) {
+		return false, nil
+	}
+	if n.op == "||" && Truthy(left) {
+		return true, nil
+	}
+
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return Truthy(right), nil
+}
+
+type comparisonNode struct {
+	op          string
+	left, right node
+	negate      bool           // "not in", "not contains", "not matches"
+	pattern     *regexp.Regexp // precompiled literal pattern of "matches"
+}
+
+func (n *comparisonNode) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	var result bool
+	switch n.op {
+	case "==":
+		result = Equal(left, right)
+	case "!=":
+		result = !Equal(left, right)
+	case ">", ">=", "<", "<=":
+		cmp, err := compare(left, right)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case ">":
+			result = cmp > 0
+		case ">=":
+			result = cmp >= 0
+		case "<":
+			result = cmp < 0
+		default:
+			result = cmp <= 0
+		}
+	case "in":
+		result, err = contains(right, left)
+	case "contains":
+		result, err = contains(left, right)
+	case "matches":
+		result, err = n.matches(left, right)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if n.negate {
+		return !result, nil
+	}
+	return result, nil
+}
+
+// matches reports whether the string form of value matches the pattern
+func (n *comparisonNode) matches(value, pattern interface{}) (bool, error) {
+	re := n.pattern
+	if re == nil {
+		source, ok := pattern.(string)
+		if !ok {
+			return false, fmt.Errorf("matches expects a string pattern, got %s", describe(pattern))
+		}
+		var err error
+		if re, err = regexp.Compile(source); err != nil {
+			return false, fmt.Errorf("invalid pattern %q: %w", source, err)
+		}
+	}
+	if value == nil {
+		return false, nil
+	}
+	return re.MatchString(toString(value)), nil
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+func (n *callNode) eval(env Env) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+
+	switch n.name {
+	case "len":
+		return length(args[0])
+	}
+	return nil, fmt.Errorf("unknown function %q", n.name)
+}
+
+// Truthy reports whether a value counts as true in a condition
+func Truthy(value interface{}) bool {
+	switch v := normalize(value).(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != "" && v != "false" && v != "0"
+	default:
+		return true
+	}
+}
+
+// Equal compares two values. Numbers and numeric strings compare as numbers,
+// booleans and "true"/"false" strings as booleans, lists and objects deeply.
+func Equal(left, right interface{}) bool {
+	left, right = normalize(left), normalize(right)
+
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+
+	if l, ok := toNumber(left); ok {
+		if r, ok := toNumber(right); ok {
+			return l == r
+		}
+	}
+
+	if l, ok := left.(bool); ok {
+		r, ok := toBool(right)
+		return ok && l == r
+	}
+	if r, ok := right.(bool); ok {
+		l, ok := toBool(left)
+		return ok && l == r
+	}
+
+	switch left.(type) {
+	case string:
+		if r, ok := right.(string); ok {
+			return left == r
+		}
+	case []interface{}, map[string]interface{}:
+		return reflect.DeepEqual(left, right)
+	}
+	return toString(left) == toString(right)
+}
+
+// compare orders two numbers or two strings
+func compare(left, right interface{}) (int, error) {
+	left, right = normalize(left), normalize(right)
+
+	if l, ok := toNumber(left); ok {
+		if r, ok := toNumber(right); ok {
+			switch {
+			case l < r:
+				return -1, nil
+			case l > r:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+
+	l, lok := left.(string)
+	r, rok := right.(string)
+	if lok && rok {
+		return strings.Compare(l, r), nil
+	}
+	return 0, fmt.Errorf("cannot compare %s with %s", describe(left), describe(right))
+}
+
+// contains reports whether a list holds an element, an object has a key or a string has a substring
+func contains(container, element interface{}) (bool, error) {
+	switch c := normalize(container).(type) {
+	case nil:
+		return false, nil
+	case []interface{}:
+		for _, item := range c {
+			if Equal(item, element) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]interface{}:
+		_, exists := c[toString(element)]
+		return exists, nil
+	case string:
+		if element == nil {
+			return false, nil
+		}
+		return strings.Contains(c, toString(element)), nil
+	default:
+		return false, fmt.Errorf("cannot search in %s", describe(container))
+	}
+}
+
+// length returns the length of a string, list or object
+func length(value interface{}) (interface{}, error) {
+	switch v := normalize(value).(type) {
+	case nil:
+		return float64(0), nil
+	case string:
+		return float64(utf8.RuneCountInString(v)), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	default:
+		return nil, fmt.Errorf("len() expects a string, list or object, got %s", describe(value))
+	}
+}
+
+// normalize converts numbers to float64 and typed slices and maps to their generic forms
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool, string, float64, []interface{}, map[string]interface{}:
+		return v
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = normalize(rv.Index(i).Interface())
+		}
+		return list
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			object := make(map[string]interface{}, rv.Len())
+			for _, key := range rv.MapKeys() {
+				object[key.String()] = normalize(rv.MapIndex(key).Interface())
+			}
+			return object
+		}
+	}
+	return value
+}
+
+// toNumber converts numbers and numeric strings to float64
+func toNumber(value interface{}) (float64, bool) {
+	switch v := normalize(value).(type) {
+	case float64:
+		return v, true
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// toBool converts booleans and "true"/"false" strings
+func toBool(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b, true
+		}
+	}
+	return false, false
+}
+
+// toString renders a value for string operations
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}, map[string]interface{}:
+		if data, err := json.Marshal(v); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// describe names the type of a value for error messages
+func describe(value interface{}) string {
+	switch normalize(value).(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,178 @@
+package expression
+
+import (
+	"strings"
+	"testing"
+)
+
+// mapEnv is a test environment backed by a map
+type mapEnv map[string]interface{}
+
+func (m mapEnv) Lookup(ref string) (interface{}, bool) {
+	if value, exists := m[ref]; exists {
+		return value, true
+	}
+	return LookupPath(func(name string) (interface{}, bool) {
+		value, exists := m[name]
+		return value, exists
+	}, ref)
+}
+
+func (m mapEnv) Substitute(input string) (string, error) {
+	for name, value := range m {
+		if s, ok := value.(string); ok {
+			input = strings.ReplaceAll(input, "{{"+name+"}}", s)
+		}
+	}
+	return input, nil
+}
+
+func TestEvaluateBool(t *testing.T) {
+	env := mapEnv{
+		"count":    5,
+		"price":    "19.90",
+		"status":   "active",
+		"note":     "a && b == c",
+		"enabled":  true,
+		"flag":     "false",
+		"empty":    "",
+		"roles":    []interface{}{"admin", "editor"},
+		"tags":     []string{"x", "y"},
+		"user":     map[string]interface{}{"name": "Ann", "address": map[string]interface{}{"city": "Oslo"}},
+		"items":    []interface{}{map[string]interface{}{"id": 7.0}},
+		"raw":      `{"ok": true}`,
+		"greeting": "hello world",
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"true", true},
+		{"false", false},
+		{"{{count}} == 5", true},
+		{"{{count}} == '5'", true},
+		{"{{count}} > 4.5 && {{count}} <= 5", true},
+		{"{{price}} > 9", true},
+		{"{{status}} == 'active'", true},
+		{`{{status}} == "inactive"`, false},
+		{"{{status}} != 'inactive'", true},
+		{"{{note}} == 'a && b == c'", true},
+		{"'{{status}}' == 'active'", true},
+		{"{{enabled}}", true},
+		{"{{enabled}} == true", true},
+		{"{{flag}}", false},
+		{"{{flag}} == false", true},
+		{"!{{enabled}}", false},
+		{"not {{enabled}} or {{count}} == 5", true},
+		{"{{missing}}", false},
+		{"{{missing}} == null", true},
+		{"{{missing}} != ''", true},
+		{"{{empty}} == ''", true},
+		{"false && true || true", true},
+		{"false && (true || true)", false},
+		{"!(false || false) && true", true},
+		{"'admin' in {{roles}}", true},
+		{"'owner' in {{roles}}", false},
+		{"'owner' not in {{roles}}", true},
+		{"{{status}} in ['active', 'pending']", true},
+		{"{{count}} in [1, 5]", true},
+		{"'x' in {{tags}}", true},
+		{"{{roles}} contains 'editor'", true},
+		{"{{greeting}} contains 'world'", true},
+		{"{{user}} contains 'name'", true},
+		{"{{greeting}} matches '^hello\\s'", true},
+		{"{{greeting}} not matches '^bye'", true},
+		{"len({{roles}}) == 2", true},
+		{"len({{greeting}}) > 20", false},
+		{"len({{missing}}) == 0", true},
+		{"{{user.address.city}} == 'Oslo'", true},
+		{"{{user.address.zip}} == null", true},
+		{"{{items[0].id}} == 7", true},
+		{"{{raw.ok}} == true", true},
+		{"-{{count}} < 0", true},
+		{"[1, 2] == [1, 2]", true},
+	}
+
+	for _, tt := range tests {
+		expr, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		got, err := expr.EvaluateBool(env)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Evaluate(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		message string
+	}{
+		{"", "empty expression"},
+		{"{{a}} = 1", `use "=="`},
+		{"{{a}} == production", `unknown identifier "production"`},
+		{"({{a}} == 1", `expected ")"`},
+		{"{{a}} == 'open", "unterminated string"},
+		{"{{a == 1", "unterminated variable reference"},
+		{"{{a}} ==", "unexpected end of expression"},
+		{"1 < {{a}} < 3", "cannot be chained"},
+		{"size({{a}})", `unknown function "size"`},
+		{"len({{a}}, {{b}})", "expects 1 argument"},
+		{"{{a}} matches '(['", "invalid pattern"},
+		{"{{a}} == 1 {{b}}", "unexpected variable {{b}}"},
+		{"{{a}} # 1", "unexpected character"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		if err == nil {
+			t.Errorf("Parse(%q) succeeded, want error containing %q", tt.expr, tt.message)
+			continue
+		}
+		if _, ok := err.(*SyntaxError); !ok {
+			t.Errorf("Parse(%q) returned %T, want *SyntaxError", tt.expr, err)
+		}
+		if !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("Parse(%q) error %q does not contain %q", tt.expr, err, tt.message)
+		}
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	env := mapEnv{"list": []interface{}{1}, "count": 1, "pattern": "(["}
+
+	for _, source := range []string{
+		"{{list}} > 1",
+		"'a' in {{count}}",
+		"len({{count}}) == 1",
+		"'x' matches {{pattern}}",
+	} {
+		expr, err := Parse(source)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", source, err)
+		}
+		if _, err := expr.Evaluate(env); err == nil {
+			t.Errorf("Evaluate(%q) succeeded, want error", source)
+		}
+	}
+}
+
+func TestShortCircuit(t *testing.T) {
+	// The right side would fail to evaluate, but is never reached
+	expr, err := Parse("{{list}} == null || {{list}} > 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := expr.EvaluateBool(mapEnv{})
+	if err != nil || !got {
+		t.Errorf("Expected true without error, got %v, %v", got, err)
+	}
+}
@@ -0,0 +1,187 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tokenKind identifies the kind of a lexical token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenVariable
+	tokenIdent
+	tokenOperator
+)
+
+// token is a lexical token of an expression
+type token struct {
+	kind  tokenKind
+	text  string      // operator, identifier or variable reference
+	value interface{} // parsed value of number and string literals
+	pos   int         // byte offset in the source
+}
+
+// String describes the token for error messages
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenNumber:
+		return fmt.Sprintf("number %s", t.text)
+	case tokenString:
+		return fmt.Sprintf("string %q", t.value)
+	case tokenVariable:
+		return fmt.Sprintf("variable {{%s}}", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// operators lists the symbolic operators, longest first
+var operators = []string{"==", "!=", ">=", "<=", "&&", "||", ">", "<", "!", "(", ")", "[", "]", ",", "-"}
+
+// tokenize splits an expression into tokens
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	pos := 0
+
+	for pos < len(source) {
+		c := source[pos]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+
+		case strings.HasPrefix(source[pos:], "{{"):
+			end := strings.Index(source[pos+2:], "}}")
+			if end < 0 {
+				return nil, syntaxError(source, pos, "unterminated variable reference")
+			}
+			ref := strings.TrimSpace(source[pos+2 : pos+2+end])
+			if ref == "" {
+				return nil, syntaxError(source, pos, "empty variable reference")
+			}
+			tokens = append(tokens, token{kind: tokenVariable, text: ref, pos: pos})
+			pos += end + 4
+
+		case c == '"' || c == '\'':
+			value, next, err := readString(source, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: source[pos:next], value: value, pos: pos})
+			pos = next
+
+		case isDigit(c) || (c == '.' && pos+1 < len(source) && isDigit(source[pos+1])):
+			next := readNumber(source, pos)
+			value, err := strconv.ParseFloat(source[pos:next], 64)
+			if err != nil {
+				return nil, syntaxError(source, pos, fmt.Sprintf("invalid number %q", source[pos:next]))
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[pos:next], value: value, pos: pos})
+			pos = next
+
+		case isLetter(c):
+			next := pos + 1
+			for next < len(source) && (isLetter(source[next]) || isDigit(source[next])) {
+				next++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[pos:next], pos: pos})
+			pos = next
+
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(source[pos:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				if c == '=' {
+					return nil, syntaxError(source, pos, "unexpected \"=\", use \"==\" to compare")
+				}
+				return nil, syntaxError(source, pos, fmt.Sprintf("unexpected character %q", c))
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: pos})
+			pos += len(op)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(source)})
+	return tokens, nil
+}
+
+// readString reads a quoted string literal starting at pos and returns its value
+// and the offset after the closing quote
+func readString(source string, pos int) (string, int, error) {
+	quote := source[pos]
+	var b strings.Builder
+
+	for i := pos + 1; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\' && i+1 < len(source):
+			i++
+			switch source[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '\\', '\'', '"':
+				b.WriteByte(source[i])
+			default:
+				// Unknown escapes are kept, so patterns like '\d+' work unchanged
+				b.WriteByte('\\')
+				b.WriteByte(source[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, syntaxError(source, pos, "unterminated string")
+}
+
+// readNumber returns the offset after the number literal starting at pos
+func readNumber(source string, pos int) int {
+	i := pos
+	for i < len(source) && isDigit(source[i]) {
+		i++
+	}
+	if i < len(source) && source[i] == '.' {
+		i++
+		for i < len(source) && isDigit(source[i]) {
+			i++
+		}
+	}
+	if i < len(source) && (source[i] == 'e' || source[i] == 'E') {
+		j := i + 1
+		if j < len(source) && (source[j] == '+' || source[j] == '-') {
+			j++
+		}
+		if j < len(source) && isDigit(source[j]) {
+			for j < len(source) && isDigit(source[j]) {
+				j++
+			}
+			i = j
+		}
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,306 @@
+package expression
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SyntaxError reports an expression that cannot be parsed
+type SyntaxError struct {
+	Expression string
+	Position   int
+	Message    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d in %q: %s", e.Position+1, e.Expression, e.Message)
+}
+
+func syntaxError(source string, pos int, message string) error {
+	return &SyntaxError{Expression: source, Position: pos, Message: message}
+}
+
+// functions lists the supported functions and their number of arguments
+var functions = map[string]int{
+	"len": 1,
+}
+
+// Expression is a parsed expression, ready to be evaluated
+type Expression struct {
+	source string
+	root   node
+}
+
+// String returns the source of the expression
+func (x *Expression) String() string {
+	return x.source
+}
+
+// Parse parses an expression.
+//
+// Grammar, from lowest to highest precedence:
+//
+//	or         = and { ("||" | "or") and }
+//	and        = unary { ("&&" | "and") unary }
+//	unary      = ("!" | "not") unary | comparison
+//	comparison = operand [ op operand ]
+//	op         = "==" | "!=" | ">" | ">=" | "<" | "<=" | ["not"] ("in" | "contains" | "matches")
+//	operand    = number | string | "true" | "false" | "null" | {{variable}}
+//	           | "-" operand | "(" or ")" | "[" [ or { "," or } ] "]" | name "(" [ or { "," or } ] ")"
+func Parse(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{source: source, tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, syntaxError(source, 0, "empty expression")
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorAt(t, fmt.Sprintf("unexpected %s", t))
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// parser is a recursive descent parser over a token list
+type parser struct {
+	source string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorAt(t token, message string) error {
+	return syntaxError(p.source, t.pos, message)
+}
+
+// isOperator reports whether t is the given symbolic operator or keyword
+func isOperator(t token, ops ...string) bool {
+	if t.kind != tokenOperator && t.kind != tokenIdent {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if t := p.peek(); !isOperator(t, op) {
+		return p.errorAt(t, fmt.Sprintf("expected %q, got %s", op, t))
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for isOperator(p.peek(), "||", "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for isOperator(p.peek(), "&&", "and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if isOperator(p.peek(), "!", "not") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	negate := false
+	if isOperator(t, "not") && isOperator(p.peekAt(1), "in", "contains", "matches") {
+		p.next()
+		negate = true
+		t = p.peek()
+	}
+	if !isOperator(t, "==", "!=", ">", ">=", "<", "<=", "in", "contains", "matches") {
+		return left, nil
+	}
+	p.next()
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &comparisonNode{op: t.text, left: left, right: right, negate: negate}
+
+	// A literal pattern is compiled up front so mistakes surface when the workflow is validated
+	if t.text == "matches" {
+		if lit, ok := right.(*literalNode); ok {
+			pattern, ok := lit.value.(string)
+			if !ok {
+				return nil, p.errorAt(t, "matches expects a string pattern")
+			}
+			if !strings.Contains(pattern, "{{") {
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return nil, p.errorAt(t, fmt.Sprintf("invalid pattern: %v", err))
+				}
+				comparison.pattern = re
+			}
+		}
+	}
+
+	if next := p.peek(); isOperator(next, "==", "!=", ">", ">=", "<", "<=", "in", "contains", "matches") {
+		return nil, p.errorAt(next, "comparisons cannot be chained, use && or parentheses")
+	}
+	return comparison, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenNumber:
+		return &literalNode{value: t.value}, nil
+	case tokenString:
+		return &literalNode{value: t.value}, nil
+	case tokenVariable:
+		return &variableNode{ref: t.text}, nil
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listNode{items: items}, nil
+		case "-":
+			operand, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return &negateNode{operand: operand}, nil
+		}
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null", "nil":
+			return &literalNode{value: nil}, nil
+		}
+
+		if isOperator(p.peek(), "(") {
+			arity, known := functions[t.text]
+			if !known {
+				return nil, p.errorAt(t, fmt.Sprintf("unknown function %q", t.text))
+			}
+			p.next()
+			args, err := p.parseList(")")
+			if err != nil {
+				return nil, err
+			}
+			if len(args) != arity {
+				return nil, p.errorAt(t, fmt.Sprintf("%s expects %d argument(s), got %d", t.text, arity, len(args)))
+			}
+			return &callNode{name: t.text, args: args}, nil
+		}
+		return nil, p.errorAt(t, fmt.Sprintf("unknown identifier %q: quote string literals and wrap variables in {{ }}", t.text))
+	}
+
+	if t.kind == tokenEOF {
+		return nil, p.errorAt(t, "unexpected end of expression")
+	}
+	return nil, p.errorAt(t, fmt.Sprintf("unexpected %s", t))
+}
+
+// parseList parses comma-separated expressions up to the closing delimiter
+func (p *parser) parseList(closing string) ([]node, error) {
+	var items []node
+	if isOperator(p.peek(), closing) {
+		p.next()
+		return items, nil
+	}
+	for {
+		item, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		if isOperator(p.peek(), ",") {
+			p.next()
+			continue
+		}
+		if err := p.expect(closing); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+}
@@ -0,0 +1,68 @@
+package expression
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// LookupPath resolves a dotted reference such as "user.address.city" or "items[0].id".
+// The longest prefix found by get names the variable; the remaining segments walk
+// into its objects and lists.
+func LookupPath(get func(string) (interface{}, bool), ref string) (interface{}, bool) {
+	segments := splitPath(ref)
+
+	for i := len(segments); i > 0; i-- {
+		value, exists := get(strings.Join(segments[:i], "."))
+		if !exists {
+			continue
+		}
+		return walk(value, segments[i:])
+	}
+	return nil, false
+}
+
+// splitPath splits "a.b[0].c" into ["a", "b", "0", "c"]
+func splitPath(ref string) []string {
+	ref = strings.ReplaceAll(ref, "[", ".")
+	ref = strings.ReplaceAll(ref, "]", "")
+
+	var segments []string
+	for _, segment := range strings.Split(ref, ".") {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// walk follows path segments into nested objects and lists
+func walk(value interface{}, segments []string) (interface{}, bool) {
+	for _, segment := range segments {
+		// Objects captured as JSON text are decoded on the way
+		if text, ok := value.(string); ok {
+			var decoded interface{}
+			if err := json.Unmarshal([]byte(text), &decoded); err == nil {
+				value = decoded
+			}
+		}
+
+		switch v := normalize(value).(type) {
+		case map[string]interface{}:
+			field, exists := v[segment]
+			if !exists {
+				return nil, false
+			}
+			value = field
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
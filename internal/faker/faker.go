@@ -0,0 +1,430 @@
+package faker
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/utils"
+)
+
+// Faker generates fake data for {{faker.*}}. All values are drawn from one random
+// source, so a run with the same seed and the same steps produces the same data.
+// It is safe for concurrent use.
+type Faker struct {
+	mu     sync.Mutex
+	rng    *rand.Rand
+	seed   int64
+	locale *locale
+	now    func() time.Time
+	used   bool // Whether a value was generated
+}
+
+// generator produces a value from the arguments written in parentheses
+type generator func(f *Faker, args []string) (string, error)
+
+// generators are the functions available as {{faker.name}} or {{faker.name(args...)}}
+var generators map[string]generator
+
+func init() {
+	generators = map[string]generator{
+		"name":        noArgs(func(f *Faker) string { return f.pick(f.locale.firstNames) + " " + f.pick(f.locale.lastNames) }),
+		"first_name":  noArgs(func(f *Faker) string { return f.pick(f.locale.firstNames) }),
+		"last_name":   noArgs(func(f *Faker) string { return f.pick(f.locale.lastNames) }),
+		"username":    noArgs((*Faker).username),
+		"email":       noArgs((*Faker).email),
+		"password":    password,
+		"phone":       noArgs(func(f *Faker) string { return f.digits(f.locale.phoneFormat) }),
+		"address":     noArgs((*Faker).address),
+		"street":      noArgs((*Faker).street),
+		"city":        noArgs(func(f *Faker) string { return f.pick(f.locale.cities) }),
+		"country":     noArgs(func(f *Faker) string { return f.locale.country }),
+		"company":     noArgs((*Faker).company),
+		"iban":        iban,
+		"credit_card": creditCard,
+		"ipv4":        noArgs((*Faker).ipv4),
+		"ipv6":        noArgs((*Faker).ipv6),
+		"url":         noArgs((*Faker).url),
+		"domain":      noArgs((*Faker).domain),
+		"latitude":    noArgs(func(f *Faker) string { return f.coordinate(90) }),
+		"longitude":   noArgs(func(f *Faker) string { return f.coordinate(180) }),
+		"uuid":        noArgs((*Faker).uuid),
+		"number":      number,
+		"boolean":     noArgs(func(f *Faker) string { return strconv.FormatBool(f.intn(2) == 1) }),
+		"date":        date(-365, 0),
+		"past_date":   date(-365, 0),
+		"future_date": date(0, 365),
+		"word":        noArgs(func(f *Faker) string { return f.pick(words) }),
+		"sentence":    noArgs((*Faker).sentence),
+		"paragraph":   noArgs((*Faker).paragraph),
+		"sha":         noArgs(func(f *Faker) string { return f.hex(40) }),
+		"enum":        enum,
+		"regex":       regex,
+	}
+}
+
+// New creates a faker with the given seed and locale. An empty locale is "en".
+func New(seed int64, localeName string) (*Faker, error) {
+	if localeName == "" {
+		localeName = "en"
+	}
+	l, exists := locales[strings.ToLower(localeName)]
+	if !exists {
+		return nil, fmt.Errorf("unknown faker locale %q (available: %s)", localeName, strings.Join(Locales(), ", "))
+	}
+	return &Faker{rng: rand.New(rand.NewSource(seed)), seed: seed, locale: l, now: time.Now}, nil
+}
+
+// Locales returns the names of the supported locales, sorted
+func Locales() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Seed returns the seed of the faker
+func (f *Faker) Seed() int64 {
+	return f.seed
+}
+
+// Used reports whether the faker generated a value
+func (f *Faker) Used() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.used
+}
+
+// Generate returns a value of the named generator, e.g. Generate("number", "1", "10")
+func (f *Faker) Generate(name string, args ...string) (string, error) {
+	gen, exists := generators[name]
+	if !exists {
+		return "", fmt.Errorf("unknown faker function %q", name)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.used = true
+	return gen(f, args)
+}
+
+// noArgs adapts a generator without arguments
+func noArgs(fn func(f *Faker) string) generator {
+	return func(f *Faker, args []string) (string, error) {
+		if len(args) > 0 {
+			return "", fmt.Errorf("expected no arguments, got %d", len(args))
+		}
+		return fn(f), nil
+	}
+}
+
+func (f *Faker) intn(n int) int {
+	return f.rng.Intn(n)
+}
+
+func (f *Faker) pick(values []string) string {
+	return values[f.intn(len(values))]
+}
+
+// digits replaces every # in format with a random digit
+func (f *Faker) digits(format string) string {
+	var b strings.Builder
+	for _, r := range format {
+		if r == '#' {
+			b.WriteByte(byte('0' + f.intn(10)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (f *Faker) hex(n int) string {
+	const alphabet = "0123456789abcdef"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[f.intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+func (f *Faker) username() string {
+	first := ascii(strings.ToLower(f.pick(f.locale.firstNames)))
+	last := ascii(strings.ToLower(f.pick(f.locale.lastNames)))
+	switch f.intn(3) {
+	case 0:
+		return first + "." + last
+	case 1:
+		return first + "_" + last + strconv.Itoa(f.intn(100))
+	default:
+		return first[:1] + last + strconv.Itoa(1950+f.intn(60))
+	}
+}
+
+func (f *Faker) email() string {
+	first := ascii(strings.ToLower(f.pick(f.locale.firstNames)))
+	last := ascii(strings.ToLower(f.pick(f.locale.lastNames)))
+	return first + "." + last + "@" + f.pick(exampleDomains)
+}
+
+func (f *Faker) domain() string {
+	return ascii(strings.ToLower(f.pick(f.locale.lastNames))) + "." + f.pick(f.locale.tlds)
+}
+
+func (f *Faker) url() string {
+	path := f.pick(words)
+	return "https://www." + f.domain() + "/" + path
+}
+
+func (f *Faker) street() string {
+	return fmt.Sprintf(f.locale.streetFormat, f.pick(f.locale.streets), 1+f.intn(199))
+}
+
+func (f *Faker) address() string {
+	return f.street() + ", " + f.digits(f.locale.postcodeFormat) + " " + f.pick(f.locale.cities)
+}
+
+func (f *Faker) company() string {
+	return f.pick(f.locale.lastNames) + " " + f.pick(f.locale.companySuffixes)
+}
+
+func (f *Faker) ipv4() string {
+	return fmt.Sprintf("%d.%d.%d.%d", 1+f.intn(223), f.intn(256), f.intn(256), 1+f.intn(254))
+}
+
+func (f *Faker) ipv6() string {
+	groups := make([]string, 8)
+	for i := range groups {
+		groups[i] = strconv.FormatInt(int64(f.intn(0x10000)), 16)
+	}
+	return strings.Join(groups, ":")
+}
+
+// coordinate returns a number between -limit and limit with 6 decimal places
+func (f *Faker) coordinate(limit float64) string {
+	return strconv.FormatFloat(f.rng.Float64()*2*limit-limit, 'f', 6, 64)
+}
+
+// uuid returns a version 4 UUID drawn from the random source
+func (f *Faker) uuid() string {
+	var b [16]byte
+	f.rng.Read(b[:])
+	b[6] = 0x40 | (b[6] & 0x0f)
+	b[8] = 0x80 | (b[8] & 0x3f)
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func (f *Faker) sentence() string {
+	n := 5 + f.intn(6)
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = f.pick(words)
+	}
+	s := strings.Join(parts, " ")
+	return strings.ToUpper(s[:1]) + s[1:] + "."
+}
+
+func (f *Faker) paragraph() string {
+	n := 3 + f.intn(3)
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = f.sentence()
+	}
+	return strings.Join(parts, " ")
+}
+
+// password returns a password with lower and upper case letters, digits and symbols.
+// The length defaults to 16.
+func password(f *Faker, args []string) (string, error) {
+	length := 16
+	if len(args) > 1 {
+		return "", fmt.Errorf("expected at most 1 argument, got %d", len(args))
+	}
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 4 {
+			return "", fmt.Errorf("invalid password length %q, must be at least 4", args[0])
+		}
+		length = n
+	}
+
+	sets := []string{"abcdefghijkmnopqrstuvwxyz", "ABCDEFGHJKLMNPQRSTUVWXYZ", "23456789", "!@#$%^&*-_+="}
+	b := make([]byte, length)
+	// One character of every set, the rest from all of them
+	for i := range b {
+		set := sets[i%len(sets)]
+		if i >= len(sets) {
+			set = strings.Join(sets, "")
+		}
+		b[i] = set[f.intn(len(set))]
+	}
+	f.rng.Shuffle(len(b), func(i, j int) { b[i], b[j] = b[j], b[i] })
+	return string(b), nil
+}
+
+// number returns an integer in [min, max], 1 to 100 by default
+func number(f *Faker, args []string) (string, error) {
+	min, max := 1, 100
+	if len(args) != 0 && len(args) != 2 {
+		return "", fmt.Errorf("expected min and max, got %d arguments", len(args))
+	}
+	if len(args) == 2 {
+		var err error
+		if min, err = strconv.Atoi(args[0]); err != nil {
+			return "", fmt.Errorf("invalid min %q", args[0])
+		}
+		if max, err = strconv.Atoi(args[1]); err != nil {
+			return "", fmt.Errorf("invalid max %q", args[1])
+		}
+		if max < min {
+			return "", fmt.Errorf("max %d is less than min %d", max, min)
+		}
+	}
+	return strconv.Itoa(min + f.intn(max-min+1)), nil
+}
+
+// date returns a generator of dates between from and to days from now. Arguments
+// are the number of days and the format, e.g. past_date(30, rfc3339).
+func date(from, to int) generator {
+	return func(f *Faker, args []string) (string, error) {
+		if len(args) > 2 {
+			return "", fmt.Errorf("expected days and format, got %d arguments", len(args))
+		}
+		from, to := from, to
+		if len(args) >= 1 && args[0] != "" {
+			days, err := strconv.Atoi(args[0])
+			if err != nil || days <= 0 {
+				return "", fmt.Errorf("invalid number of days %q", args[0])
+			}
+			if from < 0 {
+				from = -days
+			} else {
+				to = days
+			}
+		}
+		format := "date"
+		if len(args) == 2 {
+			format = args[1]
+		}
+
+		span := time.Duration(to-from) * 24 * time.Hour
+		offset := time.Duration(from)*24*time.Hour + time.Duration(f.rng.Int63n(int64(span)))
+		return utils.FormatTime(f.now().Add(offset), format), nil
+	}
+}
+
+// enum picks one of its arguments
+func enum(f *Faker, args []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("expected at least one value")
+	}
+	return f.pick(args), nil
+}
+
+// iban returns an IBAN of the locale's country, or of the country given as argument,
+// with valid check digits
+func iban(f *Faker, args []string) (string, error) {
+	country := f.locale.ibanCountry
+	if len(args) > 1 {
+		return "", fmt.Errorf("expected at most 1 argument, got %d", len(args))
+	}
+	if len(args) == 1 {
+		country = strings.ToUpper(args[0])
+	}
+	format, exists := ibanFormats[country]
+	if !exists {
+		return "", fmt.Errorf("unsupported IBAN country %q", country)
+	}
+
+	var b strings.Builder
+	for _, r := range format {
+		switch r {
+		case '#':
+			b.WriteByte(byte('0' + f.intn(10)))
+		case 'A':
+			b.WriteByte(byte('A' + f.intn(26)))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	bban := b.String()
+	return country + ibanCheckDigits(country, bban) + bban, nil
+}
+
+// ibanCheckDigits computes the ISO 13616 check digits: 98 - (bban + country + "00") mod 97,
+// with letters counted as 10 to 35
+func ibanCheckDigits(country, bban string) string {
+	var digits strings.Builder
+	for _, r := range bban + country + "00" {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+	n, _ := new(big.Int).SetString(digits.String(), 10)
+	check := 98 - new(big.Int).Mod(n, big.NewInt(97)).Int64()
+	return fmt.Sprintf("%02d", check)
+}
+
+// creditCard returns a Luhn-valid card number for visa (default), mastercard or amex
+func creditCard(f *Faker, args []string) (string, error) {
+	brand := "visa"
+	if len(args) > 1 {
+		return "", fmt.Errorf("expected at most 1 argument, got %d", len(args))
+	}
+	if len(args) == 1 {
+		brand = strings.ToLower(args[0])
+	}
+
+	var prefix string
+	length := 16
+	switch brand {
+	case "visa":
+		prefix = "4"
+	case "mastercard":
+		prefix = strconv.Itoa(51 + f.intn(5))
+	case "amex":
+		prefix = f.pick([]string{"34", "37"})
+		length = 15
+	default:
+		return "", fmt.Errorf("unsupported card brand %q (supported: visa, mastercard, amex)", brand)
+	}
+
+	number := prefix
+	for len(number) < length-1 {
+		number += strconv.Itoa(f.intn(10))
+	}
+	return number + strconv.Itoa(luhnCheckDigit(number)), nil
+}
+
+// luhnCheckDigit returns the digit that makes number + digit pass the Luhn check
+func luhnCheckDigit(number string) int {
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		// Doubling starts with the digit left of the check digit
+		if (len(number)-1-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return (10 - sum%10) % 10
+}
+
+// regex returns a string matching a regular expression
+func regex(f *Faker, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected a pattern, got %d arguments", len(args))
+	}
+	return f.matching(args[0])
+}
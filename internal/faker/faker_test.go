@@ -0,0 +1,180 @@
+package faker
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSameSeedGeneratesSameData(t *testing.T) {
+	calls := [][]string{
+		{"name"}, {"email"}, {"uuid"}, {"number", "1", "1000"}, {"iban"}, {"credit_card"},
+		{"ipv6"}, {"password", "20"}, {"regex", `[A-Z]{3}-\d{4}`}, {"sentence"},
+	}
+	generate := func(seed int64) []string {
+		f, err := New(seed, "en")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var values []string
+		for _, call := range calls {
+			value, err := f.Generate(call[0], call[1:]...)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", call[0], err)
+			}
+			values = append(values, value)
+		}
+		return values
+	}
+
+	first, second, other := generate(42), generate(42), generate(43)
+	if strings.Join(first, "\n") != strings.Join(second, "\n") {
+		t.Errorf("Expected the same values for the same seed, got %v and %v", first, second)
+	}
+	if strings.Join(first, "\n") == strings.Join(other, "\n") {
+		t.Errorf("Expected different values for different seeds, got %v", first)
+	}
+}
+
+func TestUsed(t *testing.T) {
+	f, _ := New(1, "")
+	if f.Used() {
+		t.Error("Expected a new faker not to be used")
+	}
+	if _, err := f.Generate("word"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !f.Used() {
+		t.Error("Expected the faker to be used after Generate")
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	f, err := New(1, "de")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	f.now = func() time.Time { return time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		call    []string
+		pattern string
+	}{
+		{[]string{"username"}, `^[a-z0-9._]+$`},
+		{[]string{"email"}, `^[a-z.]+@example\.(com|net|org)$`},
+		{[]string{"phone"}, `^\+49 \d{3} \d{7}$`},
+		{[]string{"iban"}, `^DE\d{20}$`},
+		{[]string{"iban", "GB"}, `^GB\d{2}[A-Z]{4}\d{14}$`},
+		{[]string{"credit_card", "amex"}, `^3[47]\d{13}$`},
+		{[]string{"ipv4"}, `^\d{1,3}(\.\d{1,3}){3}$`},
+		{[]string{"ipv6"}, `^[0-9a-f]{1,4}(:[0-9a-f]{1,4}){7}$`},
+		{[]string{"url"}, `^https://www\.[a-z]+\.[a-z]+/[a-z]+$`},
+		{[]string{"uuid"}, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+		{[]string{"past_date", "30"}, `^2024-(05-0[2-9]|05-[123]\d|06-01)$`},
+		{[]string{"future_date", "10", "unix"}, `^\d{10}$`},
+		{[]string{"enum", "active", "blocked"}, `^(active|blocked)$`},
+		{[]string{"regex", `^ORD-[0-9]{6}(-[a-z]{2})?$`}, `^ORD-\d{6}(-[a-z]{2})?$`},
+	}
+	for _, test := range tests {
+		for i := 0; i < 20; i++ {
+			value, err := f.Generate(test.call[0], test.call[1:]...)
+			if err != nil {
+				t.Fatalf("%v: unexpected error: %v", test.call, err)
+			}
+			if !regexp.MustCompile(test.pattern).MatchString(value) {
+				t.Errorf("%v: expected a value matching %s, got %q", test.call, test.pattern, value)
+			}
+		}
+	}
+}
+
+func TestCreditCardPassesLuhn(t *testing.T) {
+	f, _ := New(7, "")
+	for _, brand := range []string{"visa", "mastercard", "amex"} {
+		for i := 0; i < 50; i++ {
+			number, err := f.Generate("credit_card", brand)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			sum := 0
+			for j := len(number) - 1; j >= 0; j-- {
+				d := int(number[j] - '0')
+				if (len(number)-j)%2 == 0 {
+					d *= 2
+					if d > 9 {
+						d -= 9
+					}
+				}
+				sum += d
+			}
+			if sum%10 != 0 {
+				t.Errorf("Expected %s card %s to pass the Luhn check", brand, number)
+			}
+		}
+	}
+}
+
+func TestIBANHasValidCheckDigits(t *testing.T) {
+	f, _ := New(7, "")
+	for country := range ibanFormats {
+		value, err := f.Generate("iban", country)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// Move the country and check digits to the end and read letters as 10 to 35
+		var digits strings.Builder
+		for _, r := range value[4:] + value[:4] {
+			if r >= 'A' && r <= 'Z' {
+				digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+			} else {
+				digits.WriteRune(r)
+			}
+		}
+		n, _ := new(big.Int).SetString(digits.String(), 10)
+		if new(big.Int).Mod(n, big.NewInt(97)).Int64() != 1 {
+			t.Errorf("Expected IBAN %s to have valid check digits", value)
+		}
+	}
+}
+
+func TestLocales(t *testing.T) {
+	if _, err := New(1, "xx"); err == nil || !strings.Contains(err.Error(), "unknown faker locale") {
+		t.Errorf("Expected an unknown locale error, got %v", err)
+	}
+
+	f, err := New(1, "ru")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	name, _ := f.Generate("name")
+	if !regexp.MustCompile(`^\p{Cyrillic}+ \p{Cyrillic}+$`).MatchString(name) {
+		t.Errorf("Expected a Russian name, got %q", name)
+	}
+	email, _ := f.Generate("email")
+	if !regexp.MustCompile(`^[a-z]+\.[a-z]+@`).MatchString(email) {
+		t.Errorf("Expected a transliterated email, got %q", email)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	f, _ := New(1, "")
+	tests := [][]string{
+		{"unknown"},
+		{"name", "x"},
+		{"number", "10", "1"},
+		{"password", "2"},
+		{"credit_card", "diners"},
+		{"iban", "XX"},
+		{"enum"},
+		{"regex", "[a-"},
+		{"past_date", "-3"},
+	}
+	for _, call := range tests {
+		if value, err := f.Generate(call[0], call[1:]...); err == nil {
+			t.Errorf("%v: expected an error, got %q", call, value)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package faker
+
+import "strings"
+
+// locale holds the data generators draw from for one language and country
+type locale struct {
+	firstNames      []string
+	lastNames       []string
+	cities          []string
+	streets         []string
+	streetFormat    string // fmt format of the street name and the house number
+	postcodeFormat  string
+	phoneFormat     string
+	country         string
+	ibanCountry     string
+	tlds            []string
+	companySuffixes []string
+}
+
+var locales = map[string]*locale{
+	"en": {
+		firstNames:      []string{"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda", "William", "Elizabeth", "David", "Susan", "Richard", "Jessica", "Thomas", "Sarah", "Daniel", "Karen", "Matthew", "Emily"},
+		lastNames:       []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Miller", "Davis", "Wilson", "Anderson", "Taylor", "Thomas", "Moore", "Martin", "Jackson", "White", "Harris", "Clark", "Lewis", "Walker", "Young"},
+		cities:          []string{"New York", "Los Angeles", "Chicago", "Houston", "Phoenix", "Philadelphia", "San Antonio", "San Diego", "Dallas", "Seattle", "Boston", "Denver"},
+		streets:         []string{"Main St", "Oak Ave", "Pine Rd", "Maple Dr", "Cedar Ln", "Elm St", "Washington Ave", "Lake St", "Hill Rd", "Park Ave"},
+		streetFormat:    "%[2]d %[1]s",
+		postcodeFormat:  "#####",
+		phoneFormat:     "+1-###-###-####",
+		country:         "United States",
+		ibanCountry:     "GB",
+		tlds:            []string{"com", "net", "org", "io"},
+		companySuffixes: []string{"Inc", "LLC", "Group", "Corp", "Holdings"},
+	},
+	"de": {
+		firstNames:      []string{"Lukas", "Anna", "Leon", "Lena", "Finn", "Marie", "Jonas", "Sophie", "Paul", "Hannah", "Felix", "Emma", "Maximilian", "Lea", "Jürgen", "Jörg"},
+		lastNames:       []string{"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner", "Becker", "Schulz", "Hoffmann", "Koch", "Richter", "Wolf", "Schröder"},
+		cities:          []string{"Berlin", "Hamburg", "München", "Köln", "Frankfurt am Main", "Stuttgart", "Düsseldorf", "Leipzig", "Dresden", "Hannover"},
+		streets:         []string{"Hauptstraße", "Schulstraße", "Gartenstraße", "Bahnhofstraße", "Dorfstraße", "Bergstraße", "Lindenstraße", "Kirchstraße"},
+		streetFormat:    "%s %d",
+		postcodeFormat:  "#####",
+		phoneFormat:     "+49 ### #######",
+		country:         "Deutschland",
+		ibanCountry:     "DE",
+		tlds:            []string{"de", "com", "net"},
+		companySuffixes: []string{"GmbH", "AG", "KG", "GmbH & Co. KG"},
+	},
+	"fr": {
+		firstNames:      []string{"Gabriel", "Louise", "Léo", "Jade", "Raphaël", "Emma", "Louis", "Alice", "Hugo", "Chloé", "Jules", "Inès", "Arthur", "Léa"},
+		lastNames:       []string{"Martin", "Bernard", "Dubois", "Thomas", "Robert", "Richard", "Petit", "Durand", "Leroy", "Moreau", "Lefèvre", "Girard"},
+		cities:          []string{"Paris", "Marseille", "Lyon", "Toulouse", "Nice", "Nantes", "Strasbourg", "Montpellier", "Bordeaux", "Lille"},
+		streets:         []string{"rue de la Paix", "rue Victor Hugo", "avenue de la République", "boulevard Saint-Michel", "rue du Moulin", "place de l'Église"},
+		streetFormat:    "%[2]d %[1]s",
+		postcodeFormat:  "#####",
+		phoneFormat:     "+33 # ## ## ## ##",
+		country:         "France",
+		ibanCountry:     "FR",
+		tlds:            []string{"fr", "com", "net"},
+		companySuffixes: []string{"SA", "SARL", "SAS"},
+	},
+	"es": {
+		firstNames:      []string{"Hugo", "Lucía", "Martín", "Sofía", "Pablo", "Martina", "Alejandro", "María", "Daniel", "Paula", "Álvaro", "Julia", "Adrián", "Carmen"},
+		lastNames:       []string{"García", "Rodríguez", "González", "Fernández", "López", "Martínez", "Sánchez", "Pérez", "Gómez", "Martín", "Jiménez", "Ruiz"},
+		cities:          []string{"Madrid", "Barcelona", "Valencia", "Sevilla", "Zaragoza", "Málaga", "Murcia", "Palma", "Bilbao", "Alicante"},
+		streets:         []string{"Calle Mayor", "Calle Real", "Avenida de España", "Calle del Sol", "Paseo de la Castellana", "Calle de Alcalá"},
+		streetFormat:    "%s, %d",
+		postcodeFormat:  "#####",
+		phoneFormat:     "+34 ### ### ###",
+		country:         "España",
+		ibanCountry:     "ES",
+		tlds:            []string{"es", "com", "net"},
+		companySuffixes: []string{"S.A.", "S.L.", "S.L.U."},
+	},
+	"ru": {
+		firstNames:      []string{"Александр", "Анна", "Дмитрий", "Мария", "Максим", "Елена", "Сергей", "Ольга", "Иван", "Наталья", "Андрей", "Татьяна", "Михаил", "Екатерина"},
+		lastNames:       []string{"Иванов", "Смирнов", "Кузнецов", "Попов", "Васильев", "Петров", "Соколов", "Михайлов", "Новиков", "Федоров", "Морозов", "Волков"},
+		cities:          []string{"Москва", "Санкт-Петербург", "Новосибирск", "Екатеринбург", "Казань", "Нижний Новгород", "Самара", "Омск", "Ростов-на-Дону", "Уфа"},
+		streets:         []string{"ул. Ленина", "ул. Садовая", "ул. Мира", "ул. Гагарина", "Невский пр.", "ул. Пушкина", "ул. Советская"},
+		streetFormat:    "%s, д. %d",
+		postcodeFormat:  "######",
+		phoneFormat:     "+7 (9##) ###-##-##",
+		country:         "Россия",
+		ibanCountry:     "DE",
+		tlds:            []string{"ru", "com", "net"},
+		companySuffixes: []string{"ООО", "АО", "ПАО"},
+	},
+}
+
+// ibanFormats are the BBAN layouts per country: # is a digit, A an upper case letter
+var ibanFormats = map[string]string{
+	"DE": "##################",
+	"GB": "AAAA##############",
+	"FR": "#######################",
+	"ES": "####################",
+	"NL": "AAAA##########",
+	"IT": "A######################",
+	"CH": "#################",
+}
+
+// exampleDomains are reserved for documentation (RFC 2606), so generated addresses never reach anyone
+var exampleDomains = []string{"example.com", "example.net", "example.org"}
+
+var words = []string{
+	"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit",
+	"sed", "do", "eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore",
+	"magna", "aliqua", "enim", "ad", "minim", "veniam", "quis", "nostrud",
+	"exercitation", "ullamco", "laboris", "nisi", "aliquip", "ex", "ea", "commodo",
+}
+
+// transliteration maps letters that have no ASCII form to their usual spelling
+var transliteration = map[rune]string{
+	'ä': "ae", 'ö': "oe", 'ü': "ue", 'ß': "ss", 'à': "a", 'á': "a", 'â': "a", 'ç': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'í': "i", 'î': "i", 'ï': "i", 'ñ': "n",
+	'ó': "o", 'ô': "o", 'ú': "u", 'û': "u", 'ÿ': "y",
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ы': "y", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// ascii returns a lower case name spelled with ASCII letters, for usernames, emails and domains
+func ascii(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case transliteration[r] != "":
+			b.WriteString(transliteration[r])
+		}
+	}
+	return b.String()
+}
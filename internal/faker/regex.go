@@ -0,0 +1,108 @@
+package faker
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+// maxRepeat caps unbounded repetitions such as * and +
+const maxRepeat = 10
+
+// matching returns a random string that matches the regular expression pattern
+func (f *Faker) matching(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	var b strings.Builder
+	if err := f.generateRegex(&b, re.Simplify()); err != nil {
+		return "", fmt.Errorf("pattern %q: %w", pattern, err)
+	}
+	return b.String(), nil
+}
+
+func (f *Faker) generateRegex(b *strings.Builder, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText,
+		syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		// Anchors do not produce text
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		r, err := f.classRune(re.Rune)
+		if err != nil {
+			return err
+		}
+		b.WriteRune(r)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune(rune(' ' + f.intn('~'-' '+1)))
+	case syntax.OpCapture:
+		return f.generateRegex(b, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := f.generateRegex(b, sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		return f.generateRegex(b, re.Sub[f.intn(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := repeatRange(re)
+		for n := min + f.intn(max-min+1); n > 0; n-- {
+			if err := f.generateRegex(b, re.Sub[0]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported expression %s", re)
+	}
+	return nil
+}
+
+func repeatRange(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, maxRepeat
+	case syntax.OpPlus:
+		return 1, maxRepeat
+	case syntax.OpQuest:
+		return 0, 1
+	}
+	if re.Max < 0 {
+		return re.Min, max(re.Min, maxRepeat)
+	}
+	return re.Min, re.Max
+}
+
+// classRune picks a rune from a character class given as [lo, hi] pairs. Ranges are
+// clamped to printable ASCII when they include it, so \S or [^a] give readable text.
+func (f *Faker) classRune(ranges []rune) (rune, error) {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], ' '), min(ranges[i+1], '~')
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) == 0 {
+		printable = ranges
+	}
+	if len(printable) == 0 {
+		return 0, fmt.Errorf("empty character class")
+	}
+
+	total := 0
+	for i := 0; i < len(printable); i += 2 {
+		total += int(printable[i+1]-printable[i]) + 1
+	}
+	n := f.intn(total)
+	for i := 0; i < len(printable); i += 2 {
+		size := int(printable[i+1]-printable[i]) + 1
+		if n < size {
+			return printable[i] + rune(n), nil
+		}
+		n -= size
+	}
+	return printable[0], nil
+}
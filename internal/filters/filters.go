@@ -0,0 +1,342 @@
+package filters
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Filter transforms a value in a template pipe such as {{price | round:2}}. Args are
+// the typed values written after the colon.
+type Filter func(value interface{}, args []interface{}) (interface{}, error)
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Filter{}
+)
+
+// Register adds a filter, replacing any filter of the same name
+func Register(name string, filter Filter) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[name] = filter
+}
+
+// Lookup returns the filter registered under name
+func Lookup(name string) (Filter, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	filter, exists := registry[name]
+	return filter, exists
+}
+
+// Names returns the names of all registered filters, sorted
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply calls the filter registered under name
+func Apply(name string, value interface{}, args []interface{}) (interface{}, error) {
+	filter, exists := Lookup(name)
+	if !exists {
+		return nil, fmt.Errorf("unknown filter %q", name)
+	}
+	result, err := filter(value, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return result, nil
+}
+
+func init() {
+	Register("default", defaultFilter)
+	Register("length", length)
+	Register("round", round)
+	Register("tojson", toJSON)
+	Register("fromjson", fromJSON)
+	Register("first", first)
+	Register("last", last)
+	Register("join", join)
+	Register("split", split)
+	Register("keys", keys)
+	Register("string", func(value interface{}, _ []interface{}) (interface{}, error) { return String(value), nil })
+	Register("number", number)
+	Register("int", toInt)
+}
+
+// Args checks the number of filter arguments
+func Args(args []interface{}, min, max int) error {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			return fmt.Errorf("expected %d arguments, got %d", min, len(args))
+		}
+		return fmt.Errorf("expected %d to %d arguments, got %d", min, max, len(args))
+	}
+	return nil
+}
+
+// String formats a value as text. Objects and lists are JSON-encoded, and floats are
+// written without an exponent.
+func String(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if data, err := json.Marshal(value); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// Number converts numbers and numeric strings to float64
+func Number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// list converts lists of any element type to []interface{}
+func list(value interface{}) ([]interface{}, bool) {
+	if items, ok := value.([]interface{}); ok {
+		return items, true
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
+
+// defaultFilter replaces missing, null and empty values: {{name | default:'anonymous'}}
+func defaultFilter(value interface{}, args []interface{}) (interface{}, error) {
+	if err := Args(args, 1, 1); err != nil {
+		return nil, err
+	}
+	if value == nil || value == "" {
+		return args[0], nil
+	}
+	return value, nil
+}
+
+// length returns the length of a string, list or object; 0 for null
+func length(value interface{}, args []interface{}) (interface{}, error) {
+	if err := Args(args, 0, 0); err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return float64(0), nil
+	}
+	if s, ok := value.(string); ok {
+		return float64(len([]rune(s))), nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(rv.Len()), nil
+	}
+	return nil, fmt.Errorf("cannot take the length of %T", value)
+}
+
+// round rounds a number to the given number of decimal places, 0 by default
+func round(value interface{}, args []interface{}) (interface{}, error) {
+	if err := Args(args, 0, 1); err != nil {
+		return nil, err
+	}
+	n, ok := Number(value)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a number", value)
+	}
+	places := 0.0
+	if len(args) == 1 {
+		if places, ok = Number(args[0]); !ok {
+			return nil, fmt.Errorf("invalid number of places %v", args[0])
+		}
+	}
+	scale := math.Pow(10, places)
+	return math.Round(n*scale) / scale, nil
+}
+
+// toJSON encodes a value as JSON; strings become quoted JSON strings
+func toJSON(value interface{}, args []interface{}) (interface{}, error) {
+	if err := Args(args, 0, 0); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// fromJSON decodes JSON text into objects, lists, numbers and strings
+func fromJSON(value interface{}, args []interface{}) (interface{}, error) {
+	if err := Args(args, 0, 0); err != nil {
+		return nil, err
+	}
+	text, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// first returns the first element of a list or the first character of a string
+func first(value interface{}, args []interface{}) (interface{}, error) {
+	return element(value, args, 0)
+}
+
+// last returns the last element of a list or the last character of a string
+func last(value interface{}, args []interface{}) (interface{}, error) {
+	return element(value, args, -1)
+}
+
+func element(value interface{}, args []interface{}, index int) (interface{}, error) {
+	if err := Args(args, 0, 0); err != nil {
+		return nil, err
+	}
+	if s, ok := value.(string); ok {
+		runes := []rune(s)
+		if len(runes) == 0 {
+			return "", nil
+		}
+		if index < 0 {
+			index += len(runes)
+		}
+		return string(runes[index]), nil
+	}
+	items, ok := list(value)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a list", value)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	if index < 0 {
+		index += len(items)
+	}
+	return items[index], nil
+}
+
+// join joins the elements of a list with a separator, "," by default
+func join(value interface{}, args []interface{}) (interface{}, error) {
+	if err := Args(args, 0, 1); err != nil {
+		return nil, err
+	}
+	items, ok := list(value)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a list", value)
+	}
+	separator := ","
+	if len(args) == 1 {
+		separator = String(args[0])
+	}
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = String(item)
+	}
+	return strings.Join(parts, separator), nil
+}
+
+// split splits a string into a list, on "," by default
+func split(value interface{}, args []interface{}) (interface{}, error) {
+	if err := Args(args, 0, 1); err != nil {
+		return nil, err
+	}
+	separator := ","
+	if len(args) == 1 {
+		separator = String(args[0])
+	}
+	parts := strings.Split(String(value), separator)
+	items := make([]interface{}, len(parts))
+	for i, part := range parts {
+		items[i] = part
+	}
+	return items, nil
+}
+
+// keys returns the sorted keys of an object
+func keys(value interface{}, args []interface{}) (interface{}, error) {
+	if err := Args(args, 0, 0); err != nil {
+		return nil, err
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%T is not an object", value)
+	}
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	items := make([]interface{}, len(names))
+	for i, name := range names {
+		items[i] = name
+	}
+	return items, nil
+}
+
+// number converts numeric strings to numbers
+func number(value interface{}, args []interface{}) (interface{}, error) {
+	if err := Args(args, 0, 0); err != nil {
+		return nil, err
+	}
+	n, ok := Number(value)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a number", value)
+	}
+	return n, nil
+}
+
+// toInt converts a number or numeric string to a whole number, truncating decimals
+func toInt(value interface{}, args []interface{}) (interface{}, error) {
+	if err := Args(args, 0, 0); err != nil {
+		return nil, err
+	}
+	n, ok := Number(value)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a number", value)
+	}
+	return math.Trunc(n), nil
+}
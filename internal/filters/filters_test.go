@@ -0,0 +1,64 @@
+package filters
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		args  []interface{}
+		want  interface{}
+	}{
+		{"default", nil, []interface{}{float64(0)}, float64(0)},
+		{"default", "", []interface{}{"n/a"}, "n/a"},
+		{"default", "set", []interface{}{"n/a"}, "set"},
+		{"length", []interface{}{1, 2, 3}, nil, float64(3)},
+		{"length", "héllo", nil, float64(5)},
+		{"length", map[string]interface{}{"a": 1}, nil, float64(1)},
+		{"round", 3.14159, []interface{}{float64(2)}, 3.14},
+		{"round", "2.5", nil, float64(3)},
+		{"tojson", map[string]interface{}{"a": 1}, nil, `{"a":1}`},
+		{"fromjson", `[1,"x"]`, nil, []interface{}{float64(1), "x"}},
+		{"first", []interface{}{"a", "b"}, nil, "a"},
+		{"last", []string{"a", "b"}, nil, "b"},
+		{"join", []interface{}{"a", float64(1)}, []interface{}{"-"}, "a-1"},
+		{"split", "a,b", nil, []interface{}{"a", "b"}},
+		{"keys", map[string]interface{}{"b": 1, "a": 2}, nil, []interface{}{"a", "b"}},
+		{"int", "12.9", nil, float64(12)},
+		{"string", float64(1.5), nil, "1.5"},
+	}
+	for _, tt := range tests {
+		got, err := Apply(tt.name, tt.value, tt.args)
+		if err != nil {
+			t.Errorf("%s(%v): unexpected error: %v", tt.name, tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s(%v): expected %#v, got %#v", tt.name, tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	if _, err := Apply("missing", "x", nil); err == nil {
+		t.Error("Expected an error for an unknown filter")
+	}
+	if _, err := Apply("round", "abc", nil); err == nil {
+		t.Error("Expected an error for a non-numeric value")
+	}
+	if _, err := Apply("length", "x", []interface{}{1}); err == nil {
+		t.Error("Expected an error for an unexpected argument")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("twice", func(value interface{}, _ []interface{}) (interface{}, error) {
+		return String(value) + String(value), nil
+	})
+	if got, _ := Apply("twice", "ab", nil); got != "abab" {
+		t.Errorf("Expected abab, got %v", got)
+	}
+}
@@ -69,8 +69,8 @@ func NewClient(serverAddr string, useInsecure bool, log *logger.Logger) (*Client
 	}, nil
 }
 
-// Execute performs a gRPC request
-func (c *Client) Execute(req *Request) (*Response, error) {
+// Execute performs a gRPC request. The call is bounded by both ctx and req.Timeout.
+func (c *Client) Execute(ctx context.Context, req *Request) (*Response, error) {
 	start := time.Now()
 
 	c.logger.Debug("Making gRPC request",
@@ -79,7 +79,7 @@ func (c *Client) Execute(req *Request) (*Response, error) {
 		"server", req.ServerAddr)
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), req.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, req.Timeout)
 	defer cancel()
 
 	// Add metadata if provided
@@ -124,7 +124,7 @@ func TestExecuteRequest(t *testing.T) {
 	// This test will fail if the bug is not fixed
 	// The bug was that FindSymbol("OperationService.GetCustomerOperations") failed
 	// The fix adds fallback to find service first, then method within service
-	_, err = client.Execute(req)
+	_, err = client.Execute(context.Background(), req)
 	if err != nil {
 		// Check if error is about method not found (the bug)
 		errMsg := err.Error()
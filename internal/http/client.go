@@ -2,6 +2,7 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
@@ -30,6 +31,8 @@ type Request struct {
 	Query   map[string]string
 	Timeout time.Duration
 	Auth    *Auth
+
+	NoRedirects bool // Return 3xx responses instead of following them
 }
 
 // Auth represents authentication configuration
@@ -62,6 +65,16 @@ type Response struct {
 	Body       []byte
 	Duration   time.Duration
 	Error      error
+	Request    *SentRequest // The request as it was sent, after query, auth and body encoding
+	Redirects  []string     // URLs of the redirects that were followed, in order
+}
+
+// SentRequest is the final form of a request sent by the client
+type SentRequest struct {
+	Method  string
+	URL     string
+	Headers map[string][]string
+	Body    []byte
 }
 
 // NewClient creates a new HTTP client
@@ -92,10 +105,17 @@ func NewClient(timeout time.Duration, log *logger.Logger) *Client {
 	}
 }
 
-// Execute performs an HTTP request
-func (c *Client) Execute(req *Request) (*Response, error) {
+// Execute performs an HTTP request. The request is aborted when ctx is cancelled.
+func (c *Client) Execute(ctx context.Context, req *Request) (*Response, error) {
 	start := time.Now()
 
+	// Per-request timeout on top of the client-wide one
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+
 	// Build URL with query parameters
 	finalURL := req.URL
 	if len(req.Query) > 0 {
@@ -113,7 +133,7 @@ func (c *Client) Execute(req *Request) (*Response, error) {
 	}
 
 	// Create HTTP request
-	httpReq, err := http.NewRequest(req.Method, finalURL, nil)
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, finalURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -131,8 +151,9 @@ func (c *Client) Execute(req *Request) (*Response, error) {
 	}
 
 	// Set body if provided
+	var bodyBytes []byte
 	if req.Body != nil {
-		bodyBytes, err := c.serializeBody(req.Body)
+		bodyBytes, err = c.serializeBody(req.Body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to serialize body: %w", err)
 		}
@@ -151,8 +172,20 @@ func (c *Client) Execute(req *Request) (*Response, error) {
 		"headers", req.Headers,
 		"auth_type", authType)
 
-	// Execute request
-	resp, err := c.httpClient.Do(httpReq)
+	// Execute request, recording the redirects that are followed
+	var redirects []string
+	client := *c.httpClient
+	client.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+		if req.NoRedirects {
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		redirects = append(redirects, next.URL.String())
+		return nil
+	}
+	resp, err := client.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -181,6 +214,13 @@ func (c *Client) Execute(req *Request) (*Response, error) {
 		Headers:    resp.Header,
 		Body:       body,
 		Duration:   duration,
+		Request: &SentRequest{
+			Method:  httpReq.Method,
+			URL:     httpReq.URL.String(),
+			Headers: httpReq.Header.Clone(),
+			Body:    bodyBytes,
+		},
+		Redirects: redirects,
 	}, nil
 }
 
@@ -235,7 +275,7 @@ func (c *Client) applyOAuthAuth(req *http.Request, auth *Auth) error {
 
 	// For now, we'll support client credentials grant
 	// In a full implementation, you'd want to handle token caching and refresh
-	token, err := c.getOAuthToken(auth.OAuth)
+	token, err := c.getOAuthToken(req.Context(), auth.OAuth)
 	if err != nil {
 		return fmt.Errorf("failed to get OAuth token: %w", err)
 	}
@@ -246,7 +286,7 @@ func (c *Client) applyOAuthAuth(req *http.Request, auth *Auth) error {
 }
 
 // getOAuthToken retrieves an OAuth token
-func (c *Client) getOAuthToken(config *OAuthConfig) (string, error) {
+func (c *Client) getOAuthToken(ctx context.Context, config *OAuthConfig) (string, error) {
 	// This is a simplified OAuth implementation
 	// In production, you'd want proper token management with caching and refresh
 
@@ -264,7 +304,7 @@ func (c *Client) getOAuthToken(config *OAuthConfig) (string, error) {
 		data.Set("password", config.Password)
 	}
 
-	req, err := http.NewRequest("POST", config.TokenURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", config.TokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return "", err
 	}
@@ -362,10 +402,15 @@ func (r *Response) IsSuccess() bool {
 	return r.StatusCode >= 200 && r.StatusCode < 300
 }
 
-// GetHeader returns a specific header value
+// GetHeader returns a specific header value. Names are matched case-insensitively.
 func (r *Response) GetHeader(name string) string {
 	if values, exists := r.Headers[name]; exists && len(values) > 0 {
 		return values[0]
 	}
+	for key, values := range r.Headers {
+		if strings.EqualFold(key, name) && len(values) > 0 {
+			return values[0]
+		}
+	}
 	return ""
 }
@@ -1,6 +1,9 @@
 package http
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -91,3 +94,34 @@ func TestResponseMethods(t *testing.T) {
 		t.Error("JSON body should not be nil")
 	}
 }
+
+func TestExecuteRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/new?from=old", http.StatusMovedPermanently)
+		case "/new":
+			http.Redirect(w, r, "/final", http.StatusFound)
+		default:
+			w.Write([]byte("final"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(5*time.Second, logger.New())
+	response, err := client.Execute(context.Background(), &Request{Method: "GET", URL: server.URL + "/old"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.StatusCode != 200 || len(response.Redirects) != 2 || response.Redirects[1] != server.URL+"/final" {
+		t.Errorf("Expected two redirects to be followed, got %d %v", response.StatusCode, response.Redirects)
+	}
+
+	response, err = client.Execute(context.Background(), &Request{Method: "GET", URL: server.URL + "/old", NoRedirects: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.StatusCode != http.StatusMovedPermanently || response.GetHeader("location") != "/new?from=old" || len(response.Redirects) != 0 {
+		t.Errorf("Expected the redirect to be returned, got %d %v", response.StatusCode, response.Headers)
+	}
+}
@@ -0,0 +1,284 @@
+// Package jsondiff compares decoded JSON values path by path.
+package jsondiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Kinds of difference
+const (
+	Missing = "missing" // The expected value has a key or element the actual one lacks
+	Extra   = "extra"   // The actual value has a key or element the expected one lacks
+	Changed = "changed" // Both have a value of the same type, but it differs
+	Type    = "type"    // The values have different types
+)
+
+// Difference is a difference between two values at a path such as $.items[2].id
+type Difference struct {
+	Path     string      `json:"path"`
+	Kind     string      `json:"kind"`
+	Expected interface{} `json:"expected,omitempty"`
+	Actual   interface{} `json:"actual,omitempty"`
+}
+
+// String formats the difference as "path: message"
+func (d Difference) String() string {
+	switch d.Kind {
+	case Missing:
+		return fmt.Sprintf("%s: missing, expected %s", d.Path, format(d.Expected))
+	case Extra:
+		return fmt.Sprintf("%s: unexpected %s", d.Path, format(d.Actual))
+	case Type:
+		return fmt.Sprintf("%s: expected %s %s, got %s %s", d.Path, kind(d.Expected), format(d.Expected), kind(d.Actual), format(d.Actual))
+	default:
+		return fmt.Sprintf("%s: expected %s, got %s", d.Path, format(d.Expected), format(d.Actual))
+	}
+}
+
+// Options changes how values are compared
+type Options struct {
+	Ignore            []string // Paths left out of the comparison, such as $.id, $.items[*].created_at or $..updated_at
+	IgnoreOrder       bool     // Arrays are equal when they hold the same elements in any order
+	IgnoreExtraFields bool     // Keys of the actual objects that the expected ones lack are not differences
+	Tolerance         float64  // Numbers are equal when they differ by no more than this
+}
+
+// Compare returns the differences between an expected and an actual value, ordered
+// by path. Numbers are equal whatever their Go type.
+func Compare(expected, actual interface{}, options Options) ([]Difference, error) {
+	c := &comparer{options: options}
+	for _, path := range options.Ignore {
+		pattern, err := parsePattern(path)
+		if err != nil {
+			return nil, err
+		}
+		c.ignore = append(c.ignore, pattern)
+	}
+	c.compare(nil, expected, actual)
+	return c.differences, nil
+}
+
+// Summary joins the first differences into one line, with the number of the others
+func Summary(differences []Difference, limit int) string {
+	var parts []string
+	for i, difference := range differences {
+		if i == limit {
+			parts = append(parts, fmt.Sprintf("and %d more", len(differences)-limit))
+			break
+		}
+		parts = append(parts, difference.String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+type comparer struct {
+	options     Options
+	ignore      []pattern
+	differences []Difference
+}
+
+func (c *comparer) ignored(path []segment) bool {
+	for _, pattern := range c.ignore {
+		if pattern.match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *comparer) add(path []segment, kind string, expected, actual interface{}) {
+	c.differences = append(c.differences, Difference{Path: formatPath(path), Kind: kind, Expected: expected, Actual: actual})
+}
+
+func (c *comparer) compare(path []segment, expected, actual interface{}) {
+	if c.ignored(path) {
+		return
+	}
+	if kind(expected) != kind(actual) {
+		c.add(path, Type, expected, actual)
+		return
+	}
+
+	switch expected := expected.(type) {
+	case map[string]interface{}:
+		actual := actual.(map[string]interface{})
+		for _, key := range sortedKeys(expected) {
+			child := append(path[:len(path):len(path)], segment{key: key})
+			if value, ok := actual[key]; ok {
+				c.compare(child, expected[key], value)
+			} else if !c.ignored(child) {
+				c.add(child, Missing, expected[key], nil)
+			}
+		}
+		if c.options.IgnoreExtraFields {
+			return
+		}
+		for _, key := range sortedKeys(actual) {
+			child := append(path[:len(path):len(path)], segment{key: key})
+			if _, ok := expected[key]; !ok && !c.ignored(child) {
+				c.add(child, Extra, nil, actual[key])
+			}
+		}
+	case []interface{}:
+		actual := actual.([]interface{})
+		if c.options.IgnoreOrder {
+			c.compareUnordered(path, expected, actual)
+			return
+		}
+		for i := 0; i < len(expected) || i < len(actual); i++ {
+			child := append(path[:len(path):len(path)], segment{index: i, isIndex: true})
+			switch {
+			case i >= len(actual):
+				if !c.ignored(child) {
+					c.add(child, Missing, expected[i], nil)
+				}
+			case i >= len(expected):
+				if !c.ignored(child) {
+					c.add(child, Extra, nil, actual[i])
+				}
+			default:
+				c.compare(child, expected[i], actual[i])
+			}
+		}
+	default:
+		if !c.scalarEqual(expected, actual) {
+			c.add(path, Changed, expected, actual)
+		}
+	}
+}
+
+// compareUnordered matches every expected element with an equal actual element. The
+// elements left over are compared in order, and those without a counterpart are
+// missing or extra.
+func (c *comparer) compareUnordered(path []segment, expected, actual []interface{}) {
+	used := make([]bool, len(actual))
+	var unmatched []int
+	for i, want := range expected {
+		child := append(path[:len(path):len(path)], segment{index: i, isIndex: true})
+		found := false
+		for j, got := range actual {
+			if !used[j] && c.equal(child, want, got) {
+				used[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			unmatched = append(unmatched, i)
+		}
+	}
+
+	j := 0
+	for _, i := range unmatched {
+		child := append(path[:len(path):len(path)], segment{index: i, isIndex: true})
+		for j < len(actual) && used[j] {
+			j++
+		}
+		if j < len(actual) {
+			used[j] = true
+			c.compare(child, expected[i], actual[j])
+		} else if !c.ignored(child) {
+			c.add(child, Missing, expected[i], nil)
+		}
+	}
+	for j, got := range actual {
+		child := append(path[:len(path):len(path)], segment{index: j, isIndex: true})
+		if !used[j] && !c.ignored(child) {
+			c.add(child, Extra, nil, got)
+		}
+	}
+}
+
+// equal reports whether two values at a path have no differences
+func (c *comparer) equal(path []segment, expected, actual interface{}) bool {
+	sub := &comparer{options: c.options, ignore: c.ignore}
+	sub.compare(path, expected, actual)
+	return len(sub.differences) == 0
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// kind returns the JSON type of a value
+func kind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	if _, ok := number(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func number(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func (c *comparer) scalarEqual(expected, actual interface{}) bool {
+	if a, ok := number(expected); ok {
+		b, _ := number(actual)
+		return a == b || math.Abs(a-b) <= c.options.Tolerance
+	}
+	return reflect.DeepEqual(expected, actual)
+}
+
+// format renders a value as compact JSON, shortened for messages
+func format(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	const max = 80
+	if runes := []rune(string(data)); len(runes) > max {
+		return string(runes[:max]) + "..."
+	}
+	return string(data)
+}
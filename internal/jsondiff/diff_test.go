@@ -0,0 +1,122 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return value
+}
+
+func TestCompare(t *testing.T) {
+	expected := decode(t, `{"id": 1, "name": "Ann", "tags": ["a", "b"], "address": {"city": "Oslo", "zip": "0150"}, "created_at": "2024-01-01"}`)
+	actual := decode(t, `{"id": "1", "name": "Bob", "tags": ["a"], "address": {"city": "Oslo"}, "created_at": "2024-05-05", "role": "admin"}`)
+
+	differences, err := Compare(expected, actual, Options{Ignore: []string{"$.created_at"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var got []string
+	for _, difference := range differences {
+		got = append(got, difference.String())
+	}
+	want := []string{
+		`$.address.zip: missing, expected "0150"`,
+		`$.id: expected number 1, got string "1"`,
+		`$.name: expected "Ann", got "Bob"`,
+		`$.tags[1]: missing, expected "b"`,
+		`$.role: unexpected "admin"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	if differences, _ := Compare(map[string]interface{}{"n": 2}, decode(t, `{"n": 2.0}`), Options{}); len(differences) != 0 {
+		t.Errorf("Expected numbers of different types to be equal, got %v", differences)
+	}
+	if summary := Summary(differences, 2); summary != `$.address.zip: missing, expected "0150"; $.id: expected number 1, got string "1"; and 3 more` {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+}
+
+func TestIgnore(t *testing.T) {
+	expected := decode(t, `{"items": [{"id": 1, "meta": {"updated_at": 1}}, {"id": 2}], "meta": {"updated_at": 1, "request": "x"}, "odd key": 1}`)
+	actual := decode(t, `{"items": [{"id": 8, "meta": {"updated_at": 2}}, {"id": 9}], "meta": {"updated_at": 2, "request": "y"}, "odd key": 2}`)
+
+	tests := []struct {
+		ignore []string
+		want   []string
+	}{
+		{nil, []string{`$.items[0].id`, `$.items[0].meta.updated_at`, `$.items[1].id`, `$.meta.request`, `$.meta.updated_at`, `$["odd key"]`}},
+		{[]string{"$.items[*].id", `$["odd key"]`}, []string{`$.items[0].meta.updated_at`, `$.meta.request`, `$.meta.updated_at`}},
+		{[]string{"$..updated_at", "$.items[1]"}, []string{`$.items[0].id`, `$.meta.request`, `$["odd key"]`}},
+		{[]string{"$.items", "$.meta", "$['odd key']"}, nil},
+		{[]string{"$.*"}, nil},
+	}
+	for _, tt := range tests {
+		differences, err := Compare(expected, actual, Options{Ignore: tt.ignore})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var got []string
+		for _, difference := range differences {
+			got = append(got, difference.Path)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Ignoring %q: expected %q, got %q", tt.ignore, tt.want, got)
+		}
+	}
+
+	for _, path := range []string{"$.items[", "$.items[x]", "$.", "items"} {
+		if _, err := Compare(expected, actual, Options{Ignore: []string{path}}); err == nil {
+			t.Errorf("Expected an error for %q", path)
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	expected := decode(t, `{"items": [{"sku": "A", "qty": 1}, {"sku": "B", "qty": 2}, {"sku": "C", "qty": 3}], "price": 9.99}`)
+
+	tests := []struct {
+		name    string
+		actual  string
+		options Options
+		want    []string
+	}{
+		{"order matters", `{"items": [{"sku": "B", "qty": 2}, {"sku": "A", "qty": 1}, {"sku": "C", "qty": 3}], "price": 9.99}`, Options{},
+			[]string{`$.items[0].qty: expected 1, got 2`, `$.items[0].sku: expected "A", got "B"`, `$.items[1].qty: expected 2, got 1`, `$.items[1].sku: expected "B", got "A"`}},
+		{"ignore order", `{"items": [{"sku": "B", "qty": 2}, {"sku": "C", "qty": 3}, {"sku": "A", "qty": 1}], "price": 9.99}`, Options{IgnoreOrder: true}, nil},
+		{"ignore order with a change", `{"items": [{"sku": "C", "qty": 3}, {"sku": "B", "qty": 5}, {"sku": "A", "qty": 1}], "price": 9.99}`, Options{IgnoreOrder: true},
+			[]string{`$.items[1].qty: expected 2, got 5`}},
+		{"ignore order with a missing element", `{"items": [{"sku": "C", "qty": 3}, {"sku": "A", "qty": 1}], "price": 9.99}`, Options{IgnoreOrder: true},
+			[]string{`$.items[1]: missing, expected {"qty":2,"sku":"B"}`}},
+		{"extra fields", `{"items": [{"sku": "A", "qty": 1, "name": "a"}, {"sku": "B", "qty": 2}, {"sku": "C", "qty": 3}], "price": 9.99, "currency": "EUR"}`, Options{},
+			[]string{`$.items[0].name: unexpected "a"`, `$.currency: unexpected "EUR"`}},
+		{"ignore extra fields", `{"items": [{"sku": "A", "qty": 1, "name": "a"}, {"sku": "B", "qty": 2}, {"sku": "C", "qty": 3}], "price": 9.99, "currency": "EUR"}`, Options{IgnoreExtraFields: true}, nil},
+		{"tolerance", `{"items": [{"sku": "A", "qty": 1}, {"sku": "B", "qty": 2}, {"sku": "C", "qty": 3}], "price": 10}`, Options{Tolerance: 0.01}, nil},
+		{"outside tolerance", `{"items": [{"sku": "A", "qty": 1}, {"sku": "B", "qty": 2}, {"sku": "C", "qty": 3}], "price": 10.5}`, Options{Tolerance: 0.01},
+			[]string{`$.price: expected 9.99, got 10.5`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			differences, err := Compare(expected, decode(t, tt.actual), tt.options)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var got []string
+			for _, difference := range differences {
+				got = append(got, difference.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
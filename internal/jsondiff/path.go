@@ -0,0 +1,139 @@
+package jsondiff
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// segment is an object key or an array index of a path
+type segment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// formatPath formats a path as $.items[2].id, quoting keys that are not identifiers
+func formatPath(path []segment) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, s := range path {
+		switch {
+		case s.isIndex:
+			fmt.Fprintf(&b, "[%d]", s.index)
+		case identifier.MatchString(s.key):
+			b.WriteString("." + s.key)
+		default:
+			fmt.Fprintf(&b, "[%q]", s.key)
+		}
+	}
+	return b.String()
+}
+
+// step is a segment of an ignore pattern: a key, an index, or * for any key or
+// index, optionally preceded by .. to match at any depth
+type step struct {
+	segment
+	any       bool
+	recursive bool
+}
+
+type pattern []step
+
+// parsePattern parses an ignore pattern such as $.id, $.items[*].id, $["a b"] or $..id
+func parsePattern(path string) (pattern, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var p pattern
+	for rest != "" {
+		var s step
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			s.recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				break
+			}
+			fallthrough
+		case strings.HasPrefix(rest, "."):
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			if name == "" {
+				return nil, fmt.Errorf("invalid ignore path %q: empty key", path)
+			}
+			if name == "*" {
+				s.any = true
+			} else {
+				s.key = name
+			}
+			p = append(p, s)
+			continue
+		case !strings.HasPrefix(rest, "["):
+			return nil, fmt.Errorf("invalid ignore path %q: unexpected %q", path, rest)
+		}
+
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			return nil, fmt.Errorf("invalid ignore path %q: missing ]", path)
+		}
+		inner := rest[1:end]
+		rest = rest[end+1:]
+		switch {
+		case inner == "*":
+			s.any = true
+		case strings.HasPrefix(inner, `"`) || strings.HasPrefix(inner, "'"):
+			key, err := strconv.Unquote(`"` + strings.Trim(inner, `"'`) + `"`)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ignore path %q: %v", path, err)
+			}
+			s.key = key
+		default:
+			index, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ignore path %q: invalid index %q", path, inner)
+			}
+			s.index, s.isIndex = index, true
+		}
+		p = append(p, s)
+	}
+	return p, nil
+}
+
+// match reports whether the pattern matches the whole path
+func (p pattern) match(path []segment) bool {
+	if len(p) == 0 {
+		return len(path) == 0
+	}
+	first := p[0]
+	if first.recursive {
+		first.recursive = false
+		rest := append(pattern{first}, p[1:]...)
+		for i := range path {
+			if rest.match(path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 || !first.matches(path[0]) {
+		return false
+	}
+	return p[1:].match(path[1:])
+}
+
+func (s step) matches(segment segment) bool {
+	if s.any {
+		return true
+	}
+	if s.isIndex {
+		return segment.isIndex && segment.index == s.index
+	}
+	return !segment.isIndex && segment.key == s.key
+}
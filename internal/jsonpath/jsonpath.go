@@ -0,0 +1,460 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Extract returns the value at a JSONPath such as "$.items[0].id", "$[last]",
+// "$.users[*].email" or "$.orders[?(@.total > 100)].id".
+func Extract(data interface{}, path string) (interface{}, error) {
+	// Поддержка сложных путей с массивами: $.widgets[0].widget
+	if path == "$" {
+		return data, nil
+	}
+
+	if strings.HasPrefix(path, "$.") {
+		pathExpr := strings.TrimPrefix(path, "$.")
+		// Разбиваем путь на части с учётом индексов
+		var parts []string
+		var buf strings.Builder
+		inBracket := false
+		for _, r := range pathExpr {
+			if r == '.' && !inBracket {
+				parts = append(parts, buf.String())
+				buf.Reset()
+			} else {
+				if r == '[' {
+					inBracket = true
+				}
+				if r == ']' {
+					inBracket = false
+				}
+				buf.WriteRune(r)
+			}
+		}
+		if buf.Len() > 0 {
+			parts = append(parts, buf.String())
+		}
+		current := data
+		for i, part := range parts {
+			// Special handling for "length" property on arrays
+			if part == "length" {
+				if arr, ok := current.([]interface{}); ok {
+					return len(arr), nil
+				}
+				return nil, fmt.Errorf("cannot get length of non-array")
+			}
+
+			// Массив с индексом или фильтром: key[index] или key[?(...)]
+			if strings.Contains(part, "[") && strings.HasSuffix(part, "]") {
+				openBracket := strings.Index(part, "[")
+				closeBracket := strings.LastIndex(part, "]")
+				key := part[:openBracket]
+				indexStr := part[openBracket+1 : closeBracket]
+
+				// Получить массив из текущего объекта или использовать текущий, если это уже массив
+				var arrayData []interface{}
+				if key != "" {
+					if mapData, ok := current.(map[string]interface{}); ok {
+						if array, exists := mapData[key]; exists {
+							if arr, ok := array.([]interface{}); ok {
+								arrayData = arr
+							} else {
+								return nil, fmt.Errorf("key %s is not an array", key)
+							}
+						} else {
+							return nil, fmt.Errorf("key not found: %s", key)
+						}
+					} else {
+						return nil, fmt.Errorf("cannot access key on non-object")
+					}
+				} else {
+					// key пустой, значит применяем фильтр к текущему массиву
+					if arr, ok := current.([]interface{}); ok {
+						arrayData = arr
+					} else {
+						return nil, fmt.Errorf("cannot apply array filter to non-array")
+					}
+				}
+
+				// Обработать фильтр или индекс
+				result, err := processArrayAccessor(arrayData, indexStr)
+				if err != nil {
+					return nil, err
+				}
+
+				// Если результат - массив и есть еще части пути, нужно применить путь к каждому элементу
+				if resultArray, ok := result.([]interface{}); ok && i < len(parts)-1 {
+					// Есть еще части пути после массива - применить к каждому элементу
+					remainingPath := strings.Join(parts[i+1:], ".")
+					if !strings.HasPrefix(remainingPath, "$") {
+						remainingPath = "$." + remainingPath
+					}
+
+					var mappedResults []interface{}
+					for _, item := range resultArray {
+						value, err := Extract(item, remainingPath)
+						if err != nil {
+							// Пропускаем элементы, где путь не найден
+							continue
+						}
+						mappedResults = append(mappedResults, value)
+					}
+					return mappedResults, nil
+				}
+
+				current = result
+			} else {
+				// Обычный ключ
+				if mapData, ok := current.(map[string]interface{}); ok {
+					if value, exists := mapData[part]; exists {
+						current = value
+					} else {
+						return nil, fmt.Errorf("key not found: %s", part)
+					}
+				} else {
+					return nil, fmt.Errorf("cannot access key on non-object")
+				}
+			}
+		}
+		return current, nil
+	}
+
+	if strings.HasPrefix(path, "$[") {
+		// Handle paths like $[0] or $[filter] or $[filter].field or $[*].field
+		closeBracket := strings.Index(path, "]")
+		if closeBracket == -1 {
+			return nil, fmt.Errorf("unclosed bracket in path: %s", path)
+		}
+
+		indexStr := path[2:closeBracket]
+		remainingPath := path[closeBracket+1:]
+
+		// Apply array accessor
+		if arrayData, ok := data.([]interface{}); ok {
+			result, err := processArrayAccessor(arrayData, indexStr)
+			if err != nil {
+				return nil, err
+			}
+
+			// If there's a remaining path, continue processing
+			if remainingPath != "" {
+				if strings.HasPrefix(remainingPath, ".") {
+					remainingPath = "$" + remainingPath
+				}
+
+				// Если результат - массив (например, при использовании [*]), применить путь к каждому элементу
+				if resultArray, ok := result.([]interface{}); ok {
+					var mappedResults []interface{}
+					for _, item := range resultArray {
+						value, err := Extract(item, remainingPath)
+						if err != nil {
+							// Пропускаем элементы, где путь не найден
+							continue
+						}
+						mappedResults = append(mappedResults, value)
+					}
+					return mappedResults, nil
+				}
+
+				return Extract(result, remainingPath)
+			}
+
+			return result, nil
+		}
+		return nil, fmt.Errorf("root element is not an array")
+	}
+
+	return nil, fmt.Errorf("unsupported JSON path: %s", path)
+}
+
+// processArrayAccessor handles array access with index, filter, or special selectors
+func processArrayAccessor(arrayData []interface{}, accessor string) (interface{}, error) {
+	if len(arrayData) == 0 {
+		return nil, nil
+	}
+
+	// Filter expression: ?(@.field op value) or ?(@.field)
+	if strings.HasPrefix(accessor, "?(@.") && strings.HasSuffix(accessor, ")") {
+		return filterArray(arrayData, accessor)
+	}
+
+	// Wildcard: return all elements
+	if accessor == "*" {
+		return arrayData, nil
+	}
+
+	// Last element
+	if accessor == "last" || accessor == "-1" {
+		return arrayData[len(arrayData)-1], nil
+	}
+
+	// Slice: start:end
+	if strings.Contains(accessor, ":") {
+		return sliceArray(arrayData, accessor)
+	}
+
+	// Simple numeric index
+	index, err := strconv.Atoi(accessor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid array accessor: %s", accessor)
+	}
+
+	// Handle negative indices
+	if index < 0 {
+		index = len(arrayData) + index
+	}
+
+	if index >= 0 && index < len(arrayData) {
+		return arrayData[index], nil
+	}
+
+	return nil, fmt.Errorf("array index out of bounds: %d (length: %d)", index, len(arrayData))
+}
+
+// filterArray filters array elements based on condition
+func filterArray(arrayData []interface{}, filter string) (interface{}, error) {
+	// Remove ?(@. prefix and ) suffix
+	filter = strings.TrimPrefix(filter, "?(@.")
+	filter = strings.TrimSuffix(filter, ")")
+
+	// Parse filter expression: field op value or just field
+	var field, operator, expectedValue string
+
+	// Try to find operator
+	operators := []string{"==", "!=", ">=", "<=", ">", "<", "="}
+	for _, op := range operators {
+		if strings.Contains(filter, op) {
+			parts := strings.SplitN(filter, op, 2)
+			field = strings.TrimSpace(parts[0])
+			operator = op
+			if len(parts) > 1 {
+				expectedValue = strings.TrimSpace(parts[1])
+				// Remove quotes if present
+				expectedValue = strings.Trim(expectedValue, "\"'")
+			}
+			break
+		}
+	}
+
+	// No operator found, check for boolean field
+	if operator == "" {
+		field = strings.TrimSpace(filter)
+	}
+
+	// Find all matching elements
+	var matchedItems []interface{}
+	for _, item := range arrayData {
+		mapItem, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		// Extract field value using dot notation if needed
+		fieldValue, err := extractFieldValue(mapItem, field)
+		if err != nil {
+			continue
+		}
+
+		// Check condition
+		matched := false
+		if operator == "" {
+			// Boolean check - field exists and is truthy
+			matched = isTruthy(fieldValue)
+		} else {
+			matched = compareFieldValue(fieldValue, operator, expectedValue)
+		}
+
+		if matched {
+			matchedItems = append(matchedItems, item)
+		}
+	}
+
+	// Return array of all matches, or error if none found
+	if len(matchedItems) == 0 {
+		return nil, fmt.Errorf("no matching element found in array for filter: %s", filter)
+	}
+
+	// If only one match, return it directly (for backward compatibility)
+	// If multiple matches, return array to support subsequent field extraction like [?(...)].name
+	if len(matchedItems) == 1 {
+		return matchedItems[0], nil
+	}
+
+	return matchedItems, nil
+}
+
+// extractFieldValue extracts a field value, supporting dot notation
+func extractFieldValue(obj map[string]interface{}, field string) (interface{}, error) {
+	if !strings.Contains(field, ".") {
+		if val, exists := obj[field]; exists {
+			return val, nil
+		}
+		return nil, fmt.Errorf("field not found: %s", field)
+	}
+
+	// Handle nested fields
+	parts := strings.Split(field, ".")
+	current := interface{}(obj)
+	for _, part := range parts {
+		if mapData, ok := current.(map[string]interface{}); ok {
+			if val, exists := mapData[part]; exists {
+				current = val
+			} else {
+				return nil, fmt.Errorf("field not found: %s", part)
+			}
+		} else {
+			return nil, fmt.Errorf("cannot access field on non-object")
+		}
+	}
+	return current, nil
+}
+
+// isTruthy checks if a value is truthy
+func isTruthy(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+
+	switch val := value.(type) {
+	case bool:
+		return val
+	case string:
+		return val != ""
+	case int, int8, int16, int32, int64:
+		return val != 0
+	case float32, float64:
+		return val != 0.0
+	default:
+		return true
+	}
+}
+
+// compareFieldValue compares a field value against expected value using operator
+func compareFieldValue(fieldValue interface{}, operator, expectedValue string) bool {
+	// Try numeric comparison first
+	fieldFloat, fieldOk := toFloat64(fieldValue)
+	expectedFloat, expectedOk := toFloat64(expectedValue)
+
+	if fieldOk && expectedOk {
+		switch operator {
+		case "==", "=":
+			return fieldFloat == expectedFloat
+		case "!=":
+			return fieldFloat != expectedFloat
+		case ">":
+			return fieldFloat > expectedFloat
+		case "<":
+			return fieldFloat < expectedFloat
+		case ">=":
+			return fieldFloat >= expectedFloat
+		case "<=":
+			return fieldFloat <= expectedFloat
+		}
+	}
+
+	// Try boolean comparison
+	if fieldBool, ok := fieldValue.(bool); ok {
+		expectedBool := expectedValue == "true"
+		switch operator {
+		case "==", "=":
+			return fieldBool == expectedBool
+		case "!=":
+			return fieldBool != expectedBool
+		}
+	}
+
+	// String comparison
+	fieldStr := fmt.Sprintf("%v", fieldValue)
+	switch operator {
+	case "==", "=":
+		return fieldStr == expectedValue
+	case "!=":
+		return fieldStr != expectedValue
+	case ">":
+		return fieldStr > expectedValue
+	case "<":
+		return fieldStr < expectedValue
+	case ">=":
+		return fieldStr >= expectedValue
+	case "<=":
+		return fieldStr <= expectedValue
+	}
+
+	return false
+}
+
+// sliceArray returns a slice of array
+func sliceArray(arrayData []interface{}, sliceExpr string) (interface{}, error) {
+	parts := strings.Split(sliceExpr, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid slice expression: %s", sliceExpr)
+	}
+
+	start := 0
+	end := len(arrayData)
+
+	if parts[0] != "" {
+		var err error
+		start, err = strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid slice start: %s", parts[0])
+		}
+		if start < 0 {
+			start = len(arrayData) + start
+		}
+	}
+
+	if parts[1] != "" {
+		var err error
+		end, err = strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid slice end: %s", parts[1])
+		}
+		if end < 0 {
+			end = len(arrayData) + end
+		}
+	}
+
+	if start < 0 || end > len(arrayData) || start > end {
+		return nil, fmt.Errorf("slice out of bounds: %d:%d (length: %d)", start, end, len(arrayData))
+	}
+
+	return arrayData[start:end], nil
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,43 @@
+package jsonpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	data := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"sku": "A", "qty": float64(1)},
+			map[string]interface{}{"sku": "B", "qty": float64(5)},
+		},
+	}
+
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{"$", data},
+		{"$.items[0].sku", "A"},
+		{"$.items[last].qty", float64(5)},
+		{"$.items[*].sku", []interface{}{"A", "B"}},
+		{`$.items[?(@.sku == "B")].qty`, float64(5)},
+		{"$.items.length", 2},
+	}
+	for _, tt := range tests {
+		got, err := Extract(data, tt.path)
+		if err != nil {
+			t.Errorf("Extract(%q) failed: %v", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Extract(%q) = %#v, want %#v", tt.path, got, tt.want)
+		}
+	}
+
+	for _, path := range []string{"$.missing", "$.items[5]", "items", `$.items[?(@.sku == "Z")]`} {
+		if _, err := Extract(data, path); err == nil {
+			t.Errorf("Extract(%q): expected an error", path)
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package jsonschema
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Draft is the URI of the JSON Schema dialect implemented by this package
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// inlineBase is the base URI of schemas that are not loaded from a file
+const inlineBase = "urn:stepwise:schema"
+
+// Schema is a compiled JSON Schema with every schema it references
+type Schema struct {
+	root      resource
+	resources map[string]resource // Schema resources by absolute URI, without fragment
+	anchors   map[string]resource // $anchor and $dynamicAnchor targets by URI#name
+	dynamic   map[string]bool     // URI#name of $dynamicAnchor declarations
+	patterns  map[string]*regexp.Regexp
+	embedded  map[string]bool // Documents whose schemas are walked when referenced
+	walked    map[string]bool // Walked schemas of embedded documents by URI#pointer
+}
+
+// resource is a schema together with the base URI its relative references resolve against
+type resource struct {
+	node interface{}
+	base string
+}
+
+// Violation is a part of an instance that does not satisfy the schema
+type Violation struct {
+	InstancePath    string `json:"instance_path"`    // JSON Pointer to the value, "" for the whole instance
+	KeywordLocation string `json:"keyword_location"` // JSON Pointer to the failing keyword in the schema
+	Message         string `json:"message"`
+}
+
+// String formats the violation as "path: message", using / for the whole instance
+func (v Violation) String() string {
+	path := v.InstancePath
+	if path == "" {
+		path = "/"
+	}
+	return path + ": " + v.Message
+}
+
+// Compile compiles a schema decoded from JSON or YAML. Relative file references are
+// resolved against dir.
+func Compile(doc interface{}, dir string) (*Schema, error) {
+	base := inlineBase
+	if dir != "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		base = fileURI(filepath.Join(abs, "schema.json"))
+	}
+	return compile(normalize(doc), base)
+}
+
+// CompileFile compiles a schema from a JSON or YAML file
+func CompileFile(path string) (*Schema, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := loadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+	return compile(doc, fileURI(abs))
+}
+
+// Document is a JSON or YAML file with embedded schemas, such as an OpenAPI description
+type Document struct {
+	schemas *Schema
+	uri     string
+}
+
+// LoadDocument loads a document whose schemas are compiled on demand with Schema
+func LoadDocument(path string) (*Document, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := loadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+	uri := fileURI(abs)
+	s := newSchema(doc, uri)
+	s.resources[uri] = s.root
+	s.embedded[uri] = true
+	return &Document{schemas: s, uri: uri}, nil
+}
+
+// Root returns the decoded document
+func (d *Document) Root() interface{} {
+	return d.schemas.root.node
+}
+
+// Schema compiles the schema at a JSON Pointer of the document. Schemas of one
+// document share their references, so they must not be compiled concurrently.
+func (d *Document) Schema(pointer string) (*Schema, error) {
+	ref := d.uri + "#" + pointer
+	if err := d.schemas.resolveRefs([]string{ref}); err != nil {
+		return nil, err
+	}
+	target, err := d.schemas.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	schema := *d.schemas
+	schema.root = target
+	return &schema, nil
+}
+
+func newSchema(doc interface{}, base string) *Schema {
+	return &Schema{
+		root:      resource{node: doc, base: base},
+		resources: make(map[string]resource),
+		anchors:   make(map[string]resource),
+		dynamic:   make(map[string]bool),
+		patterns:  make(map[string]*regexp.Regexp),
+		embedded:  make(map[string]bool),
+		walked:    make(map[string]bool),
+	}
+}
+
+func compile(doc interface{}, base string) (*Schema, error) {
+	s := newSchema(doc, base)
+	refs, err := s.register(doc, base)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resolveRefs(refs); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// resolveRefs loads the files referenced by the schema, and by those files, until all
+// references resolve
+func (s *Schema) resolveRefs(refs []string) error {
+	for len(refs) > 0 {
+		ref := refs[0]
+		refs = refs[1:]
+		uri, fragment := splitFragment(ref)
+		if _, ok := s.resources[uri]; !ok {
+			if !strings.HasPrefix(uri, "file://") {
+				return fmt.Errorf("cannot resolve $ref %q: only files can be referenced", ref)
+			}
+			path := strings.TrimPrefix(uri, "file://")
+			doc, err := loadFile(path)
+			if err != nil {
+				return fmt.Errorf("cannot resolve $ref %q: %w", ref, err)
+			}
+			more, err := s.register(doc, uri)
+			if err != nil {
+				return err
+			}
+			refs = append(refs, more...)
+		}
+		target, err := s.resolve(ref)
+		if err != nil {
+			return err
+		}
+		// Schemas embedded in a document are only walked once something references them
+		if s.embedded[uri] && !s.walked[ref] {
+			s.walked[ref] = true
+			if err := s.walk(target.node, target.base, fragment, &refs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// register records the resources, anchors and patterns of a schema document and
+// returns the absolute URIs of its references
+func (s *Schema) register(doc interface{}, base string) ([]string, error) {
+	if _, exists := s.resources[base]; !exists {
+		s.resources[base] = resource{node: doc, base: base}
+	}
+
+	var refs []string
+	if err := s.walk(doc, base, "", &refs); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// walk records the resources, anchors and patterns of a schema and collects the
+// absolute URIs of its references
+func (s *Schema) walk(node interface{}, base, location string, refs *[]string) error {
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		if _, isBool := node.(bool); !isBool {
+			return fmt.Errorf("invalid schema at %q: expected an object or a boolean", "#"+location)
+		}
+		return nil
+	}
+
+	if id, ok := m["$id"].(string); ok {
+		resolved, err := resolveURI(base, id)
+		if err != nil {
+			return fmt.Errorf("invalid $id %q: %w", id, err)
+		}
+		base, _ = splitFragment(resolved)
+		s.resources[base] = resource{node: m, base: base}
+	}
+	if anchor, ok := m["$anchor"].(string); ok {
+		s.anchors[base+"#"+anchor] = resource{node: m, base: base}
+	}
+	if anchor, ok := m["$dynamicAnchor"].(string); ok {
+		s.anchors[base+"#"+anchor] = resource{node: m, base: base}
+		s.dynamic[base+"#"+anchor] = true
+	}
+	for _, keyword := range []string{"$ref", "$dynamicRef"} {
+		if ref, ok := m[keyword].(string); ok {
+			resolved, err := resolveURI(base, ref)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q: %w", keyword, ref, err)
+			}
+			*refs = append(*refs, resolved)
+		}
+	}
+	if pattern, ok := m["pattern"].(string); ok {
+		if err := s.addPattern(pattern); err != nil {
+			return err
+		}
+	}
+
+	for keyword, value := range m {
+		switch keyword {
+		case "$defs", "definitions", "properties", "patternProperties", "dependentSchemas":
+			children, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("invalid schema at %q: %s must be an object", "#"+location, keyword)
+			}
+			for name, child := range children {
+				if keyword == "patternProperties" {
+					if err := s.addPattern(name); err != nil {
+						return err
+					}
+				}
+				if err := s.walk(child, base, location+"/"+keyword+"/"+escape(name), refs); err != nil {
+					return err
+				}
+			}
+		case "allOf", "anyOf", "oneOf", "prefixItems":
+			children, ok := value.([]interface{})
+			if !ok || len(children) == 0 && keyword != "prefixItems" {
+				return fmt.Errorf("invalid schema at %q: %s must be a non-empty array", "#"+location, keyword)
+			}
+			for i, child := range children {
+				if err := s.walk(child, base, location+"/"+keyword+"/"+strconv.Itoa(i), refs); err != nil {
+					return err
+				}
+			}
+		case "items":
+			// Draft 7 style tuples are accepted as prefixItems
+			if children, ok := value.([]interface{}); ok {
+				for i, child := range children {
+					if err := s.walk(child, base, location+"/items/"+strconv.Itoa(i), refs); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := s.walk(value, base, location+"/items", refs); err != nil {
+				return err
+			}
+		case "additionalItems", "additionalProperties", "not", "if", "then", "else", "contains",
+			"propertyNames", "unevaluatedItems", "unevaluatedProperties", "contentSchema":
+			if err := s.walk(value, base, location+"/"+keyword, refs); err != nil {
+				return err
+			}
+		case "type":
+			if err := checkType(value); err != nil {
+				return fmt.Errorf("invalid schema at %q: %w", "#"+location, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) addPattern(pattern string) error {
+	if _, ok := s.patterns[pattern]; ok {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	s.patterns[pattern] = re
+	return nil
+}
+
+// resolve returns the schema an absolute URI points to, by JSON Pointer or anchor
+func (s *Schema) resolve(uri string) (resource, error) {
+	docURI, fragment := splitFragment(uri)
+	target, ok := s.resources[docURI]
+	if !ok {
+		return resource{}, fmt.Errorf("cannot resolve $ref %q", uri)
+	}
+	if fragment == "" {
+		return target, nil
+	}
+	if !strings.HasPrefix(fragment, "/") {
+		anchor, ok := s.anchors[docURI+"#"+fragment]
+		if !ok {
+			return resource{}, fmt.Errorf("cannot resolve $ref %q: unknown anchor %q", uri, fragment)
+		}
+		return anchor, nil
+	}
+
+	node, base := target.node, target.base
+	for _, token := range strings.Split(fragment[1:], "/") {
+		token = unescape(token)
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return resource{}, fmt.Errorf("cannot resolve $ref %q: no %q", uri, token)
+			}
+			node = child
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return resource{}, fmt.Errorf("cannot resolve $ref %q: no index %q", uri, token)
+			}
+			node = n[i]
+		default:
+			return resource{}, fmt.Errorf("cannot resolve $ref %q", uri)
+		}
+		if m, ok := node.(map[string]interface{}); ok {
+			if id, ok := m["$id"].(string); ok {
+				if resolved, err := resolveURI(base, id); err == nil {
+					base, _ = splitFragment(resolved)
+				}
+			}
+		}
+	}
+	return resource{node: node, base: base}, nil
+}
+
+func checkType(value interface{}) error {
+	names := []interface{}{value}
+	if list, ok := value.([]interface{}); ok {
+		names = list
+	}
+	for _, name := range names {
+		switch name {
+		case "null", "boolean", "object", "array", "number", "string", "integer":
+		default:
+			return fmt.Errorf("unknown type %v", name)
+		}
+	}
+	return nil
+}
+
+func loadFile(path string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+	// YAML is a superset of JSON, so one decoder reads both
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse schema file %s: %w", path, err)
+	}
+	return normalize(doc), nil
+}
+
+// normalize converts a decoded YAML document to the types encoding/json produces
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = normalize(item)
+		}
+		return result
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = normalize(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalize(item)
+		}
+		return result
+	}
+	if n, ok := toNumber(value); ok {
+		return n
+	}
+	return value
+}
+
+func fileURI(path string) string {
+	return "file://" + filepath.ToSlash(path)
+}
+
+func resolveURI(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	// url.ResolveReference drops the opaque part of URNs, so fragments are joined by hand
+	if b.Opaque != "" && r.Scheme == "" {
+		if strings.HasPrefix(ref, "#") || ref == "" {
+			docURI, _ := splitFragment(base)
+			return docURI + ref, nil
+		}
+		return "", fmt.Errorf("relative reference %q needs a schema_file", ref)
+	}
+	return b.ResolveReference(r).String(), nil
+}
+
+func splitFragment(uri string) (string, string) {
+	docURI, fragment, _ := strings.Cut(uri, "#")
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		fragment = unescaped
+	}
+	return docURI, fragment
+}
+
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
@@ -0,0 +1,263 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func mustCompile(t *testing.T, source string) *Schema {
+	t.Helper()
+	var doc interface{}
+	if err := yaml.Unmarshal([]byte(source), &doc); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	schema, err := Compile(doc, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return schema
+}
+
+func decode(t *testing.T, source string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(source), &value); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return value
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		instance string
+		want     []string // "path: message" of every violation
+	}{
+		{"type", `type: string`, `1`, []string{"/: expected string, got integer"}},
+		{"integer is a number", `type: number`, `1`, nil},
+		{"type list", `type: [string, "null"]`, `null`, nil},
+		{"enum", `enum: [a, b]`, `"c"`, []string{`/: must be one of ["a", "b"]`}},
+		{"const", `const: 1`, `1.0`, nil},
+		{"string", `{type: string, minLength: 2, maxLength: 3, pattern: "^[a-zä]+$"}`, `"äbcd"`, []string{"/: must be at most 3 characters long, got 4"}},
+		{"pattern", `{pattern: "^\\d+$"}`, `"12a"`, []string{`/: must match pattern "^\\d+$"`}},
+		{"number", `{minimum: 1, exclusiveMaximum: 10, multipleOf: 0.5}`, `10`, []string{"/: must be < 10, got 10"}},
+		{"multipleOf", `{multipleOf: 0.01}`, `19.99`, nil},
+		{"format", `{format: date-time}`, `"2024-01-02 10:00"`, []string{"/: must be a valid date-time"}},
+		{"unknown format", `{format: color}`, `"red"`, nil},
+		{"required and additional", `
+type: object
+required: [id, name]
+properties:
+  id: {type: integer}
+additionalProperties: false
+`, `{"id": "1", "extra": true}`, []string{
+			`/: missing required property "name"`,
+			"/extra: property \"extra\" is not allowed",
+			"/id: expected integer, got string",
+		}},
+		{"nested paths", `
+type: object
+properties:
+  items:
+    type: array
+    items:
+      type: object
+      properties:
+        price: {type: number, minimum: 0}
+`, `{"items": [{"price": 1}, {"price": -1}]}`, []string{"/items/1/price: must be >= 0, got -1"}},
+		{"pointer escaping", `{properties: {"a/b": {type: string}}}`, `{"a/b": 1}`, []string{"/a~1b: expected string, got integer"}},
+		{"patternProperties", `{patternProperties: {"^x-": {type: string}}, additionalProperties: {type: integer}}`, `{"x-a": "1", "b": 2, "c": "3"}`, []string{"/c: expected integer, got string"}},
+		{"propertyNames", `{propertyNames: {maxLength: 2}}`, `{"abc": 1}`, []string{`/: property name "abc" is invalid: must be at most 2 characters long, got 3`}},
+		{"dependentRequired", `{dependentRequired: {card: [cvv]}}`, `{"card": "1"}`, []string{`/: property "cvv" is required when "card" is present`}},
+		{"array", `{minItems: 1, maxItems: 2, uniqueItems: true}`, `[1, 1.0]`, []string{"/: items 0 and 1 are equal"}},
+		{"prefixItems", `{prefixItems: [{type: string}], items: {type: integer}}`, `["a", 1, "b"]`, []string{"/2: expected integer, got string"}},
+		{"contains", `{contains: {type: string}, minContains: 2, maxContains: 2}`, `["a", 1]`, []string{"/: must contain at least 2 matching items, got 1"}},
+		{"allOf", `{allOf: [{required: [a]}, {required: [b]}]}`, `{}`, []string{`/: missing required property "a"`, `/: missing required property "b"`}},
+		{"anyOf", `{anyOf: [{type: string}, {type: integer}]}`, `true`, []string{"/: must match at least one schema of anyOf"}},
+		{"oneOf", `{oneOf: [{type: number}, {type: integer}]}`, `1`, []string{"/: must match exactly one schema of oneOf, matched 0 and 1"}},
+		{"not", `{not: {type: "null"}}`, `null`, []string{"/: must not match the schema in not"}},
+		{"if then else", `{if: {properties: {kind: {const: card}}}, then: {required: [number]}, else: {required: [iban]}}`, `{"kind": "bank"}`, []string{`/: missing required property "iban"`}},
+		{"false schema", `{properties: {a: false}}`, `{"a": 1}`, []string{"/a: no value is allowed here"}},
+		{"ref", `
+$defs:
+  positive: {type: integer, minimum: 1}
+properties:
+  id: {$ref: "#/$defs/positive"}
+`, `{"id": 0}`, []string{"/id: must be >= 1, got 0"}},
+		{"anchor", `
+$defs:
+  name: {$anchor: name, type: string}
+items: {$ref: "#name"}
+`, `["a", 2]`, []string{"/1: expected string, got integer"}},
+		{"recursive ref", `
+type: object
+properties:
+  children:
+    type: array
+    items: {$ref: "#"}
+  name: {type: string}
+`, `{"name": "a", "children": [{"name": "b", "children": [{"name": 3}]}]}`, []string{"/children/0/children/0/name: expected string, got integer"}},
+		{"$id and relative ref", `
+$id: "https://example.com/order"
+$defs:
+  money:
+    $id: money
+    type: object
+    required: [amount]
+properties:
+  total: {$ref: money}
+`, `{"total": {}}`, []string{`/total: missing required property "amount"`}},
+		{"unevaluatedProperties", `
+allOf:
+  - properties: {a: true}
+properties: {b: true}
+unevaluatedProperties: false
+`, `{"a": 1, "b": 2, "c": 3}`, []string{"/c: property \"c\" is not allowed"}},
+		{"unevaluatedItems", `{prefixItems: [true], contains: {type: string}, unevaluatedItems: false}`, `[1, "a", 2]`, []string{"/2: no value is allowed here"}},
+		{"dynamicRef", `
+$id: "https://example.com/tree"
+$dynamicAnchor: node
+type: object
+properties:
+  children:
+    type: array
+    items: {$dynamicRef: "#node"}
+`, `{"children": [{"children": 1}]}`, []string{"/children/0/children: expected array, got integer"}},
+		{"draft 7 tuple", `{items: [{type: string}], additionalItems: false}`, `["a", 1]`, []string{"/1: no value is allowed here"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := mustCompile(t, tt.schema)
+			var got []string
+			for _, v := range schema.Validate(decode(t, tt.instance)) {
+				got = append(got, v.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Expected violations:\n%s\ngot:\n%s", strings.Join(tt.want, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestViolationLocations(t *testing.T) {
+	schema := mustCompile(t, `
+$defs:
+  id: {type: integer}
+properties:
+  user:
+    properties:
+      id: {$ref: "#/$defs/id"}
+`)
+	violations := schema.Validate(decode(t, `{"user": {"id": "x"}}`))
+	if len(violations) != 1 {
+		t.Fatalf("Expected one violation, got %v", violations)
+	}
+	if v := violations[0]; v.InstancePath != "/user/id" || v.KeywordLocation != "/properties/user/properties/id/$ref/type" {
+		t.Errorf("Unexpected violation: %+v", v)
+	}
+}
+
+func TestCompileFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return path
+	}
+	write("address.json", `{"type": "object", "required": ["city"], "properties": {"city": {"type": "string"}}}`)
+	path := write("user.yaml", `
+type: object
+properties:
+  address: {$ref: "address.json"}
+  shipping: {$ref: "address.json#/properties/city"}
+`)
+
+	schema, err := CompileFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	violations := schema.Validate(decode(t, `{"address": {}, "shipping": 1}`))
+	if len(violations) != 2 || violations[0].InstancePath != "/address" || violations[1].InstancePath != "/shipping" {
+		t.Errorf("Expected violations from the referenced file, got %v", violations)
+	}
+
+	// Inline schemas resolve files against the directory they are given
+	inline, err := Compile(map[string]interface{}{"$ref": "address.json"}, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if violations := inline.Validate(map[string]interface{}{"city": "Berlin"}); len(violations) != 0 {
+		t.Errorf("Unexpected violations: %v", violations)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, source := range []string{
+		`{type: text}`,
+		`{pattern: "("}`,
+		`{$ref: "#/$defs/missing"}`,
+		`{$ref: "#missing"}`,
+		`{$ref: "https://example.com/schema"}`,
+		`{allOf: []}`,
+		`{properties: {a: 1}}`,
+	} {
+		var doc interface{}
+		if err := yaml.Unmarshal([]byte(source), &doc); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := Compile(doc, ""); err == nil {
+			t.Errorf("Compile(%s): expected an error", source)
+		}
+	}
+
+	if _, err := CompileFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.yaml")
+	if err := os.WriteFile(path, []byte(`
+paths:
+  /users:
+    schema: {$ref: "#/components/schemas/User"}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name: {type: string, nullable: true, pattern: "^[A-Z]"}
+        age: {type: integer, minimum: 0, exclusiveMinimum: true}
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	doc, err := LoadDocument(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	schema, err := doc.Schema("/paths/~1users/schema")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if violations := schema.Validate(decode(t, `{"name": null, "age": 1}`)); len(violations) != 0 {
+		t.Errorf("Unexpected violations: %v", violations)
+	}
+	violations := schema.Validate(decode(t, `{"name": "ann", "age": 0}`))
+	if len(violations) != 2 || violations[0].String() != "/age: must be > 0, got 0" || !strings.HasPrefix(violations[1].String(), "/name: ") {
+		t.Errorf("Expected violations at /age and /name, got %v", violations)
+	}
+
+	if _, err := doc.Schema("/paths/~1orders/schema"); err == nil {
+		t.Error("Expected an error for a missing schema")
+	}
+}
@@ -0,0 +1,614 @@
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate validates an instance decoded from JSON and returns every violation,
+// ordered by instance path
+func (s *Schema) Validate(instance interface{}) []Violation {
+	state := &evaluation{schema: s}
+	violations, _ := state.eval(s.root, normalize(instance), "", "", nil)
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].InstancePath < violations[j].InstancePath
+	})
+	return violations
+}
+
+// evaluation holds the state of one validation
+type evaluation struct {
+	schema *Schema
+	depth  int
+}
+
+// evaluated records the properties and items that a schema and its subschemas
+// evaluated successfully, for unevaluatedProperties and unevaluatedItems
+type evaluated struct {
+	properties map[string]bool
+	items      map[int]bool
+	allItems   bool
+}
+
+func (e *evaluated) merge(other *evaluated) {
+	if other == nil {
+		return
+	}
+	for name := range other.properties {
+		e.properties[name] = true
+	}
+	for i := range other.items {
+		e.items[i] = true
+	}
+	e.allItems = e.allItems || other.allItems
+}
+
+func newEvaluated() *evaluated {
+	return &evaluated{properties: make(map[string]bool), items: make(map[int]bool)}
+}
+
+// maxDepth stops schemas that reference themselves without consuming the instance
+const maxDepth = 200
+
+// eval validates an instance against a schema. scope lists the base URIs of the
+// schema resources entered so far, outermost first, for $dynamicRef.
+func (e *evaluation) eval(r resource, instance interface{}, path, location string, scope []string) ([]Violation, *evaluated) {
+	fail := func(keyword, format string, args ...interface{}) Violation {
+		return Violation{InstancePath: path, KeywordLocation: location + "/" + keyword, Message: fmt.Sprintf(format, args...)}
+	}
+
+	switch node := r.node.(type) {
+	case bool:
+		if !node {
+			return []Violation{{InstancePath: path, KeywordLocation: location, Message: "no value is allowed here"}}, nil
+		}
+		return nil, newEvaluated()
+	case map[string]interface{}:
+		e.depth++
+		defer func() { e.depth-- }()
+		if e.depth > maxDepth {
+			return []Violation{{InstancePath: path, KeywordLocation: location, Message: "schema recursion is too deep"}}, nil
+		}
+		if id, ok := node["$id"].(string); ok {
+			if resolved, err := resolveURI(r.base, id); err == nil {
+				r.base, _ = splitFragment(resolved)
+			}
+		}
+		if len(scope) == 0 || scope[len(scope)-1] != r.base {
+			scope = append(scope[:len(scope):len(scope)], r.base)
+		}
+		return e.evalObject(node, r.base, instance, path, location, scope, fail)
+	}
+	return nil, newEvaluated()
+}
+
+func (e *evaluation) evalObject(node map[string]interface{}, base string, instance interface{}, path, location string, scope []string, fail func(string, string, ...interface{}) Violation) ([]Violation, *evaluated) {
+	var violations []Violation
+	annotations := newEvaluated()
+
+	// apply evaluates a subschema at a keyword, keeping its annotations when it passes
+	apply := func(sub interface{}, subBase string, value interface{}, subPath, keyword string) []Violation {
+		v, a := e.eval(resource{node: sub, base: subBase}, value, subPath, location+"/"+keyword, scope)
+		if len(v) == 0 && subPath == path {
+			annotations.merge(a)
+		}
+		return v
+	}
+	valid := func(sub interface{}, value interface{}) (bool, *evaluated) {
+		v, a := e.eval(resource{node: sub, base: base}, value, path, location, scope)
+		return len(v) == 0, a
+	}
+
+	// References
+	if ref, ok := node["$ref"].(string); ok {
+		target, err := e.resolve(base, ref)
+		if err != nil {
+			violations = append(violations, fail("$ref", "%v", err))
+		} else {
+			violations = append(violations, apply(target.node, target.base, instance, path, "$ref")...)
+		}
+	}
+	if ref, ok := node["$dynamicRef"].(string); ok {
+		target, err := e.resolveDynamic(base, ref, scope)
+		if err != nil {
+			violations = append(violations, fail("$dynamicRef", "%v", err))
+		} else {
+			violations = append(violations, apply(target.node, target.base, instance, path, "$dynamicRef")...)
+		}
+	}
+
+	// Assertions on any type
+	// OpenAPI 3.0 allows null with nullable instead of a "null" type
+	nullable, _ := node["nullable"].(bool)
+	if t, ok := node["type"]; ok && !(nullable && instance == nil) && !matchesType(instance, t) {
+		violations = append(violations, fail("type", "expected %s, got %s", typeNames(t), typeOf(instance)))
+	}
+	if enum, ok := node["enum"].([]interface{}); ok {
+		found := false
+		for _, item := range enum {
+			if equal(instance, item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			violations = append(violations, fail("enum", "must be one of %s", formatValues(enum)))
+		}
+	}
+	if constant, ok := node["const"]; ok && !equal(instance, constant) {
+		violations = append(violations, fail("const", "must be %s", formatValue(constant)))
+	}
+
+	// Combinators
+	if all, ok := node["allOf"].([]interface{}); ok {
+		for i, sub := range all {
+			violations = append(violations, apply(sub, base, instance, path, "allOf/"+strconv.Itoa(i))...)
+		}
+	}
+	if any, ok := node["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range any {
+			if ok, a := valid(sub, instance); ok {
+				matched = true
+				annotations.merge(a)
+			}
+		}
+		if !matched {
+			violations = append(violations, fail("anyOf", "must match at least one schema of anyOf"))
+		}
+	}
+	if one, ok := node["oneOf"].([]interface{}); ok {
+		var matches []string
+		for i, sub := range one {
+			if ok, a := valid(sub, instance); ok {
+				matches = append(matches, strconv.Itoa(i))
+				annotations.merge(a)
+			}
+		}
+		switch {
+		case len(matches) == 0:
+			violations = append(violations, fail("oneOf", "must match exactly one schema of oneOf, matched none"))
+		case len(matches) > 1:
+			violations = append(violations, fail("oneOf", "must match exactly one schema of oneOf, matched %s", strings.Join(matches, " and ")))
+		}
+	}
+	if not, ok := node["not"]; ok {
+		if ok, _ := valid(not, instance); ok {
+			violations = append(violations, fail("not", "must not match the schema in not"))
+		}
+	}
+	if condition, ok := node["if"]; ok {
+		if ok, a := valid(condition, instance); ok {
+			annotations.merge(a)
+			if then, ok := node["then"]; ok {
+				violations = append(violations, apply(then, base, instance, path, "then")...)
+			}
+		} else if otherwise, ok := node["else"]; ok {
+			violations = append(violations, apply(otherwise, base, instance, path, "else")...)
+		}
+	}
+
+	switch value := instance.(type) {
+	case string:
+		violations = append(violations, e.evalString(node, value, fail)...)
+	case float64:
+		violations = append(violations, evalNumber(node, value, fail)...)
+	case []interface{}:
+		violations = append(violations, e.evalArray(node, base, value, path, location, scope, annotations, fail)...)
+	case map[string]interface{}:
+		violations = append(violations, e.evalProperties(node, base, value, path, location, scope, annotations, fail)...)
+	}
+
+	if len(violations) > 0 {
+		return violations, nil
+	}
+	return nil, annotations
+}
+
+func (e *evaluation) evalString(node map[string]interface{}, value string, fail func(string, string, ...interface{}) Violation) []Violation {
+	var violations []Violation
+	length := utf8.RuneCountInString(value)
+	if min, ok := toInt(node["minLength"]); ok && length < min {
+		violations = append(violations, fail("minLength", "must be at least %d characters long, got %d", min, length))
+	}
+	if max, ok := toInt(node["maxLength"]); ok && length > max {
+		violations = append(violations, fail("maxLength", "must be at most %d characters long, got %d", max, length))
+	}
+	if pattern, ok := node["pattern"].(string); ok && !e.schema.patterns[pattern].MatchString(value) {
+		violations = append(violations, fail("pattern", "must match pattern %q", pattern))
+	}
+	if format, ok := node["format"].(string); ok {
+		if check, known := formats[format]; known && !check(value) {
+			violations = append(violations, fail("format", "must be a valid %s", format))
+		}
+	}
+	return violations
+}
+
+func evalNumber(node map[string]interface{}, value float64, fail func(string, string, ...interface{}) Violation) []Violation {
+	var violations []Violation
+	// Draft 4 and OpenAPI 3.0 make minimum and maximum exclusive with a boolean
+	exclusiveMin, _ := node["exclusiveMinimum"].(bool)
+	exclusiveMax, _ := node["exclusiveMaximum"].(bool)
+	if min, ok := toNumber(node["minimum"]); ok {
+		if exclusiveMin && value <= min {
+			violations = append(violations, fail("exclusiveMinimum", "must be > %v, got %v", min, value))
+		} else if value < min {
+			violations = append(violations, fail("minimum", "must be >= %v, got %v", min, value))
+		}
+	}
+	if min, ok := toNumber(node["exclusiveMinimum"]); ok && value <= min {
+		violations = append(violations, fail("exclusiveMinimum", "must be > %v, got %v", min, value))
+	}
+	if max, ok := toNumber(node["maximum"]); ok {
+		if exclusiveMax && value >= max {
+			violations = append(violations, fail("exclusiveMaximum", "must be < %v, got %v", max, value))
+		} else if value > max {
+			violations = append(violations, fail("maximum", "must be <= %v, got %v", max, value))
+		}
+	}
+	if max, ok := toNumber(node["exclusiveMaximum"]); ok && value >= max {
+		violations = append(violations, fail("exclusiveMaximum", "must be < %v, got %v", max, value))
+	}
+	if divisor, ok := toNumber(node["multipleOf"]); ok && divisor > 0 {
+		quotient := value / divisor
+		if math.IsInf(quotient, 0) || math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			violations = append(violations, fail("multipleOf", "must be a multiple of %v, got %v", divisor, value))
+		}
+	}
+	return violations
+}
+
+func (e *evaluation) evalArray(node map[string]interface{}, base string, items []interface{}, path, location string, scope []string, annotations *evaluated, fail func(string, string, ...interface{}) Violation) []Violation {
+	var violations []Violation
+	if min, ok := toInt(node["minItems"]); ok && len(items) < min {
+		violations = append(violations, fail("minItems", "must have at least %d items, got %d", min, len(items)))
+	}
+	if max, ok := toInt(node["maxItems"]); ok && len(items) > max {
+		violations = append(violations, fail("maxItems", "must have at most %d items, got %d", max, len(items)))
+	}
+	if unique, _ := node["uniqueItems"].(bool); unique {
+	unique:
+		for i := range items {
+			for j := i + 1; j < len(items); j++ {
+				if equal(items[i], items[j]) {
+					violations = append(violations, fail("uniqueItems", "items %d and %d are equal", i, j))
+					break unique
+				}
+			}
+		}
+	}
+
+	itemViolations := func(sub interface{}, i int, keyword string) []Violation {
+		v, _ := e.eval(resource{node: sub, base: base}, items[i], path+"/"+strconv.Itoa(i), location+"/"+keyword, scope)
+		if len(v) == 0 {
+			annotations.items[i] = true
+		}
+		return v
+	}
+
+	// prefixItems, or items as an array in older drafts, then items for the rest
+	prefix, _ := node["prefixItems"].([]interface{})
+	rest, hasRest := node["items"]
+	restKeyword := "items"
+	if tuple, ok := rest.([]interface{}); ok {
+		prefix = tuple
+		rest, hasRest = node["additionalItems"]
+		restKeyword = "additionalItems"
+	}
+	prefixKeyword := "prefixItems"
+	if restKeyword == "additionalItems" {
+		prefixKeyword = "items"
+	}
+	for i, sub := range prefix {
+		if i < len(items) {
+			violations = append(violations, itemViolations(sub, i, prefixKeyword+"/"+strconv.Itoa(i))...)
+		}
+	}
+	if hasRest {
+		for i := len(prefix); i < len(items); i++ {
+			violations = append(violations, itemViolations(rest, i, restKeyword)...)
+		}
+	}
+
+	if contains, ok := node["contains"]; ok {
+		count := 0
+		for i, item := range items {
+			if v, _ := e.eval(resource{node: contains, base: base}, item, path+"/"+strconv.Itoa(i), location+"/contains", scope); len(v) == 0 {
+				count++
+				annotations.items[i] = true
+			}
+		}
+		min, hasMin := toInt(node["minContains"])
+		if !hasMin {
+			min = 1
+		}
+		if count < min {
+			violations = append(violations, fail("contains", "must contain at least %d matching items, got %d", min, count))
+		}
+		if max, ok := toInt(node["maxContains"]); ok && count > max {
+			violations = append(violations, fail("maxContains", "must contain at most %d matching items, got %d", max, count))
+		}
+	}
+
+	if unevaluated, ok := node["unevaluatedItems"]; ok && !annotations.allItems {
+		for i := range items {
+			if !annotations.items[i] {
+				violations = append(violations, itemViolations(unevaluated, i, "unevaluatedItems")...)
+			}
+		}
+	}
+	if len(violations) == 0 && (hasRest || node["unevaluatedItems"] != nil) {
+		annotations.allItems = true
+	}
+	return violations
+}
+
+func (e *evaluation) evalProperties(node map[string]interface{}, base string, object map[string]interface{}, path, location string, scope []string, annotations *evaluated, fail func(string, string, ...interface{}) Violation) []Violation {
+	var violations []Violation
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if min, ok := toInt(node["minProperties"]); ok && len(object) < min {
+		violations = append(violations, fail("minProperties", "must have at least %d properties, got %d", min, len(object)))
+	}
+	if max, ok := toInt(node["maxProperties"]); ok && len(object) > max {
+		violations = append(violations, fail("maxProperties", "must have at most %d properties, got %d", max, len(object)))
+	}
+	if required, ok := node["required"].([]interface{}); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, exists := object[name]; !exists {
+					violations = append(violations, fail("required", "missing required property %q", name))
+				}
+			}
+		}
+	}
+	if dependent, ok := node["dependentRequired"].(map[string]interface{}); ok {
+		for _, name := range names {
+			required, _ := dependent[name].([]interface{})
+			for _, other := range required {
+				if other, ok := other.(string); ok {
+					if _, exists := object[other]; !exists {
+						violations = append(violations, fail("dependentRequired/"+escape(name), "property %q is required when %q is present", other, name))
+					}
+				}
+			}
+		}
+	}
+
+	propertyViolations := func(sub interface{}, name, keyword string) []Violation {
+		v, _ := e.eval(resource{node: sub, base: base}, object[name], path+"/"+escape(name), location+"/"+keyword, scope)
+		if len(v) == 0 {
+			annotations.properties[name] = true
+		}
+		return v
+	}
+
+	properties, _ := node["properties"].(map[string]interface{})
+	patterns, _ := node["patternProperties"].(map[string]interface{})
+	additional, hasAdditional := node["additionalProperties"]
+	for _, name := range names {
+		matched := false
+		if sub, ok := properties[name]; ok {
+			matched = true
+			violations = append(violations, propertyViolations(sub, name, "properties/"+escape(name))...)
+		}
+		for pattern, sub := range patterns {
+			if e.schema.patterns[pattern].MatchString(name) {
+				matched = true
+				violations = append(violations, propertyViolations(sub, name, "patternProperties/"+escape(pattern))...)
+			}
+		}
+		if !matched && hasAdditional {
+			if allowed, ok := additional.(bool); ok && !allowed {
+				violations = append(violations, Violation{InstancePath: path + "/" + escape(name), KeywordLocation: location + "/additionalProperties", Message: fmt.Sprintf("property %q is not allowed", name)})
+				continue
+			}
+			violations = append(violations, propertyViolations(additional, name, "additionalProperties")...)
+		}
+	}
+
+	if propertyNames, ok := node["propertyNames"]; ok {
+		for _, name := range names {
+			if v, _ := e.eval(resource{node: propertyNames, base: base}, name, path, location+"/propertyNames", scope); len(v) > 0 {
+				violations = append(violations, fail("propertyNames", "property name %q is invalid: %s", name, v[0].Message))
+			}
+		}
+	}
+	if dependent, ok := node["dependentSchemas"].(map[string]interface{}); ok {
+		for _, name := range names {
+			if sub, ok := dependent[name]; ok {
+				v, a := e.eval(resource{node: sub, base: base}, object, path, location+"/dependentSchemas/"+escape(name), scope)
+				violations = append(violations, v...)
+				if len(v) == 0 {
+					annotations.merge(a)
+				}
+			}
+		}
+	}
+
+	if unevaluated, ok := node["unevaluatedProperties"]; ok {
+		for _, name := range names {
+			if annotations.properties[name] {
+				continue
+			}
+			if allowed, ok := unevaluated.(bool); ok && !allowed {
+				violations = append(violations, Violation{InstancePath: path + "/" + escape(name), KeywordLocation: location + "/unevaluatedProperties", Message: fmt.Sprintf("property %q is not allowed", name)})
+				continue
+			}
+			violations = append(violations, propertyViolations(unevaluated, name, "unevaluatedProperties")...)
+		}
+	}
+	return violations
+}
+
+// resolve resolves a $ref against the base URI of the schema containing it
+func (e *evaluation) resolve(base, ref string) (resource, error) {
+	uri, err := resolveURI(base, ref)
+	if err != nil {
+		return resource{}, err
+	}
+	return e.schema.resolve(uri)
+}
+
+// resolveDynamic resolves a $dynamicRef. When it points to a $dynamicAnchor, the
+// outermost schema resource in scope that declares the same anchor is used instead.
+func (e *evaluation) resolveDynamic(base, ref string, scope []string) (resource, error) {
+	target, err := e.resolve(base, ref)
+	if err != nil {
+		return resource{}, err
+	}
+	uri, _ := resolveURI(base, ref)
+	docURI, anchor := splitFragment(uri)
+	if anchor == "" || strings.HasPrefix(anchor, "/") || !e.schema.dynamic[docURI+"#"+anchor] {
+		return target, nil
+	}
+	for _, resourceURI := range scope {
+		if e.schema.dynamic[resourceURI+"#"+anchor] {
+			return e.schema.anchors[resourceURI+"#"+anchor], nil
+		}
+	}
+	return target, nil
+}
+
+func matchesType(instance interface{}, t interface{}) bool {
+	names := []interface{}{t}
+	if list, ok := t.([]interface{}); ok {
+		names = list
+	}
+	actual := typeOf(instance)
+	for _, name := range names {
+		if name == actual || name == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+func typeNames(t interface{}) string {
+	if list, ok := t.([]interface{}); ok {
+		names := make([]string, len(list))
+		for i, name := range list {
+			names[i] = fmt.Sprint(name)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+// typeOf returns the JSON type of a value, with integer for whole numbers
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// equal compares JSON values, treating 1 and 1.0 as equal
+func equal(a, b interface{}) bool {
+	switch av := a.(type) {
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equal(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, value := range av {
+			other, exists := bv[key]
+			if !exists || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	}
+	if an, ok := toNumber(a); ok {
+		bn, ok := toNumber(b)
+		return ok && an == bn
+	}
+	return a == b
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func toInt(value interface{}) (int, bool) {
+	n, ok := toNumber(value)
+	return int(n), ok
+}
+
+func formatValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	if value == nil {
+		return "null"
+	}
+	return fmt.Sprint(value)
+}
+
+func formatValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = formatValue(value)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
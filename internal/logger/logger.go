@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/cjp2600/stepwise/internal/colors"
+	"github.com/cjp2600/stepwise/internal/redact"
 )
 
 // LogCallback is a function type for handling log messages
@@ -19,14 +20,32 @@ type Logger struct {
 	callback   LogCallback
 	silentMode bool
 	logBuffer  []string
+	redactor   *redact.Redactor
 }
 
 // New creates a new logger instance
 func New() *Logger {
 	return &Logger{
-		Logger: log.New(os.Stdout, "", log.LstdFlags),
-		level:  "info",
-		colors: colors.NewColors(),
+		Logger:   log.New(os.Stdout, "", log.LstdFlags),
+		level:    "info",
+		colors:   colors.NewColors(),
+		redactor: redact.New(),
+	}
+}
+
+// Redactor returns the redactor that masks sensitive values in log messages.
+// Executors sharing a logger share its redactor.
+func (l *Logger) Redactor() *redact.Redactor {
+	return l.redactor
+}
+
+// output prints a log message with sensitive values masked and passes it to the callback
+func (l *Logger) output(level, message string, args ...interface{}) {
+	line := l.redactor.String(fmt.Sprintf(message, args...))
+	l.Print(line)
+
+	if l.callback != nil {
+		l.callback(level, l.redactor.String(message))
 	}
 }
 
@@ -89,13 +108,8 @@ func (l *Logger) Info(msg string, args ...interface{}) {
 		if l.silentMode {
 			// In mute mode, don't output anything - completely silent
 			return
-		} else {
-			l.Printf(message, args...)
-		}
-
-		if l.callback != nil {
-			l.callback("INFO", message)
 		}
+		l.output("INFO", message, args...)
 	}
 }
 
@@ -108,13 +122,8 @@ func (l *Logger) Debug(msg string, args ...interface{}) {
 		if l.silentMode {
 			// In mute mode, don't output anything - completely silent
 			return
-		} else {
-			l.Printf(message, args...)
-		}
-
-		if l.callback != nil {
-			l.callback("DEBUG", message)
 		}
+		l.output("DEBUG", message, args...)
 	}
 }
 
@@ -126,13 +135,8 @@ func (l *Logger) Error(msg string, args ...interface{}) {
 	if l.silentMode {
 		// In mute mode, don't output anything - completely silent
 		return
-	} else {
-		l.Printf(message, args...)
-	}
-
-	if l.callback != nil {
-		l.callback("ERROR", message)
 	}
+	l.output("ERROR", message, args...)
 }
 
 // Warn logs a warning message
@@ -143,11 +147,6 @@ func (l *Logger) Warn(msg string, args ...interface{}) {
 	if l.silentMode {
 		// In mute mode, don't output anything - completely silent
 		return
-	} else {
-		l.Printf(message, args...)
-	}
-
-	if l.callback != nil {
-		l.callback("WARN", message)
 	}
+	l.output("WARN", message, args...)
 }
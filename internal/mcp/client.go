@@ -327,8 +327,8 @@ func (c *Client) CallMethod(ctx context.Context, method string, params interface
 	return c.transport.SendRequest(ctx, req)
 }
 
-// Execute executes an MCP request
-func (c *Client) Execute(req *Request) (*Response, error) {
+// Execute executes an MCP request. The call is bounded by both ctx and the request timeout.
+func (c *Client) Execute(ctx context.Context, req *Request) (*Response, error) {
 	start := time.Now()
 
 	// Parse timeout
@@ -339,7 +339,7 @@ func (c *Client) Execute(req *Request) (*Response, error) {
 		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	c.logger.Debug("Making MCP request",
@@ -0,0 +1,313 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	nethttp "net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cjp2600/stepwise/internal/http"
+	"github.com/cjp2600/stepwise/internal/jsonschema"
+)
+
+// Kinds of problems found by Check
+const (
+	UndocumentedEndpoint = "undocumented endpoint"
+	UndocumentedStatus   = "undocumented status"
+	InvalidParameter     = "invalid parameter"
+	InvalidRequestBody   = "invalid request body"
+	InvalidResponseBody  = "invalid response body"
+)
+
+// Result is the outcome of checking an HTTP exchange against a description
+type Result struct {
+	Operation *Operation // nil when no operation matches the request
+	Status    int
+	Problems  []Problem
+}
+
+// Problem is a difference between an exchange and the description
+type Problem struct {
+	Kind       string
+	Message    string
+	Violations []jsonschema.Violation
+}
+
+// ignoredHeaders are header parameters that the OpenAPI specification says to ignore
+var ignoredHeaders = map[string]bool{"accept": true, "content-type": true, "authorization": true}
+
+// Check matches the request of a response to an operation and checks the parameters,
+// the bodies and the status code against it
+func (s *Spec) Check(response *http.Response) Result {
+	result := Result{Status: response.StatusCode}
+	sent := response.Request
+	if sent == nil {
+		return result
+	}
+	u, err := url.Parse(sent.URL)
+	if err != nil {
+		result.Problems = append(result.Problems, Problem{Kind: UndocumentedEndpoint, Message: fmt.Sprintf("invalid request URL %q", sent.URL)})
+		return result
+	}
+
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	op, pathParams, others := s.match(strings.ToUpper(sent.Method), path)
+	if op == nil {
+		message := fmt.Sprintf("%s %s is not documented", strings.ToUpper(sent.Method), path)
+		if len(others) > 0 {
+			message += fmt.Sprintf(", the path documents %s", strings.Join(others, ", "))
+		}
+		result.Problems = append(result.Problems, Problem{Kind: UndocumentedEndpoint, Message: message})
+		return result
+	}
+	result.Operation = op
+
+	result.Problems = append(result.Problems, op.checkParameters(pathParams, u.Query(), sent.Headers)...)
+	result.Problems = append(result.Problems, op.checkRequestBody(sent.Headers, sent.Body)...)
+	result.Problems = append(result.Problems, op.checkResponse(response)...)
+	return result
+}
+
+// match finds the most specific operation for a method and path. Without one, it
+// returns the methods documented for the path.
+func (s *Spec) match(method, path string) (*Operation, map[string]string, []string) {
+	candidates := []string{path}
+	for _, base := range s.basePaths {
+		if path == base || strings.HasPrefix(path, base+"/") {
+			candidates = append([]string{strings.TrimPrefix(path, base)}, candidates...)
+		}
+	}
+
+	for _, candidate := range candidates {
+		var best *Operation
+		var values []string
+		var others []string
+		for _, op := range s.Operations {
+			match := op.pattern.FindStringSubmatch(candidate)
+			if match == nil {
+				continue
+			}
+			if op.Method != method {
+				others = append(others, op.Method)
+				continue
+			}
+			// Literal segments win over parameters: /users/me before /users/{id}
+			if best == nil || op.literal > best.literal {
+				best, values = op, match[1:]
+			}
+		}
+		if best != nil {
+			params := make(map[string]string, len(values))
+			for i, value := range values {
+				if unescaped, err := url.PathUnescape(value); err == nil {
+					value = unescaped
+				}
+				params[best.names[i]] = value
+			}
+			return best, params, nil
+		}
+		if len(others) > 0 {
+			return nil, nil, others
+		}
+	}
+	return nil, nil, nil
+}
+
+func (o *Operation) checkParameters(pathParams map[string]string, query url.Values, headers map[string][]string) []Problem {
+	header := nethttp.Header(headers)
+	cookies := (&nethttp.Request{Header: header}).Cookies()
+
+	var problems []Problem
+	for _, p := range o.parameters {
+		var values []string
+		switch p.in {
+		case "path":
+			if value, ok := pathParams[p.name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[p.name]
+		case "header":
+			if ignoredHeaders[strings.ToLower(p.name)] {
+				continue
+			}
+			values = header.Values(p.name)
+		case "cookie":
+			for _, cookie := range cookies {
+				if cookie.Name == p.name {
+					values = append(values, cookie.Value)
+				}
+			}
+		default:
+			continue
+		}
+
+		name := fmt.Sprintf("%s parameter %s", p.in, p.name)
+		if len(values) == 0 {
+			if p.required {
+				problems = append(problems, Problem{Kind: InvalidParameter, Message: "missing required " + name})
+			}
+			continue
+		}
+		if p.schema == nil {
+			continue
+		}
+		if violations := validateParameter(p.schema, values); len(violations) > 0 {
+			problems = append(problems, Problem{
+				Kind:       InvalidParameter,
+				Message:    name + ": " + joinViolations(violations),
+				Violations: violations,
+			})
+		}
+	}
+	return problems
+}
+
+// validateParameter validates the values of a parameter, which are strings on the
+// wire, in the form that fits the schema best: as strings or typed, alone or as an array
+func validateParameter(schema *jsonschema.Schema, values []string) []jsonschema.Violation {
+	typed := make([]interface{}, len(values))
+	raw := make([]interface{}, len(values))
+	for i, value := range values {
+		typed[i] = typedValue(value)
+		raw[i] = value
+	}
+
+	var candidates []interface{}
+	if len(values) == 1 {
+		candidates = append(candidates, typed[0], raw[0])
+	}
+	candidates = append(candidates, typed, raw)
+	if len(values) == 1 && strings.Contains(values[0], ",") {
+		// Arrays of the default form style may be sent as a comma separated list
+		parts := strings.Split(values[0], ",")
+		split := make([]interface{}, len(parts))
+		for i, part := range parts {
+			split[i] = typedValue(part)
+		}
+		candidates = append(candidates, split)
+	}
+
+	var best []jsonschema.Violation
+	for i, candidate := range candidates {
+		violations := schema.Validate(candidate)
+		if len(violations) == 0 {
+			return nil
+		}
+		if i == 0 || len(violations) < len(best) {
+			best = violations
+		}
+	}
+	return best
+}
+
+func typedValue(value string) interface{} {
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		return n
+	}
+	if value == "true" || value == "false" {
+		return value == "true"
+	}
+	return value
+}
+
+func (o *Operation) checkRequestBody(headers map[string][]string, body []byte) []Problem {
+	if o.body == nil {
+		return nil
+	}
+	if len(body) == 0 {
+		if o.body.required {
+			return []Problem{{Kind: InvalidRequestBody, Message: "missing required request body"}}
+		}
+		return nil
+	}
+	return checkBody(InvalidRequestBody, "request body", o.body.content, nethttp.Header(headers).Get("Content-Type"), body)
+}
+
+func (o *Operation) checkResponse(response *http.Response) []Problem {
+	resp := o.response(response.StatusCode)
+	if resp == nil {
+		return []Problem{{
+			Kind:    UndocumentedStatus,
+			Message: fmt.Sprintf("status %d is not documented for %s", response.StatusCode, o.Label()),
+		}}
+	}
+	if len(response.Body) == 0 {
+		return nil
+	}
+	return checkBody(InvalidResponseBody, "response body", resp.content, nethttp.Header(response.Headers).Get("Content-Type"), response.Body)
+}
+
+// response returns the response documented for a status code, by exact code, range
+// or default
+func (o *Operation) response(status int) *response {
+	code := strconv.Itoa(status)
+	if resp, ok := o.responses[code]; ok {
+		return resp
+	}
+	if resp, ok := o.responses[code[:1]+"XX"]; ok {
+		return resp
+	}
+	return o.responses["default"]
+}
+
+// checkBody validates a JSON body against the schema of its media type. Bodies of
+// other media types are only checked to be documented.
+func checkBody(kind, name string, content map[string]*jsonschema.Schema, contentType string, body []byte) []Problem {
+	if len(content) == 0 {
+		return nil
+	}
+	mediaType := "application/json"
+	if contentType != "" {
+		if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+			mediaType = parsed
+		}
+	}
+
+	schema, ok := mediaSchema(content, mediaType)
+	if !ok {
+		return []Problem{{Kind: kind, Message: fmt.Sprintf("%s media type %s is not documented", name, mediaType)}}
+	}
+	if schema == nil || !isJSON(mediaType) {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []Problem{{Kind: kind, Message: fmt.Sprintf("%s is not valid JSON: %v", name, err)}}
+	}
+	if violations := schema.Validate(value); len(violations) > 0 {
+		return []Problem{{Kind: kind, Message: name + ": " + joinViolations(violations), Violations: violations}}
+	}
+	return nil
+}
+
+// mediaSchema finds the content of a media type, by exact type, type/* or */*
+func mediaSchema(content map[string]*jsonschema.Schema, mediaType string) (*jsonschema.Schema, bool) {
+	mediaType = strings.ToLower(mediaType)
+	major, _, _ := strings.Cut(mediaType, "/")
+	for _, key := range []string{mediaType, major + "/*", "*/*"} {
+		if schema, ok := content[key]; ok {
+			return schema, true
+		}
+	}
+	return nil, false
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func joinViolations(violations []jsonschema.Violation) string {
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.String()
+	}
+	return strings.Join(messages, "; ")
+}
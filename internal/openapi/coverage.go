@@ -0,0 +1,148 @@
+package openapi
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Coverage counts the calls made to the operations of a description
+type Coverage struct {
+	Spec         string
+	Operations   []*OperationCoverage // In the order of the description
+	Undocumented int                  // Calls that matched no operation
+
+	mu      sync.Mutex
+	byLabel map[string]*OperationCoverage
+}
+
+// OperationCoverage counts the calls of one operation
+type OperationCoverage struct {
+	Operation  string // "METHOD /path"
+	ID         string
+	Documented []string    // Documented response codes
+	Calls      int         // Calls, including failed ones
+	Failed     int         // Calls that did not conform to the description
+	Statuses   map[int]int // Calls by response status
+}
+
+// NewCoverage returns an empty coverage of the operations of the description
+func (s *Spec) NewCoverage() *Coverage {
+	c := &Coverage{Spec: s.Path, byLabel: make(map[string]*OperationCoverage)}
+	for _, op := range s.Operations {
+		c.add(&OperationCoverage{
+			Operation:  op.Label(),
+			ID:         op.ID,
+			Documented: op.Responses(),
+			Statuses:   make(map[int]int),
+		})
+	}
+	return c
+}
+
+func (c *Coverage) add(op *OperationCoverage) {
+	c.Operations = append(c.Operations, op)
+	c.byLabel[op.Operation] = op
+}
+
+// Record counts a checked exchange. It is safe for concurrent use.
+func (c *Coverage) Record(result Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if result.Operation == nil {
+		c.Undocumented++
+		return
+	}
+	op, ok := c.byLabel[result.Operation.Label()]
+	if !ok {
+		return
+	}
+	op.Calls++
+	op.Statuses[result.Status]++
+	if len(result.Problems) > 0 {
+		op.Failed++
+	}
+}
+
+// Merge adds the counts of another coverage of the same description
+func (c *Coverage) Merge(other *Coverage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Undocumented += other.Undocumented
+	for _, theirs := range other.Operations {
+		ours, ok := c.byLabel[theirs.Operation]
+		if !ok {
+			ours = &OperationCoverage{
+				Operation:  theirs.Operation,
+				ID:         theirs.ID,
+				Documented: theirs.Documented,
+				Statuses:   make(map[int]int),
+			}
+			c.add(ours)
+		}
+		ours.Calls += theirs.Calls
+		ours.Failed += theirs.Failed
+		for status, count := range theirs.Statuses {
+			ours.Statuses[status] += count
+		}
+	}
+}
+
+// Covered returns the number of operations that were called at least once
+func (c *Coverage) Covered() int {
+	covered := 0
+	for _, op := range c.Operations {
+		if op.Calls > 0 {
+			covered++
+		}
+	}
+	return covered
+}
+
+// Seen returns the response statuses of the calls in order
+func (o *OperationCoverage) Seen() []int {
+	statuses := make([]int, 0, len(o.Statuses))
+	for status := range o.Statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	return statuses
+}
+
+// Missing returns the documented response codes no call has returned. A range such
+// as 4XX is covered by any status in it, default by any status without its own entry.
+func (o *OperationCoverage) Missing() []string {
+	var missing []string
+	for _, code := range o.Documented {
+		covered := false
+		for status := range o.Statuses {
+			text := strconv.Itoa(status)
+			switch {
+			case code == text:
+				covered = true
+			case len(code) == 3 && code[1:] == "XX" && code[0] == text[0]:
+				covered = !o.documents(text)
+			case code == "default":
+				covered = !o.documents(text) && !o.documents(text[:1]+"XX")
+			}
+			if covered {
+				break
+			}
+		}
+		if !covered {
+			missing = append(missing, code)
+		}
+	}
+	return missing
+}
+
+func (o *OperationCoverage) documents(code string) bool {
+	for _, documented := range o.Documented {
+		if documented == code {
+			return true
+		}
+	}
+	return false
+}
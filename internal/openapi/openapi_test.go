@@ -0,0 +1,253 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cjp2600/stepwise/internal/http"
+)
+
+const testSpec = `openapi: 3.0.3
+info: {title: Users, version: "1"}
+servers:
+  - url: https://api.example.com/{version}
+    variables:
+      version: {default: v1}
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      parameters:
+        - name: limit
+          in: query
+          schema: {type: integer, maximum: 100}
+        - name: tag
+          in: query
+          schema: {type: array, items: {type: string}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: "#/components/schemas/User"}
+    post:
+      operationId: createUser
+      parameters:
+        - $ref: "#/components/parameters/RequestID"
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/User"}
+      responses:
+        "201": {description: created}
+        4XX: {description: bad request}
+  /users/me:
+    get:
+      operationId: currentUser
+      responses:
+        "200": {description: ok}
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema: {type: integer, minimum: 1}
+    get:
+      operationId: getUser
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/User"}
+        default: {description: error}
+components:
+  parameters:
+    RequestID:
+      name: X-Request-ID
+      in: header
+      required: true
+      schema: {type: string, format: uuid}
+  schemas:
+    User:
+      type: object
+      required: [id, email]
+      properties:
+        id: {type: integer}
+        email: {type: string, format: email}
+        nickname: {type: string, nullable: true}
+`
+
+func loadTestSpec(t *testing.T) *Spec {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "api.yaml")
+	if err := os.WriteFile(path, []byte(testSpec), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	spec, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return spec
+}
+
+func exchange(method, url string, headers map[string][]string, body string, status int, responseBody string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Headers:    map[string][]string{"Content-Type": {"application/json"}},
+		Body:       []byte(responseBody),
+		Request:    &http.SentRequest{Method: method, URL: url, Headers: headers, Body: []byte(body)},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	spec := loadTestSpec(t)
+
+	var labels []string
+	for _, op := range spec.Operations {
+		labels = append(labels, op.Label())
+	}
+	expected := "GET /users, POST /users, GET /users/me, GET /users/{id}"
+	if strings.Join(labels, ", ") != expected {
+		t.Errorf("Expected operations %s, got %s", expected, strings.Join(labels, ", "))
+	}
+
+	dir := t.TempDir()
+	swagger := filepath.Join(dir, "swagger.yaml")
+	os.WriteFile(swagger, []byte("swagger: \"2.0\"\npaths: {}\n"), 0644)
+	if _, err := Load(swagger); err == nil || !strings.Contains(err.Error(), "Swagger 2.0") {
+		t.Errorf("Expected Swagger 2.0 to be rejected, got %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	spec := loadTestSpec(t)
+	requestID := map[string][]string{"X-Request-Id": {"7c9e6679-7425-40de-944b-e07fc1f90ae7"}}
+
+	tests := []struct {
+		name      string
+		response  *http.Response
+		operation string
+		problems  []string
+	}{
+		{
+			name:      "conforming call under the server base path",
+			response:  exchange("GET", "https://api.example.com/v1/users/42", nil, "", 200, `{"id": 42, "email": "a@example.com", "nickname": null}`),
+			operation: "GET /users/{id}",
+		},
+		{
+			name:      "literal path wins over a template",
+			response:  exchange("GET", "https://api.example.com/v1/users/me", nil, "", 200, ""),
+			operation: "GET /users/me",
+		},
+		{
+			name:      "typed and repeated query parameters",
+			response:  exchange("GET", "https://api.example.com/v1/users?limit=10&tag=a&tag=b", nil, "", 200, `[]`),
+			operation: "GET /users",
+		},
+		{
+			name:      "invalid query parameter",
+			response:  exchange("GET", "https://api.example.com/v1/users?limit=500", nil, "", 200, `[]`),
+			operation: "GET /users",
+			problems:  []string{"query parameter limit: /: must be <= 100, got 500"},
+		},
+		{
+			name:      "invalid path parameter",
+			response:  exchange("GET", "https://api.example.com/v1/users/0", nil, "", 200, `{"id": 0, "email": "a@example.com"}`),
+			operation: "GET /users/{id}",
+			problems:  []string{"path parameter id: /: must be >= 1, got 0"},
+		},
+		{
+			name:      "response schema drift",
+			response:  exchange("GET", "https://api.example.com/v1/users/1", nil, "", 200, `{"id": "1"}`),
+			operation: "GET /users/{id}",
+			problems:  []string{"response body: /: missing required property \"email\"; /id: expected integer, got string"},
+		},
+		{
+			name:      "default response",
+			response:  exchange("GET", "https://api.example.com/v1/users/1", nil, "", 503, ""),
+			operation: "GET /users/{id}",
+		},
+		{
+			name:      "undocumented status",
+			response:  exchange("GET", "https://api.example.com/v1/users/me", nil, "", 404, ""),
+			operation: "GET /users/me",
+			problems:  []string{"status 404 is not documented for GET /users/me"},
+		},
+		{
+			name:      "status range",
+			response:  exchange("POST", "https://api.example.com/v1/users", requestID, `{"id": 1, "email": "a@example.com"}`, 409, ""),
+			operation: "POST /users",
+		},
+		{
+			name:      "missing header and invalid request body",
+			response:  exchange("POST", "https://api.example.com/v1/users", nil, `{"id": 1}`, 201, ""),
+			operation: "POST /users",
+			problems:  []string{"missing required header parameter X-Request-ID", "request body: /: missing required property \"email\""},
+		},
+		{
+			name:      "missing request body",
+			response:  exchange("POST", "https://api.example.com/v1/users", requestID, "", 201, ""),
+			operation: "POST /users",
+			problems:  []string{"missing required request body"},
+		},
+		{
+			name:     "undocumented method",
+			response: exchange("DELETE", "https://api.example.com/v1/users/1", nil, "", 204, ""),
+			problems: []string{"DELETE /v1/users/1 is not documented, the path documents GET"},
+		},
+		{
+			name:     "undocumented path",
+			response: exchange("GET", "https://api.example.com/v1/orders", nil, "", 200, ""),
+			problems: []string{"GET /v1/orders is not documented"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := spec.Check(tt.response)
+			operation := ""
+			if result.Operation != nil {
+				operation = result.Operation.Label()
+			}
+			if operation != tt.operation {
+				t.Errorf("Expected operation %q, got %q", tt.operation, operation)
+			}
+			var problems []string
+			for _, problem := range result.Problems {
+				problems = append(problems, problem.Message)
+			}
+			if strings.Join(problems, "\n") != strings.Join(tt.problems, "\n") {
+				t.Errorf("Expected problems %q, got %q", tt.problems, problems)
+			}
+		})
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	spec := loadTestSpec(t)
+	coverage := spec.NewCoverage()
+	coverage.Record(spec.Check(exchange("GET", "https://api.example.com/v1/users/1", nil, "", 200, `{"id": 1, "email": "a@example.com"}`)))
+	coverage.Record(spec.Check(exchange("GET", "https://api.example.com/v1/users/1", nil, "", 200, `{"id": 1}`)))
+	coverage.Record(spec.Check(exchange("GET", "https://api.example.com/v1/orders", nil, "", 200, "")))
+
+	other := spec.NewCoverage()
+	other.Record(spec.Check(exchange("GET", "https://api.example.com/v1/users/me", nil, "", 200, "")))
+	coverage.Merge(other)
+
+	if coverage.Covered() != 2 || coverage.Undocumented != 1 {
+		t.Errorf("Expected 2 covered operations and 1 undocumented call, got %d and %d", coverage.Covered(), coverage.Undocumented)
+	}
+	getUser := coverage.Operations[3]
+	if getUser.Operation != "GET /users/{id}" || getUser.Calls != 2 || getUser.Failed != 1 || getUser.Statuses[200] != 2 {
+		t.Errorf("Unexpected coverage of GET /users/{id}: %+v", getUser)
+	}
+	if missing := getUser.Missing(); len(missing) != 1 || missing[0] != "default" {
+		t.Errorf("Expected the default response to be missing, got %v", missing)
+	}
+}
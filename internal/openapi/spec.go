@@ -0,0 +1,352 @@
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cjp2600/stepwise/internal/jsonschema"
+)
+
+// methods are the operations a path item can declare, in report order
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Spec is a loaded OpenAPI 3 description
+type Spec struct {
+	Path       string
+	Operations []*Operation // Sorted by path and method
+	basePaths  []string     // Path prefixes of the servers, longest first
+}
+
+// Operation is a documented method of a path
+type Operation struct {
+	Method     string // Upper case
+	Path       string // Path template, e.g. /users/{id}
+	ID         string // operationId
+	parameters []*parameter
+	body       *requestBody
+	responses  map[string]*response // By status code, range such as 2XX, or default
+	pattern    *regexp.Regexp
+	names      []string // Path parameter names in pattern order
+	literal    int      // Length of the template without parameters, for picking the most specific match
+}
+
+// Label names the operation as "METHOD /path"
+func (o *Operation) Label() string {
+	return o.Method + " " + o.Path
+}
+
+// Responses returns the documented response codes in order
+func (o *Operation) Responses() []string {
+	codes := make([]string, 0, len(o.responses))
+	for code := range o.responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+type parameter struct {
+	name     string
+	in       string // path, query, header or cookie
+	required bool
+	schema   *jsonschema.Schema
+}
+
+type requestBody struct {
+	required bool
+	content  map[string]*jsonschema.Schema // By media type, nil schema when none is given
+}
+
+type response struct {
+	content map[string]*jsonschema.Schema
+}
+
+// Load loads an OpenAPI 3.0 or 3.1 description from a JSON or YAML file
+func Load(path string) (*Spec, error) {
+	doc, err := jsonschema.LoadDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := doc.Root().(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s is not an OpenAPI description", path)
+	}
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		if _, ok := root["swagger"]; ok {
+			return nil, fmt.Errorf("%s is a Swagger 2.0 description, only OpenAPI 3 is supported", path)
+		}
+		return nil, fmt.Errorf("%s is not an OpenAPI 3 description", path)
+	}
+
+	l := &loader{doc: doc, root: root}
+	spec := &Spec{Path: path, basePaths: serverBasePaths(root["servers"])}
+
+	paths, _ := root["paths"].(map[string]interface{})
+	for template, value := range paths {
+		item, pointer, err := l.deref(value, "/paths/"+escape(template))
+		if err != nil {
+			return nil, err
+		}
+		shared, err := l.parameters(item["parameters"], pointer+"/parameters")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", template, err)
+		}
+		pattern, names := compileTemplate(template)
+		for _, method := range methods {
+			value, ok := item[method]
+			if !ok {
+				continue
+			}
+			op := &Operation{
+				Method:  strings.ToUpper(method),
+				Path:    template,
+				pattern: pattern,
+				names:   names,
+				literal: len(templateParam.ReplaceAllString(template, "")),
+			}
+			if err := l.operation(op, value, pointer+"/"+method, shared); err != nil {
+				return nil, fmt.Errorf("%s: %w", op.Label(), err)
+			}
+			spec.Operations = append(spec.Operations, op)
+		}
+	}
+
+	sort.Slice(spec.Operations, func(i, j int) bool {
+		a, b := spec.Operations[i], spec.Operations[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return methodIndex(a.Method) < methodIndex(b.Method)
+	})
+	return spec, nil
+}
+
+// Name returns the file name of the description
+func (s *Spec) Name() string {
+	return filepath.Base(s.Path)
+}
+
+// loader reads the parts of a description and compiles their schemas
+type loader struct {
+	doc  *jsonschema.Document
+	root map[string]interface{}
+}
+
+func (l *loader) operation(op *Operation, value interface{}, pointer string, shared []*parameter) error {
+	node, pointer, err := l.deref(value, pointer)
+	if err != nil {
+		return err
+	}
+	op.ID, _ = node["operationId"].(string)
+
+	own, err := l.parameters(node["parameters"], pointer+"/parameters")
+	if err != nil {
+		return err
+	}
+	// Operation parameters override path item parameters of the same name and location
+	op.parameters = own
+	for _, p := range shared {
+		overridden := false
+		for _, o := range own {
+			if o.name == p.name && o.in == p.in {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			op.parameters = append(op.parameters, p)
+		}
+	}
+
+	if value, ok := node["requestBody"]; ok {
+		body, bodyPointer, err := l.deref(value, pointer+"/requestBody")
+		if err != nil {
+			return err
+		}
+		op.body = &requestBody{}
+		op.body.required, _ = body["required"].(bool)
+		if op.body.content, err = l.content(body["content"], bodyPointer+"/content"); err != nil {
+			return fmt.Errorf("request body: %w", err)
+		}
+	}
+
+	op.responses = make(map[string]*response)
+	responses, _ := node["responses"].(map[string]interface{})
+	for code, value := range responses {
+		resp, respPointer, err := l.deref(value, pointer+"/responses/"+escape(code))
+		if err != nil {
+			return err
+		}
+		content, err := l.content(resp["content"], respPointer+"/content")
+		if err != nil {
+			return fmt.Errorf("response %s: %w", code, err)
+		}
+		if code = strings.ToUpper(code); code == "DEFAULT" {
+			code = "default"
+		}
+		op.responses[code] = &response{content: content}
+	}
+	return nil
+}
+
+func (l *loader) parameters(value interface{}, pointer string) ([]*parameter, error) {
+	list, _ := value.([]interface{})
+	var params []*parameter
+	for i, item := range list {
+		node, itemPointer, err := l.deref(item, fmt.Sprintf("%s/%d", pointer, i))
+		if err != nil {
+			return nil, err
+		}
+		p := &parameter{}
+		p.name, _ = node["name"].(string)
+		p.in, _ = node["in"].(string)
+		p.required, _ = node["required"].(bool)
+		if p.name == "" || p.in == "" {
+			return nil, fmt.Errorf("parameter %d needs a name and in", i)
+		}
+		if p.in == "path" {
+			p.required = true
+		}
+
+		// A parameter is described by a schema, or by the schema of a single media type
+		schemaPointer := itemPointer + "/schema"
+		if _, ok := node["schema"]; !ok {
+			schemaPointer = ""
+			content, _ := node["content"].(map[string]interface{})
+			for media, value := range content {
+				if m, ok := value.(map[string]interface{}); ok && m["schema"] != nil {
+					schemaPointer = itemPointer + "/content/" + escape(media) + "/schema"
+				}
+			}
+		}
+		if schemaPointer != "" {
+			if p.schema, err = l.doc.Schema(schemaPointer); err != nil {
+				return nil, fmt.Errorf("parameter %s: %w", p.name, err)
+			}
+		}
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+func (l *loader) content(value interface{}, pointer string) (map[string]*jsonschema.Schema, error) {
+	media, _ := value.(map[string]interface{})
+	if len(media) == 0 {
+		return nil, nil
+	}
+	content := make(map[string]*jsonschema.Schema, len(media))
+	for mediaType, value := range media {
+		content[strings.ToLower(mediaType)] = nil
+		if m, ok := value.(map[string]interface{}); !ok || m["schema"] == nil {
+			continue
+		}
+		schema, err := l.doc.Schema(pointer + "/" + escape(mediaType) + "/schema")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", mediaType, err)
+		}
+		content[strings.ToLower(mediaType)] = schema
+	}
+	return content, nil
+}
+
+// deref follows $ref to components of the same document and returns the object
+// together with its JSON Pointer
+func (l *loader) deref(value interface{}, pointer string) (map[string]interface{}, string, error) {
+	for depth := 0; depth < 32; depth++ {
+		node, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, "", fmt.Errorf("expected an object at #%s", pointer)
+		}
+		ref, ok := node["$ref"].(string)
+		if !ok {
+			return node, pointer, nil
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			return nil, "", fmt.Errorf("cannot resolve $ref %q: only references within the description are supported", ref)
+		}
+		pointer = ref[1:]
+		if value, ok = lookup(l.root, pointer); !ok {
+			return nil, "", fmt.Errorf("cannot resolve $ref %q", ref)
+		}
+	}
+	return nil, "", fmt.Errorf("too many nested references at #%s", pointer)
+}
+
+func lookup(node interface{}, pointer string) (interface{}, bool) {
+	if unescaped, err := url.PathUnescape(pointer); err == nil {
+		pointer = unescaped
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if node, ok = m[token]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// serverBasePaths returns the path prefixes of the server URLs, with server variables
+// set to their defaults
+func serverBasePaths(value interface{}) []string {
+	var paths []string
+	servers, _ := value.([]interface{})
+	for _, item := range servers {
+		server, _ := item.(map[string]interface{})
+		raw, _ := server["url"].(string)
+		variables, _ := server["variables"].(map[string]interface{})
+		for name, value := range variables {
+			variable, _ := value.(map[string]interface{})
+			raw = strings.ReplaceAll(raw, "{"+name+"}", fmt.Sprint(variable["default"]))
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+		if base := strings.TrimSuffix(u.Path, "/"); base != "" {
+			paths = append(paths, base)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool { return len(paths[i]) > len(paths[j]) })
+	return paths
+}
+
+var templateParam = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// compileTemplate turns a path template into a pattern capturing its parameters
+func compileTemplate(template string) (*regexp.Regexp, []string) {
+	var names []string
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	last := 0
+	for _, match := range templateParam.FindAllStringSubmatchIndex(template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(template[last:match[0]]))
+		pattern.WriteString("([^/]+)")
+		names = append(names, template[match[2]:match[3]])
+		last = match[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+	pattern.WriteString("$")
+	return regexp.MustCompile(pattern.String()), names
+}
+
+func methodIndex(method string) int {
+	for i, m := range methods {
+		if strings.EqualFold(m, method) {
+			return i
+		}
+	}
+	return len(methods)
+}
+
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -1,6 +1,7 @@
 package performance
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -141,7 +142,7 @@ func (lt *LoadTester) worker(id int, test *LoadTest, wg *sync.WaitGroup, results
 		requestStart := time.Now()
 
 		// Execute request
-		_, err := lt.client.Execute(test.Request)
+		_, err := lt.client.Execute(context.Background(), test.Request)
 		duration := time.Since(requestStart)
 
 		// Send result
@@ -0,0 +1,151 @@
+package redact
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is one step of a sensitive JSON path
+type segment struct {
+	name      string // Object key; empty for indexes and wildcards
+	index     int    // List index, or -1
+	wildcard  bool   // Any key or index
+	recursive bool   // Matches at any depth, as in $..password
+}
+
+// parsePath parses the JSONPath subset used for sensitive fields: $.a.b, $.a[0],
+// $.a[*].b, $.a.* and $..b
+func parsePath(path string) ([]segment, error) {
+	rest := strings.TrimSpace(path)
+	if !strings.HasPrefix(rest, "$") {
+		return nil, fmt.Errorf("invalid sensitive path %q: must start with $", path)
+	}
+	rest = rest[1:]
+
+	var segments []segment
+	for rest != "" {
+		recursive := false
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			recursive = true
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid sensitive path %q: unclosed [", path)
+			}
+			inner := strings.Trim(rest[1:end], `'"`)
+			rest = rest[end+1:]
+			if inner == "*" {
+				segments = append(segments, segment{index: -1, wildcard: true})
+			} else if index, err := strconv.Atoi(inner); err == nil {
+				segments = append(segments, segment{index: index})
+			} else {
+				segments = append(segments, segment{name: inner, index: -1})
+			}
+			continue
+		default:
+			return nil, fmt.Errorf("invalid sensitive path %q", path)
+		}
+
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		name := rest[:end]
+		rest = rest[end:]
+		if name == "" {
+			return nil, fmt.Errorf("invalid sensitive path %q: empty name", path)
+		}
+		segments = append(segments, segment{name: name, index: -1, wildcard: name == "*", recursive: recursive})
+	}
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid sensitive path %q: the whole document cannot be sensitive", path)
+	}
+	return segments, nil
+}
+
+// children calls fn for the children of value matched by seg, ignoring seg.recursive
+func children(value interface{}, seg segment, fn func(key string, index int, child interface{})) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if seg.wildcard || (seg.name != "" && key == seg.name) {
+				fn(key, -1, child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			if seg.wildcard || (seg.name == "" && seg.index == i) {
+				fn("", i, child)
+			}
+		}
+	}
+}
+
+// descendants calls fn for every map and list value nested in value, at any depth
+func descendants(value interface{}, fn func(key string, index int, child interface{})) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			fn(key, -1, child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			fn("", i, child)
+		}
+	}
+}
+
+// mask replaces the values matched by path in value, which must be a copy
+func mask(value interface{}, path []segment) interface{} {
+	if len(path) == 0 {
+		return Mask
+	}
+	seg, rest := path[0], path[1:]
+
+	set := func(key string, index int, child interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			v[key] = child
+		case []interface{}:
+			v[index] = child
+		}
+	}
+
+	children(value, seg, func(key string, index int, child interface{}) {
+		set(key, index, mask(child, rest))
+	})
+	if seg.recursive {
+		descendants(value, func(key string, index int, child interface{}) {
+			// Children matched above are already masked
+			if _, masked := child.(string); masked && child == Mask {
+				return
+			}
+			set(key, index, mask(child, path))
+		})
+	}
+	return value
+}
+
+// collect calls fn with the values matched by path in value
+func collect(value interface{}, path []segment, fn func(interface{})) {
+	if len(path) == 0 {
+		fn(value)
+		return
+	}
+	seg, rest := path[0], path[1:]
+
+	children(value, seg, func(_ string, _ int, child interface{}) {
+		collect(child, rest, fn)
+	})
+	if seg.recursive {
+		descendants(value, func(_ string, _ int, child interface{}) {
+			collect(child, path, fn)
+		})
+	}
+}
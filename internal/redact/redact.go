@@ -0,0 +1,245 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Mask replaces redacted values
+const Mask = "***"
+
+// minValueLength is the length below which values are not masked by value, as short
+// values such as "1" or "yes" would mask unrelated text. They are still masked by path.
+const minValueLength = 4
+
+// sensitiveHeaders are request and response headers whose values are always redacted
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+	"x-api-key":           true,
+}
+
+// sensitiveNameParts mark variable and environment variable names that hold secrets
+var sensitiveNameParts = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "authorization", "credential", "private_key", "cookie", "session"}
+
+// Redactor masks sensitive values in text and structured data. Values are registered
+// as they become known during a run, e.g. when a secret is resolved or an Authorization
+// header is set, and are then masked wherever they appear. Values at sensitive JSON
+// paths are masked in response and request bodies. It is safe for concurrent use.
+type Redactor struct {
+	mu     sync.RWMutex
+	values map[string]bool
+	sorted []string // values, longest first, so that longer values are replaced before their substrings
+	paths  [][]segment
+}
+
+// New creates a redactor without any sensitive values
+func New() *Redactor {
+	return &Redactor{values: make(map[string]bool)}
+}
+
+// SensitiveName reports whether a variable or environment variable name suggests a secret,
+// e.g. API_TOKEN, db_password or client_secret
+func SensitiveName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, part := range sensitiveNameParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// SensitiveHeader reports whether the values of a header are always redacted
+func SensitiveHeader(name string) bool {
+	return sensitiveHeaders[strings.ToLower(name)]
+}
+
+// AddValue registers a sensitive value
+func (r *Redactor) AddValue(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minValueLength {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.values[value] {
+		return
+	}
+	r.values[value] = true
+	r.sorted = append(r.sorted, value)
+	sort.SliceStable(r.sorted, func(i, j int) bool { return len(r.sorted[i]) > len(r.sorted[j]) })
+}
+
+// AddValueOf registers the string form of a captured or extracted value. Objects and
+// lists register each of their leaves.
+func (r *Redactor) AddValueOf(value interface{}) {
+	switch v := value.(type) {
+	case nil, bool:
+	case string:
+		r.AddValue(v)
+	case float64:
+		r.AddValue(strconv.FormatFloat(v, 'f', -1, 64))
+	case map[string]interface{}:
+		for _, item := range v {
+			r.AddValueOf(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			r.AddValueOf(item)
+		}
+	default:
+		r.AddValue(fmt.Sprintf("%v", v))
+	}
+}
+
+// AddHeader registers the value of a sensitive header. For credentials with a scheme,
+// such as "Bearer abc123", the credentials are registered too; for cookies, each cookie value.
+func (r *Redactor) AddHeader(name, value string) {
+	if !SensitiveHeader(name) {
+		return
+	}
+	r.AddValue(value)
+
+	switch strings.ToLower(name) {
+	case "cookie", "set-cookie":
+		for _, cookie := range strings.Split(value, ";") {
+			if _, cookieValue, found := strings.Cut(cookie, "="); found {
+				r.AddValue(cookieValue)
+			}
+			// Attributes of Set-Cookie follow the first cookie
+			if strings.ToLower(name) == "set-cookie" {
+				break
+			}
+		}
+	default:
+		if _, credentials, found := strings.Cut(value, " "); found {
+			r.AddValue(credentials)
+		}
+	}
+}
+
+// AddPaths registers JSON paths whose values are masked in bodies, e.g. "$.token",
+// "$.users[*].password" or "$..secret"
+func (r *Redactor) AddPaths(paths ...string) error {
+	parsed := make([][]segment, 0, len(paths))
+	for _, path := range paths {
+		segments, err := parsePath(path)
+		if err != nil {
+			return err
+		}
+		parsed = append(parsed, segments)
+	}
+
+	r.mu.Lock()
+	r.paths = append(r.paths, parsed...)
+	r.mu.Unlock()
+	return nil
+}
+
+// AddValuesAt registers the values found at the sensitive paths of data, so that they
+// are masked in logs and results too
+func (r *Redactor) AddValuesAt(data interface{}) {
+	r.mu.RLock()
+	paths := r.paths
+	r.mu.RUnlock()
+
+	for _, path := range paths {
+		collect(data, path, r.AddValueOf)
+	}
+}
+
+// String masks the registered values in s
+func (r *Redactor) String(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, value := range r.sorted {
+		if strings.Contains(s, value) {
+			s = strings.ReplaceAll(s, value, Mask)
+		}
+	}
+	return s
+}
+
+// Value returns a copy of a JSON-like value with the values at sensitive paths and the
+// registered values masked. The original value is not modified.
+func (r *Redactor) Value(value interface{}) interface{} {
+	copied := r.copyValue(value)
+
+	r.mu.RLock()
+	paths := r.paths
+	r.mu.RUnlock()
+	for _, path := range paths {
+		copied = mask(copied, path)
+	}
+	return copied
+}
+
+// Map returns a copy of a map with its values redacted as by Value
+func (r *Redactor) Map(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		result[key] = r.Value(value)
+	}
+	return result
+}
+
+// Body redacts a response or request body. JSON bodies with a value at a sensitive
+// path are re-encoded with the value masked; other bodies keep their formatting and
+// are masked by value.
+func (r *Redactor) Body(body []byte) []byte {
+	r.mu.RLock()
+	hasPaths := len(r.paths) > 0
+	r.mu.RUnlock()
+
+	var data interface{}
+	if hasPaths && json.Unmarshal(body, &data) == nil {
+		matched := false
+		r.mu.RLock()
+		for _, path := range r.paths {
+			collect(data, path, func(interface{}) { matched = true })
+		}
+		r.mu.RUnlock()
+		if matched {
+			if indented, err := json.MarshalIndent(r.Value(data), "", "  "); err == nil {
+				return indented
+			}
+		}
+	}
+	return []byte(r.String(string(body)))
+}
+
+// copyValue deep-copies maps and lists and masks the registered values in strings
+func (r *Redactor) copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.String(v)
+	case float64:
+		if r.String(strconv.FormatFloat(v, 'f', -1, 64)) == Mask {
+			return Mask
+		}
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = r.copyValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = r.copyValue(item)
+		}
+		return result
+	}
+	return value
+}
@@ -0,0 +1,119 @@
+package redact
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	r := New()
+	r.AddValue("abc123")
+	r.AddValue("abc123456")
+	r.AddValue("no") // too short to be masked by value
+
+	got := r.String("token=abc123456 other=abc123 answer=no")
+	if got != "token=*** other=*** answer=no" {
+		t.Errorf("Unexpected result: %q", got)
+	}
+}
+
+func TestAddHeader(t *testing.T) {
+	r := New()
+	r.AddHeader("Authorization", "Bearer tok-123")
+	r.AddHeader("Cookie", "session=s-456; theme=dark")
+	r.AddHeader("Set-Cookie", "sid=s-789; Path=/; HttpOnly")
+	r.AddHeader("Content-Type", "application/json")
+
+	got := r.String("tok-123 s-456 s-789 application/json")
+	if got != "*** *** *** application/json" {
+		t.Errorf("Unexpected result: %q", got)
+	}
+	if r.String("/Path=/") != "/Path=/" {
+		t.Error("Expected Set-Cookie attributes not to be registered")
+	}
+}
+
+func TestSensitiveName(t *testing.T) {
+	for _, name := range []string{"API_TOKEN", "db_password", "client_secret", "x_api_key", "SESSION_ID"} {
+		if !SensitiveName(name) {
+			t.Errorf("Expected %s to be sensitive", name)
+		}
+	}
+	for _, name := range []string{"base_url", "user_id", "region"} {
+		if SensitiveName(name) {
+			t.Errorf("Expected %s not to be sensitive", name)
+		}
+	}
+}
+
+func TestValuePaths(t *testing.T) {
+	r := New()
+	if err := r.AddPaths("$.token", "$.users[*].password", "$..pin", "$.list[1]"); err != nil {
+		t.Fatal(err)
+	}
+
+	original := map[string]interface{}{
+		"token": "t",
+		"users": []interface{}{
+			map[string]interface{}{"name": "alice", "password": "p1"},
+			map[string]interface{}{"name": "bob", "password": "p2"},
+		},
+		"nested": map[string]interface{}{"deep": map[string]interface{}{"pin": float64(1234)}},
+		"list":   []interface{}{"a", "b"},
+	}
+	got := r.Value(original)
+
+	want := map[string]interface{}{
+		"token": Mask,
+		"users": []interface{}{
+			map[string]interface{}{"name": "alice", "password": Mask},
+			map[string]interface{}{"name": "bob", "password": Mask},
+		},
+		"nested": map[string]interface{}{"deep": map[string]interface{}{"pin": Mask}},
+		"list":   []interface{}{"a", Mask},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if original["token"] != "t" {
+		t.Error("Expected the original value to be left unchanged")
+	}
+}
+
+func TestAddValuesAt(t *testing.T) {
+	r := New()
+	r.AddPaths("$.auth.access_token")
+	r.AddValuesAt(map[string]interface{}{"auth": map[string]interface{}{"access_token": "eyJhbGci"}})
+
+	if got := r.String("Authorization: Bearer eyJhbGci"); got != "Authorization: Bearer ***" {
+		t.Errorf("Unexpected result: %q", got)
+	}
+}
+
+func TestBody(t *testing.T) {
+	r := New()
+	r.AddValue("secret-value")
+
+	// Without a sensitive path the body keeps its formatting
+	if got := string(r.Body([]byte(`{"a":"secret-value"}`))); got != `{"a":"***"}` {
+		t.Errorf("Unexpected body: %s", got)
+	}
+
+	r.AddPaths("$.pin")
+	got := string(r.Body([]byte(`{"pin":"12","id":1}`)))
+	if !strings.Contains(got, `"pin": "***"`) || !strings.Contains(got, `"id": 1`) {
+		t.Errorf("Unexpected body: %s", got)
+	}
+	if got := string(r.Body([]byte("plain secret-value"))); got != "plain ***" {
+		t.Errorf("Unexpected text body: %s", got)
+	}
+}
+
+func TestInvalidPaths(t *testing.T) {
+	for _, path := range []string{"token", "$", "$.a[0", "$.a..", "$."} {
+		if err := New().AddPaths(path); err == nil {
+			t.Errorf("Expected an error for %q", path)
+		}
+	}
+}
@@ -17,6 +17,14 @@ type WorkflowGroup struct {
 	FileName     string
 	WorkflowName string
 	Results      []workflow.TestResult
+	Captures     map[string]interface{} // Values of the workflow captures
+	Seed         *int64                 // Faker seed the workflow ran with, if it used one
+}
+
+// CaptureSection lists the values captured by the workflow captures of one workflow
+type CaptureSection struct {
+	Name   string
+	Values map[string]interface{}
 }
 
 // HTMLReportData represents the data structure for HTML report
@@ -32,12 +40,16 @@ type HTMLReportData struct {
 	SuccessRate   float64
 	Results       []workflow.TestResult
 	WorkflowFile  string
-	Groups        []WorkflowGroup // Groups for multi-file reports
-	IsGrouped     bool            // Whether to show grouped view
+	Environment   string           // Environment the workflows ran against, if any
+	Seed          *int64           // Faker seed of a single workflow run; grouped reports show it per group
+	Groups        []WorkflowGroup  // Groups for multi-file reports
+	IsGrouped     bool             // Whether to show grouped view
+	Captures      []CaptureSection // Values of workflow captures, one section per workflow
 }
 
 // GenerateHTMLReport generates a self-contained HTML report from test results
-func GenerateHTMLReport(results []workflow.TestResult, workflowName string, workflowFile string, outputPath string) error {
+// and the values of the workflow captures
+func GenerateHTMLReport(results []workflow.TestResult, captures map[string]interface{}, workflowName string, workflowFile string, environment string, seed *int64, outputPath string) error {
 	// Calculate statistics
 	passed := 0
 	failed := 0
@@ -74,8 +86,19 @@ func GenerateHTMLReport(results []workflow.TestResult, workflowName string, work
 		SuccessRate:   successRate,
 		Results:       results,
 		WorkflowFile:  workflowFile,
+		Environment:   environment,
+		Seed:          seed,
 		IsGrouped:     false,
 	}
+	if len(captures) > 0 {
+		data.Captures = []CaptureSection{{Name: workflowName, Values: captures}}
+	}
+
+	// Data-driven runs are shown with one group per data row
+	if hasRows(results) {
+		data.Groups = splitByRow(WorkflowGroup{FileName: filepath.Base(workflowFile), WorkflowName: workflowName, Results: results, Seed: seed})
+		data.IsGrouped = true
+	}
 
 	// Generate HTML
 	htmlContent, err := generateHTML(data)
@@ -106,7 +129,7 @@ func GenerateHTMLReport(results []workflow.TestResult, workflowName string, work
 }
 
 // GenerateHTMLReportFromGroups generates a self-contained HTML report from grouped test results
-func GenerateHTMLReportFromGroups(groups []WorkflowGroup, workflowName string, outputPath string) error {
+func GenerateHTMLReportFromGroups(groups []WorkflowGroup, workflowName string, environment string, outputPath string) error {
 	// Calculate statistics from all groups
 	passed := 0
 	failed := 0
@@ -114,6 +137,21 @@ func GenerateHTMLReportFromGroups(groups []WorkflowGroup, workflowName string, o
 	totalDuration := time.Duration(0)
 	allResults := make([]workflow.TestResult, 0)
 
+	// Data-driven workflows are split into one group per data row
+	var expanded []WorkflowGroup
+	var captures []CaptureSection
+	for _, group := range groups {
+		expanded = append(expanded, splitByRow(group)...)
+		if len(group.Captures) > 0 {
+			name := group.WorkflowName
+			if name == "" {
+				name = group.FileName
+			}
+			captures = append(captures, CaptureSection{Name: name, Values: group.Captures})
+		}
+	}
+	groups = expanded
+
 	for _, group := range groups {
 		for _, result := range group.Results {
 			allResults = append(allResults, result)
@@ -146,8 +184,10 @@ func GenerateHTMLReportFromGroups(groups []WorkflowGroup, workflowName string, o
 		TotalDuration: totalDuration,
 		SuccessRate:   successRate,
 		Results:       allResults,
+		Environment:   environment,
 		Groups:        groups,
 		IsGrouped:     true,
+		Captures:      captures,
 	}
 
 	// Generate HTML
@@ -178,6 +218,46 @@ func GenerateHTMLReportFromGroups(groups []WorkflowGroup, workflowName string, o
 	return nil
 }
 
+// splitByRow splits the results of a data-driven workflow into one group per data row.
+// Groups without data rows are returned unchanged.
+func splitByRow(group WorkflowGroup) []WorkflowGroup {
+	if !hasRows(group.Results) {
+		return []WorkflowGroup{group}
+	}
+
+	var groups []WorkflowGroup
+	index := make(map[string]int)
+
+	for _, result := range group.Results {
+		i, exists := index[result.Row]
+		if !exists {
+			name := group.WorkflowName
+			if result.Row != "" {
+				if name == "" {
+					name = group.FileName
+				}
+				name = fmt.Sprintf("%s (%s)", name, result.Row)
+			}
+			i = len(groups)
+			index[result.Row] = i
+			groups = append(groups, WorkflowGroup{FileName: group.FileName, WorkflowName: name, Seed: group.Seed})
+		}
+		groups[i].Results = append(groups[i].Results, result)
+	}
+
+	return groups
+}
+
+// hasRows reports whether results come from a data-driven workflow
+func hasRows(results []workflow.TestResult) bool {
+	for _, result := range results {
+		if result.Row != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // generateHTML generates the HTML content using a template
 func generateHTML(data HTMLReportData) (string, error) {
 	tmpl := `<!DOCTYPE html>
@@ -418,6 +498,22 @@ func generateHTML(data HTMLReportData) (string, error) {
             color: #856404;
         }
         
+        .phase-badge {
+            margin-right: 8px;
+            padding: 2px 8px;
+            border-radius: 10px;
+            font-size: 0.75em;
+            font-weight: 600;
+            text-transform: uppercase;
+            background: #e2e3e5;
+            color: #383d41;
+        }
+        
+        .phase-badge.teardown {
+            background: #d1ecf1;
+            color: #0c5460;
+        }
+        
         .test-duration {
             color: #6c757d;
             font-size: 0.9em;
@@ -481,6 +577,11 @@ func generateHTML(data HTMLReportData) (string, error) {
             border-left: 3px solid #dc3545;
         }
         
+        .validation.warning {
+            background: #fff3cd;
+            border-left: 3px solid #ffc107;
+        }
+        
         .validation-icon {
             font-size: 1.2em;
             font-weight: bold;
@@ -501,6 +602,28 @@ func generateHTML(data HTMLReportData) (string, error) {
             margin-top: 4px;
         }
         
+        .validation-diff {
+            list-style: none;
+            margin: 6px 0 0;
+            padding: 0;
+            font-family: monospace;
+            font-size: 0.9em;
+        }
+        
+        .validation-diff li::before {
+            display: inline-block;
+            width: 1.2em;
+        }
+        
+        .validation-diff .diff-missing { color: #dc3545; }
+        .validation-diff .diff-missing::before { content: "-"; }
+        .validation-diff .diff-extra { color: #28a745; }
+        .validation-diff .diff-extra::before { content: "+"; }
+        .validation-diff .diff-changed,
+        .validation-diff .diff-type { color: #b8860b; }
+        .validation-diff .diff-changed::before,
+        .validation-diff .diff-type::before { content: "~"; }
+        
         .captured-data {
             background: #e7f3ff;
             padding: 15px;
@@ -615,6 +738,8 @@ func generateHTML(data HTMLReportData) (string, error) {
             <div class="subtitle">
                 <strong>{{.WorkflowName}}</strong>
                 {{if .WorkflowFile}}<br>{{.WorkflowFile}}{{end}}
+                {{if .Environment}}<br>Environment: <strong>{{.Environment}}</strong>{{end}}
+                {{if .Seed}}<br>Seed: <strong>{{.Seed}}</strong>{{end}}
             </div>
             <div class="subtitle" style="margin-top: 15px; font-size: 0.9em;">
                 Generated at {{.GeneratedAt}}
@@ -650,6 +775,20 @@ func generateHTML(data HTMLReportData) (string, error) {
             </div>
         </div>
         
+        {{if .Captures}}
+        <div class="results">
+            <div class="results-header">Captured Values</div>
+            {{range .Captures}}
+            <div class="detail-section">
+                <h4>{{.Name}}</h4>
+                <div class="captured-data">
+                    <pre>{{formatJSON .Values}}</pre>
+                </div>
+            </div>
+            {{end}}
+        </div>
+        {{end}}
+
         <div class="results">
             <div class="results-header">Test Results</div>
             {{if .IsGrouped}}
@@ -660,7 +799,7 @@ func generateHTML(data HTMLReportData) (string, error) {
                         <div class="workflow-group-title">
                             {{if $group.WorkflowName}}{{$group.WorkflowName}}{{else}}{{$group.FileName}}{{end}}
                         </div>
-                        <div class="workflow-group-subtitle">{{$group.FileName}}</div>
+                        <div class="workflow-group-subtitle">{{$group.FileName}}{{if $group.Seed}} • seed {{$group.Seed}}{{end}}</div>
                     </div>
                     <div class="workflow-group-stats">
                         <div>Tests: {{len $group.Results}}</div>
@@ -672,7 +811,7 @@ func generateHTML(data HTMLReportData) (string, error) {
                     {{$globalIndex := (add (mul $groupIndex 1000) $resultIndex)}}
                     <div class="test-result {{$result.Status}}" onclick="toggleDetails({{$globalIndex}})">
                         <div class="test-header">
-                            <div class="test-name">{{$result.Name}}</div>
+                            <div class="test-name">{{if $result.Phase}}<span class="phase-badge {{$result.Phase}}">{{$result.Phase}}</span>{{end}}{{$result.Name}}</div>
                             <div class="test-status">
                                 <span class="status-badge {{$result.Status}}">{{$result.Status}}</span>
                                 <span class="test-duration">{{formatDuration $result.Duration}}</span>
@@ -714,13 +853,14 @@ func generateHTML(data HTMLReportData) (string, error) {
                                 <h4>Validations ({{len $result.Validations}})</h4>
                                 <div class="validations">
                                     {{range $result.Validations}}
-                                    <div class="validation {{if .Passed}}passed{{else}}failed{{end}}">
-                                        <span class="validation-icon">{{if .Passed}}✓{{else}}✗{{end}}</span>
+                                    <div class="validation {{if .Passed}}passed{{else if .Warning}}warning{{else}}failed{{end}}">
+                                        <span class="validation-icon">{{if .Passed}}✓{{else if .Warning}}!{{else}}✗{{end}}</span>
                                         <div class="validation-details">
                                             <div class="validation-type">{{.Type}}</div>
                                             <div class="validation-expected">
                                                 Expected: {{formatValue .Expected}} | Actual: {{formatValue .Actual}}
-                                                {{if .Error}}<br><strong>Error:</strong> {{.Error}}{{end}}
+                                                {{if .Error}}<br><strong>{{if .Warning}}Warning{{else}}Error{{end}}:</strong> {{.Error}}{{end}}
+                                                {{if .Diff}}<ul class="validation-diff">{{range .Diff}}<li class="diff-{{.Kind}}">{{.String}}</li>{{end}}</ul>{{end}}
                                             </div>
                                         </div>
                                     </div>
@@ -744,7 +884,7 @@ func generateHTML(data HTMLReportData) (string, error) {
                                 <div class="repeat-results">
                                     {{range $i, $repeatResult := $result.RepeatResults}}
                                     <div class="repeat-iteration">
-                                        <strong>Iteration {{add $i 1}}:</strong> 
+                                        <strong>Iteration {{add $i 1}}:</strong> {{if $repeatResult.Item}}<code>{{$repeatResult.Item}}</code>{{end}}
                                         <span class="status-badge {{$repeatResult.Status}}">{{$repeatResult.Status}}</span>
                                         <span class="test-duration">{{formatDuration $repeatResult.Duration}}</span>
                                         {{if $repeatResult.Error}}
@@ -772,7 +912,7 @@ func generateHTML(data HTMLReportData) (string, error) {
             {{range $index, $result := .Results}}
             <div class="test-result {{$result.Status}}" onclick="toggleDetails({{$index}})">
                 <div class="test-header">
-                    <div class="test-name">{{$result.Name}}</div>
+                    <div class="test-name">{{if $result.Phase}}<span class="phase-badge {{$result.Phase}}">{{$result.Phase}}</span>{{end}}{{$result.Name}}</div>
                     <div class="test-status">
                         <span class="status-badge {{$result.Status}}">{{$result.Status}}</span>
                         <span class="test-duration">{{formatDuration $result.Duration}}</span>
@@ -814,13 +954,14 @@ func generateHTML(data HTMLReportData) (string, error) {
                         <h4>Validations ({{len $result.Validations}})</h4>
                         <div class="validations">
                             {{range $result.Validations}}
-                            <div class="validation {{if .Passed}}passed{{else}}failed{{end}}">
-                                <span class="validation-icon">{{if .Passed}}✓{{else}}✗{{end}}</span>
+                            <div class="validation {{if .Passed}}passed{{else if .Warning}}warning{{else}}failed{{end}}">
+                                <span class="validation-icon">{{if .Passed}}✓{{else if .Warning}}!{{else}}✗{{end}}</span>
                                 <div class="validation-details">
                                     <div class="validation-type">{{.Type}}</div>
                                     <div class="validation-expected">
                                         Expected: {{formatValue .Expected}} | Actual: {{formatValue .Actual}}
-                                        {{if .Error}}<br><strong>Error:</strong> {{.Error}}{{end}}
+                                        {{if .Error}}<br><strong>{{if .Warning}}Warning{{else}}Error{{end}}:</strong> {{.Error}}{{end}}
+                                        {{if .Diff}}<ul class="validation-diff">{{range .Diff}}<li class="diff-{{.Kind}}">{{.String}}</li>{{end}}</ul>{{end}}
                                     </div>
                                 </div>
                             </div>
@@ -844,7 +985,7 @@ func generateHTML(data HTMLReportData) (string, error) {
                         <div class="repeat-results">
                             {{range $i, $repeatResult := $result.RepeatResults}}
                             <div class="repeat-iteration">
-                                <strong>Iteration {{add $i 1}}:</strong> 
+                                <strong>Iteration {{add $i 1}}:</strong> {{if $repeatResult.Item}}<code>{{$repeatResult.Item}}</code>{{end}}
                                 <span class="status-badge {{$repeatResult.Status}}">{{$repeatResult.Status}}</span>
                                 <span class="test-duration">{{formatDuration $repeatResult.Duration}}</span>
                                 {{if $repeatResult.Error}}
@@ -0,0 +1,67 @@
+package script
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// builtins are the names scripts have besides those of the Starlark universe
+var builtins = starlark.StringDict{
+	"fail":    starlark.NewBuiltin("fail", builtinFail),
+	"matches": starlark.NewBuiltin("matches", builtinMatches),
+	"struct":  starlark.NewBuiltin("struct", starlarkstruct.Make),
+	"json":    json.Module,
+}
+
+// failError is the error of fail(), which fails the script rather than breaking it
+type failError struct {
+	message string
+}
+
+func (e *failError) Error() string {
+	return "fail: " + e.message
+}
+
+// builtinFail fails the script with its arguments joined by sep as the message
+func builtinFail(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	sep := " "
+	if err := starlark.UnpackArgs("fail", nil, kwargs, "sep?", &sep); err != nil {
+		return nil, err
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = str(arg)
+	}
+	return nil, &failError{message: strings.Join(parts, sep)}
+}
+
+var (
+	patternMu sync.Mutex
+	patterns  = make(map[string]*regexp.Regexp)
+)
+
+// builtinMatches reports whether a string matches a regular expression
+func builtinMatches(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern, s string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &pattern, &s); err != nil {
+		return nil, err
+	}
+	patternMu.Lock()
+	re, ok := patterns[pattern]
+	if !ok {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			patternMu.Unlock()
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+		patterns[pattern] = re
+	}
+	patternMu.Unlock()
+	return starlark.Bool(re.MatchString(s)), nil
+}
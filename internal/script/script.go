@@ -0,0 +1,226 @@
+// Package script runs Starlark scripts, the language of custom validation rules.
+package script
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.starlark.net/resolve"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// maxSteps bounds the work of a run, counted in Starlark instructions
+const maxSteps = 1000000
+
+// Names of the function that holds the statements of a script, and of its result
+const (
+	checkFunction  = "_stepwise_check"
+	resultVariable = "_stepwise_result"
+)
+
+// fileOptions are the language options of scripts and the files they load. There is
+// no while loop and no recursion, so that, with the step limit, every run ends.
+var fileOptions = &syntax.FileOptions{
+	Set:             true,
+	TopLevelControl: true,
+	GlobalReassign:  true,
+}
+
+// Program is a parsed script
+type Program struct {
+	name   string
+	dir    string // Directory that load() paths are relative to
+	source string
+}
+
+// Result is the outcome of a script that ran to completion
+type Result struct {
+	Passed  bool
+	Message string
+}
+
+// Parse parses a script. The name appears in errors, and load() paths are resolved
+// against dir.
+func Parse(name, source, dir string) (*Program, error) {
+	p := &Program{name: name, dir: dir, source: source}
+	f, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	// Names of globals are only known when the script runs, so they are resolved then
+	anyName := func(string) bool { return true }
+	if err := resolve.File(f, anyName, starlark.Universe.Has); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ParseFile parses a script file
+func ParseFile(path string) (*Program, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+	return Parse(filepath.Base(path), string(source), filepath.Dir(path))
+}
+
+// parse parses the script and moves its statements, except load statements, into a
+// function, so that a script can return its result from the top level
+func (p *Program) parse() (*syntax.File, error) {
+	f, err := fileOptions.Parse(p.name, p.source, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var loads, body []syntax.Stmt
+	for _, stmt := range f.Stmts {
+		if _, ok := stmt.(*syntax.LoadStmt); ok {
+			loads = append(loads, stmt)
+		} else {
+			body = append(body, stmt)
+		}
+	}
+	if len(body) == 0 {
+		return f, nil
+	}
+	pos := syntax.Start(body[0])
+	f.Stmts = append(loads,
+		&syntax.DefStmt{Def: pos, Name: &syntax.Ident{NamePos: pos, Name: checkFunction}, Body: body},
+		&syntax.AssignStmt{
+			OpPos: pos,
+			Op:    syntax.EQ,
+			LHS:   &syntax.Ident{NamePos: pos, Name: resultVariable},
+			RHS:   &syntax.CallExpr{Fn: &syntax.Ident{NamePos: pos, Name: checkFunction}, Lparen: pos, Rparen: pos},
+		},
+	)
+	return f, nil
+}
+
+// Run runs the script with the given global variables, converted by ToValue. print()
+// output goes to the print function when it is not nil.
+//
+// A script passes when it ends without a return, returns None or True, or returns a
+// (True, message) tuple. It fails when it returns False or a (False, message)
+// tuple, or calls fail(). Other errors are returned.
+func (p *Program) Run(globals map[string]interface{}, print func(string)) (Result, error) {
+	predeclared := starlark.StringDict{}
+	for name, value := range builtins {
+		predeclared[name] = value
+	}
+	for name, value := range globals {
+		predeclared[name] = ToValue(value)
+	}
+
+	l := &loader{predeclared: predeclared, dirs: map[string]string{p.name: p.dir}, modules: make(map[string]*module)}
+	thread := &starlark.Thread{
+		Name: p.name,
+		Load: l.load,
+		Print: func(_ *starlark.Thread, msg string) {
+			if print != nil {
+				print(msg)
+			}
+		},
+	}
+	thread.SetMaxExecutionSteps(maxSteps)
+
+	f, err := p.parse()
+	if err != nil {
+		return Result{}, err
+	}
+	program, err := starlark.FileProgram(f, predeclared.Has)
+	if err != nil {
+		return Result{}, err
+	}
+	values, err := program.Init(thread, predeclared)
+	if err != nil {
+		var fail *failError
+		if errors.As(err, &fail) {
+			return Result{Passed: false, Message: fail.message}, nil
+		}
+		if thread.ExecutionSteps() >= maxSteps {
+			return Result{}, fmt.Errorf("%s: script exceeded %d steps", p.name, maxSteps)
+		}
+		return Result{}, scriptError(err)
+	}
+	return result(p.name, values[resultVariable])
+}
+
+func result(name string, value starlark.Value) (Result, error) {
+	switch v := value.(type) {
+	case nil, starlark.NoneType:
+		return Result{Passed: true}, nil
+	case starlark.Bool:
+		return Result{Passed: bool(v)}, nil
+	case starlark.Tuple:
+		if len(v) == 2 {
+			if passed, ok := v[0].(starlark.Bool); ok {
+				return Result{Passed: bool(passed), Message: str(v[1])}, nil
+			}
+		}
+	}
+	return Result{}, fmt.Errorf("%s: script must return a bool or a (bool, message) tuple, got %s", name, value.Type())
+}
+
+// scriptError prefixes an evaluation error with the position in the script or loaded
+// file it occurred at
+func scriptError(err error) error {
+	var evalErr *starlark.EvalError
+	if !errors.As(err, &evalErr) {
+		return err
+	}
+	for i := 0; i < len(evalErr.CallStack); i++ {
+		if frame := evalErr.CallStack.At(i); frame.Pos.Filename() != "<builtin>" {
+			return fmt.Errorf("%s: %s", frame.Pos, evalErr.Msg)
+		}
+	}
+	return err
+}
+
+// loader loads the files of load() statements, once per run
+type loader struct {
+	predeclared starlark.StringDict
+	dirs        map[string]string // Directory of each file, by the name in its positions
+	modules     map[string]*module
+}
+
+// module is a loaded file, or nil while it is being loaded
+type module struct {
+	globals starlark.StringDict
+	err     error
+}
+
+func (l *loader) load(thread *starlark.Thread, path string) (starlark.StringDict, error) {
+	if !filepath.IsAbs(path) {
+		from := thread.CallFrame(0).Pos.Filename()
+		dir, ok := l.dirs[from]
+		if !ok {
+			dir = filepath.Dir(from)
+		}
+		path = filepath.Join(dir, path)
+	}
+
+	if m, ok := l.modules[path]; ok {
+		if m == nil {
+			return nil, fmt.Errorf("cycle in load graph")
+		}
+		return m.globals, m.err
+	}
+	l.modules[path] = nil
+
+	m := &module{}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		m.err = fmt.Errorf("failed to read script: %w", err)
+	} else {
+		m.globals, m.err = starlark.ExecFileOptions(fileOptions, thread, path, source, l.predeclared)
+	}
+	l.modules[path] = m
+	return m.globals, m.err
+}
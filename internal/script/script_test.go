@@ -0,0 +1,202 @@
+package script
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func run(t *testing.T, source string, globals map[string]interface{}) (Result, error) {
+	t.Helper()
+	program, err := Parse("test.star", source, t.TempDir())
+	if err != nil {
+		return Result{}, err
+	}
+	return program.Run(globals, nil)
+}
+
+func TestRun(t *testing.T) {
+	globals := map[string]interface{}{
+		"response": NewStruct("response", map[string]interface{}{
+			"status": 200,
+			"body": map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"id": float64(1), "price": 9.5, "tags": []interface{}{"a"}},
+					map[string]interface{}{"id": float64(2), "price": 20.0, "tags": []interface{}{}},
+				},
+				"total": float64(2),
+			},
+		}),
+		"duration": 120,
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		passed  bool
+		message string
+		err     string
+	}{
+		{name: "no return", source: "x = 1", passed: true},
+		{name: "bool", source: "return response.status == 200", passed: true},
+		{name: "false", source: "return duration < 100", passed: false},
+		{name: "tuple", source: `return False, "took %dms" % duration`, passed: false, message: "took 120ms"},
+		{
+			name: "loops and functions",
+			source: `
+def total(items, field = "price"):
+    sum = 0
+    for item in items:
+        if item[field] > 10:
+            continue
+        sum += item[field]
+    return sum
+
+ids = [i["id"] for i in response.body["items"] if len(i["tags"]) == 0]
+if ids != [2]:
+    fail("unexpected ids", ids)
+return total(response.body["items"]) == 9.5, "total is {}".format(total(response.body["items"]))
+`,
+			passed:  true,
+			message: "total is 9.5",
+		},
+		{name: "fail", source: `fail("bad", "status", response.status)`, passed: false, message: "bad status 200"},
+		{name: "fail with sep", source: `fail("a", "b", sep = "-")`, passed: false, message: "a-b"},
+		{name: "runtime error", source: "x = 1\nreturn response.missing", err: `test.star:2:16: "response" struct has no .missing attribute`},
+		{name: "error in function", source: "def f(d):\n    return d[\"x\"]\nreturn f({})", err: "test.star:2:13: key \"x\" not in dict"},
+		{name: "undefined", source: "return y", err: "test.star:1:8: undefined: y"},
+		{name: "bad return", source: "return 1", err: "script must return a bool or a (bool, message) tuple, got int"},
+		{name: "syntax error", source: "if True\n    pass", err: "test.star:2:1: got newline, want ':'"},
+		{name: "while", source: "while True:\n    pass", err: "dialect does not support while loops"},
+		{name: "assert", source: "assert True", err: "test.star:1:1"},
+		{name: "recursion", source: "def f(n):\n    return f(n - 1)\nf(1)", err: "function f called recursively"},
+		{name: "step limit", source: "for i in range(1000000):\n    for j in range(10):\n        pass", err: "script exceeded 1000000 steps"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := run(t, tt.source, globals)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Passed != tt.passed || result.Message != tt.message {
+				t.Errorf("Expected %v %q, got %v %q", tt.passed, tt.message, result.Passed, result.Message)
+			}
+		})
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	globals := map[string]interface{}{
+		"response": NewStruct("response", map[string]interface{}{"status": 200}),
+	}
+	for _, expr := range []string{
+		`sorted({"b": 2, "a": 1}.keys(), reverse = True) == ["b", "a"]`,
+		`max([3, 7, 5]) == 7 and min(3, 1) == 1`,
+		`"x-Request-Id".lower().startswith("x-")`,
+		`matches(r"^\d{3}$", str(response.status)) and not matches("^4", str(response.status))`,
+		`json.decode('{"a": [1, 2.5]}')["a"][1] == 2.5`,
+		`json.encode({"a": None}) == '{"a":null}'`,
+		`[x * 2 for x in range(3)] == [0, 2, 4]`,
+		`"a,b,,c".split(",")[-1] == "c" and "abc"[::-1] == "cba"`,
+		`{k: v for k, v in zip(["a", "b"], [1, 2])} == dict(a = 1, b = 2)`,
+		`7 // 2 == 3 and -7 // 2 == -4 and 7 / 2 == 3.5 and -7 % 3 == 2`,
+		`type(response) == "struct" and hasattr(response, "status")`,
+		`struct(a = 1).a == 1 and (lambda x: x + 1)(1) == 2`,
+		`"%s has %d items" % ("list", 2) == "list has 2 items"`,
+		`"{}-{name}".format(1, name = "x") == "1-x"`,
+		`set([1, 2, 2]) == set([2, 1])`,
+		`[c for c in "ab".elems()] == ["a", "b"]`,
+	} {
+		result, err := run(t, "return "+expr, globals)
+		if err != nil || !result.Passed {
+			t.Errorf("Expected %s to be true, got %+v (%v)", expr, result, err)
+		}
+	}
+
+	if _, err := run(t, `return matches("(", "x")`, nil); err == nil || !strings.Contains(err.Error(), "matches: error parsing regexp") {
+		t.Errorf("Expected an invalid pattern error, got %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "lib"), 0755); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "lib", "checks.star"), []byte(`
+load("limits.star", "MAX")
+
+def paginated(body):
+    return "items" in body and len(body["items"]) <= MAX
+
+_private = 1
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "lib", "limits.star"), []byte("MAX = 2\n"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "lib", "cycle.star"), []byte("load(\"cycle.star\", \"x\")\nx = 1\n"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(dir, "check.star")
+	if err := os.WriteFile(path, []byte(`
+load("lib/checks.star", "paginated")
+load("lib/limits.star", limit = "MAX")
+return paginated(body), "limit is %d" % limit
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	program, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := program.Run(map[string]interface{}{"body": map[string]interface{}{"items": []interface{}{1, 2, 3}}}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Passed || result.Message != "limit is 2" {
+		t.Errorf("Expected a failed result with the limit, got %+v", result)
+	}
+
+	if _, err := Parse("inline", `load("lib/checks.star", "_private")`, dir); err == nil || !strings.Contains(err.Error(), "not exported: _private") {
+		t.Errorf("Expected private names not to be loadable, got %v", err)
+	}
+
+	for source, want := range map[string]string{
+		`load("lib/checks.star", "missing")`: "load: name missing not found in module lib/checks.star",
+		`load("lib/cycle.star", "x")`:        "cycle in load graph",
+		`load("lib/nothing.star", "x")`:      "failed to read script",
+	} {
+		program, err := Parse("inline", source, dir)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := program.Run(nil, nil); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error containing %q, got %v", source, want, err)
+		}
+	}
+}
+
+func TestPrint(t *testing.T) {
+	program, err := Parse("inline", `print("status", 200, sep = "=")`, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var lines []string
+	if _, err := program.Run(nil, func(line string) { lines = append(lines, line) }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(lines) != 1 || lines[0] != "status=200" {
+		t.Errorf("Expected one printed line, got %q", lines)
+	}
+}
@@ -0,0 +1,116 @@
+package script
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// Struct is a record whose fields are read as attributes, e.g. response.status
+type Struct struct {
+	name   string
+	fields map[string]interface{}
+}
+
+// NewStruct returns a struct with fields converted by ToValue
+func NewStruct(name string, fields map[string]interface{}) *Struct {
+	return &Struct{name: name, fields: fields}
+}
+
+// ToValue converts decoded JSON or YAML data and Go scalars to Starlark values. Whole
+// numbers become ints, so that ids read from JSON print as 1 rather than 1.0.
+func ToValue(v interface{}) starlark.Value {
+	switch x := v.(type) {
+	case nil:
+		return starlark.None
+	case starlark.Value:
+		return x
+	case bool:
+		return starlark.Bool(x)
+	case string:
+		return starlark.String(x)
+	case int:
+		return starlark.MakeInt(x)
+	case int64:
+		return starlark.MakeInt64(x)
+	case float64:
+		if x == math.Trunc(x) && math.Abs(x) < 1<<53 {
+			return starlark.MakeInt64(int64(x))
+		}
+		return starlark.Float(x)
+	case float32:
+		return ToValue(float64(x))
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return starlark.MakeInt64(i)
+		}
+		f, _ := x.Float64()
+		return starlark.Float(f)
+	case []interface{}:
+		elems := make([]starlark.Value, len(x))
+		for i, elem := range x {
+			elems[i] = ToValue(elem)
+		}
+		return starlark.NewList(elems)
+	case map[string]interface{}:
+		d := starlark.NewDict(len(x))
+		for _, key := range sortedKeys(x) {
+			d.SetKey(starlark.String(key), ToValue(x[key]))
+		}
+		return d
+	case *Struct:
+		fields := make(starlark.StringDict, len(x.fields))
+		for name, value := range x.fields {
+			fields[name] = ToValue(value)
+		}
+		return starlarkstruct.FromStringDict(starlark.String(x.name), fields)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return starlark.MakeInt64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return starlark.MakeUint64(rv.Uint())
+	case reflect.Slice, reflect.Array:
+		elems := make([]starlark.Value, rv.Len())
+		for i := range elems {
+			elems[i] = ToValue(rv.Index(i).Interface())
+		}
+		return starlark.NewList(elems)
+	case reflect.Map:
+		m := make(map[string]interface{}, rv.Len())
+		for _, key := range rv.MapKeys() {
+			m[fmt.Sprint(key.Interface())] = rv.MapIndex(key).Interface()
+		}
+		return ToValue(m)
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return starlark.None
+		}
+		return ToValue(rv.Elem().Interface())
+	}
+	return starlark.String(fmt.Sprint(v))
+}
+
+// str returns the text of a string, or the representation of another value
+func str(v starlark.Value) string {
+	if s, ok := starlark.AsString(v); ok {
+		return s
+	}
+	return v.String()
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// namePlaceholder is replaced by the secret name in the arguments of a command provider
+const namePlaceholder = "{name}"
+
+// commandTimeout limits how long a command provider may take for one secret
+const commandTimeout = 30 * time.Second
+
+// CommandProvider runs an external command, such as a password manager or vault CLI,
+// and uses its output as the value of the secret. The command is split on whitespace
+// and run without a shell; {name} in an argument is replaced by the secret name, and
+// without a placeholder the name is appended as the last argument.
+type CommandProvider struct {
+	command string
+	args    []string
+}
+
+// NewCommandProvider creates a provider for a command line, e.g. "pass show stepwise/{name}"
+func NewCommandProvider(commandLine string) (*CommandProvider, error) {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("secrets command is empty")
+	}
+	return &CommandProvider{command: fields[0], args: fields[1:]}, nil
+}
+
+// Name returns a short description of the provider
+func (p *CommandProvider) Name() string {
+	return "command " + p.command
+}
+
+// Lookup runs the command for a secret. Trailing newlines of the output are removed.
+// A command that exits with a non-zero status is reported as an error, not as a missing secret.
+func (p *CommandProvider) Lookup(name string) (string, bool, error) {
+	if !validName(name) {
+		return "", false, nil
+	}
+
+	args := make([]string, 0, len(p.args)+1)
+	placeholder := false
+	for _, arg := range p.args {
+		if strings.Contains(arg, namePlaceholder) {
+			placeholder = true
+			arg = strings.ReplaceAll(arg, namePlaceholder, name)
+		}
+		args = append(args, arg)
+	}
+	if !placeholder {
+		args = append(args, name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.command, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", false, fmt.Errorf("failed to get secret %s: %w: %s", name, err, message)
+		}
+		return "", false, fmt.Errorf("failed to get secret %s: %w", name, err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), true, nil
+}
@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileProvider reads secrets from a YAML or JSON file with a flat map of values,
+// or from a directory with one file per secret, as mounted by Docker and Kubernetes
+type FileProvider struct {
+	path   string
+	dir    bool
+	values map[string]string
+}
+
+// NewFileProvider creates a provider for a secrets file or directory
+func NewFileProvider(path string) (*FileProvider, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open secrets file: %w", err)
+	}
+	if info.IsDir() {
+		return &FileProvider{path: path, dir: true}, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+	values := make(map[string]string)
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file %s: %w", path, err)
+	}
+	return &FileProvider{path: path, values: values}, nil
+}
+
+// Name returns a short description of the provider
+func (p *FileProvider) Name() string {
+	return "file " + p.path
+}
+
+// Lookup returns the value of a secret. In a directory, trailing newlines of the file are removed.
+func (p *FileProvider) Lookup(name string) (string, bool, error) {
+	if !p.dir {
+		value, found := p.values[name]
+		return value, found, nil
+	}
+
+	if !validName(name) {
+		return "", false, nil
+	}
+	content, err := os.ReadFile(filepath.Join(p.path, name))
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), true, nil
+}
@@ -0,0 +1,243 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// PassphraseEnv is the environment variable holding the keystore passphrase
+const PassphraseEnv = "STEPWISE_KEYSTORE_PASSPHRASE"
+
+// keystoreVersion is the version of the keystore file format
+const keystoreVersion = 1
+
+// keystoreCheck is encrypted into every keystore to detect a wrong passphrase
+const keystoreCheck = "stepwise-keystore"
+
+// Default scrypt parameters, as recommended for interactive use
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// maxScryptN bounds the cost parameter read from a keystore file, so that a tampered
+// file cannot make opening it take gigabytes of memory
+const maxScryptN = 1 << 20
+
+// keystoreFile is the on-disk form of a keystore. Secret names are kept in clear
+// text so the file can be reviewed and diffed; each value is encrypted separately
+// with the name as additional data, so values cannot be swapped between names.
+type keystoreFile struct {
+	Version int               `yaml:"version"`
+	KDF     keystoreKDF       `yaml:"kdf"`
+	Check   string            `yaml:"check"`
+	Secrets map[string]string `yaml:"secrets"`
+}
+
+type keystoreKDF struct {
+	Name string `yaml:"name"`
+	Salt string `yaml:"salt"`
+	N    int    `yaml:"n"`
+	R    int    `yaml:"r"`
+	P    int    `yaml:"p"`
+}
+
+// validate checks that the scrypt parameters are ones a keystore is written with:
+// N is a power of two up to maxScryptN, and R and P are at most the defaults
+func (k keystoreKDF) validate() error {
+	if k.N < 2 || k.N > maxScryptN || k.N&(k.N-1) != 0 {
+		return fmt.Errorf("n must be a power of two between 2 and %d, got %d", maxScryptN, k.N)
+	}
+	if k.R < 1 || k.R > scryptR {
+		return fmt.Errorf("r must be between 1 and %d, got %d", scryptR, k.R)
+	}
+	if k.P < 1 || k.P > scryptP {
+		return fmt.Errorf("p must be between 1 and %d, got %d", scryptP, k.P)
+	}
+	return nil
+}
+
+// Keystore is a local file of secrets encrypted with a key derived from a passphrase
+type Keystore struct {
+	path string
+	file keystoreFile
+	key  []byte
+}
+
+// OpenKeystore opens the keystore at path, or creates an empty one if the file does not exist.
+// A new keystore is only written by Save.
+func OpenKeystore(path, passphrase string) (*Keystore, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("keystore passphrase is empty, set %s", PassphraseEnv)
+	}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return newKeystore(path, passphrase)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+
+	ks := &Keystore{path: path}
+	if err := yaml.Unmarshal(content, &ks.file); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore %s: %w", path, err)
+	}
+	if ks.file.Version != keystoreVersion || ks.file.KDF.Name != "scrypt" {
+		return nil, fmt.Errorf("unsupported keystore %s: version %d, kdf %q", path, ks.file.Version, ks.file.KDF.Name)
+	}
+	if err := ks.file.KDF.validate(); err != nil {
+		return nil, fmt.Errorf("invalid keystore %s: %w", path, err)
+	}
+	salt, err := base64.StdEncoding.DecodeString(ks.file.KDF.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore salt: %w", err)
+	}
+	ks.key, err = scrypt.Key([]byte(passphrase), salt, ks.file.KDF.N, ks.file.KDF.R, ks.file.KDF.P, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive keystore key: %w", err)
+	}
+
+	if check, err := ks.decrypt("", ks.file.Check); err != nil || check != keystoreCheck {
+		return nil, fmt.Errorf("failed to open keystore %s: wrong passphrase", path)
+	}
+	if ks.file.Secrets == nil {
+		ks.file.Secrets = make(map[string]string)
+	}
+	return ks, nil
+}
+
+// newKeystore creates an empty keystore with a random salt
+func newKeystore(path, passphrase string) (*Keystore, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive keystore key: %w", err)
+	}
+
+	ks := &Keystore{
+		path: path,
+		key:  key,
+		file: keystoreFile{
+			Version: keystoreVersion,
+			KDF:     keystoreKDF{Name: "scrypt", Salt: base64.StdEncoding.EncodeToString(salt), N: scryptN, R: scryptR, P: scryptP},
+			Secrets: make(map[string]string),
+		},
+	}
+	if ks.file.Check, err = ks.encrypt("", keystoreCheck); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Name returns a short description of the provider
+func (ks *Keystore) Name() string {
+	return "keystore " + ks.path
+}
+
+// Lookup decrypts a secret
+func (ks *Keystore) Lookup(name string) (string, bool, error) {
+	encrypted, found := ks.file.Secrets[name]
+	if !found {
+		return "", false, nil
+	}
+	value, err := ks.decrypt(name, encrypted)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to decrypt secret %s: %w", name, err)
+	}
+	return value, true, nil
+}
+
+// Set encrypts and stores a secret. The keystore file is written by Save.
+func (ks *Keystore) Set(name, value string) error {
+	if !validName(name) {
+		return fmt.Errorf("invalid secret name %q", name)
+	}
+	encrypted, err := ks.encrypt(name, value)
+	if err != nil {
+		return err
+	}
+	ks.file.Secrets[name] = encrypted
+	return nil
+}
+
+// Delete removes a secret and reports whether it existed. The keystore file is written by Save.
+func (ks *Keystore) Delete(name string) bool {
+	_, found := ks.file.Secrets[name]
+	delete(ks.file.Secrets, name)
+	return found
+}
+
+// Names returns the names of the secrets, sorted
+func (ks *Keystore) Names() []string {
+	names := make([]string, 0, len(ks.file.Secrets))
+	for name := range ks.file.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save writes the keystore file, readable by the owner only
+func (ks *Keystore) Save() error {
+	content, err := yaml.Marshal(&ks.file)
+	if err != nil {
+		return fmt.Errorf("failed to encode keystore: %w", err)
+	}
+	if dir := filepath.Dir(ks.path); dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(ks.path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	return nil
+}
+
+// encrypt seals value with a random nonce and returns base64(nonce || ciphertext)
+func (ks *Keystore) encrypt(name, value string) (string, error) {
+	aead, err := chacha20poly1305.NewX(ks.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt opens a value sealed by encrypt
+func (ks *Keystore) decrypt(name, encrypted string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("invalid encoding: %w", err)
+	}
+	aead, err := chacha20poly1305.NewX(ks.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("value is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("authentication failed")
+	}
+	return string(plain), nil
+}
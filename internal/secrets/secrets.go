@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned when no provider knows a secret
+var ErrNotFound = errors.New("secret not found")
+
+// Provider defines the interface for secret providers
+type Provider interface {
+	// Name returns a short description of the provider, used in errors
+	Name() string
+
+	// Lookup returns the value of a secret and whether the provider knows it
+	Lookup(name string) (string, bool, error)
+}
+
+// Store resolves secrets from a list of providers, asking them in order.
+// Resolved values are cached, so a command provider runs once per secret.
+// It is safe for concurrent use.
+type Store struct {
+	mu        sync.Mutex
+	providers []Provider
+	cache     map[string]string
+}
+
+// NewStore creates a store that asks the providers in the given order
+func NewStore(providers ...Provider) *Store {
+	return &Store{
+		providers: providers,
+		cache:     make(map[string]string),
+	}
+}
+
+// Get returns the value of a secret from the first provider that knows it
+func (s *Store) Get(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if value, exists := s.cache[name]; exists {
+		return value, nil
+	}
+	for _, provider := range s.providers {
+		value, found, err := provider.Lookup(name)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", provider.Name(), err)
+		}
+		if found {
+			s.cache[name] = value
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// validName reports whether name can be used as a secret name. Names that start with
+// "-" are rejected, as the command provider passes them as arguments.
+func validName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "/\\ \t\n") && name != "." && name != ".." && !strings.HasPrefix(name, "-")
+}
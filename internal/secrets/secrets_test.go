@@ -0,0 +1,206 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secrets.yml")
+	if err := os.WriteFile(path, []byte("api_token: abc123\npin: 1234\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := NewFileProvider(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value, found, _ := provider.Lookup("api_token"); !found || value != "abc123" {
+		t.Errorf("Expected api_token = abc123, got %q (found %v)", value, found)
+	}
+	if value, _, _ := provider.Lookup("pin"); value != "1234" {
+		t.Errorf("Expected numbers to be read as strings, got %q", value)
+	}
+	if _, found, _ := provider.Lookup("missing"); found {
+		t.Error("Expected missing secret not to be found")
+	}
+}
+
+func TestFileProviderDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "db_password"), []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := NewFileProvider(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value, found, _ := provider.Lookup("db_password"); !found || value != "s3cret" {
+		t.Errorf("Expected db_password = s3cret, got %q (found %v)", value, found)
+	}
+	if _, found, _ := provider.Lookup("../db_password"); found {
+		t.Error("Expected names with path separators to be rejected")
+	}
+}
+
+func TestCommandProvider(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo is not available")
+	}
+
+	provider, err := NewCommandProvider("echo secret-{name}")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value, found, err := provider.Lookup("token"); err != nil || !found || value != "secret-token" {
+		t.Errorf("Expected secret-token, got %q (found %v, error %v)", value, found, err)
+	}
+
+	appended, _ := NewCommandProvider("echo value")
+	if value, _, _ := appended.Lookup("token"); value != "value token" {
+		t.Errorf("Expected the name to be appended, got %q", value)
+	}
+
+	if _, found, err := appended.Lookup("--help"); found || err != nil {
+		t.Errorf("Expected a name that starts with - not to be looked up, got found %v, error %v", found, err)
+	}
+
+	failing, _ := NewCommandProvider("false")
+	if _, _, err := failing.Lookup("token"); err == nil {
+		t.Error("Expected an error for a failing command")
+	}
+
+	if _, err := NewCommandProvider("  "); err == nil {
+		t.Error("Expected an error for an empty command")
+	}
+}
+
+func TestKeystore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".stepwise", "keystore.yml")
+
+	ks, err := OpenKeystore(path, "correct horse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := ks.Set("api_token", "abc123"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"bad/name", "-o"} {
+		if err := ks.Set(name, "x"); err == nil {
+			t.Errorf("Expected an error for the invalid name %q", name)
+		}
+	}
+	if err := ks.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(path)
+	if strings.Contains(string(content), "abc123") || !strings.Contains(string(content), "api_token") {
+		t.Errorf("Expected the name in clear text and the value encrypted, got:\n%s", content)
+	}
+
+	reopened, err := OpenKeystore(path, "correct horse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value, found, err := reopened.Lookup("api_token"); err != nil || !found || value != "abc123" {
+		t.Errorf("Expected api_token = abc123, got %q (found %v, error %v)", value, found, err)
+	}
+	if names := reopened.Names(); len(names) != 1 || names[0] != "api_token" {
+		t.Errorf("Unexpected names: %v", names)
+	}
+
+	if _, err := OpenKeystore(path, "wrong"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("Expected wrong passphrase error, got %v", err)
+	}
+	if _, err := OpenKeystore(path, ""); err == nil {
+		t.Error("Expected an error for an empty passphrase")
+	}
+}
+
+func TestKeystoreRejectsCostlyParameters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.yml")
+	ks, err := OpenKeystore(path, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Save(); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(path)
+
+	tests := []struct{ original, tampered string }{
+		{`"n": 32768`, `"n": 1073741824`},
+		{`"n": 32768`, `"n": 30000`},
+		{"r: 8", "r: 1024"},
+		{"p: 1", "p: 64"},
+		{"p: 1", "p: 0"},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(string(content), tt.original) {
+			t.Fatalf("Expected %q in the keystore, got:\n%s", tt.original, content)
+		}
+		if err := os.WriteFile(path, []byte(strings.Replace(string(content), tt.original, tt.tampered, 1)), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := OpenKeystore(path, "passphrase"); err == nil || !strings.Contains(err.Error(), "invalid keystore") {
+			t.Errorf("Expected an invalid keystore error for %q, got %v", tt.tampered, err)
+		}
+	}
+}
+
+func TestKeystoreValuesAreBoundToNames(t *testing.T) {
+	ks, err := OpenKeystore(filepath.Join(t.TempDir(), "keystore.yml"), "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks.Set("a", "value-a")
+	ks.Set("b", "value-b")
+
+	// Moving an encrypted value to another name must not decrypt
+	ks.file.Secrets["b"] = ks.file.Secrets["a"]
+	if _, _, err := ks.Lookup("b"); err == nil {
+		t.Error("Expected a swapped value to fail to decrypt")
+	}
+}
+
+// staticProvider is a provider backed by a map that counts lookups
+type staticProvider struct {
+	values  map[string]string
+	lookups int
+}
+
+func (p *staticProvider) Name() string { return "static" }
+
+func (p *staticProvider) Lookup(name string) (string, bool, error) {
+	p.lookups++
+	value, found := p.values[name]
+	return value, found, nil
+}
+
+func TestStore(t *testing.T) {
+	first := &staticProvider{values: map[string]string{"token": "first"}}
+	second := &staticProvider{values: map[string]string{"token": "second", "password": "pw"}}
+	store := NewStore(first, second)
+
+	if value, _ := store.Get("token"); value != "first" {
+		t.Errorf("Expected the first provider to win, got %q", value)
+	}
+	if value, _ := store.Get("password"); value != "pw" {
+		t.Errorf("Expected fallback to the second provider, got %q", value)
+	}
+	store.Get("password")
+	if second.lookups != 1 {
+		t.Errorf("Expected resolved secrets to be cached, got %d lookups", second.lookups)
+	}
+
+	if _, err := store.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/cjp2600/stepwise/internal/filters"
+)
+
+func init() {
+	registerStringFilter("upper", strings.ToUpper)
+	registerStringFilter("lower", strings.ToLower)
+	registerStringFilter("trim", strings.TrimSpace)
+	registerStringFilter("base64", Base64Encode)
+	registerStringFilter("sha256", SHA256)
+	registerStringFilter("md5", MD5)
+	registerStringFilter("hex", Hex)
+	registerStringFilter("url_encode", URLEncode)
+
+	filters.Register("base64_decode", func(value interface{}, args []interface{}) (interface{}, error) {
+		if err := filters.Args(args, 0, 0); err != nil {
+			return nil, err
+		}
+		return Base64Decode(filters.String(value))
+	})
+	filters.Register("hmac_sha256", func(value interface{}, args []interface{}) (interface{}, error) {
+		if err := filters.Args(args, 1, 1); err != nil {
+			return nil, err
+		}
+		return HMACSHA256(filters.String(args[0]), filters.String(value)), nil
+	})
+	filters.Register("jwt_decode", func(value interface{}, args []interface{}) (interface{}, error) {
+		if err := filters.Args(args, 0, 0); err != nil {
+			return nil, err
+		}
+		claims, err := JWTDecode(filters.String(value))
+		if err != nil {
+			return nil, err
+		}
+		return claims, nil
+	})
+}
+
+// registerStringFilter registers a function of one string as a filter without arguments
+func registerStringFilter(name string, fn func(string) string) {
+	filters.Register(name, func(value interface{}, args []interface{}) (interface{}, error) {
+		if err := filters.Args(args, 0, 0); err != nil {
+			return nil, err
+		}
+		return fn(filters.String(value)), nil
+	})
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// JWTSign creates a signed JWT with the given claims. HS256, HS384 and HS512 sign with
+// the key as a shared secret; RS256 signs with a PEM-encoded RSA private key.
+func JWTSign(alg, key string, claims map[string]interface{}) (string, error) {
+	alg = strings.ToUpper(alg)
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claims: %w", err)
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch alg {
+	case "HS256":
+		signature = jwtHMAC(sha256.New, key, signingInput)
+	case "HS384":
+		signature = jwtHMAC(sha512.New384, key, signingInput)
+	case "HS512":
+		signature = jwtHMAC(sha512.New, key, signingInput)
+	case "RS256":
+		privateKey, err := parseRSAPrivateKey(key)
+		if err != nil {
+			return "", err
+		}
+		digest := sha256.Sum256([]byte(signingInput))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+		if err != nil {
+			return "", fmt.Errorf("failed to sign token: %w", err)
+		}
+	default:
+		return "", fmt.Errorf("unsupported JWT algorithm %q (supported: HS256, HS384, HS512, RS256)", alg)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// JWTDecode returns the claims of a JWT without verifying its signature
+func JWTDecode(token string) (map[string]interface{}, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid JWT: expected 3 parts, got %d", len(parts))
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT payload: %w", err)
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+	return claims, nil
+}
+
+func jwtHMAC(h func() hash.Hash, key, input string) []byte {
+	mac := hmac.New(h, []byte(key))
+	mac.Write([]byte(input))
+	return mac.Sum(nil)
+}
+
+// parseRSAPrivateKey parses a PKCS#1 or PKCS#8 PEM-encoded RSA private key
+func parseRSAPrivateKey(key string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, fmt.Errorf("invalid RSA key: no PEM block found")
+	}
+	if privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return privateKey, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RSA key: %w", err)
+	}
+	privateKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid RSA key: not an RSA private key")
+	}
+	return privateKey, nil
+}
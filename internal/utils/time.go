@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeFormats are the named formats accepted by FormatTime
+var timeFormats = map[string]string{
+	"":            time.RFC3339,
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"rfc1123":     time.RFC1123,
+	"date":        "2006-01-02",
+	"datetime":    "2006-01-02 15:04:05",
+	"time":        "15:04:05",
+	"iso8601":     "2006-01-02T15:04:05.000Z07:00",
+	"http":        "Mon, 02 Jan 2006 15:04:05 GMT",
+}
+
+// randomAlphabet is the alphabet of RandomString
+const randomAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// FormatTime formats a time by name (rfc3339, date, unix, unix_ms, ...) or by Go
+// layout, e.g. "2006-01-02". An empty format is RFC 3339.
+func FormatTime(t time.Time, format string) string {
+	switch strings.ToLower(format) {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unix_ms":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case "http":
+		return t.UTC().Format(timeFormats["http"])
+	}
+	if layout, ok := timeFormats[strings.ToLower(format)]; ok {
+		return t.Format(layout)
+	}
+	return t.Format(format)
+}
+
+// ParseDuration parses a Go duration such as "1h30m", with days allowed as "7d" or "-1d"
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, found := strings.CutSuffix(s, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// UUIDv7 returns a time-ordered UUID as defined by RFC 9562
+func UUIDv7() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", err
+	}
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(b[:6], ms[2:])
+	b[6] = 0x70 | (b[6] & 0x0f) // version 7
+	b[8] = 0x80 | (b[8] & 0x3f) // RFC 9562 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// RandomString returns a random alphanumeric string of length n
+func RandomString(n int) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("invalid length %d", n)
+	}
+	result := make([]byte, n)
+	max := big.NewInt(int64(len(randomAlphabet)))
+	for i := range result {
+		index, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		result[i] = randomAlphabet[index.Int64()]
+	}
+	return string(result), nil
+}
@@ -1,8 +1,14 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net/url"
 )
 
 // Base64Encode encodes a string to base64
@@ -18,3 +24,38 @@ func Base64Decode(input string) (string, error) {
 	}
 	return string(decoded), nil
 }
+
+// SHA256 returns the hex-encoded SHA-256 digest of a string
+func SHA256(input string) string {
+	sum := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(sum[:])
+}
+
+// MD5 returns the hex-encoded MD5 digest of a string
+func MD5(input string) string {
+	sum := md5.Sum([]byte(input))
+	return hex.EncodeToString(sum[:])
+}
+
+// HMACSHA256 returns the hex-encoded HMAC-SHA256 of a message
+func HMACSHA256(key, message string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Hex encodes the bytes of a string as hex
+func Hex(input string) string {
+	return hex.EncodeToString([]byte(input))
+}
+
+// URLEncode escapes a string for use in a URL query
+func URLEncode(input string) string {
+	return url.QueryEscape(input)
+}
+
+// JSONEncode encodes a string as a quoted JSON string
+func JSONEncode(input string) string {
+	data, _ := json.Marshal(input)
+	return string(data)
+}
@@ -0,0 +1,114 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cjp2600/stepwise/internal/http"
+)
+
+// validateAnyOf passes when at least one of the rules passes
+func (v *Validator) validateAnyOf(response *http.Response, rules []ValidationRule) ValidationResult {
+	result := ValidationResult{Type: "any_of", Expected: fmt.Sprintf("any of %d rules", len(rules))}
+	var errors []string
+	passed := 0
+	for _, rule := range rules {
+		nested := v.validateNested(response, rule)
+		result.Results = append(result.Results, nested)
+		if nested.Passed {
+			passed++
+		} else {
+			errors = append(errors, nested.Error)
+		}
+	}
+	result.Actual = fmt.Sprintf("%d passed", passed)
+	result.Passed = passed > 0
+	if !result.Passed {
+		result.Error = "any_of validation failed: no rule passed: " + strings.Join(errors, "; ")
+	}
+	return result
+}
+
+// validateAllOf passes when all of the rules pass
+func (v *Validator) validateAllOf(response *http.Response, rules []ValidationRule) ValidationResult {
+	result := ValidationResult{Type: "all_of", Expected: fmt.Sprintf("all of %d rules", len(rules))}
+	var errors []string
+	for _, rule := range rules {
+		nested := v.validateNested(response, rule)
+		result.Results = append(result.Results, nested)
+		if !nested.Passed {
+			errors = append(errors, nested.Error)
+		}
+	}
+	result.Actual = fmt.Sprintf("%d passed", len(rules)-len(errors))
+	result.Passed = len(errors) == 0
+	if !result.Passed {
+		result.Error = fmt.Sprintf("all_of validation failed: %d of %d rules failed: %s", len(errors), len(rules), strings.Join(errors, "; "))
+	}
+	return result
+}
+
+// validateNested validates a rule held by another rule
+func (v *Validator) validateNested(response *http.Response, rule ValidationRule) ValidationResult {
+	if result, ok := nestedSeverity(rule); ok {
+		return result
+	}
+	return v.validateRule(response, rule)
+}
+
+// nestedSeverity returns an invalid rule result for a nested rule with a severity.
+// Only the outer rule decides whether a failure fails the step.
+func nestedSeverity(rule ValidationRule) (ValidationResult, bool) {
+	if rule.Severity == "" {
+		return ValidationResult{}, false
+	}
+	return ValidationResult{
+		Type:     "severity",
+		Expected: "no severity",
+		Actual:   "invalid rule",
+		Error:    fmt.Sprintf("severity %q of a nested rule: only the outer rule can have a severity", rule.Severity),
+	}, true
+}
+
+// ruleErrors are the Actual values of results of rules that could not be evaluated
+var ruleErrors = map[string]bool{
+	"invalid rule":           true,
+	"no matching rule found": true,
+	"substitution failed":    true,
+	"extraction failed":      true,
+	"invalid JSON":           true,
+	"invalid schema":         true,
+	"invalid script":         true,
+	"invalid args":           true,
+	"script error":           true,
+	"invalid snapshot":       true,
+	"unreadable snapshot":    true,
+	"write failed":           true,
+}
+
+// evaluated reports whether a rule was evaluated, rather than failing with an error of
+// the rule itself
+func evaluated(result ValidationResult) bool {
+	actual, ok := result.Actual.(string)
+	return !ok || !ruleErrors[actual]
+}
+
+// validateNot passes when the rule fails. A rule that could not be evaluated fails
+// the not rule with its own error.
+func (v *Validator) validateNot(response *http.Response, rule ValidationRule) ValidationResult {
+	nested := v.validateNested(response, rule)
+	result := ValidationResult{
+		Type:     "not",
+		Expected: "not " + nested.Type,
+		Actual:   nested.Actual,
+		Passed:   !nested.Passed,
+		Results:  []ValidationResult{nested},
+	}
+	if !evaluated(nested) {
+		result.Passed = false
+		result.Error = "not validation failed: " + nested.Error
+	} else if !result.Passed {
+		result.Error = fmt.Sprintf("not validation failed: %s rule passed, expected %v, got %v", nested.Type, nested.Expected, nested.Actual)
+	}
+	return result
+}
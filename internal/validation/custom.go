@@ -0,0 +1,124 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cjp2600/stepwise/internal/http"
+	"github.com/cjp2600/stepwise/internal/script"
+)
+
+// validateCustom runs a script assertion, written inline or in a script file
+func (v *Validator) validateCustom(response *http.Response, rule ValidationRule) ValidationResult {
+	expected := rule.CustomFile
+	if expected == "" {
+		expected = "inline script"
+	}
+	result := ValidationResult{Type: "custom", Expected: expected}
+
+	program, err := v.compileScript(rule)
+	if err != nil {
+		result.Actual = "invalid script"
+		result.Error = fmt.Sprintf("invalid script: %v", err)
+		return result
+	}
+
+	args, err := v.substituteArgs(rule.Args)
+	if err != nil {
+		result.Actual = "invalid args"
+		result.Error = fmt.Sprintf("failed to substitute args: %v", err)
+		return result
+	}
+
+	outcome, err := program.Run(map[string]interface{}{
+		"response": scriptResponse(response),
+		"duration": response.Duration.Milliseconds(),
+		"vars":     v.varManager.GetAll(),
+		"args":     args,
+	}, func(line string) {
+		v.logger.Info("Script output", "script", expected, "output", line)
+	})
+	if err != nil {
+		result.Actual = "script error"
+		result.Error = fmt.Sprintf("script error: %v", err)
+		return result
+	}
+
+	result.Passed = outcome.Passed
+	switch {
+	case outcome.Message != "":
+		result.Actual = outcome.Message
+	case outcome.Passed:
+		result.Actual = "passed"
+	default:
+		result.Actual = "failed"
+	}
+	if !result.Passed {
+		result.Error = fmt.Sprintf("custom validation failed: %v", result.Actual)
+	}
+	return result
+}
+
+// compileScript parses the inline script of a rule, or its script file
+func (v *Validator) compileScript(rule ValidationRule) (*script.Program, error) {
+	if rule.Custom != "" && rule.CustomFile != "" {
+		return nil, fmt.Errorf("custom and custom_file cannot be used together")
+	}
+	if rule.CustomFile != "" {
+		return script.ParseFile(rule.CustomFile)
+	}
+	return script.Parse("custom", rule.Custom, rule.ScriptDir)
+}
+
+// substituteArgs substitutes variables in the string values of script arguments
+func (v *Validator) substituteArgs(args map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		substituted, err := v.substituteArg(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		result[name] = substituted
+	}
+	return result, nil
+}
+
+func (v *Validator) substituteArg(value interface{}) (interface{}, error) {
+	switch x := value.(type) {
+	case string:
+		return v.varManager.SubstituteValue(x)
+	case []interface{}:
+		list := make([]interface{}, len(x))
+		for i, elem := range x {
+			substituted, err := v.substituteArg(elem)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = substituted
+		}
+		return list, nil
+	case map[string]interface{}:
+		return v.substituteArgs(x)
+	}
+	return value, nil
+}
+
+// scriptResponse returns the response as scripts see it. Header names are lowercase,
+// and the body is parsed JSON, or text when it is not JSON.
+func scriptResponse(response *http.Response) *script.Struct {
+	headers := make(map[string]interface{}, len(response.Headers))
+	for name, values := range response.Headers {
+		headers[strings.ToLower(name)] = strings.Join(values, ", ")
+	}
+	var body interface{} = response.GetTextBody()
+	if parsed, err := response.GetJSONBody(); err == nil {
+		body = parsed
+	}
+	return script.NewStruct("response", map[string]interface{}{
+		"status":   response.StatusCode,
+		"headers":  headers,
+		"body":     body,
+		"text":     response.GetTextBody(),
+		"duration": response.Duration.Milliseconds(),
+	})
+}
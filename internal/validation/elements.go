@@ -0,0 +1,115 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cjp2600/stepwise/internal/http"
+)
+
+// validateElements applies rules to the elements of the array at path. An each rule
+// passes when every element passes all the rules, and a some rule when at least one
+// does. Within the rules, json paths are relative to the element.
+func (v *Validator) validateElements(response *http.Response, path string, rules []ValidationRule, ruleType string) ValidationResult {
+	if path == "" {
+		path = "$"
+	}
+	result := ValidationResult{Type: ruleType, Expected: path}
+	for _, rule := range rules {
+		if nested, ok := nestedSeverity(rule); ok {
+			result.Actual = nested.Actual
+			result.Results = []ValidationResult{nested}
+			result.Error = fmt.Sprintf("%s validation failed: %s", ruleType, nested.Error)
+			return result
+		}
+	}
+
+	body, err := response.GetJSONBody()
+	if err != nil {
+		result.Actual = "invalid JSON"
+		result.Error = fmt.Sprintf("failed to parse JSON: %v", err)
+		return result
+	}
+	value, err := v.extractJSONValue(body, path)
+	if err != nil {
+		result.Actual = "extraction failed"
+		result.Error = fmt.Sprintf("failed to extract value: %v", err)
+		return result
+	}
+	elements, ok := value.([]interface{})
+	if !ok {
+		result.Actual = value
+		result.Error = fmt.Sprintf("%s validation failed: expected an array at %s, got %v", ruleType, path, value)
+		return result
+	}
+
+	matched := 0
+	firstFailure := ""
+	for i, element := range elements {
+		results, err := v.validateElement(response, element, rules)
+		if err != nil {
+			result.Actual = element
+			result.Error = fmt.Sprintf("%s validation failed: element %d: %v", ruleType, i, err)
+			return result
+		}
+		var errors []string
+		for _, nested := range results {
+			if !nested.Passed {
+				errors = append(errors, nested.Error)
+			}
+		}
+		if len(errors) == 0 {
+			matched++
+			if ruleType == "some" {
+				result.Actual = fmt.Sprintf("element %d: %s", i, compact(element))
+				result.Passed = true
+				return result
+			}
+			continue
+		}
+		if firstFailure == "" {
+			firstFailure = fmt.Sprintf("element %d (%s): %s", i, compact(element), strings.Join(errors, "; "))
+			if ruleType == "each" {
+				result.Actual = fmt.Sprintf("element %d: %s", i, compact(element))
+				result.Results = results
+				result.Error = "each validation failed: " + firstFailure
+				return result
+			}
+		}
+	}
+
+	if ruleType == "each" {
+		result.Actual = fmt.Sprintf("%d elements passed", matched)
+		result.Passed = true
+		return result
+	}
+	result.Actual = fmt.Sprintf("none of %d elements", len(elements))
+	if firstFailure == "" {
+		result.Error = "some validation failed: the array is empty"
+	} else {
+		result.Error = fmt.Sprintf("some validation failed: none of %d elements passed, first: %s", len(elements), firstFailure)
+	}
+	return result
+}
+
+// validateElement applies rules to an array element, as the body of a response with
+// the status and headers of the original one
+func (v *Validator) validateElement(response *http.Response, element interface{}, rules []ValidationRule) ([]ValidationResult, error) {
+	body, err := json.Marshal(element)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode element: %w", err)
+	}
+	elementResponse := *response
+	elementResponse.Body = body
+	return v.Validate(&elementResponse, rules)
+}
+
+// compact formats an element for validation messages
+func compact(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return excerpt(string(data))
+}
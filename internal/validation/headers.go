@@ -0,0 +1,232 @@
+package validation
+
+import (
+	"fmt"
+	"mime"
+	nethttp "net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/cjp2600/stepwise/internal/http"
+)
+
+// headerValues returns the values of a header, whatever the case of its name
+func headerValues(headers map[string][]string, name string) []string {
+	var values []string
+	for key, vals := range headers {
+		if strings.EqualFold(key, name) {
+			values = append(values, vals...)
+		}
+	}
+	return values
+}
+
+// validateHeader applies the comparators of a rule to a response header. A header
+// sent several times gives a list of its values.
+func (v *Validator) validateHeader(response *http.Response, rule ValidationRule) ValidationResult {
+	name, err := v.varManager.Substitute(rule.Header)
+	if err != nil {
+		return ValidationResult{
+			Type:     "header",
+			Expected: rule.Header,
+			Actual:   "substitution failed",
+			Error:    fmt.Sprintf("failed to substitute variables in header name: %v", err),
+		}
+	}
+
+	var value interface{}
+	switch values := headerValues(response.Headers, name); len(values) {
+	case 0:
+		if rule.Nil == nil && rule.Empty == nil {
+			return missing("header", name)
+		}
+	case 1:
+		// Header values are text, so type: number and type: boolean accept text in those formats
+		value = typedText(values[0], rule.Type)
+	default:
+		list := make([]interface{}, len(values))
+		for i, value := range values {
+			list[i] = value
+		}
+		value = list
+	}
+	return v.validateValue(value, rule, "header", name)
+}
+
+// missing is the result of a rule on a header or cookie the response does not have.
+// Only nil and empty rules accept it.
+func missing(ruleType, name string) ValidationResult {
+	return ValidationResult{
+		Type:     ruleType,
+		Expected: name,
+		Actual:   nil,
+		Passed:   false,
+		Error:    fmt.Sprintf("%s validation failed: response has no %s %s", ruleType, ruleType, name),
+	}
+}
+
+// validateCookie applies the comparators of a rule to the value of a cookie set by the
+// response, or nil when it sets no such cookie
+func (v *Validator) validateCookie(response *http.Response, rule ValidationRule) ValidationResult {
+	name, err := v.varManager.Substitute(rule.Cookie)
+	if err != nil {
+		return ValidationResult{
+			Type:     "cookie",
+			Expected: rule.Cookie,
+			Actual:   "substitution failed",
+			Error:    fmt.Sprintf("failed to substitute variables in cookie name: %v", err),
+		}
+	}
+
+	header := nethttp.Header{"Set-Cookie": headerValues(response.Headers, "Set-Cookie")}
+	var value interface{}
+	for _, cookie := range (&nethttp.Response{Header: header}).Cookies() {
+		if cookie.Name == name {
+			value = cookie.Value
+		}
+	}
+	if value == nil && rule.Nil == nil && rule.Empty == nil {
+		return missing("cookie", name)
+	}
+	return v.validateValue(value, rule, "cookie", name)
+}
+
+// validateContentType checks the media type of the response. Parameters such as
+// charset are compared only when the rule has them, and type/* matches any subtype.
+func (v *Validator) validateContentType(response *http.Response, expected string) ValidationResult {
+	result := ValidationResult{Type: "content_type", Expected: expected}
+
+	wantType, wantParams, err := mime.ParseMediaType(expected)
+	if err != nil {
+		result.Actual = "invalid rule"
+		result.Error = fmt.Sprintf("invalid content type %q: %v", expected, err)
+		return result
+	}
+
+	values := headerValues(response.Headers, "Content-Type")
+	if len(values) == 0 {
+		result.Actual = "no Content-Type header"
+		result.Error = "content type validation failed: response has no Content-Type header"
+		return result
+	}
+	result.Actual = values[0]
+	gotType, gotParams, err := mime.ParseMediaType(values[0])
+	if err != nil {
+		result.Error = fmt.Sprintf("content type validation failed: invalid Content-Type %q: %v", values[0], err)
+		return result
+	}
+
+	result.Passed = gotType == wantType ||
+		strings.HasSuffix(wantType, "/*") && strings.HasPrefix(gotType, strings.TrimSuffix(wantType, "*"))
+	for name, want := range wantParams {
+		if !strings.EqualFold(gotParams[name], want) {
+			result.Passed = false
+		}
+	}
+	result.Error = v.getErrorMessage(result.Passed, "content type", expected, values[0])
+	return result
+}
+
+// validateBodyContains checks that the raw body contains a text
+func (v *Validator) validateBodyContains(response *http.Response, expected string) ValidationResult {
+	substituted, err := v.varManager.Substitute(expected)
+	if err != nil {
+		return ValidationResult{
+			Type:     "body_contains",
+			Expected: expected,
+			Actual:   "substitution failed",
+			Error:    fmt.Sprintf("failed to substitute variables: %v", err),
+		}
+	}
+	body := response.GetTextBody()
+	passed := strings.Contains(body, substituted)
+	return ValidationResult{
+		Type:     "body_contains",
+		Expected: substituted,
+		Actual:   excerpt(body),
+		Passed:   passed,
+		Error:    v.getErrorMessage(passed, "body contains", substituted, excerpt(body)),
+	}
+}
+
+// validateBodyPattern checks that the raw body matches a regular expression
+func (v *Validator) validateBodyPattern(response *http.Response, expected string) ValidationResult {
+	pattern, err := v.varManager.Substitute(expected)
+	if err != nil {
+		return ValidationResult{
+			Type:     "body_pattern",
+			Expected: expected,
+			Actual:   "substitution failed",
+			Error:    fmt.Sprintf("failed to substitute variables: %v", err),
+		}
+	}
+	body := response.GetTextBody()
+	result := ValidationResult{Type: "body_pattern", Expected: pattern, Actual: excerpt(body)}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		result.Error = fmt.Sprintf("invalid regex pattern: %v", err)
+		return result
+	}
+	result.Passed = re.MatchString(body)
+	result.Error = v.getErrorMessage(result.Passed, "body pattern match", pattern, excerpt(body))
+	return result
+}
+
+// excerpt shortens a body for validation messages
+func excerpt(body string) string {
+	const max = 200
+	if runes := []rune(body); len(runes) > max {
+		return string(runes[:max]) + "..."
+	}
+	return body
+}
+
+// validateRedirect checks where the request was redirected: the Location of a 3xx
+// response when redirects are not followed, or else the last redirect followed. A
+// rule with a path, such as /login, is compared with the path and query only.
+func (v *Validator) validateRedirect(response *http.Response, expected string) ValidationResult {
+	substituted, err := v.varManager.Substitute(expected)
+	if err != nil {
+		return ValidationResult{
+			Type:     "redirect_to",
+			Expected: expected,
+			Actual:   "substitution failed",
+			Error:    fmt.Sprintf("failed to substitute variables: %v", err),
+		}
+	}
+	result := ValidationResult{Type: "redirect_to", Expected: substituted}
+
+	target := ""
+	if location := headerValues(response.Headers, "Location"); response.StatusCode >= 300 && response.StatusCode < 400 && len(location) > 0 {
+		target = location[0]
+		if response.Request != nil {
+			if base, err := url.Parse(response.Request.URL); err == nil {
+				if ref, err := base.Parse(target); err == nil {
+					target = ref.String()
+				}
+			}
+		}
+	} else if len(response.Redirects) > 0 {
+		target = response.Redirects[len(response.Redirects)-1]
+	}
+	if target == "" {
+		result.Actual = fmt.Sprintf("status %d without redirect", response.StatusCode)
+		result.Error = fmt.Sprintf("redirect validation failed: expected a redirect to %s, got %s", substituted, result.Actual)
+		return result
+	}
+	result.Actual = target
+
+	actual := target
+	if want, err := url.Parse(substituted); err == nil && want.Scheme == "" && want.Host == "" {
+		if got, err := url.Parse(target); err == nil {
+			actual = got.EscapedPath()
+			if want.RawQuery != "" || strings.HasSuffix(substituted, "?") {
+				actual += "?" + got.RawQuery
+			}
+		}
+	}
+	result.Passed = actual == substituted
+	result.Error = v.getErrorMessage(result.Passed, "redirect", substituted, target)
+	return result
+}
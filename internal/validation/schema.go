@@ -0,0 +1,87 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cjp2600/stepwise/internal/http"
+	"github.com/cjp2600/stepwise/internal/jsonschema"
+)
+
+// validateSchema validates the response body, or the value at rule.JSON, against a
+// JSON Schema and reports every violation
+func (v *Validator) validateSchema(response *http.Response, rule ValidationRule) ValidationResult {
+	expected := rule.SchemaFile
+	if expected == "" {
+		expected = "inline schema"
+	}
+	result := ValidationResult{Type: "schema", Expected: expected}
+
+	schema, err := v.compileSchema(rule)
+	if err != nil {
+		result.Actual = "invalid schema"
+		result.Error = fmt.Sprintf("invalid schema: %v", err)
+		return result
+	}
+
+	value, err := response.GetJSONBody()
+	if err != nil {
+		result.Actual = "invalid JSON"
+		result.Error = fmt.Sprintf("failed to parse JSON: %v", err)
+		return result
+	}
+	if rule.JSON != "" {
+		if value, err = v.extractJSONValue(value, rule.JSON); err != nil {
+			result.Actual = "extraction failed"
+			result.Error = fmt.Sprintf("failed to extract value: %v", err)
+			return result
+		}
+	}
+
+	result.Violations = schema.Validate(value)
+	result.Passed = len(result.Violations) == 0
+	if result.Passed {
+		result.Actual = "valid"
+		return result
+	}
+
+	messages := make([]string, len(result.Violations))
+	for i, violation := range result.Violations {
+		messages[i] = violation.String()
+	}
+	result.Actual = fmt.Sprintf("%d violations", len(result.Violations))
+	result.Error = "schema validation failed: " + strings.Join(messages, "; ")
+	return result
+}
+
+// compileSchema compiles the inline schema of a rule, or loads its schema file.
+// Schema files are compiled once.
+func (v *Validator) compileSchema(rule ValidationRule) (*jsonschema.Schema, error) {
+	if rule.Schema != nil && rule.SchemaFile != "" {
+		return nil, fmt.Errorf("schema and schema_file cannot be used together")
+	}
+
+	if rule.SchemaFile == "" {
+		doc := rule.Schema
+		// A schema may also be written as a JSON string
+		if source, ok := doc.(string); ok {
+			if err := json.Unmarshal([]byte(source), &doc); err != nil {
+				return nil, fmt.Errorf("failed to parse schema: %w", err)
+			}
+		}
+		return jsonschema.Compile(doc, "")
+	}
+
+	v.schemaMu.Lock()
+	defer v.schemaMu.Unlock()
+	if schema, ok := v.schemas[rule.SchemaFile]; ok {
+		return schema, nil
+	}
+	schema, err := jsonschema.CompileFile(rule.SchemaFile)
+	if err != nil {
+		return nil, err
+	}
+	v.schemas[rule.SchemaFile] = schema
+	return schema, nil
+}
@@ -0,0 +1,150 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cjp2600/stepwise/internal/http"
+	"github.com/cjp2600/stepwise/internal/jsondiff"
+)
+
+// SnapshotsDir is the directory of snapshot files, next to the workflow file
+const SnapshotsDir = "__snapshots__"
+
+// validateSnapshot compares the body, or the value at rule.JSON, with a snapshot file.
+// A .json snapshot is compared structurally with the options of the rule, and any other
+// file as text. Missing snapshots are written, and so are snapshots that
+// differ in update mode.
+func (v *Validator) validateSnapshot(response *http.Response, rule ValidationRule) ValidationResult {
+	result := ValidationResult{Type: "snapshot", Expected: rule.Snapshot}
+
+	name, err := v.varManager.Substitute(rule.Snapshot)
+	if err != nil {
+		result.Actual = "substitution failed"
+		result.Error = fmt.Sprintf("failed to substitute variables in snapshot name: %v", err)
+		return result
+	}
+	if filepath.Ext(name) == "" {
+		name += ".json"
+	}
+	display := filepath.ToSlash(filepath.Join(SnapshotsDir, name))
+	path := filepath.Join(rule.SnapshotDir, name)
+	result.Expected = display
+
+	var actual interface{}
+	var content []byte
+	isJSON := strings.EqualFold(filepath.Ext(name), ".json")
+	if isJSON {
+		if actual, err = response.GetJSONBody(); err != nil {
+			result.Actual = "invalid JSON"
+			result.Error = fmt.Sprintf("snapshot validation failed: response body is not JSON: %v", err)
+			return result
+		}
+		if rule.JSON != "" {
+			if actual, err = v.extractJSONValue(actual, rule.JSON); err != nil {
+				result.Actual = "extraction failed"
+				result.Error = fmt.Sprintf("failed to extract value: %v", err)
+				return result
+			}
+		}
+		if content, err = json.MarshalIndent(actual, "", "  "); err != nil {
+			result.Actual = "invalid JSON"
+			result.Error = fmt.Sprintf("failed to encode snapshot: %v", err)
+			return result
+		}
+		content = append(content, '\n')
+	} else {
+		content = response.Body
+	}
+
+	stored, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return v.writeSnapshot(result, path, content, "snapshot written")
+	}
+	if err != nil {
+		result.Actual = "unreadable snapshot"
+		result.Error = fmt.Sprintf("failed to read snapshot: %v", err)
+		return result
+	}
+
+	if isJSON {
+		var expected interface{}
+		if err := json.Unmarshal(stored, &expected); err != nil {
+			result.Actual = "invalid snapshot"
+			result.Error = fmt.Sprintf("invalid snapshot %s: %v", display, err)
+			return result
+		}
+		differences, err := jsondiff.Compare(expected, actual, diffOptions(rule))
+		if err != nil {
+			result.Actual = "invalid rule"
+			result.Error = err.Error()
+			return result
+		}
+		if len(differences) == 0 {
+			result.Actual = "snapshot matches"
+			result.Passed = true
+			return result
+		}
+		if v.updateSnapshots {
+			return v.writeSnapshot(result, path, content, "snapshot updated")
+		}
+		result.Diff = differences
+		result.Actual = plural(len(differences), "difference")
+		result.Error = fmt.Sprintf("snapshot validation failed: %s from %s: %s", result.Actual, display, jsondiff.Summary(differences, 5))
+		return result
+	}
+
+	if bytes.Equal(stored, content) {
+		result.Actual = "snapshot matches"
+		result.Passed = true
+		return result
+	}
+	if v.updateSnapshots {
+		return v.writeSnapshot(result, path, content, "snapshot updated")
+	}
+	line, want, got := firstDifferentLine(string(stored), string(content))
+	result.Actual = excerpt(string(content))
+	result.Error = fmt.Sprintf("snapshot validation failed: line %d differs from %s: expected %q, got %q", line, display, want, got)
+	return result
+}
+
+// writeSnapshot writes a snapshot file and passes the rule
+func (v *Validator) writeSnapshot(result ValidationResult, path string, content []byte, action string) ValidationResult {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err == nil {
+		err = os.WriteFile(path, content, 0644)
+	}
+	if err != nil {
+		result.Actual = "write failed"
+		result.Error = fmt.Sprintf("failed to write snapshot: %v", err)
+		return result
+	}
+	v.logger.Info("Snapshot written", "file", path)
+	result.Actual = action
+	result.Passed = true
+	return result
+}
+
+// firstDifferentLine returns the number and the two versions of the first line that
+// differs between two texts
+func firstDifferentLine(expected, actual string) (int, string, string) {
+	want := strings.Split(expected, "\n")
+	got := strings.Split(actual, "\n")
+	for i := 0; i < len(want) || i < len(got); i++ {
+		var w, g string
+		if i < len(want) {
+			w = want[i]
+		}
+		if i < len(got) {
+			g = got[i]
+		}
+		if w != g || i >= len(want) || i >= len(got) {
+			return i + 1, excerpt(w), excerpt(g)
+		}
+	}
+	return len(want), "", ""
+}
@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/cjp2600/stepwise/internal/jsondiff"
+)
+
+// diffOptions returns the options of a rule for structural comparisons
+func diffOptions(rule ValidationRule) jsondiff.Options {
+	return jsondiff.Options{
+		Ignore:            rule.Ignore,
+		IgnoreOrder:       rule.IgnoreOrder,
+		IgnoreExtraFields: rule.IgnoreExtraFields,
+		Tolerance:         rule.Tolerance,
+	}
+}
+
+// substituteExpected substitutes variables in an expected value, including the strings
+// of objects and arrays
+func (v *Validator) substituteExpected(expected interface{}) interface{} {
+	switch value := expected.(type) {
+	case string:
+		if substituted, err := v.varManager.SubstituteValue(value); err == nil {
+			return substituted
+		}
+		return value
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			result[key] = v.substituteExpected(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, item := range value {
+			result[i] = v.substituteExpected(item)
+		}
+		return result
+	}
+	return expected
+}
+
+// isStructured reports whether a value is an object or an array
+func isStructured(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+// validateStructure compares an object or array with an expected value and reports
+// the differences path by path
+func (v *Validator) validateStructure(actual, expected interface{}, options jsondiff.Options) ValidationResult {
+	result := ValidationResult{Type: "equals", Expected: expected, Actual: actual}
+	differences, err := jsondiff.Compare(expected, actual, options)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Passed = len(differences) == 0
+	if !result.Passed {
+		result.Diff = differences
+		result.Error = fmt.Sprintf("value validation failed: %s: %s", plural(len(differences), "difference"), jsondiff.Summary(differences, 5))
+	}
+	return result
+}
+
+// plural formats a count with a noun, such as "1 difference" or "3 differences"
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
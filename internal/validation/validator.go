@@ -4,45 +4,89 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cjp2600/stepwise/internal/http"
+	"github.com/cjp2600/stepwise/internal/jsondiff"
+	"github.com/cjp2600/stepwise/internal/jsonpath"
+	"github.com/cjp2600/stepwise/internal/jsonschema"
 	"github.com/cjp2600/stepwise/internal/logger"
 	"github.com/cjp2600/stepwise/internal/variables"
+	"github.com/cjp2600/stepwise/internal/xpath"
 )
 
 // Validator represents a validation engine
 type Validator struct {
 	logger     *logger.Logger
 	varManager *variables.Manager
+
+	schemaMu sync.Mutex
+	schemas  map[string]*jsonschema.Schema // Compiled schema files by path
+
+	updateSnapshots bool // Write snapshots that differ instead of failing
 }
 
 // ValidationRule represents a validation rule
 type ValidationRule struct {
-	Status       int         `yaml:"status" json:"status"`
-	JSON         string      `yaml:"json" json:"json"`
-	XML          string      `yaml:"xml" json:"xml"`
-	Time         string      `yaml:"time" json:"time"`
-	Equals       interface{} `yaml:"equals" json:"equals"`
-	Contains     string      `yaml:"contains" json:"contains"`
-	Type         string      `yaml:"type" json:"type"`
-	Greater      interface{} `yaml:"greater" json:"greater"`
-	Less         interface{} `yaml:"less" json:"less"`
-	Pattern      string      `yaml:"pattern" json:"pattern"`
-	Custom       string      `yaml:"custom" json:"custom"`
-	Value        string      `yaml:"value" json:"value"`
-	Empty        *bool       `yaml:"empty,omitempty" json:"empty,omitempty"`   // true: must be empty, false: must not be empty
-	Nil          *bool       `yaml:"nil,omitempty" json:"nil,omitempty"`       // true: must be nil, false: must not be nil
-	Len          *int        `yaml:"len,omitempty" json:"len,omitempty"`       // length must be equal to this
-	Decode       string      `yaml:"decode,omitempty" json:"decode,omitempty"` // "base64json"
-	JSONPath     string      `yaml:"jsonpath,omitempty" json:"jsonpath,omitempty"`
-	PrintDecoded bool        `yaml:"print_decoded,omitempty" json:"print_decoded,omitempty"`
+	Status       int                    `yaml:"status" json:"status"`
+	JSON         string                 `yaml:"json" json:"json"`
+	XML          string                 `yaml:"xml" json:"xml"`
+	Time         string                 `yaml:"time" json:"time"`
+	Equals       interface{}            `yaml:"equals" json:"equals"`
+	Contains     string                 `yaml:"contains" json:"contains"`
+	Type         string                 `yaml:"type" json:"type"`
+	Greater      interface{}            `yaml:"greater" json:"greater"`
+	Less         interface{}            `yaml:"less" json:"less"`
+	Pattern      string                 `yaml:"pattern" json:"pattern"`
+	Custom       string                 `yaml:"custom" json:"custom"`
+	Value        string                 `yaml:"value" json:"value"`
+	Empty        *bool                  `yaml:"empty,omitempty" json:"empty,omitempty"`   // true: must be empty, false: must not be empty
+	Nil          *bool                  `yaml:"nil,omitempty" json:"nil,omitempty"`       // true: must be nil, false: must not be nil
+	Len          *int                   `yaml:"len,omitempty" json:"len,omitempty"`       // length must be equal to this
+	Decode       string                 `yaml:"decode,omitempty" json:"decode,omitempty"` // "base64json"
+	JSONPath     string                 `yaml:"jsonpath,omitempty" json:"jsonpath,omitempty"`
+	PrintDecoded bool                   `yaml:"print_decoded,omitempty" json:"print_decoded,omitempty"`
+	Header       string                 `yaml:"header,omitempty" json:"header,omitempty"`               // Response header, compared with the comparators
+	Cookie       string                 `yaml:"cookie,omitempty" json:"cookie,omitempty"`               // Cookie set by the response, compared with the comparators
+	ContentType  string                 `yaml:"content_type,omitempty" json:"content_type,omitempty"`   // Media type, e.g. application/json or text/*
+	BodyContains string                 `yaml:"body_contains,omitempty" json:"body_contains,omitempty"` // Text the raw body must contain
+	BodyPattern  string                 `yaml:"body_pattern,omitempty" json:"body_pattern,omitempty"`   // Regular expression the raw body must match
+	RedirectTo   string                 `yaml:"redirect_to,omitempty" json:"redirect_to,omitempty"`     // URL or path the request must be redirected to
+	Namespaces   map[string]string      `yaml:"namespaces,omitempty" json:"namespaces,omitempty"`       // XPath prefixes of xml rules
+	Schema       interface{}            `yaml:"schema,omitempty" json:"schema,omitempty"`               // Inline JSON Schema for the body or the value at json
+	SchemaFile   string                 `yaml:"schema_file,omitempty" json:"schema_file,omitempty"`     // JSON Schema file, relative to the workflow
+	CustomFile   string                 `yaml:"custom_file,omitempty" json:"custom_file,omitempty"`     // Script file, relative to the workflow
+	Args         map[string]interface{} `yaml:"args,omitempty" json:"args,omitempty"`                   // Arguments of the script, with variables substituted
+	ScriptDir    string                 `yaml:"-" json:"-"`                                             // Directory load() paths of inline scripts are relative to
+	AnyOf        []ValidationRule       `yaml:"any_of,omitempty" json:"any_of,omitempty"`               // Passes when one of the rules passes
+	AllOf        []ValidationRule       `yaml:"all_of,omitempty" json:"all_of,omitempty"`               // Passes when all of the rules pass
+	Not          *ValidationRule        `yaml:"not,omitempty" json:"not,omitempty"`                     // Passes when the rule fails
+	Each         []ValidationRule       `yaml:"each,omitempty" json:"each,omitempty"`                   // Rules every element of the array at json must pass
+	Some         []ValidationRule       `yaml:"some,omitempty" json:"some,omitempty"`                   // Rules at least one element of the array at json must pass
+	Snapshot     string                 `yaml:"snapshot,omitempty" json:"snapshot,omitempty"`           // Name of the snapshot file the body is compared with
+	Ignore       []string               `yaml:"ignore,omitempty" json:"ignore,omitempty"`               // JSON paths left out of the snapshot or equals comparison
+	SnapshotDir  string                 `yaml:"-" json:"-"`                                             // Directory of the snapshot files
+	Severity     string                 `yaml:"severity,omitempty" json:"severity,omitempty"`           // "error" (default) or "warn": a failing rule does not fail the step
+	Message      string                 `yaml:"message,omitempty" json:"message,omitempty"`             // Replaces the error of a failing rule, with variables substituted
+
+	// Options of structural comparisons, by equals and snapshot
+	IgnoreOrder       bool    `yaml:"ignore_order,omitempty" json:"ignore_order,omitempty"`               // Arrays may hold their elements in any order
+	IgnoreExtraFields bool    `yaml:"ignore_extra_fields,omitempty" json:"ignore_extra_fields,omitempty"` // Objects may have keys the expected ones lack
+	Tolerance         float64 `yaml:"tolerance,omitempty" json:"tolerance,omitempty"`                     // Numbers may differ by this much
 }
 
+// Rule severities
+const (
+	SeverityError = "error"
+	SeverityWarn  = "warn"
+)
+
 // ValidationResult represents the result of a validation
 type ValidationResult struct {
 	Type     string      `json:"type"`
@@ -50,6 +94,22 @@ type ValidationResult struct {
 	Actual   interface{} `json:"actual"`
 	Passed   bool        `json:"passed"`
 	Error    string      `json:"error,omitempty"`
+
+	Severity string `json:"severity,omitempty"` // "warn" for rules that do not fail the step
+
+	Violations []jsonschema.Violation `json:"violations,omitempty"` // Every violation of a schema rule
+	Diff       []jsondiff.Difference  `json:"diff,omitempty"`       // Differences from a snapshot or an equals object or array
+	Results    []ValidationResult     `json:"results,omitempty"`    // Results of the rules of any_of, all_of and not
+}
+
+// Failed reports whether the result fails the step
+func (r ValidationResult) Failed() bool {
+	return !r.Passed && r.Severity != SeverityWarn
+}
+
+// Warning reports whether the result is a failing rule of severity warn
+func (r ValidationResult) Warning() bool {
+	return !r.Passed && r.Severity == SeverityWarn
 }
 
 // NewValidator creates a new validator
@@ -57,9 +117,16 @@ func NewValidator(log *logger.Logger) *Validator {
 	return &Validator{
 		logger:     log,
 		varManager: variables.NewManager(log),
+		schemas:    make(map[string]*jsonschema.Schema),
 	}
 }
 
+// SetUpdateSnapshots makes snapshot rules write the snapshots that differ from the
+// response instead of failing
+func (v *Validator) SetUpdateSnapshots(update bool) {
+	v.updateSnapshots = update
+}
+
 // SetVariableManager sets the variable manager for the validator
 func (v *Validator) SetVariableManager(varManager *variables.Manager) {
 	v.varManager = varManager
@@ -82,8 +149,52 @@ func (v *Validator) Validate(response *http.Response, rules []ValidationRule) ([
 	return results, nil
 }
 
-// validateRule validates a single rule
+// validateRule validates a single rule, applying its message and severity
 func (v *Validator) validateRule(response *http.Response, rule ValidationRule) ValidationResult {
+	result := v.checkRule(response, rule)
+
+	switch rule.Severity {
+	case "", SeverityError:
+	case SeverityWarn:
+		result.Severity = SeverityWarn
+	default:
+		return ValidationResult{
+			Type:     result.Type,
+			Expected: rule.Severity,
+			Actual:   "invalid rule",
+			Error:    fmt.Sprintf("invalid severity %q: expected %s or %s", rule.Severity, SeverityError, SeverityWarn),
+		}
+	}
+
+	if !result.Passed && rule.Message != "" {
+		message, err := v.varManager.Substitute(rule.Message)
+		if err != nil {
+			message = rule.Message
+		}
+		result.Error = message
+	}
+	return result
+}
+
+// checkRule evaluates a single rule
+func (v *Validator) checkRule(response *http.Response, rule ValidationRule) ValidationResult {
+	// Rule combinators
+	if len(rule.AnyOf) > 0 {
+		return v.validateAnyOf(response, rule.AnyOf)
+	}
+	if len(rule.AllOf) > 0 {
+		return v.validateAllOf(response, rule.AllOf)
+	}
+	if rule.Not != nil {
+		return v.validateNot(response, *rule.Not)
+	}
+	if len(rule.Each) > 0 {
+		return v.validateElements(response, rule.JSON, rule.Each, "each")
+	}
+	if len(rule.Some) > 0 {
+		return v.validateElements(response, rule.JSON, rule.Some, "some")
+	}
+
 	// Status code validation
 	if rule.Status != 0 {
 		return v.validateStatus(response, rule.Status)
@@ -94,6 +205,41 @@ func (v *Validator) validateRule(response *http.Response, rule ValidationRule) V
 		return v.validateTime(response, rule.Time)
 	}
 
+	// Header, cookie and raw body validation
+	if rule.Header != "" {
+		return v.validateHeader(response, rule)
+	}
+	if rule.Cookie != "" {
+		return v.validateCookie(response, rule)
+	}
+	if rule.ContentType != "" {
+		return v.validateContentType(response, rule.ContentType)
+	}
+	if rule.BodyContains != "" {
+		return v.validateBodyContains(response, rule.BodyContains)
+	}
+	if rule.BodyPattern != "" {
+		return v.validateBodyPattern(response, rule.BodyPattern)
+	}
+	if rule.RedirectTo != "" {
+		return v.validateRedirect(response, rule.RedirectTo)
+	}
+
+	// JSON Schema validation, of the whole body or the value at rule.JSON
+	if rule.Schema != nil || rule.SchemaFile != "" {
+		return v.validateSchema(response, rule)
+	}
+
+	// Golden-file snapshot of the body
+	if rule.Snapshot != "" {
+		return v.validateSnapshot(response, rule)
+	}
+
+	// Script assertion
+	if rule.Custom != "" || rule.CustomFile != "" {
+		return v.validateCustom(response, rule)
+	}
+
 	// JSON validation
 	if rule.JSON != "" {
 		return v.validateJSON(response, rule)
@@ -249,6 +395,11 @@ func (v *Validator) validateJSON(response *http.Response, rule ValidationRule) V
 
 	value = jsonData
 
+	return v.validateValue(value, rule, "json", rule.JSON)
+}
+
+// validateValue applies the comparators of a rule to a value extracted from the response
+func (v *Validator) validateValue(value interface{}, rule ValidationRule, ruleType, path string) ValidationResult {
 	// Apply validation based on rule type
 	if rule.Nil != nil {
 		isNil := value == nil
@@ -306,7 +457,7 @@ func (v *Validator) validateJSON(response *http.Response, rule ValidationRule) V
 		}
 	}
 	if rule.Equals != nil {
-		return v.validateEquals(value, rule.Equals)
+		return v.validateEquals(value, rule.Equals, diffOptions(rule))
 	}
 
 	if rule.Contains != "" {
@@ -332,34 +483,96 @@ func (v *Validator) validateJSON(response *http.Response, rule ValidationRule) V
 	// Default validation - just check if value exists
 	passed := value != nil
 	return ValidationResult{
-		Type:     "json",
-		Expected: rule.JSON,
+		Type:     ruleType,
+		Expected: path,
 		Actual:   value,
 		Passed:   passed,
-		Error:    v.getErrorMessage(passed, "JSON value", rule.JSON, value),
+		Error:    v.getErrorMessage(passed, strings.ToUpper(ruleType)+" value", path, value),
 	}
 }
 
-// validateXML validates XML response (placeholder for future implementation)
+// validateXML validates an XML or SOAP response with an XPath 1.0 expression
 func (v *Validator) validateXML(response *http.Response, rule ValidationRule) ValidationResult {
-	return ValidationResult{
-		Type:     "xml",
-		Expected: rule.XML,
-		Actual:   "not implemented",
-		Passed:   false,
-		Error:    "XML validation not implemented yet",
+	value, err := v.ExtractXMLValue(response.Body, rule.XML, rule.Namespaces)
+	if err != nil {
+		return ValidationResult{
+			Type:     "xml",
+			Expected: rule.XML,
+			Actual:   "extraction failed",
+			Passed:   false,
+			Error:    fmt.Sprintf("failed to extract value: %v", err),
+		}
+	}
+
+	// Text is untyped in XML, so type: number and type: boolean accept text in those formats
+	if text, ok := value.(string); ok {
+		value = typedText(text, rule.Type)
 	}
+
+	return v.validateValue(value, rule, "xml", rule.XML)
 }
 
-// validateEquals validates equality
-func (v *Validator) validateEquals(actual, expected interface{}) ValidationResult {
-	// Substitute variables in expected value if it's a string
-	var substitutedExpected interface{} = expected
-	if expectedStr, ok := expected.(string); ok {
-		if substitutedStr, err := v.varManager.Substitute(expectedStr); err == nil {
-			substitutedExpected = substitutedStr
+// typedText converts the text of a node to the type a rule expects, when it has that format
+func typedText(text, expectedType string) interface{} {
+	switch expectedType {
+	case "number":
+		if f, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(strings.TrimSpace(text)); err == nil {
+			return b
 		}
 	}
+	return text
+}
+
+// ExtractXMLValue evaluates an XPath 1.0 expression against an XML document. A node-set
+// gives the text of its node, a list of texts for several nodes, or nil when it is empty.
+// Prefixes are resolved with namespaces first and the declarations of the document second.
+func (v *Validator) ExtractXMLValue(body []byte, path string, namespaces map[string]string) (interface{}, error) {
+	// Substitute variables in the path first
+	substitutedPath, err := v.varManager.Substitute(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to substitute variables in path '%s': %w", path, err)
+	}
+
+	doc, err := xpath.Parse(body)
+	if err != nil {
+		return nil, err
+	}
+	result, err := xpath.Evaluate(doc, substitutedPath, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, ok := result.([]*xpath.Node)
+	if !ok {
+		return result, nil
+	}
+	switch len(nodes) {
+	case 0:
+		return nil, nil
+	case 1:
+		return nodes[0].StringValue(), nil
+	}
+	values := make([]interface{}, len(nodes))
+	for i, node := range nodes {
+		values[i] = node.StringValue()
+	}
+	return values, nil
+}
+
+// validateEquals validates equality
+func (v *Validator) validateEquals(actual, expected interface{}, options jsondiff.Options) ValidationResult {
+	// Substitute variables in expected value if it's a string. A single reference such as
+	// "{{ids | first}}" keeps the type of its value.
+	substitutedExpected := v.substituteExpected(expected)
+
+	// Objects and arrays are compared path by path
+	if isStructured(substitutedExpected) || isStructured(actual) {
+		return v.validateStructure(actual, substitutedExpected, options)
+	}
 
 	// Convert both values to float64 for numeric comparison
 	actualFloat, actualOk := v.toFloat64(actual)
@@ -368,7 +581,7 @@ func (v *Validator) validateEquals(actual, expected interface{}) ValidationResul
 	var passed bool
 	if actualOk && expectedOk {
 		// Both are numeric, compare as floats
-		passed = actualFloat == expectedFloat
+		passed = actualFloat == expectedFloat || math.Abs(actualFloat-expectedFloat) <= options.Tolerance
 	} else {
 		// Use deep equality for non-numeric values
 		passed = reflect.DeepEqual(actual, substitutedExpected)
@@ -505,421 +718,7 @@ func (v *Validator) extractJSONValue(data interface{}, path string) (interface{}
 	if err != nil {
 		return nil, fmt.Errorf("failed to substitute variables in path '%s': %w", path, err)
 	}
-
-	// Поддержка сложных путей с массивами: $.widgets[0].widget
-	if substitutedPath == "$" {
-		return data, nil
-	}
-
-	if strings.HasPrefix(substitutedPath, "$.") {
-		pathExpr := strings.TrimPrefix(substitutedPath, "$.")
-		// Разбиваем путь на части с учётом индексов
-		var parts []string
-		var buf strings.Builder
-		inBracket := false
-		for _, r := range pathExpr {
-			if r == '.' && !inBracket {
-				parts = append(parts, buf.String())
-				buf.Reset()
-			} else {
-				if r == '[' {
-					inBracket = true
-				}
-				if r == ']' {
-					inBracket = false
-				}
-				buf.WriteRune(r)
-			}
-		}
-		if buf.Len() > 0 {
-			parts = append(parts, buf.String())
-		}
-		current := data
-		for i, part := range parts {
-			// Special handling for "length" property on arrays
-			if part == "length" {
-				if arr, ok := current.([]interface{}); ok {
-					return len(arr), nil
-				}
-				return nil, fmt.Errorf("cannot get length of non-array")
-			}
-
-			// Массив с индексом или фильтром: key[index] или key[?(...)]
-			if strings.Contains(part, "[") && strings.HasSuffix(part, "]") {
-				openBracket := strings.Index(part, "[")
-				closeBracket := strings.LastIndex(part, "]")
-				key := part[:openBracket]
-				indexStr := part[openBracket+1 : closeBracket]
-
-				// Получить массив из текущего объекта или использовать текущий, если это уже массив
-				var arrayData []interface{}
-				if key != "" {
-					if mapData, ok := current.(map[string]interface{}); ok {
-						if array, exists := mapData[key]; exists {
-							if arr, ok := array.([]interface{}); ok {
-								arrayData = arr
-							} else {
-								return nil, fmt.Errorf("key %s is not an array", key)
-							}
-						} else {
-							return nil, fmt.Errorf("key not found: %s", key)
-						}
-					} else {
-						return nil, fmt.Errorf("cannot access key on non-object")
-					}
-				} else {
-					// key пустой, значит применяем фильтр к текущему массиву
-					if arr, ok := current.([]interface{}); ok {
-						arrayData = arr
-					} else {
-						return nil, fmt.Errorf("cannot apply array filter to non-array")
-					}
-				}
-
-				// Обработать фильтр или индекс
-				result, err := v.processArrayAccessor(arrayData, indexStr)
-				if err != nil {
-					return nil, err
-				}
-				
-				// Если результат - массив и есть еще части пути, нужно применить путь к каждому элементу
-				if resultArray, ok := result.([]interface{}); ok && i < len(parts)-1 {
-					// Есть еще части пути после массива - применить к каждому элементу
-					remainingPath := strings.Join(parts[i+1:], ".")
-					if !strings.HasPrefix(remainingPath, "$") {
-						remainingPath = "$." + remainingPath
-					}
-					
-					var mappedResults []interface{}
-					for _, item := range resultArray {
-						value, err := v.extractJSONValue(item, remainingPath)
-						if err != nil {
-							// Пропускаем элементы, где путь не найден
-							continue
-						}
-						mappedResults = append(mappedResults, value)
-					}
-					return mappedResults, nil
-				}
-				
-				current = result
-			} else {
-				// Обычный ключ
-				if mapData, ok := current.(map[string]interface{}); ok {
-					if value, exists := mapData[part]; exists {
-						current = value
-					} else {
-						return nil, fmt.Errorf("key not found: %s", part)
-					}
-				} else {
-					return nil, fmt.Errorf("cannot access key on non-object")
-				}
-			}
-		}
-		return current, nil
-	}
-
-	if strings.HasPrefix(substitutedPath, "$[") {
-		// Handle paths like $[0] or $[filter] or $[filter].field or $[*].field
-		closeBracket := strings.Index(substitutedPath, "]")
-		if closeBracket == -1 {
-			return nil, fmt.Errorf("unclosed bracket in path: %s", substitutedPath)
-		}
-
-		indexStr := substitutedPath[2:closeBracket]
-		remainingPath := substitutedPath[closeBracket+1:]
-
-		// Apply array accessor
-		if arrayData, ok := data.([]interface{}); ok {
-			result, err := v.processArrayAccessor(arrayData, indexStr)
-			if err != nil {
-				return nil, err
-			}
-
-			// If there's a remaining path, continue processing
-			if remainingPath != "" {
-				if strings.HasPrefix(remainingPath, ".") {
-					remainingPath = "$" + remainingPath
-				}
-				
-				// Если результат - массив (например, при использовании [*]), применить путь к каждому элементу
-				if resultArray, ok := result.([]interface{}); ok {
-					var mappedResults []interface{}
-					for _, item := range resultArray {
-						value, err := v.extractJSONValue(item, remainingPath)
-						if err != nil {
-							// Пропускаем элементы, где путь не найден
-							continue
-						}
-						mappedResults = append(mappedResults, value)
-					}
-					return mappedResults, nil
-				}
-				
-				return v.extractJSONValue(result, remainingPath)
-			}
-
-			return result, nil
-		}
-		return nil, fmt.Errorf("root element is not an array")
-	}
-
-	return nil, fmt.Errorf("unsupported JSON path: %s", substitutedPath)
-}
-
-// processArrayAccessor handles array access with index, filter, or special selectors
-func (v *Validator) processArrayAccessor(arrayData []interface{}, accessor string) (interface{}, error) {
-	if len(arrayData) == 0 {
-		return nil, nil
-	}
-
-	// Filter expression: ?(@.field op value) or ?(@.field)
-	if strings.HasPrefix(accessor, "?(@.") && strings.HasSuffix(accessor, ")") {
-		return v.filterArray(arrayData, accessor)
-	}
-
-	// Wildcard: return all elements
-	if accessor == "*" {
-		return arrayData, nil
-	}
-
-	// Last element
-	if accessor == "last" || accessor == "-1" {
-		return arrayData[len(arrayData)-1], nil
-	}
-
-	// Slice: start:end
-	if strings.Contains(accessor, ":") {
-		return v.sliceArray(arrayData, accessor)
-	}
-
-	// Simple numeric index
-	index, err := strconv.Atoi(accessor)
-	if err != nil {
-		return nil, fmt.Errorf("invalid array accessor: %s", accessor)
-	}
-
-	// Handle negative indices
-	if index < 0 {
-		index = len(arrayData) + index
-	}
-
-	if index >= 0 && index < len(arrayData) {
-		return arrayData[index], nil
-	}
-
-	return nil, fmt.Errorf("array index out of bounds: %d (length: %d)", index, len(arrayData))
-}
-
-// filterArray filters array elements based on condition
-func (v *Validator) filterArray(arrayData []interface{}, filter string) (interface{}, error) {
-	// Remove ?(@. prefix and ) suffix
-	filter = strings.TrimPrefix(filter, "?(@.")
-	filter = strings.TrimSuffix(filter, ")")
-
-	// Parse filter expression: field op value or just field
-	var field, operator, expectedValue string
-
-	// Try to find operator
-	operators := []string{"==", "!=", ">=", "<=", ">", "<", "="}
-	for _, op := range operators {
-		if strings.Contains(filter, op) {
-			parts := strings.SplitN(filter, op, 2)
-			field = strings.TrimSpace(parts[0])
-			operator = op
-			if len(parts) > 1 {
-				expectedValue = strings.TrimSpace(parts[1])
-				// Remove quotes if present
-				expectedValue = strings.Trim(expectedValue, "\"'")
-			}
-			break
-		}
-	}
-
-	// No operator found, check for boolean field
-	if operator == "" {
-		field = strings.TrimSpace(filter)
-	}
-
-	// Find all matching elements
-	var matchedItems []interface{}
-	for _, item := range arrayData {
-		mapItem, ok := item.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		// Extract field value using dot notation if needed
-		fieldValue, err := v.extractFieldValue(mapItem, field)
-		if err != nil {
-			continue
-		}
-
-		// Check condition
-		matched := false
-		if operator == "" {
-			// Boolean check - field exists and is truthy
-			matched = v.isTruthy(fieldValue)
-		} else {
-			matched = v.compareFieldValue(fieldValue, operator, expectedValue)
-		}
-
-		if matched {
-			matchedItems = append(matchedItems, item)
-		}
-	}
-
-	// Return array of all matches, or error if none found
-	if len(matchedItems) == 0 {
-		return nil, fmt.Errorf("no matching element found in array for filter: %s", filter)
-	}
-
-	// If only one match, return it directly (for backward compatibility)
-	// If multiple matches, return array to support subsequent field extraction like [?(...)].name
-	if len(matchedItems) == 1 {
-		return matchedItems[0], nil
-	}
-
-	return matchedItems, nil
-}
-
-// extractFieldValue extracts a field value, supporting dot notation
-func (v *Validator) extractFieldValue(obj map[string]interface{}, field string) (interface{}, error) {
-	if !strings.Contains(field, ".") {
-		if val, exists := obj[field]; exists {
-			return val, nil
-		}
-		return nil, fmt.Errorf("field not found: %s", field)
-	}
-
-	// Handle nested fields
-	parts := strings.Split(field, ".")
-	current := interface{}(obj)
-	for _, part := range parts {
-		if mapData, ok := current.(map[string]interface{}); ok {
-			if val, exists := mapData[part]; exists {
-				current = val
-			} else {
-				return nil, fmt.Errorf("field not found: %s", part)
-			}
-		} else {
-			return nil, fmt.Errorf("cannot access field on non-object")
-		}
-	}
-	return current, nil
-}
-
-// isTruthy checks if a value is truthy
-func (v *Validator) isTruthy(value interface{}) bool {
-	if value == nil {
-		return false
-	}
-
-	switch val := value.(type) {
-	case bool:
-		return val
-	case string:
-		return val != ""
-	case int, int8, int16, int32, int64:
-		return val != 0
-	case float32, float64:
-		return val != 0.0
-	default:
-		return true
-	}
-}
-
-// compareFieldValue compares a field value against expected value using operator
-func (v *Validator) compareFieldValue(fieldValue interface{}, operator, expectedValue string) bool {
-	// Try numeric comparison first
-	fieldFloat, fieldOk := v.toFloat64(fieldValue)
-	expectedFloat, expectedOk := v.toFloat64(expectedValue)
-
-	if fieldOk && expectedOk {
-		switch operator {
-		case "==", "=":
-			return fieldFloat == expectedFloat
-		case "!=":
-			return fieldFloat != expectedFloat
-		case ">":
-			return fieldFloat > expectedFloat
-		case "<":
-			return fieldFloat < expectedFloat
-		case ">=":
-			return fieldFloat >= expectedFloat
-		case "<=":
-			return fieldFloat <= expectedFloat
-		}
-	}
-
-	// Try boolean comparison
-	if fieldBool, ok := fieldValue.(bool); ok {
-		expectedBool := expectedValue == "true"
-		switch operator {
-		case "==", "=":
-			return fieldBool == expectedBool
-		case "!=":
-			return fieldBool != expectedBool
-		}
-	}
-
-	// String comparison
-	fieldStr := fmt.Sprintf("%v", fieldValue)
-	switch operator {
-	case "==", "=":
-		return fieldStr == expectedValue
-	case "!=":
-		return fieldStr != expectedValue
-	case ">":
-		return fieldStr > expectedValue
-	case "<":
-		return fieldStr < expectedValue
-	case ">=":
-		return fieldStr >= expectedValue
-	case "<=":
-		return fieldStr <= expectedValue
-	}
-
-	return false
-}
-
-// sliceArray returns a slice of array
-func (v *Validator) sliceArray(arrayData []interface{}, sliceExpr string) (interface{}, error) {
-	parts := strings.Split(sliceExpr, ":")
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid slice expression: %s", sliceExpr)
-	}
-
-	start := 0
-	end := len(arrayData)
-
-	if parts[0] != "" {
-		var err error
-		start, err = strconv.Atoi(parts[0])
-		if err != nil {
-			return nil, fmt.Errorf("invalid slice start: %s", parts[0])
-		}
-		if start < 0 {
-			start = len(arrayData) + start
-		}
-	}
-
-	if parts[1] != "" {
-		var err error
-		end, err = strconv.Atoi(parts[1])
-		if err != nil {
-			return nil, fmt.Errorf("invalid slice end: %s", parts[1])
-		}
-		if end < 0 {
-			end = len(arrayData) + end
-		}
-	}
-
-	if start < 0 || end > len(arrayData) || start > end {
-		return nil, fmt.Errorf("slice out of bounds: %d:%d (length: %d)", start, end, len(arrayData))
-	}
-
-	return arrayData[start:end], nil
+	return jsonpath.Extract(data, substitutedPath)
 }
 
 func (v *Validator) matchesType(value interface{}, expectedType string) bool {
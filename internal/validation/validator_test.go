@@ -4,10 +4,14 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/cjp2600/stepwise/internal/http"
+	"github.com/cjp2600/stepwise/internal/jsondiff"
 	"github.com/cjp2600/stepwise/internal/logger"
 )
 
@@ -124,16 +128,31 @@ func TestValidateEquals(t *testing.T) {
 	validator := NewValidator(log)
 
 	// Test successful validation
-	result := validator.validateEquals("test", "test")
+	result := validator.validateEquals("test", "test", jsondiff.Options{})
 	if !result.Passed {
 		t.Error("Equals validation should pass for matching values")
 	}
 
 	// Test failed validation
-	result = validator.validateEquals("test", "different")
+	result = validator.validateEquals("test", "different", jsondiff.Options{})
 	if result.Passed {
 		t.Error("Equals validation should fail for non-matching values")
 	}
+
+	// Pipes in the expected value keep the type of their result
+	validator.varManager.Set("ids", []interface{}{float64(1), float64(2)})
+	result = validator.validateEquals([]interface{}{float64(2), float64(1)}, "{{ids | length}}", jsondiff.Options{})
+	if result.Passed {
+		t.Error("Equals validation should fail for a list compared with its length")
+	}
+	result = validator.validateEquals(float64(2), "{{ids | length}}", jsondiff.Options{})
+	if !result.Passed {
+		t.Errorf("Equals validation should pass for a piped length, got expected %v", result.Expected)
+	}
+	result = validator.validateEquals([]interface{}{float64(1), float64(2)}, "{{ids}}", jsondiff.Options{})
+	if !result.Passed {
+		t.Error("Equals validation should pass for a list variable")
+	}
 }
 
 func TestValidateContains(t *testing.T) {
@@ -965,5 +984,492 @@ func TestExtractJSONValueAllPatterns(t *testing.T) {
 	}
 }
 
+func TestValidateXML(t *testing.T) {
+	log := logger.New()
+	validator := NewValidator(log)
+	validator.varManager.Set("sku", "B")
+
+	response := &http.Response{
+		StatusCode: 200,
+		Body: []byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <r:Result xmlns:r="urn:result" code="OK">
+      <r:Item sku="A">1</r:Item>
+      <r:Item sku="B">5</r:Item>
+      <r:Note></r:Note>
+      <r:Active>true</r:Active>
+    </r:Result>
+  </soap:Body>
+</soap:Envelope>`),
+	}
+	namespaces := map[string]string{"x": "urn:result"}
+
+	tests := []struct {
+		name   string
+		rule   ValidationRule
+		passed bool
+	}{
+		{"equals attribute", ValidationRule{XML: "//x:Result/@code", Equals: "OK"}, true},
+		{"equals number", ValidationRule{XML: "//x:Item[@sku = 'B']", Equals: 5}, true},
+		{"equals with variable in path", ValidationRule{XML: "//x:Item[@sku = '{{sku}}']", Equals: "5"}, true},
+		{"equals count", ValidationRule{XML: "count(//x:Item)", Equals: 2}, true},
+		{"equals boolean", ValidationRule{XML: "sum(//x:Item) = 6", Equals: true}, true},
+		{"equals fails", ValidationRule{XML: "//x:Item[1]", Equals: "2"}, false},
+		{"contains", ValidationRule{XML: "//soap:Body", Contains: "5"}, true},
+		{"pattern", ValidationRule{XML: "//x:Item[1]/@sku", Pattern: "^[A-Z]$"}, true},
+		{"type number", ValidationRule{XML: "//x:Item[1]", Type: "number"}, true},
+		{"type boolean", ValidationRule{XML: "//x:Active", Type: "boolean"}, true},
+		{"type string", ValidationRule{XML: "//x:Result/@code", Type: "string"}, true},
+		{"type number fails", ValidationRule{XML: "//x:Result/@code", Type: "number"}, false},
+		{"type array", ValidationRule{XML: "//x:Item", Type: "array"}, true},
+		{"len", ValidationRule{XML: "//x:Item", Len: intPtr(2)}, true},
+		{"empty element", ValidationRule{XML: "//x:Note", Empty: boolPtr(true)}, true},
+		{"empty missing", ValidationRule{XML: "//x:Missing", Empty: boolPtr(true)}, true},
+		{"nil missing", ValidationRule{XML: "//x:Missing", Nil: boolPtr(true)}, true},
+		{"exists", ValidationRule{XML: "//x:Result"}, true},
+		{"exists fails", ValidationRule{XML: "//x:Missing"}, false},
+		{"document prefix", ValidationRule{XML: "//r:Item[2]/@sku", Equals: "B"}, true},
+		{"undeclared prefix", ValidationRule{XML: "//y:Item"}, false},
+		{"invalid expression", ValidationRule{XML: "//x:Item["}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.rule.Namespaces == nil {
+				tt.rule.Namespaces = namespaces
+			}
+			result := validator.validateRule(response, tt.rule)
+			if result.Passed != tt.passed {
+				t.Errorf("Expected passed=%v, got %+v", tt.passed, result)
+			}
+		})
+	}
+
+	invalid := &http.Response{StatusCode: 200, Body: []byte(`{"status": "ok"}`)}
+	if result := validator.validateRule(invalid, ValidationRule{XML: "/status"}); result.Passed || !strings.Contains(result.Error, "invalid XML") {
+		t.Errorf("Expected an invalid XML error, got %+v", result)
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	log := logger.New()
+	validator := NewValidator(log)
+
+	response := &http.Response{
+		StatusCode: 200,
+		Body:       []byte(`{"id": 7, "items": [{"sku": "A", "qty": 1}, {"sku": 5, "qty": 0}]}`),
+	}
+	schema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"id", "items"},
+		"properties": map[string]interface{}{
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"sku": map[string]interface{}{"type": "string"},
+						"qty": map[string]interface{}{"type": "integer", "minimum": 1},
+					},
+				},
+			},
+		},
+	}
+
+	result := validator.validateRule(response, ValidationRule{Schema: schema})
+	if result.Passed || len(result.Violations) != 2 {
+		t.Fatalf("Expected two violations, got %+v", result)
+	}
+	if result.Violations[0].InstancePath != "/items/1/qty" || result.Violations[1].InstancePath != "/items/1/sku" {
+		t.Errorf("Unexpected violations: %+v", result.Violations)
+	}
+	if !strings.Contains(result.Error, "/items/1/qty: must be >= 1, got 0") || !strings.Contains(result.Error, "/items/1/sku: expected string, got integer") {
+		t.Errorf("Expected every violation in the error, got %q", result.Error)
+	}
+
+	// The value at json is validated instead of the whole body
+	item := map[string]interface{}{"required": []interface{}{"sku"}, "properties": map[string]interface{}{"sku": map[string]interface{}{"type": "string"}}}
+	if result := validator.validateRule(response, ValidationRule{JSON: "$.items[0]", Schema: item}); !result.Passed {
+		t.Errorf("Expected the first item to be valid, got %+v", result)
+	}
+
+	// Schemas may be written as JSON strings
+	if result := validator.validateRule(response, ValidationRule{Schema: `{"required": ["missing"]}`}); result.Passed {
+		t.Errorf("Expected a missing property, got %+v", result)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "order.json")
+	if err := os.WriteFile(path, []byte(`{"properties": {"id": {"type": "string"}}}`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result = validator.validateRule(response, ValidationRule{SchemaFile: path})
+	if result.Passed || result.Expected != path || result.Violations[0].InstancePath != "/id" {
+		t.Errorf("Expected the schema file to be used, got %+v", result)
+	}
+
+	for _, rule := range []ValidationRule{
+		{SchemaFile: filepath.Join(dir, "missing.json")},
+		{Schema: map[string]interface{}{"type": "text"}},
+		{Schema: schema, SchemaFile: path},
+	} {
+		if result := validator.validateRule(response, rule); result.Passed || !strings.Contains(result.Error, "invalid schema") {
+			t.Errorf("Expected an invalid schema error, got %+v", result)
+		}
+	}
+}
+
+func TestValidateCustom(t *testing.T) {
+	log := logger.New()
+	validator := NewValidator(log)
+	validator.varManager.Set("expected_total", float64(2))
+	validator.varManager.Set("limit", float64(100))
+
+	response := &http.Response{
+		StatusCode: 200,
+		Headers:    map[string][]string{"X-Request-Id": {"abc"}},
+		Body:       []byte(`{"items": [{"price": 5}, {"price": 7}], "total": 2}`),
+		Duration:   80 * time.Millisecond,
+	}
+
+	result := validator.validateRule(response, ValidationRule{Custom: `
+if response.headers["x-request-id"] != "abc":
+    fail("unexpected request id")
+prices = [item["price"] for item in response.body["items"]]
+return len(prices) == vars["expected_total"] and duration < args["max_ms"], "prices %s" % prices
+`, Args: map[string]interface{}{"max_ms": "{{limit}}"}})
+	if !result.Passed || result.Type != "custom" || result.Actual != "prices [5, 7]" {
+		t.Errorf("Expected the script to pass, got %+v", result)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sorted.star")
+	if err := os.WriteFile(path, []byte(`
+prices = [item["price"] for item in response.body["items"]]
+if prices != sorted(prices, reverse = True):
+    fail("prices are not sorted descending:", prices)
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result = validator.validateRule(response, ValidationRule{CustomFile: path})
+	if result.Passed || result.Expected != path || result.Error != "custom validation failed: prices are not sorted descending: [5, 7]" {
+		t.Errorf("Expected the script file to fail, got %+v", result)
+	}
+
+	for _, rule := range []ValidationRule{
+		{CustomFile: filepath.Join(dir, "missing.star")},
+		{Custom: "if True"},
+		{Custom: "return True", CustomFile: path},
+	} {
+		if result := validator.validateRule(response, rule); result.Passed || !strings.Contains(result.Error, "invalid script") {
+			t.Errorf("Expected an invalid script error, got %+v", result)
+		}
+	}
+}
+
+func TestValidateHeaders(t *testing.T) {
+	log := logger.New()
+	validator := NewValidator(log)
+	validator.varManager.Set("request_id", "abc-123")
+	validator.varManager.Set("session_cookie_name", "session")
+	validator.varManager.Set("order_id", 17)
+
+	response := &http.Response{
+		StatusCode: 302,
+		Headers: map[string][]string{
+			"Content-Type":   {"text/html; charset=UTF-8"},
+			"X-Request-Id":   {"abc-123"},
+			"X-Rate-Remain":  {"42"},
+			"Vary":           {"Accept", "Origin"},
+			"Set-Cookie":     {"session=s3cr3t; Path=/; HttpOnly", "theme=dark"},
+			"location":       {"/login?next=%2Fcart"},
+			"Content-Length": {"27"},
+		},
+		Body:    []byte(`<html>Order 17 shipped</html>`),
+		Request: &http.SentRequest{Method: "GET", URL: "https://shop.example.com/cart"},
+	}
+
+	tests := []struct {
+		name   string
+		rule   ValidationRule
+		passed bool
+	}{
+		{name: "header exists", rule: ValidationRule{Header: "x-request-id"}, passed: true},
+		{name: "header equals variable", rule: ValidationRule{Header: "X-REQUEST-ID", Equals: "{{request_id}}"}, passed: true},
+		{name: "header number", rule: ValidationRule{Header: "X-Rate-Remain", Type: "number"}, passed: true},
+		{name: "header greater", rule: ValidationRule{Header: "X-Rate-Remain", Greater: 50}, passed: false},
+		{name: "header pattern", rule: ValidationRule{Header: "X-Request-Id", Pattern: "^[a-z]+-\\d+$"}, passed: true},
+		{name: "multi-value header", rule: ValidationRule{Header: "Vary", Len: intPtr(2)}, passed: true},
+		{name: "missing header", rule: ValidationRule{Header: "ETag"}, passed: false},
+		{name: "absent header", rule: ValidationRule{Header: "ETag", Nil: boolPtr(true)}, passed: true},
+		{name: "cookie", rule: ValidationRule{Cookie: "session", Equals: "s3cr3t"}, passed: true},
+		{name: "missing cookie", rule: ValidationRule{Cookie: "cart"}, passed: false},
+		{name: "cookie variable", rule: ValidationRule{Cookie: "{{session_cookie_name}}", Equals: "s3cr3t"}, passed: true},
+		{name: "content type", rule: ValidationRule{ContentType: "text/html"}, passed: true},
+		{name: "content type charset", rule: ValidationRule{ContentType: "text/html; charset=utf-8"}, passed: true},
+		{name: "content type wildcard", rule: ValidationRule{ContentType: "text/*"}, passed: true},
+		{name: "wrong content type", rule: ValidationRule{ContentType: "application/json"}, passed: false},
+		{name: "body contains", rule: ValidationRule{BodyContains: "Order 17"}, passed: true},
+		{name: "body pattern", rule: ValidationRule{BodyPattern: "Order \\d+ (shipped|delivered)"}, passed: true},
+		{name: "body pattern variable", rule: ValidationRule{BodyPattern: "Order {{order_id}} (shipped|delivered)"}, passed: true},
+		{name: "body missing text", rule: ValidationRule{BodyContains: "cancelled"}, passed: false},
+		{name: "redirect path", rule: ValidationRule{RedirectTo: "/login"}, passed: true},
+		{name: "redirect path and query", rule: ValidationRule{RedirectTo: "/login?next=%2Fcart"}, passed: true},
+		{name: "redirect url", rule: ValidationRule{RedirectTo: "https://shop.example.com/login?next=%2Fcart"}, passed: true},
+		{name: "wrong redirect", rule: ValidationRule{RedirectTo: "/home"}, passed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validator.validateRule(response, tt.rule)
+			if result.Passed != tt.passed {
+				t.Errorf("Expected passed %v, got %+v", tt.passed, result)
+			}
+		})
+	}
+
+	// Redirects that were followed are checked by the last one
+	followed := &http.Response{StatusCode: 200, Redirects: []string{"https://shop.example.com/login", "https://shop.example.com/welcome"}}
+	if result := validator.validateRule(followed, ValidationRule{RedirectTo: "/welcome"}); !result.Passed {
+		t.Errorf("Expected the last redirect to match, got %+v", result)
+	}
+	direct := &http.Response{StatusCode: 200}
+	if result := validator.validateRule(direct, ValidationRule{RedirectTo: "/welcome"}); result.Passed || result.Error != "redirect validation failed: expected a redirect to /welcome, got status 200 without redirect" {
+		t.Errorf("Expected no redirect, got %+v", result)
+	}
+}
+
+func TestValidateCombinators(t *testing.T) {
+	log := logger.New()
+	validator := NewValidator(log)
+	validator.varManager.Set("user", "alice")
+
+	response := &http.Response{
+		StatusCode: 201,
+		Headers:    map[string][]string{"Content-Type": {"application/json"}},
+		Body:       []byte(`{"id": 7, "name": "alice", "deleted": false}`),
+		Duration:   5 * time.Millisecond,
+	}
+
+	tests := []struct {
+		name   string
+		rule   ValidationRule
+		passed bool
+	}{
+		{name: "any of passes", rule: ValidationRule{AnyOf: []ValidationRule{{Status: 200}, {Status: 201}}}, passed: true},
+		{name: "any of fails", rule: ValidationRule{AnyOf: []ValidationRule{{Status: 200}, {Status: 204}}}, passed: false},
+		{name: "all of passes", rule: ValidationRule{AllOf: []ValidationRule{{Status: 201}, {JSON: "$.id", Type: "number"}}}, passed: true},
+		{name: "all of fails", rule: ValidationRule{AllOf: []ValidationRule{{Status: 201}, {JSON: "$.id", Type: "string"}}}, passed: false},
+		{name: "not passes", rule: ValidationRule{Not: &ValidationRule{JSON: "$.deleted", Equals: true}}, passed: true},
+		{name: "not fails", rule: ValidationRule{Not: &ValidationRule{Status: 201}}, passed: false},
+		{name: "nested", rule: ValidationRule{AnyOf: []ValidationRule{
+			{AllOf: []ValidationRule{{Status: 201}, {Not: &ValidationRule{JSON: "$.name", Equals: "bob"}}}},
+			{Status: 200},
+		}}, passed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validator.validateRule(response, tt.rule)
+			if result.Passed != tt.passed {
+				t.Errorf("Expected passed %v, got %+v", tt.passed, result)
+			}
+		})
+	}
+
+	result := validator.validateRule(response, ValidationRule{AnyOf: []ValidationRule{{Status: 200}, {Status: 204}}})
+	if len(result.Results) != 2 || result.Error != "any_of validation failed: no rule passed: status code validation failed: expected 200, got 201; status code validation failed: expected 204, got 201" {
+		t.Errorf("Expected the errors of both rules, got %+v", result)
+	}
+
+	// A message replaces the error of a failing rule
+	result = validator.validateRule(response, ValidationRule{JSON: "$.name", Equals: "bob", Message: "expected {{user}} to be renamed"})
+	if result.Passed || result.Error != "expected alice to be renamed" {
+		t.Errorf("Expected the rule message, got %+v", result)
+	}
+
+	// A failing rule of severity warn is a warning, not a failure
+	results, err := validator.Validate(response, []ValidationRule{
+		{Status: 201},
+		{Time: "< 1ms", Severity: SeverityWarn},
+		{JSON: "$.name", Equals: "alice", Severity: SeverityWarn},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results[0].Failed() || results[0].Warning() {
+		t.Errorf("Expected the status rule to pass, got %+v", results[0])
+	}
+	if results[1].Failed() || !results[1].Warning() || results[1].Severity != SeverityWarn {
+		t.Errorf("Expected a warning, got %+v", results[1])
+	}
+	if results[2].Failed() || results[2].Warning() {
+		t.Errorf("Expected a passing warn rule not to be a warning, got %+v", results[2])
+	}
+
+	result = validator.validateRule(response, ValidationRule{Status: 201, Severity: "info"})
+	if result.Passed || result.Error != `invalid severity "info": expected error or warn` {
+		t.Errorf("Expected an invalid severity, got %+v", result)
+	}
+
+	// A rule that cannot be evaluated fails not, rather than passing it
+	for _, nested := range []ValidationRule{
+		{Equals: 201},
+		{JSON: "$.missing", Equals: 1},
+		{Status: 200, Severity: "info"},
+	} {
+		result = validator.validateRule(response, ValidationRule{Not: &nested})
+		if result.Passed || !strings.HasPrefix(result.Error, "not validation failed: ") {
+			t.Errorf("Expected not of %+v to fail, got %+v", nested, result)
+		}
+	}
+	result = validator.validateRule(response, ValidationRule{Not: &ValidationRule{Equals: 201}})
+	if result.Error != "not validation failed: no validation rule matched" {
+		t.Errorf("Expected the error of the unknown rule, got %+v", result)
+	}
+
+	// Only the outer rule has a severity
+	result = validator.validateRule(response, ValidationRule{AnyOf: []ValidationRule{{Status: 200, Severity: SeverityWarn}, {Status: 204}}})
+	if result.Passed || len(result.Results) != 2 || result.Results[0].Actual != "invalid rule" {
+		t.Errorf("Expected a nested severity to be an invalid rule, got %+v", result)
+	}
+	result = validator.validateRule(response, ValidationRule{AllOf: []ValidationRule{{Status: 201}, {Status: 200}}, Severity: SeverityWarn})
+	if result.Failed() || !result.Warning() {
+		t.Errorf("Expected the severity of the outer rule to apply, got %+v", result)
+	}
+}
+
+func TestValidateElements(t *testing.T) {
+	log := logger.New()
+	validator := NewValidator(log)
+	validator.varManager.Set("created_id", 3)
+
+	response := &http.Response{
+		StatusCode: 200,
+		Body: []byte(`{"items": [
+			{"id": 1, "status": "active", "tags": ["a"]},
+			{"id": 2, "status": "inactive", "tags": []},
+			{"id": 3, "status": "active", "tags": ["b", "c"]}
+		], "names": ["ann", "bob"], "empty": [], "total": 3}`),
+	}
+
+	tests := []struct {
+		name   string
+		rule   ValidationRule
+		passed bool
+		error  string
+	}{
+		{name: "each passes", rule: ValidationRule{JSON: "$.items", Each: []ValidationRule{{JSON: "$.id", Type: "number"}, {JSON: "$.status", Pattern: "active$"}}}, passed: true},
+		{name: "each fails", rule: ValidationRule{JSON: "$.items", Each: []ValidationRule{{JSON: "$.status", Equals: "active"}}},
+			error: `each validation failed: element 1 ({"id":2,"status":"inactive","tags":[]}): value validation failed: expected active, got inactive`},
+		{name: "each scalar", rule: ValidationRule{JSON: "$.names", Each: []ValidationRule{{JSON: "$", Type: "string"}, {JSON: "$", Len: intPtr(3)}}}, passed: true},
+		{name: "each empty array", rule: ValidationRule{JSON: "$.empty", Each: []ValidationRule{{JSON: "$", Type: "string"}}}, passed: true},
+		{name: "some passes", rule: ValidationRule{JSON: "$.items", Some: []ValidationRule{{JSON: "$.id", Equals: "{{created_id}}"}, {JSON: "$.tags", Len: intPtr(2)}}}, passed: true},
+		{name: "some fails", rule: ValidationRule{JSON: "$.items", Some: []ValidationRule{{JSON: "$.id", Equals: 4}}},
+			error: `some validation failed: none of 3 elements passed, first: element 0 ({"id":1,"status":"active","tags":["a"]}): value validation failed: expected 4, got 1`},
+		{name: "some empty array", rule: ValidationRule{JSON: "$.empty", Some: []ValidationRule{{JSON: "$", Type: "string"}}},
+			error: "some validation failed: the array is empty"},
+		{name: "not an array", rule: ValidationRule{JSON: "$.total", Each: []ValidationRule{{JSON: "$", Type: "number"}}},
+			error: "each validation failed: expected an array at $.total, got 3"},
+		{name: "nested", rule: ValidationRule{JSON: "$.items", Some: []ValidationRule{{JSON: "$.tags", Each: []ValidationRule{{JSON: "$", Pattern: "^[bc]$"}}}, {JSON: "$.tags", Empty: boolPtr(false)}}}, passed: true},
+		{name: "nested severity", rule: ValidationRule{JSON: "$.items", Each: []ValidationRule{{JSON: "$.id", Type: "number", Severity: SeverityWarn}}},
+			error: `each validation failed: severity "warn" of a nested rule: only the outer rule can have a severity`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validator.validateRule(response, tt.rule)
+			if result.Passed != tt.passed || result.Error != tt.error {
+				t.Errorf("Expected passed %v and error %q, got %+v", tt.passed, tt.error, result)
+			}
+		})
+	}
+}
+
+func TestValidateSnapshot(t *testing.T) {
+	log := logger.New()
+	validator := NewValidator(log)
+	dir := t.TempDir()
+
+	first := &http.Response{StatusCode: 200, Body: []byte(`{"id": 1, "name": "Ann", "tags": ["a"], "created_at": "2024-01-01"}`)}
+	second := &http.Response{StatusCode: 200, Body: []byte(`{"id": 2, "name": "Bob", "tags": ["a"], "created_at": "2024-02-02", "role": "admin"}`)}
+	rule := ValidationRule{Snapshot: "user", Ignore: []string{"$.id", "$.created_at"}, SnapshotDir: dir}
+
+	// A missing snapshot is written
+	if result := validator.validateRule(first, rule); !result.Passed || result.Actual != "snapshot written" {
+		t.Fatalf("Expected the snapshot to be written, got %+v", result)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "user.json")); err != nil || !strings.Contains(string(data), "\n  \"name\": \"Ann\",\n") {
+		t.Fatalf("Expected an indented snapshot, got %q (%v)", data, err)
+	}
+
+	if result := validator.validateRule(first, rule); !result.Passed || result.Actual != "snapshot matches" {
+		t.Errorf("Expected the snapshot to match, got %+v", result)
+	}
+
+	result := validator.validateRule(second, rule)
+	want := `snapshot validation failed: 2 differences from __snapshots__/user.json: $.name: expected "Ann", got "Bob"; $.role: unexpected "admin"`
+	if result.Passed || result.Error != want || len(result.Diff) != 2 {
+		t.Errorf("Expected the differences, got %+v", result)
+	}
+
+	validator.SetUpdateSnapshots(true)
+	if result := validator.validateRule(second, rule); !result.Passed || result.Actual != "snapshot updated" {
+		t.Errorf("Expected the snapshot to be updated, got %+v", result)
+	}
+	validator.SetUpdateSnapshots(false)
+	if result := validator.validateRule(second, rule); !result.Passed {
+		t.Errorf("Expected the updated snapshot to match, got %+v", result)
+	}
+
+	// Other files are compared as text
+	text := ValidationRule{Snapshot: "page.html", SnapshotDir: dir}
+	validator.validateRule(&http.Response{Body: []byte("<h1>Title</h1>\n<p>One</p>\n")}, text)
+	result = validator.validateRule(&http.Response{Body: []byte("<h1>Title</h1>\n<p>Two</p>\n")}, text)
+	if result.Passed || result.Error != `snapshot validation failed: line 2 differs from __snapshots__/page.html: expected "<p>One</p>", got "<p>Two</p>"` {
+		t.Errorf("Expected the first different line, got %+v", result)
+	}
+
+	if result := validator.validateRule(&http.Response{Body: []byte("<h1>Title</h1>")}, rule); result.Passed {
+		t.Errorf("Expected a body that is not JSON to fail a JSON snapshot, got %+v", result)
+	}
+}
+
+func TestValidateEqualsStructure(t *testing.T) {
+	log := logger.New()
+	validator := NewValidator(log)
+	validator.varManager.Set("user_id", 7)
+
+	response := &http.Response{
+		StatusCode: 200,
+		Body:       []byte(`{"user": {"id": 7, "name": "Ann", "roles": ["admin", "dev"], "score": 4.98, "created_at": "2024-01-01"}}`),
+	}
+	user := map[string]interface{}{"id": "{{user_id}}", "name": "Ann", "roles": []interface{}{"dev", "admin"}, "score": 5}
+
+	tests := []struct {
+		name  string
+		rule  ValidationRule
+		error string
+	}{
+		{name: "differences", rule: ValidationRule{JSON: "$.user", Equals: user},
+			error: `value validation failed: 4 differences: $.roles[0]: expected "dev", got "admin"; $.roles[1]: expected "admin", got "dev"; $.score: expected 5, got 4.98; $.created_at: unexpected "2024-01-01"`},
+		{name: "options", rule: ValidationRule{JSON: "$.user", Equals: user, IgnoreOrder: true, IgnoreExtraFields: true, Tolerance: 0.05}},
+		{name: "ignore", rule: ValidationRule{JSON: "$.user", Equals: user, IgnoreOrder: true, Ignore: []string{"$.score", "$.created_at"}}},
+		{name: "type mismatch", rule: ValidationRule{JSON: "$.user.roles", Equals: "admin"},
+			error: `value validation failed: 1 difference: $: expected string "admin", got array ["admin","dev"]`},
+		{name: "scalar tolerance", rule: ValidationRule{JSON: "$.user.score", Equals: 5, Tolerance: 0.05}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validator.validateRule(response, tt.rule)
+			if result.Passed != (tt.error == "") || result.Error != tt.error {
+				t.Errorf("Expected error %q, got %+v", tt.error, result)
+			}
+			if !result.Passed && len(result.Diff) == 0 {
+				t.Errorf("Expected the differences in the result, got %+v", result)
+			}
+		})
+	}
+}
+
 func boolPtr(b bool) *bool { return &b }
 func intPtr(i int) *int    { return &i }
@@ -0,0 +1,113 @@
+package variables
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/faker"
+)
+
+// SetFaker sets the faker that generates {{faker.*}} values in this scope and its children
+func (m *Manager) SetFaker(f *faker.Faker) {
+	m.mu.Lock()
+	m.faker = f
+	m.mu.Unlock()
+}
+
+// fakerSource returns the faker of this scope or of the nearest parent that has one.
+// Without one, the root scope gets an unseeded faker.
+func (m *Manager) fakerSource() *faker.Faker {
+	root := m
+	for scope := m; scope != nil; scope = scope.parent {
+		scope.mu.RLock()
+		f := scope.faker
+		scope.mu.RUnlock()
+		if f != nil {
+			return f
+		}
+		root = scope
+	}
+
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	if root.faker == nil {
+		root.faker, _ = faker.New(time.Now().UnixNano(), "")
+	}
+	return root.faker
+}
+
+// substituteFakerFunctions substitutes {{faker.function}} and {{faker.function(args...)}}
+// patterns. Arguments are written like the arguments of utils functions. Unknown functions
+// and calls that fail are left as they are.
+func (m *Manager) substituteFakerFunctions(input string) string {
+	const prefix = "{{faker."
+
+	var result strings.Builder
+	rest := input
+	for {
+		start := strings.Index(rest, prefix)
+		if start < 0 {
+			result.WriteString(rest)
+			return result.String()
+		}
+		result.WriteString(rest[:start])
+		rest = rest[start:]
+
+		name, rawArgs, length, ok := parseFakerCall(rest[len(prefix):])
+		end := len(prefix) + length
+		if !ok || !strings.HasPrefix(strings.TrimLeft(rest[end:], " "), "}}") {
+			result.WriteString(prefix)
+			rest = rest[len(prefix):]
+			continue
+		}
+		end += strings.Index(rest[end:], "}}") + 2
+
+		value, err := m.callFaker(name, rawArgs)
+		if err != nil {
+			m.logger.Warn("Faker function failed", "function", name, "error", err)
+			result.WriteString(rest[:end])
+		} else {
+			result.WriteString(value)
+		}
+		rest = rest[end:]
+	}
+}
+
+// parseFakerCall parses name or name(args...) at the start of s, returning the name,
+// the raw arguments and the length of the call
+func parseFakerCall(s string) (name string, args []string, length int, ok bool) {
+	s = strings.TrimLeft(s, " ")
+	offset := len(s)
+	for i, r := range s {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			offset = i
+			break
+		}
+	}
+	if offset == 0 {
+		return "", nil, 0, false
+	}
+	name = s[:offset]
+	if offset == len(s) || s[offset] != '(' {
+		return name, nil, offset, true
+	}
+
+	args, closing, ok := splitArguments(s[offset+1:])
+	if !ok {
+		return "", nil, 0, false
+	}
+	return name, args, offset + 1 + closing + 1, true
+}
+
+// callFaker evaluates the arguments of a faker function and calls it
+func (m *Manager) callFaker(name string, rawArgs []string) (string, error) {
+	args := make([]string, len(rawArgs))
+	for i, raw := range rawArgs {
+		value, err := m.evaluateArgument(raw)
+		if err != nil {
+			return "", err
+		}
+		args[i] = value
+	}
+	return m.fakerSource().Generate(name, args...)
+}
@@ -0,0 +1,325 @@
+package variables
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/utils"
+)
+
+// utilsFunction is a function available as {{utils.name(args...)}}
+type utilsFunction struct {
+	minArgs, maxArgs int
+	call             func(m *Manager, args []string) (string, error)
+}
+
+// utilsFunctions are the functions available as {{utils.name(args...)}}
+var utilsFunctions = map[string]utilsFunction{
+	"base64":        {1, 1, func(_ *Manager, args []string) (string, error) { return utils.Base64Encode(args[0]), nil }},
+	"base64_decode": {1, 1, func(_ *Manager, args []string) (string, error) { return utils.Base64Decode(args[0]) }},
+	"sha256":        {1, 1, func(_ *Manager, args []string) (string, error) { return utils.SHA256(args[0]), nil }},
+	"md5":           {1, 1, func(_ *Manager, args []string) (string, error) { return utils.MD5(args[0]), nil }},
+	"hmac_sha256":   {2, 2, func(_ *Manager, args []string) (string, error) { return utils.HMACSHA256(args[0], args[1]), nil }},
+	"hex":           {1, 1, func(_ *Manager, args []string) (string, error) { return utils.Hex(args[0]), nil }},
+	"url_encode":    {1, 1, func(_ *Manager, args []string) (string, error) { return utils.URLEncode(args[0]), nil }},
+	"json_encode":   {1, 1, func(_ *Manager, args []string) (string, error) { return utils.JSONEncode(args[0]), nil }},
+	"jwt_sign":      {3, 3, jwtSign},
+	"jwt_decode":    {1, 2, jwtDecode},
+	"now":           {0, 1, now},
+	"now_add":       {1, 2, nowAdd},
+	"unix_ms": {0, 0, func(_ *Manager, _ []string) (string, error) {
+		return strconv.FormatInt(time.Now().UnixMilli(), 10), nil
+	}},
+	"uuid_v7":       {0, 0, func(_ *Manager, _ []string) (string, error) { return utils.UUIDv7() }},
+	"random_string": {1, 1, randomString},
+	"upper":         {1, 1, func(_ *Manager, args []string) (string, error) { return strings.ToUpper(args[0]), nil }},
+	"lower":         {1, 1, func(_ *Manager, args []string) (string, error) { return strings.ToLower(args[0]), nil }},
+	"trim":          {1, 1, func(_ *Manager, args []string) (string, error) { return strings.TrimSpace(args[0]), nil }},
+	"substr":        {2, 3, substr},
+}
+
+// identifier matches argument names that may refer to a variable
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// substituteUtilsFunctions substitutes {{utils.function(args...)}} patterns. Arguments
+// are separated by commas and may be quoted strings, {{variable}} references, variable
+// names, nested function calls such as utils.sha256("x"), or plain text.
+// Calls that fail are left as they are.
+func (m *Manager) substituteUtilsFunctions(input string) string {
+	const prefix = "{{utils."
+
+	var result strings.Builder
+	rest := input
+	for {
+		start := strings.Index(rest, prefix)
+		if start < 0 {
+			result.WriteString(rest)
+			return result.String()
+		}
+		result.WriteString(rest[:start])
+		rest = rest[start:]
+
+		name, args, length, ok := parseUtilsCall(rest[2:])
+		end := 2 + length
+		if !ok || !strings.HasPrefix(strings.TrimLeft(rest[end:], " "), "}}") {
+			// Not a well-formed call, keep the text as is
+			result.WriteString(prefix)
+			rest = rest[len(prefix):]
+			continue
+		}
+		end += strings.Index(rest[end:], "}}") + 2
+
+		value, err := m.callUtils(name, args)
+		if err != nil {
+			m.logger.Warn("utils function failed", "function", name, "error", err)
+			result.WriteString(rest[:end])
+		} else {
+			result.WriteString(value)
+		}
+		rest = rest[end:]
+	}
+}
+
+// parseUtilsCall parses a call such as utils.name(a, "b", utils.c(d)) at the start of s.
+// It returns the function name, the raw arguments and the length of the call.
+func parseUtilsCall(s string) (name string, args []string, length int, ok bool) {
+	body := strings.TrimPrefix(s, "utils.")
+	offset := len(s) - len(body)
+
+	open := strings.Index(body, "(")
+	if open <= 0 {
+		return "", nil, 0, false
+	}
+	name = body[:open]
+	for _, r := range name {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return "", nil, 0, false
+		}
+	}
+
+	args, closing, ok := splitArguments(body[open+1:])
+	if !ok {
+		return "", nil, 0, false
+	}
+	// Functions of one argument take all the text, as before arguments were split, so
+	// utils.base64(user,pass) encodes "user,pass"
+	if fn, known := utilsFunctions[name]; known && fn.maxArgs == 1 && len(args) > 1 {
+		args = []string{strings.TrimSpace(body[open+1 : open+1+closing])}
+	}
+	return name, args, offset + open + 1 + closing + 1, true
+}
+
+// splitArguments splits a comma-separated argument list up to its closing parenthesis,
+// respecting quotes and nested (), [], {} and {{...}}. It returns the raw arguments and
+// the index of the closing parenthesis.
+func splitArguments(s string) ([]string, int, bool) {
+	var args []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		case ')':
+			if depth == 0 {
+				arg := strings.TrimSpace(s[start:i])
+				if arg != "" || len(args) > 0 {
+					args = append(args, arg)
+				}
+				return args, i, true
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return nil, 0, false
+}
+
+// callUtils evaluates the arguments of a utils function and calls it
+func (m *Manager) callUtils(name string, rawArgs []string) (string, error) {
+	fn, exists := utilsFunctions[name]
+	if !exists {
+		return "", fmt.Errorf("unknown utils function %q", name)
+	}
+	if len(rawArgs) < fn.minArgs || len(rawArgs) > fn.maxArgs {
+		if fn.minArgs == fn.maxArgs {
+			return "", fmt.Errorf("%s expects %d arguments, got %d", name, fn.minArgs, len(rawArgs))
+		}
+		return "", fmt.Errorf("%s expects %d to %d arguments, got %d", name, fn.minArgs, fn.maxArgs, len(rawArgs))
+	}
+
+	args := make([]string, len(rawArgs))
+	for i, raw := range rawArgs {
+		value, err := m.evaluateArgument(raw)
+		if err != nil {
+			return "", err
+		}
+		args[i] = value
+	}
+	return fn.call(m, args)
+}
+
+// evaluateArgument returns the value of a raw utils function argument
+func (m *Manager) evaluateArgument(raw string) (string, error) {
+	// Quoted string; templates inside are substituted
+	if len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && closingQuote(raw) == len(raw)-1 {
+		return m.Substitute(unquote(raw[1 : len(raw)-1]))
+	}
+
+	// Nested call
+	if name, args, length, ok := parseUtilsCall(raw); ok && length == len(raw) {
+		if _, known := utilsFunctions[name]; known {
+			return m.callUtils(name, args)
+		}
+	}
+
+	// Variable name
+	if identifier.MatchString(raw) {
+		if value, exists := m.Get(raw); exists {
+			return formatValue(value), nil
+		}
+	}
+
+	return m.Substitute(raw)
+}
+
+// closingQuote returns the index of the quote closing the string that starts s
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		} else if s[i] == s[0] {
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote resolves backslash escapes in a quoted argument. Other backslashes are kept,
+// so patterns such as "\d+" can be written without doubling them.
+func unquote(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '\\', '"', '\'':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func jwtSign(m *Manager, args []string) (string, error) {
+	var claims map[string]interface{}
+	if err := json.Unmarshal([]byte(args[2]), &claims); err != nil {
+		return "", fmt.Errorf("jwt_sign: claims must be a JSON object: %w", err)
+	}
+	token, err := utils.JWTSign(args[0], args[1], claims)
+	if err != nil {
+		return "", err
+	}
+	// Signed tokens are credentials
+	m.sensitive(token)
+	return token, nil
+}
+
+func jwtDecode(_ *Manager, args []string) (string, error) {
+	claims, err := utils.JWTDecode(args[0])
+	if err != nil {
+		return "", err
+	}
+	if len(args) == 2 {
+		value, exists := claims[args[1]]
+		if !exists {
+			return "", fmt.Errorf("jwt_decode: claim %q not found", args[1])
+		}
+		return formatValue(value), nil
+	}
+	return formatValue(claims), nil
+}
+
+func now(_ *Manager, args []string) (string, error) {
+	format := ""
+	if len(args) == 1 {
+		format = args[0]
+	}
+	return utils.FormatTime(time.Now(), format), nil
+}
+
+func nowAdd(_ *Manager, args []string) (string, error) {
+	d, err := utils.ParseDuration(args[0])
+	if err != nil {
+		return "", err
+	}
+	format := ""
+	if len(args) == 2 {
+		format = args[1]
+	}
+	return utils.FormatTime(time.Now().Add(d), format), nil
+}
+
+func randomString(_ *Manager, args []string) (string, error) {
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "", fmt.Errorf("random_string: invalid length %q", args[0])
+	}
+	return utils.RandomString(n)
+}
+
+func substr(_ *Manager, args []string) (string, error) {
+	runes := []rune(args[0])
+	start, err := strconv.Atoi(args[1])
+	if err != nil {
+		return "", fmt.Errorf("substr: invalid start %q", args[1])
+	}
+	if start < 0 {
+		start += len(runes)
+	}
+	start = min(max(start, 0), len(runes))
+
+	end := len(runes)
+	if len(args) == 3 {
+		length, err := strconv.Atoi(args[2])
+		if err != nil || length < 0 {
+			return "", fmt.Errorf("substr: invalid length %q", args[2])
+		}
+		end = min(start+length, len(runes))
+	}
+	return string(runes[start:end]), nil
+}
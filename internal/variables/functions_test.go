@@ -0,0 +1,118 @@
+package variables
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/logger"
+	"github.com/cjp2600/stepwise/internal/utils"
+)
+
+func TestUtilsFunctions(t *testing.T) {
+	vm := NewManager(logger.New())
+	vm.Set("body", `{"id":1}`)
+	vm.Set("name", "  Alice  ")
+	vm.Set("purchase_id", "purchase_12345")
+
+	tests := map[string]string{
+		`{{utils.sha256("abc")}}`: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		`{{utils.md5('abc')}}`:    "900150983cd24fb0d6963f7d28e17f72",
+		`{{utils.hmac_sha256("key", "The quick brown fox jumps over the lazy dog")}}`: "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		`{{utils.hex("hi")}}`:                             "6869",
+		`{{utils.url_encode("a b&c=d")}}`:                 "a+b%26c%3Dd",
+		`{{utils.json_encode("say \"hi\"")}}`:             `"say \"hi\""`,
+		`{{utils.upper(abc)}}`:                            "ABC",
+		`{{utils.lower("ABC")}}`:                          "abc",
+		`{{utils.trim({{name}})}}`:                        "Alice",
+		`{{utils.substr("abcdef", 1, 3)}}`:                "bcd",
+		`{{utils.substr("abcdef", -2)}}`:                  "ef",
+		`{{utils.base64(purchase_id)}}`:                   "cHVyY2hhc2VfMTIzNDU=",
+		`sig={{utils.hmac_sha256("k", {{body}})}}`:        "sig=" + utils.HMACSHA256("k", `{"id":1}`),
+		`{{utils.upper(utils.base64("hello"))}}`:          "AGVSBG8=",
+		`{{utils.sha256(utils.lower("ABC"))}}`:            "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		`{{utils.base64("a, b")}} and {{utils.hex("c")}}`: "YSwgYg== and 63",
+		`{{utils.base64(user,pass)}}`:                     "dXNlcixwYXNz",
+		`{{utils.upper(a, b)}}`:                           "A, B",
+	}
+	for input, want := range tests {
+		got, err := vm.Substitute(input)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", input, err)
+		} else if got != want {
+			t.Errorf("%s: expected %q, got %q", input, want, got)
+		}
+	}
+}
+
+func TestUtilsFunctionErrorsLeaveCall(t *testing.T) {
+	vm := NewManager(logger.New())
+	for _, input := range []string{
+		`{{utils.unknown("x")}}`,
+		`{{utils.hmac_sha256("k")}}`,
+		`{{utils.base64_decode("%%%")}}`,
+		`{{utils.random_string(many)}}`,
+	} {
+		if got, _ := vm.Substitute(input); got != input {
+			t.Errorf("Expected %s to be left as is, got %q", input, got)
+		}
+	}
+}
+
+func TestUtilsJWT(t *testing.T) {
+	vm := NewManager(logger.New())
+	vm.Set("claims", map[string]interface{}{"sub": "user-1", "admin": true})
+
+	token, _ := vm.Substitute(`{{utils.jwt_sign("HS256", "secret", {"sub": "user-1", "n": 2})}}`)
+	if !regexp.MustCompile(`^[\w-]+\.[\w-]+\.[\w-]+$`).MatchString(token) {
+		t.Fatalf("Unexpected token: %q", token)
+	}
+	vm.Set("token", token)
+	if sub, _ := vm.Substitute(`{{utils.jwt_decode({{token}}, "sub")}}`); sub != "user-1" {
+		t.Errorf("Expected sub claim user-1, got %q", sub)
+	}
+	if claims, _ := vm.Substitute(`{{utils.jwt_decode(token)}}`); claims != `{"n":2,"sub":"user-1"}` {
+		t.Errorf("Unexpected claims: %q", claims)
+	}
+
+	// Claims may come from a variable holding an object
+	token, _ = vm.Substitute(`{{utils.jwt_sign(HS256, "secret", {{claims}})}}`)
+	vm.Set("token", token)
+	if admin, _ := vm.Substitute(`{{utils.jwt_decode(token, admin)}}`); admin != "true" {
+		t.Errorf("Expected admin claim true, got %q", admin)
+	}
+}
+
+func TestUtilsTimeAndIDs(t *testing.T) {
+	vm := NewManager(logger.New())
+
+	before := time.Now().UnixMilli()
+	ms, _ := vm.Substitute("{{utils.unix_ms()}}")
+	if n, err := strconv.ParseInt(ms, 10, 64); err != nil || n < before {
+		t.Errorf("Unexpected unix_ms: %q", ms)
+	}
+
+	date, _ := vm.Substitute(`{{utils.now("date")}}`)
+	if date != time.Now().Format("2006-01-02") {
+		t.Errorf("Unexpected date: %q", date)
+	}
+	tomorrow, _ := vm.Substitute(`{{utils.now_add("1d", "2006-01-02")}}`)
+	if tomorrow != time.Now().AddDate(0, 0, 1).Format("2006-01-02") {
+		t.Errorf("Unexpected date: %q", tomorrow)
+	}
+	stamp, _ := vm.Substitute(`{{utils.now_add(-90m)}}`)
+	if parsed, err := time.Parse(time.RFC3339, stamp); err != nil || time.Since(parsed) < 89*time.Minute {
+		t.Errorf("Unexpected timestamp: %q", stamp)
+	}
+
+	uuid, _ := vm.Substitute("{{utils.uuid_v7()}}")
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(uuid) {
+		t.Errorf("Unexpected uuid_v7: %q", uuid)
+	}
+
+	random, _ := vm.Substitute("{{utils.random_string(16)}}")
+	if !regexp.MustCompile(`^[A-Za-z0-9]{16}$`).MatchString(random) {
+		t.Errorf("Unexpected random string: %q", random)
+	}
+}
@@ -0,0 +1,235 @@
+package variables
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/cjp2600/stepwise/internal/expression"
+	"github.com/cjp2600/stepwise/internal/filters"
+	"github.com/cjp2600/stepwise/internal/jsonpath"
+)
+
+// Resolve returns the value of a template reference, keeping its type. The reference
+// is what is written between {{ and }}: a variable, a field such as user.address.city,
+// or a pipe through filters such as "price | default:0 | round:2".
+func (m *Manager) Resolve(ref string) (interface{}, bool) {
+	stages := splitTopLevel(ref, '|')
+	if len(stages) == 1 {
+		return m.lookup(strings.TrimSpace(ref))
+	}
+
+	value, exists := m.resolveOperand(stages[0])
+	for _, stage := range stages[1:] {
+		name, args := m.parseFilter(stage)
+		// A missing value only makes it through a default
+		if !exists && name != "default" {
+			m.logger.Warn("Variable not found", "variable", strings.TrimSpace(stages[0]))
+			return nil, false
+		}
+
+		result, err := filters.Apply(name, value, args)
+		if err != nil {
+			m.logger.Warn("Filter failed", "reference", ref, "error", err)
+			return nil, false
+		}
+		value, exists = result, true
+	}
+	return value, exists
+}
+
+// lookup returns a variable or a value inside an object or list variable. The path
+// after the variable name is a JSONPath as in validations, so user.address.city,
+// items[0].sku, items[-1], items[*].sku and items[?(@.sku == "A")].qty all work.
+func (m *Manager) lookup(name string) (interface{}, bool) {
+	if value, exists := m.Get(name); exists {
+		return value, true
+	}
+
+	// The longest prefix that is a variable wins, since names may contain dots
+	for i := len(name) - 1; i > 0; i-- {
+		if name[i] != '.' && name[i] != '[' {
+			continue
+		}
+		value, exists := m.Get(strings.TrimSpace(name[:i]))
+		if !exists {
+			continue
+		}
+		// Objects captured as JSON text are decoded
+		if text, ok := value.(string); ok {
+			var decoded interface{}
+			if err := json.Unmarshal([]byte(text), &decoded); err == nil {
+				value = decoded
+			}
+		}
+		if result, err := jsonpath.Extract(value, "$"+name[i:]); err == nil {
+			return result, true
+		}
+		break
+	}
+	return expression.LookupPath(m.Get, name)
+}
+
+// substitutePipes substitutes {{value | filter...}} patterns. Pipes that fail are
+// left as they are.
+func (m *Manager) substitutePipes(input string) string {
+	var result strings.Builder
+	rest := input
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			result.WriteString(rest)
+			return result.String()
+		}
+		end := templateEnd(rest[start:])
+		if end < 0 {
+			result.WriteString(rest)
+			return result.String()
+		}
+		end += start
+
+		inner := rest[start+2 : end-2]
+		result.WriteString(rest[:start])
+		if len(splitTopLevel(inner, '|')) > 1 {
+			if value, exists := m.Resolve(inner); exists {
+				result.WriteString(formatValue(value))
+				rest = rest[end:]
+				continue
+			}
+		}
+		result.WriteString(rest[start:end])
+		rest = rest[end:]
+	}
+}
+
+// templateEnd returns the length of the {{...}} template at the start of s, counting
+// nested templates, or -1 if it is not closed
+func templateEnd(s string) int {
+	depth := 0
+	for i := 0; i+1 < len(s); i++ {
+		switch s[i : i+2] {
+		case "{{":
+			depth++
+			i++
+		case "}}":
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits s on sep outside quotes and brackets. A doubled "||" is not a separator.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case sep:
+			if depth != 0 {
+				continue
+			}
+			if sep == '|' && i+1 < len(s) && s[i+1] == '|' {
+				i++
+				continue
+			}
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseFilter parses a pipe stage such as "round:2" or "default:'n/a'" into the filter
+// name and its evaluated arguments
+func (m *Manager) parseFilter(stage string) (string, []interface{}) {
+	name, rawArgs, found := strings.Cut(strings.TrimSpace(stage), ":")
+	name = strings.TrimSpace(name)
+	if !found {
+		return name, nil
+	}
+
+	var args []interface{}
+	for _, raw := range splitTopLevel(rawArgs, ',') {
+		value, exists := m.resolveOperand(raw)
+		if !exists {
+			// Words that are not variables are text, e.g. default:anonymous
+			value = strings.TrimSpace(raw)
+		}
+		args = append(args, value)
+	}
+	return name, args
+}
+
+// resolveOperand returns the value of the start of a pipe or of a filter argument:
+// a literal, a {{template}}, a variable, or an env, secret, utils or faker reference.
+// Names of variables that do not exist are missing; other text is taken as is.
+func (m *Manager) resolveOperand(raw string) (interface{}, bool) {
+	raw = strings.TrimSpace(raw)
+
+	switch {
+	case raw == "":
+		return "", true
+	case len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && closingQuote(raw) == len(raw)-1:
+		value, _ := m.Substitute(unquote(raw[1 : len(raw)-1]))
+		return value, true
+	case raw == "true" || raw == "false":
+		return raw == "true", true
+	case raw == "null":
+		return nil, true
+	case strings.HasPrefix(raw, "{{") && strings.HasSuffix(raw, "}}"):
+		value, err := m.SubstituteValue(raw)
+		if err != nil || value == raw {
+			return nil, false
+		}
+		return value, true
+	case strings.HasPrefix(raw, "env."):
+		value, exists := m.envValue(strings.TrimPrefix(raw, "env."))
+		return value, exists
+	case strings.HasPrefix(raw, "secret."):
+		value, exists := m.secretValue(strings.TrimPrefix(raw, "secret."))
+		return value, exists
+	case strings.HasPrefix(raw, "faker."):
+		return m.substituteFakerFunctions("{{" + raw + "}}"), true
+	case strings.HasPrefix(raw, "utils."):
+		if name, args, length, ok := parseUtilsCall(raw); ok && length == len(raw) {
+			value, err := m.callUtils(name, args)
+			if err != nil {
+				m.logger.Warn("utils function failed", "function", name, "error", err)
+				return nil, false
+			}
+			return value, true
+		}
+	}
+
+	if n, err := strconv.ParseFloat(raw, 64); err == nil {
+		return n, true
+	}
+	if identifier.MatchString(raw) || strings.ContainsAny(raw, "[") {
+		if value, exists := m.lookup(raw); exists {
+			return value, true
+		}
+		return nil, false
+	}
+	return raw, true
+}
@@ -0,0 +1,70 @@
+package variables
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cjp2600/stepwise/internal/logger"
+)
+
+func TestSubstitutePipes(t *testing.T) {
+	vm := NewManager(logger.New())
+	vm.Set("user", map[string]interface{}{"name": "alice", "tags": []interface{}{"a", "b"}})
+	vm.Set("items", []interface{}{1, 2, 3})
+	vm.Set("price", 12.3456)
+	vm.Set("payload", map[string]interface{}{"id": 1})
+
+	tests := map[string]string{
+		"{{user.name | upper}}":                      "ALICE",
+		"{{items | length}}":                         "3",
+		"{{price | default:0 | round:2}}":            "12.35",
+		"{{missing | default:0 | round:2}}":          "0",
+		"{{missing | default:anonymous}}":            "anonymous",
+		"{{payload | tojson | base64}}":              "eyJpZCI6MX0=",
+		"{{user.tags | join:', '}}":                  "a, b",
+		"Hello {{ user.name | upper }}!":             "Hello ALICE!",
+		"{{'abc' | upper}}":                          "ABC",
+		"{{user.name | hmac_sha256:'key' | length}}": "64",
+		"{{utils.base64('x') | lower}}":              "ea==",
+	}
+	for input, want := range tests {
+		got, err := vm.Substitute(input)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", input, err)
+		} else if got != want {
+			t.Errorf("%s: expected %q, got %q", input, want, got)
+		}
+	}
+}
+
+func TestSubstituteValuePipesKeepType(t *testing.T) {
+	vm := NewManager(logger.New())
+	vm.Set("items", []interface{}{"a", "b"})
+	vm.Set("raw", `{"id": 7}`)
+
+	tests := map[string]interface{}{
+		"{{items | length}}":         float64(2),
+		"{{items | first}}":          "a",
+		"{{raw | fromjson}}":         map[string]interface{}{"id": float64(7)},
+		"{{missing | default:true}}": true,
+		"{{'a,b' | split}}":          []interface{}{"a", "b"},
+	}
+	for input, want := range tests {
+		got, err := vm.SubstituteValue(input)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", input, err)
+		} else if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %#v, got %#v", input, want, got)
+		}
+	}
+}
+
+func TestFailedPipesAreLeft(t *testing.T) {
+	vm := NewManager(logger.New())
+	vm.Set("name", "alice")
+	for _, input := range []string{"{{missing | upper}}", "{{name | nosuchfilter}}", "{{name | round}}"} {
+		if got, _ := vm.Substitute(input); got != input {
+			t.Errorf("Expected %s to be left as is, got %q", input, got)
+		}
+	}
+}
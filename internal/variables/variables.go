@@ -1,20 +1,38 @@
 package variables
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
+	"sync"
 
+	"github.com/cjp2600/stepwise/internal/faker"
 	"github.com/cjp2600/stepwise/internal/logger"
-	"github.com/cjp2600/stepwise/internal/utils"
+	"github.com/cjp2600/stepwise/internal/redact"
+	"github.com/cjp2600/stepwise/internal/secrets"
 )
 
-// Manager handles variable substitution and management
+// Manager handles variable substitution and management.
+// It is safe for concurrent use.
+//
+// Managers form a chain of scopes (global, workflow, group, step, iteration).
+// Lookups fall back to the parent scope, while Set only writes to the scope it
+// is called on, so child scopes used by parallel branches never see each
+// other's writes. Promote copies the variables set in a scope to its parent.
 type Manager struct {
+	mu        sync.RWMutex
 	variables map[string]interface{}
+	bindings  map[string]interface{}
+	env       map[string]string // Values of .env files, used by {{env.*}} after the process environment
+	secrets   *secrets.Store    // Resolves {{secret.*}}
+	redactor  *redact.Redactor  // Receives the values of secrets and sensitive environment variables
+	faker     *faker.Faker      // Generates {{faker.*}} values
+	parent    *Manager
+	scope     string
 	logger    *logger.Logger
 }
 
@@ -22,34 +40,177 @@ type Manager struct {
 func NewManager(log *logger.Logger) *Manager {
 	return &Manager{
 		variables: make(map[string]interface{}),
+		bindings:  make(map[string]interface{}),
+		scope:     "global",
 		logger:    log,
 	}
 }
 
-// Set sets a variable
+// NewScope creates a child scope. Bindings are visible in the child scope only
+// and are never promoted to the parent, which makes them suitable for loop
+// variables such as item or index.
+func (m *Manager) NewScope(name string, bindings map[string]interface{}) *Manager {
+	child := &Manager{
+		variables: make(map[string]interface{}),
+		bindings:  make(map[string]interface{}, len(bindings)),
+		parent:    m,
+		scope:     name,
+		logger:    m.logger,
+	}
+	for key, value := range bindings {
+		child.bindings[key] = value
+	}
+	return child
+}
+
+// Scope returns the name of the scope
+func (m *Manager) Scope() string {
+	return m.scope
+}
+
+// Parent returns the parent scope, or nil for the root scope
+func (m *Manager) Parent() *Manager {
+	return m.parent
+}
+
+// Promote copies the variables set in this scope to the parent scope
+func (m *Manager) Promote() {
+	if m.parent == nil {
+		return
+	}
+	for key, value := range m.Local() {
+		m.parent.Set(key, value)
+	}
+}
+
+// Local returns the variables set in this scope, without bindings or parent variables
+func (m *Manager) Local() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make(map[string]interface{}, len(m.variables))
+	for key, value := range m.variables {
+		result[key] = value
+	}
+	return result
+}
+
+// Set sets a variable in this scope
 func (m *Manager) Set(key string, value interface{}) {
+	m.mu.Lock()
 	m.variables[key] = value
-	m.logger.Debug("Set variable", "key", key, "value", value)
+	m.mu.Unlock()
+	m.logger.Debug("Set variable", "key", key, "value", value, "scope", m.scope)
 }
 
-// Get gets a variable value
+// Get gets a variable value, looking it up in this scope first and then in its parents
 func (m *Manager) Get(key string) (interface{}, bool) {
+	m.mu.RLock()
 	value, exists := m.variables[key]
+	if !exists {
+		value, exists = m.bindings[key]
+	}
+	m.mu.RUnlock()
+
+	if !exists && m.parent != nil {
+		return m.parent.Get(key)
+	}
 	return value, exists
 }
 
-// GetAll gets all variables
+// GetAll gets all variables visible in this scope
 func (m *Manager) GetAll() map[string]interface{} {
 	result := make(map[string]interface{})
+	if m.parent != nil {
+		result = m.parent.GetAll()
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for key, value := range m.bindings {
+		result[key] = value
+	}
 	for key, value := range m.variables {
 		result[key] = value
 	}
 	return result
 }
 
-// Delete removes a variable by key
+// SetEnv adds values of .env files to this scope. The process environment takes
+// precedence over them.
+func (m *Manager) SetEnv(values map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.env == nil {
+		m.env = make(map[string]string, len(values))
+	}
+	for key, value := range values {
+		m.env[key] = value
+	}
+}
+
+// LookupEnv returns an environment variable from the process environment, or else
+// from the .env values of this scope and its parents
+func (m *Manager) LookupEnv(name string) (string, bool) {
+	if value := os.Getenv(name); value != "" {
+		return value, true
+	}
+	for scope := m; scope != nil; scope = scope.parent {
+		scope.mu.RLock()
+		value, exists := scope.env[name]
+		scope.mu.RUnlock()
+		if exists {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// SetSecrets sets the store that resolves {{secret.*}} in this scope and its children
+func (m *Manager) SetSecrets(store *secrets.Store) {
+	m.mu.Lock()
+	m.secrets = store
+	m.mu.Unlock()
+}
+
+// SetRedactor sets the redactor that is given the values of {{secret.*}} and of
+// {{env.*}} variables with sensitive names, in this scope and its children
+func (m *Manager) SetRedactor(redactor *redact.Redactor) {
+	m.mu.Lock()
+	m.redactor = redactor
+	m.mu.Unlock()
+}
+
+// sensitive registers a sensitive value with the redactor of this scope or of the nearest parent
+func (m *Manager) sensitive(value string) {
+	for scope := m; scope != nil; scope = scope.parent {
+		scope.mu.RLock()
+		redactor := scope.redactor
+		scope.mu.RUnlock()
+		if redactor != nil {
+			redactor.AddValue(value)
+			return
+		}
+	}
+}
+
+// secretStore returns the secret store of this scope or of the nearest parent that has one
+func (m *Manager) secretStore() *secrets.Store {
+	for scope := m; scope != nil; scope = scope.parent {
+		scope.mu.RLock()
+		store := scope.secrets
+		scope.mu.RUnlock()
+		if store != nil {
+			return store
+		}
+	}
+	return nil
+}
+
+// Delete removes a variable from this scope
 func (m *Manager) Delete(key string) {
+	m.mu.Lock()
 	delete(m.variables, key)
+	m.mu.Unlock()
 	m.logger.Debug("Delete variable", "key", key)
 }
 
@@ -64,6 +225,8 @@ func (m *Manager) Substitute(input string) (string, error) {
 	for i := 0; i < 10; i++ { // ограничение на глубину рекурсии
 		previous = current
 
+		// Pipes through filters
+		current = m.substitutePipes(current)
 		// Handle utils functions
 		current = m.substituteUtilsFunctions(current)
 		// Faker
@@ -72,6 +235,8 @@ func (m *Manager) Substitute(input string) (string, error) {
 		current = m.substituteVariables(current)
 		// Env
 		current = m.substituteEnvironmentVariables(current)
+		// Secrets
+		current = m.substituteSecrets(current)
 
 		if current == previous || !strings.Contains(current, "{{") {
 			break
@@ -80,21 +245,81 @@ func (m *Manager) Substitute(input string) (string, error) {
 	return current, nil
 }
 
-// substituteVariables substitutes {{variable}} patterns
+// variableReference matches a string that consists of exactly one {{variable}} reference
+var variableReference = regexp.MustCompile(`^\{\{\s*([^{}]+?)\s*\}\}$`)
+
+// SubstituteValue substitutes variables in a string. When the string is exactly one
+// {{variable}} reference, the value is returned with its original type, so numbers,
+// booleans, objects and lists are preserved in request bodies.
+func (m *Manager) SubstituteValue(input string) (interface{}, error) {
+	// A variable may itself refer to another variable, e.g. payload: "{{order}}"
+	for i := 0; i < 10; i++ {
+		match := variableReference.FindStringSubmatch(input)
+		if match == nil {
+			break
+		}
+		name := match[1]
+		// Pipes keep the type of their result, e.g. {{items | length}} is a number
+		if len(splitTopLevel(name, '|')) > 1 {
+			if value, exists := m.Resolve(name); exists {
+				return value, nil
+			}
+			break
+		}
+		if strings.HasPrefix(name, "faker.") || strings.HasPrefix(name, "env.") || strings.HasPrefix(name, "utils.") || strings.HasPrefix(name, "secret.") {
+			break
+		}
+		value, exists := m.lookup(name)
+		if !exists {
+			break
+		}
+		s, isString := value.(string)
+		if !isString {
+			return value, nil
+		}
+		input = s
+	}
+	return m.Substitute(input)
+}
+
+// formatValue formats a variable value for insertion into a string. Objects and lists
+// are JSON-encoded, and floats are written without an exponent.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return "null"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if data, err := json.Marshal(value); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// substituteVariables substitutes {{variable}} and {{variable.path}} patterns
 func (m *Manager) substituteVariables(input string) string {
 	re := regexp.MustCompile(`\{\{([^}]+)\}\}`)
 	return re.ReplaceAllStringFunc(input, func(match string) string {
 		// Extract variable name
 		varName := strings.TrimSpace(strings.Trim(match, "{}"))
 
-		// Skip if it's a faker function or environment variable
-		if strings.HasPrefix(varName, "faker.") || strings.HasPrefix(varName, "env.") {
+		// Skip if it's a faker function, environment variable or secret
+		if strings.HasPrefix(varName, "faker.") || strings.HasPrefix(varName, "env.") || strings.HasPrefix(varName, "secret.") {
 			return match
 		}
 
-		// Get variable value
-		if value, exists := m.variables[varName]; exists {
-			return fmt.Sprintf("%v", value)
+		// Get variable value, or a value inside an object or list variable
+		if value, exists := m.lookup(varName); exists {
+			return formatValue(value)
 		}
 
 		// Return original if not found
@@ -103,35 +328,6 @@ func (m *Manager) substituteVariables(input string) string {
 	})
 }
 
-// substituteFakerFunctions substitutes {{faker.function}} patterns
-func (m *Manager) substituteFakerFunctions(input string) string {
-	re := regexp.MustCompile(`\{\{faker\.([^}]+)\}\}`)
-	return re.ReplaceAllStringFunc(input, func(match string) string {
-		// Extract function name and parameters
-		funcCall := strings.TrimSpace(strings.TrimPrefix(strings.Trim(match, "{}"), "faker."))
-
-		// Parse function call
-		parts := strings.Split(funcCall, "(")
-		funcName := parts[0]
-
-		var params []string
-		if len(parts) > 1 {
-			// Extract parameters
-			paramStr := strings.TrimSuffix(parts[1], ")")
-			if paramStr != "" {
-				params = strings.Split(paramStr, ",")
-				for i, param := range params {
-					params[i] = strings.TrimSpace(param)
-				}
-			}
-		}
-
-		// Generate fake data based on function name
-		result := m.generateFakerData(funcName, params)
-		return result
-	})
-}
-
 // substituteEnvironmentVariables substitutes {{env.VARIABLE}} patterns
 func (m *Manager) substituteEnvironmentVariables(input string) string {
 	re := regexp.MustCompile(`\{\{env\.([^}]+)\}\}`)
@@ -139,180 +335,67 @@ func (m *Manager) substituteEnvironmentVariables(input string) string {
 		// Extract environment variable name
 		envVar := strings.TrimSpace(strings.TrimPrefix(strings.Trim(match, "{}"), "env."))
 
-		// First, try to get from OS environment variables
-		if value := os.Getenv(envVar); value != "" {
+		if value, exists := m.envValue(envVar); exists {
 			return value
 		}
 
-		// Fallback to variables map (for backward compatibility)
-		if value, exists := m.variables[envVar]; exists {
-			return fmt.Sprintf("%v", value)
-		}
-
 		// Return original if not found
 		m.logger.Warn("Environment variable not found", "variable", envVar)
 		return match
 	})
 }
 
-// substituteUtilsFunctions substitutes {{utils.function}} patterns
-func (m *Manager) substituteUtilsFunctions(input string) string {
-	re := regexp.MustCompile(`\{\{utils\.([a-zA-Z0-9_]+)\((.*?)\)\}\}`)
-	return re.ReplaceAllStringFunc(input, func(match string) string {
-		// Extract function name and argument string
-		parts := re.FindStringSubmatch(match)
-		if len(parts) < 3 {
-			return match
-		}
-		funcName := parts[1]
-		argStr := strings.TrimSpace(parts[2])
-
-		// Поддержка вложенных шаблонов: если аргумент содержит {{...}}, подставить переменные
-		if strings.Contains(argStr, "{{") {
-			argStr, _ = m.Substitute(argStr)
-		}
-		argStr = strings.Trim(argStr, "\"'") // убрать кавычки
-
-		switch funcName {
-		case "base64":
-			return utils.Base64Encode(argStr)
-		case "base64_decode":
-			decoded, err := utils.Base64Decode(argStr)
-			if err != nil {
-				m.logger.Warn("base64_decode error", "arg", argStr, "err", err)
-				return ""
-			}
-			return decoded
-		// Здесь можно добавить другие утилиты
-		default:
-			m.logger.Warn("Unknown utils function", "function", funcName)
-			return match
+// envValue returns the value of {{env.NAME}}. Values of variables whose name suggests
+// a secret are registered for redaction.
+func (m *Manager) envValue(name string) (string, bool) {
+	// First, try the OS environment and .env files
+	value, exists := m.LookupEnv(name)
+	if !exists {
+		// Fallback to variables map (for backward compatibility)
+		var variable interface{}
+		if variable, exists = m.Get(name); !exists {
+			return "", false
 		}
-	})
-}
-
-// generateFakerData generates fake data based on function name
-func (m *Manager) generateFakerData(funcName string, params []string) string {
-	switch funcName {
-	case "name":
-		return m.generateName()
-	case "email":
-		return m.generateEmail()
-	case "phone":
-		return m.generatePhone()
-	case "address":
-		return m.generateAddress()
-	case "uuid":
-		return m.generateUUID()
-	case "number":
-		return m.generateNumber(params)
-	case "date":
-		return m.generateDate()
-	case "sentence":
-		return m.generateSentence()
-	case "paragraph":
-		return m.generateParagraph()
-	case "sha":
-		return m.generateSHA()
-	default:
-		m.logger.Warn("Unknown faker function", "function", funcName)
-		return fmt.Sprintf("{{faker.%s}}", funcName)
+		value = formatValue(variable)
 	}
-}
 
-// Faker data generation methods
-func (m *Manager) generateName() string {
-	names := []string{
-		"John Doe", "Jane Smith", "Bob Johnson", "Alice Brown",
-		"Charlie Wilson", "Diana Davis", "Edward Miller", "Fiona Garcia",
-		"George Martinez", "Helen Anderson", "Ian Taylor", "Julia Thomas",
-		"Kevin Jackson", "Laura White", "Michael Harris", "Nancy Clark",
+	if redact.SensitiveName(name) {
+		m.sensitive(value)
 	}
-	return names[time.Now().UnixNano()%int64(len(names))]
-}
-
-func (m *Manager) generateEmail() string {
-	domains := []string{"example.com", "test.org", "demo.net", "sample.io"}
-	name := strings.ToLower(strings.ReplaceAll(m.generateName(), " ", "."))
-	domain := domains[time.Now().UnixNano()%int64(len(domains))]
-	return fmt.Sprintf("%s@%s", name, domain)
-}
-
-func (m *Manager) generatePhone() string {
-	// Generate a simple phone number
-	return fmt.Sprintf("+1-%03d-%03d-%04d",
-		time.Now().UnixNano()%900+100,
-		time.Now().UnixNano()%900+100,
-		time.Now().UnixNano()%9000+1000)
-}
-
-func (m *Manager) generateAddress() string {
-	streets := []string{"Main St", "Oak Ave", "Pine Rd", "Elm Blvd"}
-	cities := []string{"New York", "Los Angeles", "Chicago", "Houston"}
-	street := streets[time.Now().UnixNano()%int64(len(streets))]
-	number := time.Now().UnixNano()%9999 + 1
-	city := cities[time.Now().UnixNano()%int64(len(cities))]
-	return fmt.Sprintf("%d %s, %s", number, street, city)
-}
-
-func (m *Manager) generateUUID() string {
-	// Simple UUID generation
-	timestamp := time.Now().UnixNano()
-	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x",
-		timestamp&0xffffffff,
-		(timestamp>>32)&0xffff,
-		(timestamp>>48)&0xffff,
-		(timestamp>>64)&0xffff,
-		timestamp&0xffffffffffff)
+	return value, true
 }
 
-func (m *Manager) generateNumber(params []string) string {
-	if len(params) >= 2 {
-		min, _ := strconv.Atoi(params[0])
-		max, _ := strconv.Atoi(params[1])
-		if max > min {
-			result := min + int(time.Now().UnixNano()%int64(max-min+1))
-			return strconv.Itoa(result)
+// substituteSecrets substitutes {{secret.NAME}} patterns. Unresolved secrets are left
+// as they are; the value of a secret is never logged.
+func (m *Manager) substituteSecrets(input string) string {
+	re := regexp.MustCompile(`\{\{secret\.([^}]+)\}\}`)
+	return re.ReplaceAllStringFunc(input, func(match string) string {
+		name := strings.TrimSpace(strings.TrimPrefix(strings.Trim(match, "{}"), "secret."))
+		if value, exists := m.secretValue(name); exists {
+			return value
 		}
-	}
-	// Default to 1-100
-	return strconv.Itoa(int(time.Now().UnixNano()%100) + 1)
-}
-
-func (m *Manager) generateDate() string {
-	// Generate a random date within the last year
-	daysAgo := time.Now().UnixNano() % 365
-	date := time.Now().AddDate(0, 0, -int(daysAgo))
-	return date.Format("2006-01-02")
+		return match
+	})
 }
 
-func (m *Manager) generateSentence() string {
-	sentences := []string{
-		"This is a test sentence.",
-		"Another example sentence for testing.",
-		"A third sentence to demonstrate functionality.",
-		"Testing the sentence generation feature.",
-		"Sample sentence for API testing.",
+// secretValue returns the value of {{secret.NAME}} and registers it for redaction
+func (m *Manager) secretValue(name string) (string, bool) {
+	store := m.secretStore()
+	if store == nil {
+		m.logger.Warn("Secret not found, no secret providers configured", "secret", name)
+		return "", false
 	}
-	return sentences[time.Now().UnixNano()%int64(len(sentences))]
-}
-
-func (m *Manager) generateParagraph() string {
-	paragraphs := []string{
-		"This is a test paragraph that contains multiple sentences. It demonstrates the paragraph generation functionality. The content is suitable for testing purposes.",
-		"Another example paragraph with different content. This paragraph has multiple sentences to test the generation feature. It provides realistic test data.",
-		"A third paragraph example for demonstration. This content shows how the paragraph generator works. It includes various sentence structures.",
+	value, err := store.Get(name)
+	if err != nil {
+		m.logger.Warn("Secret not found", "secret", name, "error", err)
+		return "", false
 	}
-	return paragraphs[time.Now().UnixNano()%int64(len(paragraphs))]
-}
-
-func (m *Manager) generateSHA() string {
-	// Simple SHA-like string generation
-	timestamp := time.Now().UnixNano()
-	return fmt.Sprintf("%040x", timestamp)
+	m.sensitive(value)
+	return value, true
 }
 
-// SubstituteMap substitutes variables in a map, including keys
+// SubstituteMap substitutes variables in a map, including keys. Values that are exactly
+// one {{variable}} reference keep the type of the variable.
 func (m *Manager) SubstituteMap(input map[string]interface{}) (map[string]interface{}, error) {
 	result := make(map[string]interface{})
 
@@ -327,7 +410,7 @@ func (m *Manager) SubstituteMap(input map[string]interface{}) (map[string]interf
 		// Substitute variables in the value
 		switch v := value.(type) {
 		case string:
-			substitutedValue, err := m.Substitute(v)
+			substitutedValue, err := m.SubstituteValue(v)
 			if err != nil {
 				return nil, fmt.Errorf("failed to substitute value for key '%s': %w", key, err)
 			}
@@ -371,11 +454,11 @@ func (m *Manager) SubstituteMap(input map[string]interface{}) (map[string]interf
 			// Check if value needs further substitution
 			switch v := value.(type) {
 			case string:
-				substitutedValue, err := m.Substitute(v)
+				substitutedValue, err := m.SubstituteValue(v)
 				if err != nil {
 					return nil, fmt.Errorf("failed to substitute value for key '%s' in second pass: %w", key, err)
 				}
-				if substitutedValue != v {
+				if s, isString := substitutedValue.(string); !isString || s != v {
 					changed = true
 					newResult[substitutedKey] = substitutedValue
 				}
@@ -403,14 +486,15 @@ func (m *Manager) SubstituteMap(input map[string]interface{}) (map[string]interf
 	return result, nil
 }
 
-// SubstituteSlice substitutes variables in a slice
+// SubstituteSlice substitutes variables in a slice. Elements that are exactly one
+// {{variable}} reference keep the type of the variable.
 func (m *Manager) SubstituteSlice(input []interface{}) ([]interface{}, error) {
 	result := make([]interface{}, len(input))
 
 	for i, value := range input {
 		switch v := value.(type) {
 		case string:
-			substituted, err := m.Substitute(v)
+			substituted, err := m.SubstituteValue(v)
 			if err != nil {
 				return nil, err
 			}
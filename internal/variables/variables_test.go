@@ -1,10 +1,16 @@
 package variables
 
 import (
+	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/cjp2600/stepwise/internal/faker"
 	"github.com/cjp2600/stepwise/internal/logger"
+	"github.com/cjp2600/stepwise/internal/secrets"
 )
 
 func TestNewManager(t *testing.T) {
@@ -56,6 +62,79 @@ func TestSetAndGet(t *testing.T) {
 	}
 }
 
+func TestScopes(t *testing.T) {
+	global := NewManager(logger.New())
+	global.Set("base_url", "http://api")
+	global.Set("token", "global")
+
+	workflow := global.NewScope("workflow", nil)
+	workflow.Set("token", "workflow")
+
+	iteration := workflow.NewScope("iteration", map[string]interface{}{"index": 3})
+	iteration.Set("id", 42)
+
+	if value, _ := iteration.Get("base_url"); value != "http://api" {
+		t.Errorf("Expected base_url from the global scope, got %v", value)
+	}
+	if value, _ := iteration.Get("token"); value != "workflow" {
+		t.Errorf("Expected the workflow scope to shadow the global one, got %v", value)
+	}
+	if result, _ := iteration.Substitute("{{base_url}}/items/{{index}}/{{id}}"); result != "http://api/items/3/42" {
+		t.Errorf("Unexpected substitution: %s", result)
+	}
+	if _, exists := workflow.Get("id"); exists {
+		t.Error("Variables of a child scope should not be visible in the parent before promotion")
+	}
+	if all := iteration.GetAll(); len(all) != 4 || all["token"] != "workflow" {
+		t.Errorf("Unexpected GetAll result: %v", all)
+	}
+
+	iteration.Promote()
+	if value, _ := workflow.Get("id"); value != 42 {
+		t.Errorf("Expected id to be promoted, got %v", value)
+	}
+	if _, exists := workflow.Get("index"); exists {
+		t.Error("Bindings should not be promoted")
+	}
+	if _, exists := global.Get("id"); exists {
+		t.Error("Promote should only copy to the direct parent")
+	}
+	if iteration.Scope() != "iteration" || iteration.Parent() != workflow || global.Parent() != nil {
+		t.Error("Unexpected scope chain")
+	}
+}
+
+func TestConcurrentScopes(t *testing.T) {
+	parent := NewManager(logger.New())
+	parent.Set("shared", "value")
+
+	var wg sync.WaitGroup
+	scopes := make([]*Manager, 10)
+	for i := range scopes {
+		scopes[i] = parent.NewScope("step", map[string]interface{}{"index": i})
+		wg.Add(1)
+		go func(scope *Manager, i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				scope.Set("result", fmt.Sprintf("%d-%d", i, j))
+				if value, _ := scope.Get("index"); value != i {
+					t.Errorf("Scope %d sees index %v", i, value)
+				}
+				scope.Substitute("{{shared}} {{result}}")
+				parent.GetAll()
+			}
+		}(scopes[i], i)
+	}
+	wg.Wait()
+
+	for _, scope := range scopes {
+		scope.Promote()
+	}
+	if value, _ := parent.Get("result"); value != "9-99" {
+		t.Errorf("Expected the last scope to win, got %v", value)
+	}
+}
+
 func TestSubstituteVariables(t *testing.T) {
 	log := logger.New()
 	manager := NewManager(log)
@@ -89,6 +168,82 @@ func TestSubstituteVariables(t *testing.T) {
 	}
 }
 
+func TestSubstituteValueKeepsTypes(t *testing.T) {
+	manager := NewManager(logger.New())
+	order := map[string]interface{}{"id": float64(1042), "items": []interface{}{"a", "b"}}
+	manager.Set("order", order)
+	manager.Set("count", 3)
+	manager.Set("active", true)
+	manager.Set("payload", "{{order}}")
+	manager.Set("name", "Ann")
+
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{"{{count}}", 3},
+		{"{{ active }}", true},
+		{"{{order}}", order},
+		{"{{payload}}", order},
+		{"{{name}}", "Ann"},
+		{"{{missing}}", "{{missing}}"},
+		{"id-{{count}}", "id-3"},
+		{"{{count}}{{count}}", "33"},
+	}
+
+	for _, tt := range tests {
+		got, err := manager.SubstituteValue(tt.input)
+		if err != nil {
+			t.Errorf("SubstituteValue(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SubstituteValue(%q) = %#v, want %#v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSubstituteEncodesObjects(t *testing.T) {
+	manager := NewManager(logger.New())
+	manager.Set("order", map[string]interface{}{"id": float64(1042)})
+	manager.Set("tags", []interface{}{"a", "b"})
+	manager.Set("total", float64(1500000))
+	manager.Set("empty", nil)
+
+	result, _ := manager.Substitute(`{"order": {{order}}, "tags": {{tags}}, "total": {{total}}, "note": {{empty}}}`)
+	want := `{"order": {"id":1042}, "tags": ["a","b"], "total": 1500000, "note": null}`
+	if result != want {
+		t.Errorf("Expected %s, got %s", want, result)
+	}
+}
+
+func TestSubstituteMapKeepsTypes(t *testing.T) {
+	manager := NewManager(logger.New())
+	manager.Set("user", map[string]interface{}{"id": float64(7), "name": "Ann"})
+	manager.Set("user_id", float64(7))
+	manager.Set("admin", false)
+
+	result, err := manager.SubstituteMap(map[string]interface{}{
+		"user":    "{{user}}",
+		"user_id": "{{user_id}}",
+		"label":   "user {{user_id}}",
+		"flags":   []interface{}{"{{admin}}", "{{user_id}}"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"user":    map[string]interface{}{"id": float64(7), "name": "Ann"},
+		"user_id": float64(7),
+		"label":   "user 7",
+		"flags":   []interface{}{false, float64(7)},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Expected %#v, got %#v", want, result)
+	}
+}
+
 func TestSubstituteFakerFunctions(t *testing.T) {
 	log := logger.New()
 	manager := NewManager(log)
@@ -115,6 +270,31 @@ func TestSubstituteFakerFunctions(t *testing.T) {
 	}
 }
 
+func TestSeededFaker(t *testing.T) {
+	input := `{{faker.name}} {{faker.enum("a, b", c)}} {{faker.regex("^[A-Z]{2}-\d{3}$")}} {{faker.unknown}}`
+	generate := func() string {
+		manager := NewManager(logger.New())
+		f, err := faker.New(42, "en")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		manager.SetFaker(f)
+		result, err := manager.NewScope("step", nil).Substitute(input)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return result
+	}
+
+	result := generate()
+	if result != generate() {
+		t.Errorf("Expected the same values for the same seed")
+	}
+	if !regexp.MustCompile(`^\w+ \w+ (a, b|c) [A-Z]{2}-\d{3} \{\{faker\.unknown\}\}$`).MatchString(result) {
+		t.Errorf("Unexpected result: %q", result)
+	}
+}
+
 func TestSubstituteEnvironmentVariables(t *testing.T) {
 	log := logger.New()
 	manager := NewManager(log)
@@ -135,6 +315,53 @@ func TestSubstituteEnvironmentVariables(t *testing.T) {
 	}
 }
 
+func TestDotEnvValues(t *testing.T) {
+	t.Setenv("STEPWISE_TEST_PROCESS", "process")
+
+	global := NewManager(logger.New())
+	global.SetEnv(map[string]string{"STEPWISE_TEST_PROCESS": "dotenv", "STEPWISE_TEST_FILE": "global"})
+	child := global.NewScope("workflow", nil)
+	child.SetEnv(map[string]string{"STEPWISE_TEST_FILE": "workflow"})
+
+	result, _ := child.Substitute("{{env.STEPWISE_TEST_PROCESS}} {{env.STEPWISE_TEST_FILE}}")
+	if result != "process workflow" {
+		t.Errorf("Expected the process environment, then the nearest .env values, got %q", result)
+	}
+	if value, _ := global.LookupEnv("STEPWISE_TEST_FILE"); value != "global" {
+		t.Errorf("Expected child .env values not to leak into the parent, got %q", value)
+	}
+}
+
+// mapProvider is a secrets provider backed by a map
+type mapProvider map[string]string
+
+func (p mapProvider) Name() string { return "map" }
+
+func (p mapProvider) Lookup(name string) (string, bool, error) {
+	value, found := p[name]
+	return value, found, nil
+}
+
+func TestSubstituteSecrets(t *testing.T) {
+	global := NewManager(logger.New())
+	global.Set("secret", "not a secret")
+	global.SetSecrets(secrets.NewStore(mapProvider{"api_token": "abc123"}))
+	scope := global.NewScope("step", nil)
+
+	result, _ := scope.Substitute("Bearer {{secret.api_token}} {{secret.missing}}")
+	if result != "Bearer abc123 {{secret.missing}}" {
+		t.Errorf("Unexpected result: %q", result)
+	}
+	if value, _ := scope.SubstituteValue("{{secret.api_token}}"); value != "abc123" {
+		t.Errorf("Expected SubstituteValue to resolve secrets, got %v", value)
+	}
+
+	// Without providers secrets are left unresolved
+	if result, _ := NewManager(logger.New()).Substitute("{{secret.api_token}}"); result != "{{secret.api_token}}" {
+		t.Errorf("Expected unresolved secret, got %q", result)
+	}
+}
+
 func TestSubstituteMap(t *testing.T) {
 	log := logger.New()
 	manager := NewManager(log)
@@ -176,13 +403,19 @@ func TestGenerateFakerData(t *testing.T) {
 	manager := NewManager(log)
 
 	// Test name generation
-	name := manager.generateName()
+	name, err := manager.callFaker("name", nil)
+	if err != nil {
+		t.Fatalf("Failed to generate name: %v", err)
+	}
 	if name == "" {
 		t.Error("Generated name should not be empty")
 	}
 
 	// Test email generation
-	email := manager.generateEmail()
+	email, err := manager.callFaker("email", nil)
+	if err != nil {
+		t.Fatalf("Failed to generate email: %v", err)
+	}
 	if email == "" {
 		t.Error("Generated email should not be empty")
 	}
@@ -191,7 +424,10 @@ func TestGenerateFakerData(t *testing.T) {
 	}
 
 	// Test UUID generation
-	uuid := manager.generateUUID()
+	uuid, err := manager.callFaker("uuid", nil)
+	if err != nil {
+		t.Fatalf("Failed to generate uuid: %v", err)
+	}
 	if uuid == "" {
 		t.Error("Generated UUID should not be empty")
 	}
@@ -200,13 +436,19 @@ func TestGenerateFakerData(t *testing.T) {
 	}
 
 	// Test number generation
-	number := manager.generateNumber([]string{"1", "10"})
+	number, err := manager.callFaker("number", []string{"1", "10"})
+	if err != nil {
+		t.Fatalf("Failed to generate number: %v", err)
+	}
 	if number == "" {
 		t.Error("Generated number should not be empty")
 	}
 
 	// Test date generation
-	date := manager.generateDate()
+	date, err := manager.callFaker("date", nil)
+	if err != nil {
+		t.Fatalf("Failed to generate date: %v", err)
+	}
 	if date == "" {
 		t.Error("Generated date should not be empty")
 	}
@@ -422,3 +664,53 @@ func TestSubstituteMapWithFakerInKeys(t *testing.T) {
 		t.Error("Expected to find an order key with faker.uuid")
 	}
 }
+
+func TestSubstitutePaths(t *testing.T) {
+	manager := NewManager(logger.New())
+	manager.Set("user", map[string]interface{}{
+		"id":      float64(7),
+		"address": map[string]interface{}{"city": "Oslo"},
+	})
+	manager.Set("items", []interface{}{
+		map[string]interface{}{"sku": "A-1", "qty": float64(2), "active": true},
+		map[string]interface{}{"sku": "B-2", "qty": float64(5), "active": false},
+		map[string]interface{}{"sku": "C-3", "qty": float64(9), "active": true},
+	})
+	manager.Set("raw", `{"token": {"value": "abc"}}`)
+	manager.Set("config.name", "dotted")
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"{{user.id}}", "7"},
+		{"{{user.address.city}}", "Oslo"},
+		{"{{items[0].sku}}", "A-1"},
+		{"{{items[-1].sku}}", "C-3"},
+		{"{{items[last].qty}}", "9"},
+		{"{{items[*].sku}}", `["A-1","B-2","C-3"]`},
+		{"{{items[0:2].qty}}", "[2,5]"},
+		{`{{items[?(@.sku == "B-2")].qty}}`, "5"},
+		{"{{items[?(@.qty > 4)].sku}}", `["B-2","C-3"]`},
+		{"{{items[?(@.active)].sku}}", `["A-1","C-3"]`},
+		{"{{raw.token.value}}", "abc"},
+		{"{{config.name}}", "dotted"},
+		{"{{user.missing}}", "{{user.missing}}"},
+		{"{{items[7].sku}}", "{{items[7].sku}}"},
+	}
+	for _, tt := range tests {
+		got, err := manager.Substitute(tt.input)
+		if err != nil {
+			t.Errorf("Substitute(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Substitute(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	value, err := manager.SubstituteValue("{{items[?(@.sku == 'C-3')]}}")
+	if err != nil || !reflect.DeepEqual(value, map[string]interface{}{"sku": "C-3", "qty": float64(9), "active": true}) {
+		t.Errorf("Expected the typed element, got %#v (%v)", value, err)
+	}
+}
@@ -0,0 +1,189 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+)
+
+func TestSoftAssertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": "pending", "items": []}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "workflow.yml")
+	if err := os.WriteFile(path, []byte(`name: soft
+steps:
+  - name: warn
+    request:
+      method: GET
+      url: "`+server.URL+`"
+    validate:
+      - any_of:
+          - json: "$.status"
+            equals: "done"
+          - json: "$.status"
+            equals: "pending"
+      - json: "$.items"
+        empty: false
+        severity: warn
+        message: "no items yet"
+  - name: fail
+    request:
+      method: GET
+      url: "`+server.URL+`"
+    validate:
+      - not:
+          json: "$.status"
+          equals: "pending"
+        message: "order is still pending"
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wf, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if results[0].Status != "passed" || len(results[0].Warnings) != 1 || results[0].Warnings[0] != "no items yet" {
+		t.Errorf("Expected the step to pass with a warning, got %s: %s %v", results[0].Status, results[0].Error, results[0].Warnings)
+	}
+	if results[1].Status != "failed" || !strings.Contains(results[1].Error, "order is still pending") {
+		t.Errorf("Expected the step to fail with the rule message, got %s: %s", results[1].Status, results[1].Error)
+	}
+}
+
+func TestArrayAssertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items": [{"id": 1, "price": 5}, {"id": 2, "price": -1}]}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "item.json"), []byte(`{"type": "object", "required": ["id"]}`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(dir, "workflow.yml")
+	if err := os.WriteFile(path, []byte(`name: arrays
+variables:
+  created_id: 2
+steps:
+  - name: some
+    request:
+      method: GET
+      url: "`+server.URL+`"
+    validate:
+      - json: "$.items"
+        some:
+          - json: "$.id"
+            equals: "{{created_id}}"
+  - name: each
+    request:
+      method: GET
+      url: "`+server.URL+`"
+    validate:
+      - json: "$.items"
+        each:
+          - schema_file: item.json
+          - json: "$.price"
+            greater: 0
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wf, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if results[0].Status != "passed" {
+		t.Errorf("Expected some element to match, got %s: %s", results[0].Status, results[0].Error)
+	}
+	if results[1].Status != "failed" || !strings.Contains(results[1].Error, `each validation failed: element 1 ({"id":2,"price":-1})`) {
+		t.Errorf("Expected the second element to fail, got %s: %s", results[1].Status, results[1].Error)
+	}
+}
+
+func TestStructuralEquals(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"order": {"id": 42, "lines": [{"sku": "B", "qty": 2}, {"sku": "A", "qty": 1}], "total": 29.999, "updated_at": "now"}}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "workflow.yml")
+	if err := os.WriteFile(path, []byte(`name: equals
+variables:
+  order_id: 42
+steps:
+  - name: loose
+    request:
+      method: GET
+      url: "`+server.URL+`"
+    validate:
+      - json: "$.order"
+        equals:
+          id: "{{order_id}}"
+          lines:
+            - sku: A
+              qty: 1
+            - sku: B
+              qty: 2
+          total: 30
+        ignore_order: true
+        ignore_extra_fields: true
+        tolerance: 0.01
+  - name: strict
+    request:
+      method: GET
+      url: "`+server.URL+`"
+    validate:
+      - json: "$.order.lines"
+        equals:
+          - sku: A
+            qty: 1
+          - sku: B
+            qty: 2
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wf, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if results[0].Status != "passed" {
+		t.Errorf("Expected the order to match, got %s: %s", results[0].Status, results[0].Error)
+	}
+	if results[1].Status != "failed" || !strings.Contains(results[1].Error, `value validation failed: 4 differences: $[0].qty: expected 1, got 2`) || len(results[1].Validations[0].Diff) != 4 {
+		t.Errorf("Expected the lines to differ, got %s: %s", results[1].Status, results[1].Error)
+	}
+}
@@ -0,0 +1,178 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	httpclient "github.com/cjp2600/stepwise/internal/http"
+	"gopkg.in/yaml.v3"
+)
+
+// CaptureConfig is a workflow-level capture. It is evaluated against the response of
+// every step, or only of the steps listed in After, and the last match wins.
+type CaptureConfig struct {
+	Path  string   `yaml:"path" json:"path"`                       // JSONPath into the step response, or XPath for XML responses
+	After []string `yaml:"after,omitempty" json:"after,omitempty"` // Names of the steps to capture from; all steps by default
+}
+
+// UnmarshalYAML accepts the full form as well as the shorthand `order_id: "$.id"`
+func (c *CaptureConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&c.Path)
+	}
+	type plain CaptureConfig
+	return node.Decode((*plain)(c))
+}
+
+// appliesTo reports whether the capture is evaluated after the named step
+func (c CaptureConfig) appliesTo(stepName string) bool {
+	if len(c.After) == 0 {
+		return true
+	}
+	for _, name := range c.After {
+		if name == stepName {
+			return true
+		}
+	}
+	return false
+}
+
+// validateWorkflowCaptures checks that workflow captures have a path and only
+// reference steps that exist in the workflow
+func validateWorkflowCaptures(wf *Workflow) error {
+	if len(wf.Captures) == 0 {
+		return nil
+	}
+
+	steps := make(map[string]bool)
+	collectStepNames(steps, wf.Setup)
+	collectStepNames(steps, wf.Steps)
+	collectStepNames(steps, wf.Teardown)
+	collectGroupStepNames(steps, wf.Groups)
+
+	for _, name := range sortedCaptureNames(wf.Captures) {
+		capture := wf.Captures[name]
+		if capture.Path == "" {
+			return fmt.Errorf("capture %s: path is required", name)
+		}
+		for _, step := range capture.After {
+			if !steps[step] {
+				return fmt.Errorf("capture %s: unknown step %q", name, step)
+			}
+		}
+	}
+	return nil
+}
+
+// collectStepNames adds the names of steps, including branch steps, to names
+func collectStepNames(names map[string]bool, steps []Step) {
+	for _, step := range steps {
+		if step.Name != "" {
+			names[step.Name] = true
+		}
+		collectStepNames(names, step.Then)
+		collectStepNames(names, step.Else)
+		for _, branch := range step.Branches {
+			collectStepNames(names, branch.Steps)
+		}
+	}
+}
+
+// collectGroupStepNames adds the names of the steps of groups, including nested groups, to names
+func collectGroupStepNames(names map[string]bool, groups []StepGroup) {
+	for _, group := range groups {
+		collectStepNames(names, group.Setup)
+		collectStepNames(names, group.Steps)
+		collectStepNames(names, group.Teardown)
+		collectGroupStepNames(names, group.Groups)
+	}
+}
+
+// sortedCaptureNames returns the capture names in a stable order
+func sortedCaptureNames(captures map[string]CaptureConfig) []string {
+	names := make([]string, 0, len(captures))
+	for name := range captures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// captureStore collects the values of workflow captures during a run.
+// It is shared by forked executors, so it is safe for concurrent use.
+type captureStore struct {
+	mu     sync.Mutex
+	values map[string]interface{}
+}
+
+// newCaptureStore creates an empty capture store
+func newCaptureStore() *captureStore {
+	return &captureStore{values: make(map[string]interface{})}
+}
+
+func (s *captureStore) set(name string, value interface{}) {
+	s.mu.Lock()
+	s.values[name] = value
+	s.mu.Unlock()
+}
+
+func (s *captureStore) all() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make(map[string]interface{}, len(s.values))
+	for name, value := range s.values {
+		result[name] = value
+	}
+	return result
+}
+
+// Captured returns the values of the workflow captures of the last run
+func (e *Executor) Captured() map[string]interface{} {
+	return e.captured.all()
+}
+
+// SetVariables sets variables in the global scope, visible to every workflow the
+// executor runs. It is used to pass captures of earlier workflows to later ones.
+func (e *Executor) SetVariables(vars map[string]interface{}) {
+	for name, value := range vars {
+		e.varManager.Set(name, value)
+	}
+}
+
+// applyWorkflowCaptures evaluates the workflow captures against the response of a step.
+// Captures whose path does not match the response are left unchanged.
+func (e *Executor) applyWorkflowCaptures(stepName string, response *httpclient.Response) {
+	if len(e.captures) == 0 || response == nil {
+		return
+	}
+
+	jsonData, jsonErr := response.GetJSONBody()
+
+	for _, name := range sortedCaptureNames(e.captures) {
+		capture := e.captures[name]
+		if !capture.appliesTo(stepName) {
+			continue
+		}
+		var value interface{}
+		var err error
+		switch {
+		case isXPath(capture.Path):
+			if value, err = e.validator.ExtractXMLValue(response.Body, capture.Path, nil); err == nil && value == nil {
+				err = fmt.Errorf("no node matched")
+			}
+		case jsonErr != nil:
+			err = jsonErr
+		default:
+			value, err = e.validator.ExtractJSONValue(jsonData, capture.Path)
+		}
+		if err != nil {
+			e.logger.Debug("Workflow capture did not match", "key", name, "step", stepName, "path", capture.Path)
+			continue
+		}
+		e.registerCapture(name, value)
+		e.varManager.Set(name, value)
+		e.captured.set(name, value)
+		e.logger.Debug("Captured workflow value", "key", name, "step", stepName, "value", value)
+	}
+}
@@ -0,0 +1,149 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+	"github.com/cjp2600/stepwise/internal/validation"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCaptureConfigUnmarshal(t *testing.T) {
+	var wf Workflow
+	if err := yaml.Unmarshal([]byte(`
+name: "captures"
+captures:
+  last_id: "$.id"
+  token:
+    path: "$.token"
+    after: ["login"]
+`), &wf); err != nil {
+		t.Fatal(err)
+	}
+
+	if wf.Captures["last_id"].Path != "$.id" || len(wf.Captures["last_id"].After) != 0 {
+		t.Errorf("Unexpected shorthand capture: %+v", wf.Captures["last_id"])
+	}
+	if token := wf.Captures["token"]; token.Path != "$.token" || len(token.After) != 1 || token.After[0] != "login" {
+		t.Errorf("Unexpected full capture: %+v", token)
+	}
+}
+
+func TestWorkflowCaptures(t *testing.T) {
+	recorder := &pathRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder.mu.Lock()
+		recorder.paths = append(recorder.paths, r.URL.Path)
+		recorder.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/login":
+			w.Write([]byte(`{"id": 1, "token": "secret"}`))
+		case "/orders":
+			w.Write([]byte(`{"id": 99, "token": "other"}`))
+		default:
+			w.Write([]byte(`{"status": "ok"}`))
+		}
+	}))
+	defer server.Close()
+
+	wf := &Workflow{
+		Name: "Captures",
+		Captures: map[string]CaptureConfig{
+			"last_id":  {Path: "$.id"},
+			"token":    {Path: "$.token", After: []string{"login"}},
+			"order_id": {Path: "$.id", After: []string{"create order"}},
+		},
+		Steps: []Step{
+			{Name: "login", Request: Request{Method: "POST", URL: server.URL + "/login"}},
+			{Name: "create order", Request: Request{Method: "POST", URL: server.URL + "/orders"}},
+			{
+				Name:     "get order",
+				Request:  Request{Method: "GET", URL: server.URL + "/orders/{{order_id}}/{{token}}"},
+				Validate: []validation.ValidationRule{{Status: 200}},
+			},
+		},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	if _, err := executor.Execute(context.Background(), wf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	captured := executor.Captured()
+	// The last step has no id, so last_id keeps the value of the step before it
+	if captured["last_id"] != float64(99) || captured["order_id"] != float64(99) || captured["token"] != "secret" {
+		t.Errorf("Unexpected captured values: %v", captured)
+	}
+	if got := recorder.sorted(); got[len(got)-1] != "/orders/99/secret" {
+		t.Errorf("Expected captures to be usable by later steps, got %v", got)
+	}
+}
+
+func TestSetVariablesSharedAcrossRuns(t *testing.T) {
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	executor.SetVariables(map[string]interface{}{"order_id": 7})
+
+	wf := &Workflow{Name: "Uses exported"}
+	if _, err := executor.Execute(context.Background(), wf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value, _ := executor.varManager.Get("order_id"); value != 7 {
+		t.Errorf("Expected exported variable to be visible, got %v", value)
+	}
+}
+
+func TestLoadRejectsInvalidCaptures(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		message string
+	}{
+		"unknown step": {
+			content: "name: wf\ncaptures:\n  id:\n    path: \"$.id\"\n    after: [\"missing\"]\nsteps:\n  - name: s\n",
+			message: `capture id: unknown step "missing"`,
+		},
+		"empty path": {
+			content: "name: wf\ncaptures:\n  id:\n    after: [\"s\"]\nsteps:\n  - name: s\n",
+			message: "capture id: path is required",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writeTempWorkflow(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
+
+func TestComponentCapturesAreMerged(t *testing.T) {
+	dir := t.TempDir()
+	component := "name: auth\ntype: step\ncaptures:\n  session: \"$.session\"\nsteps:\n  - name: login\n    request:\n      method: POST\n      url: http://localhost/login\n"
+	if err := os.WriteFile(filepath.Join(dir, "auth.yml"), []byte(component), 0644); err != nil {
+		t.Fatal(err)
+	}
+	workflowFile := filepath.Join(dir, "workflow.yml")
+	content := "name: wf\nimports:\n  - path: auth.yml\ncaptures:\n  user_id:\n    path: \"$.user.id\"\n    after: [\"sign in\"]\nsteps:\n  - name: sign in\n    use: auth\n"
+	if err := os.WriteFile(workflowFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wf, err := LoadWithImports(workflowFile, []string{dir})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if wf.Captures["session"].Path != "$.session" || wf.Captures["user_id"].Path != "$.user.id" {
+		t.Errorf("Expected component and workflow captures, got %+v", wf.Captures)
+	}
+}
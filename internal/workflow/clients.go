@@ -0,0 +1,77 @@
+package workflow
+
+import (
+	"fmt"
+	"sync"
+
+	grpcclient "github.com/cjp2600/stepwise/internal/grpc"
+	"github.com/cjp2600/stepwise/internal/logger"
+	mcpclient "github.com/cjp2600/stepwise/internal/mcp"
+)
+
+// clientPool holds the gRPC and MCP clients of a run. Clients are created on first use
+// and shared by all steps, including steps running in parallel, so a client is never
+// replaced or closed while another step is still using it.
+type clientPool struct {
+	mu   sync.Mutex
+	grpc map[string]*grpcclient.Client
+	mcp  map[string]*mcpclient.Client
+}
+
+// newClientPool creates an empty client pool
+func newClientPool() *clientPool {
+	return &clientPool{
+		grpc: make(map[string]*grpcclient.Client),
+		mcp:  make(map[string]*mcpclient.Client),
+	}
+}
+
+// grpcClient returns the client for a server, creating it on first use
+func (p *clientPool) grpcClient(serverAddr string, insecure bool, log *logger.Logger) (*grpcclient.Client, error) {
+	key := fmt.Sprintf("%s:%v", serverAddr, insecure)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if client, exists := p.grpc[key]; exists {
+		return client, nil
+	}
+	client, err := grpcclient.NewClient(serverAddr, insecure, log)
+	if err != nil {
+		return nil, err
+	}
+	p.grpc[key] = client
+	log.Debug("Created new gRPC client", "server", serverAddr)
+	return client, nil
+}
+
+// mcpClient returns the client for key, creating it with create on first use
+func (p *clientPool) mcpClient(key string, create func() (*mcpclient.Client, error)) (*mcpclient.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if client, exists := p.mcp[key]; exists {
+		return client, nil
+	}
+	client, err := create()
+	if err != nil {
+		return nil, err
+	}
+	p.mcp[key] = client
+	return client, nil
+}
+
+// closeAll closes and forgets all clients
+func (p *clientPool) closeAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, client := range p.grpc {
+		client.Close()
+		delete(p.grpc, key)
+	}
+	for key, client := range p.mcp {
+		client.Close()
+		delete(p.mcp, key)
+	}
+}
@@ -11,16 +11,18 @@ import (
 
 // Component represents a reusable workflow component
 type Component struct {
-	Name        string                 `yaml:"name" json:"name"`
-	Version     string                 `yaml:"version" json:"version"`
-	Description string                 `yaml:"description" json:"description"`
-	Type        string                 `yaml:"type" json:"type"` // "step", "group", "workflow"
-	Variables   map[string]interface{} `yaml:"variables,omitempty" json:"variables,omitempty"`
-	Steps       []Step                 `yaml:"steps,omitempty" json:"steps,omitempty"`
-	Groups      []StepGroup            `yaml:"groups,omitempty" json:"groups,omitempty"`
-	Exports     []string               `yaml:"exports,omitempty" json:"exports,omitempty"` // Names of exported steps/groups
-	Imports     []Import               `yaml:"imports,omitempty" json:"imports,omitempty"`
-	Captures    map[string]string      `yaml:"captures,omitempty" json:"captures,omitempty"` // Global captures for the component
+	Name        string                   `yaml:"name" json:"name"`
+	Version     string                   `yaml:"version" json:"version"`
+	Description string                   `yaml:"description" json:"description"`
+	Type        string                   `yaml:"type" json:"type"` // "step", "group", "workflow"
+	Variables   map[string]interface{}   `yaml:"variables,omitempty" json:"variables,omitempty"`
+	Setup       []Step                   `yaml:"setup,omitempty" json:"setup,omitempty"`
+	Steps       []Step                   `yaml:"steps,omitempty" json:"steps,omitempty"`
+	Groups      []StepGroup              `yaml:"groups,omitempty" json:"groups,omitempty"`
+	Teardown    []Step                   `yaml:"teardown,omitempty" json:"teardown,omitempty"`
+	Exports     []string                 `yaml:"exports,omitempty" json:"exports,omitempty"` // Names of exported steps/groups
+	Imports     []Import                 `yaml:"imports,omitempty" json:"imports,omitempty"`
+	Captures    map[string]CaptureConfig `yaml:"captures,omitempty" json:"captures,omitempty"` // Captures added to the workflows importing the component
 }
 
 // ComponentManager handles component loading, caching, and dependency resolution
@@ -316,6 +318,7 @@ func (cm *ComponentManager) mergeStepIntoComponent(target *Component, source *Co
 
 	// Add step to target component
 	target.Steps = append(target.Steps, step)
+	target.Setup, target.Teardown = mergeLifecycleSteps(target.Setup, target.Teardown, source)
 
 	// Merge variables
 	if source.Variables != nil {
@@ -330,7 +333,7 @@ func (cm *ComponentManager) mergeStepIntoComponent(target *Component, source *Co
 	// Merge captures
 	if source.Captures != nil {
 		if target.Captures == nil {
-			target.Captures = make(map[string]string)
+			target.Captures = make(map[string]CaptureConfig)
 		}
 		for key, value := range source.Captures {
 			target.Captures[key] = value
@@ -346,8 +349,10 @@ func (cm *ComponentManager) mergeGroupIntoComponent(target *Component, source *C
 	group := StepGroup{
 		Name:        source.Name,
 		Description: source.Description,
+		Setup:       source.Setup,
 		Steps:       source.Steps,
 		Groups:      source.Groups,
+		Teardown:    source.Teardown,
 	}
 
 	// Apply alias if specified
@@ -371,7 +376,7 @@ func (cm *ComponentManager) mergeGroupIntoComponent(target *Component, source *C
 	// Merge captures
 	if source.Captures != nil {
 		if target.Captures == nil {
-			target.Captures = make(map[string]string)
+			target.Captures = make(map[string]CaptureConfig)
 		}
 		for key, value := range source.Captures {
 			target.Captures[key] = value
@@ -385,6 +390,7 @@ func (cm *ComponentManager) mergeGroupIntoComponent(target *Component, source *C
 func (cm *ComponentManager) mergeWorkflowIntoComponent(target *Component, source *Component, imp *Import) error {
 	// Merge steps
 	target.Steps = append(target.Steps, source.Steps...)
+	target.Setup, target.Teardown = mergeLifecycleSteps(target.Setup, target.Teardown, source)
 
 	// Merge groups
 	target.Groups = append(target.Groups, source.Groups...)
@@ -402,7 +408,7 @@ func (cm *ComponentManager) mergeWorkflowIntoComponent(target *Component, source
 	// Merge captures
 	if source.Captures != nil {
 		if target.Captures == nil {
-			target.Captures = make(map[string]string)
+			target.Captures = make(map[string]CaptureConfig)
 		}
 		for key, value := range source.Captures {
 			target.Captures[key] = value
@@ -473,6 +479,7 @@ func (cm *ComponentManager) resolveWorkflowImport(wf *Workflow, imp *Import) err
 func (cm *ComponentManager) mergeStepIntoWorkflow(wf *Workflow, component *Component, imp *Import) error {
 	// Не добавляем шаги из компонента в wf.Steps!
 	// Просто регистрируем переменные и captures, если нужно
+	wf.Setup, wf.Teardown = mergeLifecycleSteps(wf.Setup, wf.Teardown, component)
 
 	// Merge variables
 	if component.Variables != nil {
@@ -487,7 +494,7 @@ func (cm *ComponentManager) mergeStepIntoWorkflow(wf *Workflow, component *Compo
 	// Merge captures
 	if component.Captures != nil {
 		if wf.Captures == nil {
-			wf.Captures = make(map[string]string)
+			wf.Captures = make(map[string]CaptureConfig)
 		}
 		for key, value := range component.Captures {
 			wf.Captures[key] = value
@@ -503,8 +510,10 @@ func (cm *ComponentManager) mergeGroupIntoWorkflow(wf *Workflow, component *Comp
 	group := StepGroup{
 		Name:        component.Name,
 		Description: component.Description,
+		Setup:       component.Setup,
 		Steps:       component.Steps,
 		Groups:      component.Groups,
+		Teardown:    component.Teardown,
 	}
 
 	// Apply alias if specified
@@ -528,7 +537,7 @@ func (cm *ComponentManager) mergeGroupIntoWorkflow(wf *Workflow, component *Comp
 	// Merge captures
 	if component.Captures != nil {
 		if wf.Captures == nil {
-			wf.Captures = make(map[string]string)
+			wf.Captures = make(map[string]CaptureConfig)
 		}
 		for key, value := range component.Captures {
 			wf.Captures[key] = value
@@ -542,6 +551,7 @@ func (cm *ComponentManager) mergeGroupIntoWorkflow(wf *Workflow, component *Comp
 func (cm *ComponentManager) mergeWorkflowIntoWorkflow(wf *Workflow, component *Component, imp *Import) error {
 	// Merge steps
 	wf.Steps = append(wf.Steps, component.Steps...)
+	wf.Setup, wf.Teardown = mergeLifecycleSteps(wf.Setup, wf.Teardown, component)
 
 	// Merge groups
 	wf.Groups = append(wf.Groups, component.Groups...)
@@ -559,7 +569,7 @@ func (cm *ComponentManager) mergeWorkflowIntoWorkflow(wf *Workflow, component *C
 	// Merge captures
 	if component.Captures != nil {
 		if wf.Captures == nil {
-			wf.Captures = make(map[string]string)
+			wf.Captures = make(map[string]CaptureConfig)
 		}
 		for key, value := range component.Captures {
 			wf.Captures[key] = value
@@ -568,3 +578,16 @@ func (cm *ComponentManager) mergeWorkflowIntoWorkflow(wf *Workflow, component *C
 
 	return nil
 }
+
+// mergeLifecycleSteps hoists setup and teardown of an imported component into the importer.
+// Setup of the component runs after the importer's existing setup, its teardown runs
+// before the existing teardown so resources are released in reverse order of creation.
+func mergeLifecycleSteps(setup, teardown []Step, component *Component) ([]Step, []Step) {
+	if len(component.Setup) > 0 {
+		setup = append(setup, component.Setup...)
+	}
+	if len(component.Teardown) > 0 {
+		teardown = append(append([]Step{}, component.Teardown...), teardown...)
+	}
+	return setup, teardown
+}
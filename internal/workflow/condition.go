@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/cjp2600/stepwise/internal/expression"
+)
+
+// conditionEnv resolves variables of condition expressions from the executor
+type conditionEnv struct {
+	e *Executor
+}
+
+// Lookup returns the value of a variable, keeping its type. Fields of captured objects
+// are reached with dots, e.g. {{user.address.city}}, and pipes keep the type of their
+// result, e.g. {{items | length}}. Other references such as {{faker.email}} or
+// {{env.HOME}} are expanded as strings.
+func (c conditionEnv) Lookup(ref string) (interface{}, bool) {
+	if value, exists := c.e.varManager.Resolve(ref); exists {
+		return value, true
+	}
+
+	placeholder := "{{" + ref + "}}"
+	substituted, err := c.e.varManager.Substitute(placeholder)
+	if err != nil || substituted == placeholder {
+		return nil, false
+	}
+	return substituted, true
+}
+
+// Substitute expands variables inside string literals
+func (c conditionEnv) Substitute(input string) (string, error) {
+	return c.e.varManager.Substitute(input)
+}
+
+// evaluateCondition evaluates a condition expression. Conditions that cannot be
+// evaluated, e.g. comparing a list with a number, count as false.
+func (e *Executor) evaluateCondition(condition string) bool {
+	if condition == "" {
+		return true
+	}
+
+	expr, err := expression.Parse(condition)
+	if err != nil {
+		e.logger.Warn("Invalid condition", "condition", condition, "error", err)
+		return false
+	}
+
+	result, err := expr.EvaluateBool(conditionEnv{e: e})
+	if err != nil {
+		e.logger.Warn("Failed to evaluate condition", "condition", condition, "error", err)
+		return false
+	}
+	return result
+}
+
+// validateWorkflowConditions parses every condition of a workflow so that syntax
+// errors are reported when the workflow is loaded rather than at run time
+func validateWorkflowConditions(wf *Workflow) error {
+	for _, steps := range [][]Step{wf.Setup, wf.Steps, wf.Teardown} {
+		if err := validateStepConditions(steps); err != nil {
+			return err
+		}
+	}
+	return validateGroupConditions(wf.Groups)
+}
+
+// validateGroupConditions validates the conditions of groups and their steps
+func validateGroupConditions(groups []StepGroup) error {
+	for _, group := range groups {
+		if err := validateCondition(group.Condition); err != nil {
+			return fmt.Errorf("group %s: %w", group.Name, err)
+		}
+		for _, steps := range [][]Step{group.Setup, group.Steps, group.Teardown} {
+			if err := validateStepConditions(steps); err != nil {
+				return fmt.Errorf("group %s: %w", group.Name, err)
+			}
+		}
+		if err := validateGroupConditions(group.Groups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateStepConditions validates condition, if and branch conditions of steps,
+// including the steps nested in then, else and branches
+func validateStepConditions(steps []Step) error {
+	for _, step := range steps {
+		if err := validateCondition(step.Condition); err != nil {
+			return fmt.Errorf("step %s: %w", step.Name, err)
+		}
+		if err := validateCondition(step.If); err != nil {
+			return fmt.Errorf("step %s: if: %w", step.Name, err)
+		}
+		for i, branch := range step.Branches {
+			if err := validateCondition(branch.Condition); err != nil {
+				return fmt.Errorf("step %s: branch %d: %w", step.Name, i+1, err)
+			}
+			if err := validateStepConditions(branch.Steps); err != nil {
+				return fmt.Errorf("step %s: %w", step.Name, err)
+			}
+		}
+		for _, nested := range [][]Step{step.Then, step.Else} {
+			if err := validateStepConditions(nested); err != nil {
+				return fmt.Errorf("step %s: %w", step.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateCondition parses a single condition; empty conditions are valid
+func validateCondition(condition string) error {
+	if condition == "" {
+		return nil
+	}
+	if _, err := expression.Parse(condition); err != nil {
+		return fmt.Errorf("invalid condition: %w", err)
+	}
+	return nil
+}
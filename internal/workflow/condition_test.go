@@ -0,0 +1,97 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	executor.varManager.Set("user", map[string]interface{}{
+		"name":    "Ann",
+		"roles":   []interface{}{"admin"},
+		"address": map[string]interface{}{"city": "Oslo"},
+	})
+	executor.varManager.Set("user_id", 42)
+	executor.varManager.Set("items", []interface{}{
+		map[string]interface{}{"sku": "A", "qty": float64(1)},
+		map[string]interface{}{"sku": "B", "qty": float64(5)},
+	})
+	executor.varManager.Set("title", "Tom & Jerry == classic")
+
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{"", true},
+		{"{{user_id}} == 42 && {{user.name}} == 'Ann'", true},
+		{"{{user.address.city}} == 'Oslo'", true},
+		{"'admin' in {{user.roles}}", true},
+		{"({{user_id}} > 100 || {{user_id}} < 50) && len({{user.roles}}) == 1", true},
+		{"{{title}} == 'Tom & Jerry == classic'", true},
+		{"{{title}} contains '&&'", false},
+		{"{{user.missing}}", false},
+		{"{{user.roles | length}} == 1", true},
+		{"{{user.name | upper}} == 'ANN'", true},
+		{"{{user.nickname | default:'none'}} == 'none'", true},
+		{"{{items[1].qty}} == 5", true},
+		{"{{items[last].sku}} == 'B'", true},
+		{"{{items[?(@.sku == \"B\")].qty}} > 3", true},
+		{"'A' in {{items[*].sku}}", true},
+		// Evaluation errors count as false
+		{"{{user.roles}} > 1", false},
+		// Syntax errors count as false
+		{"{{user_id}} = 42", false},
+	}
+
+	for _, tt := range tests {
+		if got := executor.evaluateCondition(tt.condition); got != tt.want {
+			t.Errorf("evaluateCondition(%q) = %v, want %v", tt.condition, got, tt.want)
+		}
+	}
+}
+
+func TestLoadRejectsInvalidConditions(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		message string
+	}{
+		"step condition": {
+			content: "name: wf\nsteps:\n  - name: s\n    condition: \"{{a}} = 1\"\n",
+			message: "step s: invalid condition",
+		},
+		"if": {
+			content: "name: wf\nsteps:\n  - name: s\n    if: \"{{a}} == open\"\n",
+			message: "step s: if: invalid condition",
+		},
+		"branch": {
+			content: "name: wf\nsteps:\n  - name: s\n    branches:\n      - condition: \"({{a}}\"\n        steps: []\n",
+			message: "step s: branch 1: invalid condition",
+		},
+		"nested then step": {
+			content: "name: wf\nsteps:\n  - name: s\n    if: \"true\"\n    then:\n      - name: inner\n        condition: \"{{a}} ==\"\n",
+			message: "step s: step inner: invalid condition",
+		},
+		"group": {
+			content: "name: wf\ngroups:\n  - name: g\n    condition: \"{{a}} &&\"\n    steps: []\n",
+			message: "group g: invalid condition",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writeTempWorkflow(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+
+	if _, err := Load(writeTempWorkflow(t, "name: wf\nsteps:\n  - name: s\n    condition: \"{{a}} == 'x' && ({{b}} > 1 || !{{c}})\"\n")); err != nil {
+		t.Errorf("Expected valid condition to load, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+)
+
+func TestCustomValidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items": [{"id": 3}, {"id": 1}, {"id": 2}], "total": 3}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "checks"), 0755); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "checks", "lib.star"), []byte(`
+def ids(body):
+    return [item["id"] for item in body["items"]]
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "checks", "total.star"), []byte(`
+load("lib.star", "ids")
+return len(ids(response.body)) == args["total"], "got %d items" % len(ids(response.body))
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(dir, "workflow.yml")
+	if err := os.WriteFile(path, []byte(`name: custom
+variables:
+  total: 3
+steps:
+  - name: file
+    request:
+      method: GET
+      url: "`+server.URL+`"
+    validate:
+      - custom_file: checks/total.star
+        args:
+          total: "{{total}}"
+  - name: inline
+    request:
+      method: GET
+      url: "`+server.URL+`"
+    validate:
+      - custom: |
+          load("checks/lib.star", "ids")
+          if ids(response.body) != sorted(ids(response.body)):
+              fail("ids are not sorted:", ids(response.body))
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wf, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if results[0].Status != "passed" || results[0].Validations[0].Actual != "got 3 items" {
+		t.Errorf("Expected the script file to pass, got %s: %s %+v", results[0].Status, results[0].Error, results[0].Validations)
+	}
+	if results[1].Status != "failed" || !strings.Contains(results[1].Error, "custom validation failed: ids are not sorted: [3, 1, 2]") {
+		t.Errorf("Expected the inline script to fail, got %s: %s", results[1].Status, results[1].Error)
+	}
+}
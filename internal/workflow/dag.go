@@ -0,0 +1,295 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// defaultMaxParallel is the worker limit for steps scheduled by needs when the workflow doesn't set max_parallel
+const defaultMaxParallel = 4
+
+// stepRunner executes the step at the given index and returns its result.
+// A non-nil error stops scheduling of any further steps (fail-fast).
+type stepRunner func(stepIndex int, step *Step) (*TestResult, error)
+
+// stepKey returns the name other steps use to reference a step in needs
+func stepKey(step *Step) string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return step.Use
+}
+
+// hasStepDependencies reports whether any of the steps declares needs
+func hasStepDependencies(steps []Step) bool {
+	for _, step := range steps {
+		if len(step.Needs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// validateWorkflowDependencies validates needs of the workflow steps and of all nested groups
+func validateWorkflowDependencies(wf *Workflow) error {
+	if err := validateStepDependencies(wf.Steps); err != nil {
+		return err
+	}
+	return validateGroupDependencies(wf.Groups)
+}
+
+// validateGroupDependencies validates needs of the steps in each group recursively
+func validateGroupDependencies(groups []StepGroup) error {
+	for _, group := range groups {
+		if err := validateStepDependencies(group.Steps); err != nil {
+			return fmt.Errorf("group %s: %w", group.Name, err)
+		}
+		if err := validateGroupDependencies(group.Groups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateStepDependencies checks that needs reference existing sibling steps and form no cycles
+func validateStepDependencies(steps []Step) error {
+	if !hasStepDependencies(steps) {
+		return nil
+	}
+
+	index := make(map[string]int, len(steps))
+	for i := range steps {
+		key := stepKey(&steps[i])
+		if key == "" {
+			return fmt.Errorf("step #%d must have a name when needs are used", i+1)
+		}
+		if _, exists := index[key]; exists {
+			return fmt.Errorf("duplicate step name %q: names must be unique when needs are used", key)
+		}
+		index[key] = i
+	}
+
+	for i := range steps {
+		for _, need := range steps[i].Needs {
+			if _, exists := index[need]; !exists {
+				return fmt.Errorf("step %q needs unknown step %q", stepKey(&steps[i]), need)
+			}
+			if need == stepKey(&steps[i]) {
+				return fmt.Errorf("step %q depends on itself", need)
+			}
+		}
+	}
+
+	// Depth-first search with three colors to find cycles
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(steps))
+	var path []string
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		state[i] = visiting
+		path = append(path, stepKey(&steps[i]))
+		for _, need := range steps[i].Needs {
+			j := index[need]
+			switch state[j] {
+			case visiting:
+				// Cut the path at the first occurrence of the repeated step
+				start := 0
+				for k, name := range path {
+					if name == need {
+						start = k
+						break
+					}
+				}
+				cycle := append(append([]string{}, path[start:]...), need)
+				return fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+			case unvisited:
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+
+	for i := range steps {
+		if state[i] == unvisited {
+			if err := visit(i); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveMaxParallel returns the worker limit for steps scheduled by needs
+func (e *Executor) resolveMaxParallel(wf *Workflow) int {
+	if wf != nil && wf.MaxParallel > 0 {
+		return wf.MaxParallel
+	}
+	return defaultMaxParallel
+}
+
+// executeStepsDAG runs steps as a dependency graph built from their needs.
+// Independent steps run concurrently, bounded by maxParallel. Steps whose
+// dependencies did not pass are not executed and are reported as skipped,
+// unless they are marked always. Results are returned in declaration order.
+func (e *Executor) executeStepsDAG(steps []Step, maxParallel int, run stepRunner) ([]TestResult, error) {
+	if maxParallel <= 0 {
+		maxParallel = defaultMaxParallel
+	}
+
+	index := make(map[string]int, len(steps))
+	for i := range steps {
+		index[stepKey(&steps[i])] = i
+	}
+
+	pending := make([]int, len(steps))      // number of unfinished dependencies
+	dependents := make([][]int, len(steps)) // steps waiting for this one
+	blockedBy := make([]string, len(steps)) // first dependency that did not pass
+	blockedStatus := make([]string, len(steps))
+	for i := range steps {
+		for _, need := range steps[i].Needs {
+			j, ok := index[need]
+			if !ok {
+				return nil, fmt.Errorf("step %q needs unknown step %q", stepKey(&steps[i]), need)
+			}
+			pending[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	e.logger.Info("Executing steps as dependency graph", "steps", len(steps), "max_parallel", maxParallel)
+
+	type completion struct {
+		index  int
+		result *TestResult
+		err    error
+	}
+
+	results := make([]*TestResult, len(steps))
+	done := make(chan completion, len(steps))
+	var wg sync.WaitGroup
+
+	var ready []int
+	for i := range steps {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	running := 0
+	finished := 0
+	var stopErr error
+
+	// complete records a finished step and releases its dependents
+	var complete func(i int, result *TestResult)
+	complete = func(i int, result *TestResult) {
+		results[i] = result
+		finished++
+		for _, d := range dependents[i] {
+			if result.Status != "passed" && blockedBy[d] == "" {
+				blockedBy[d] = stepKey(&steps[i])
+				blockedStatus[d] = result.Status
+			}
+			pending[d]--
+			if pending[d] > 0 {
+				continue
+			}
+			if blockedBy[d] == "" || steps[d].Always {
+				ready = append(ready, d)
+				continue
+			}
+			reason := "dependency failed"
+			if blockedStatus[d] == "skipped" {
+				reason = "dependency skipped"
+			}
+			e.logger.Info("Skipping step due to dependency", "step", stepKey(&steps[d]), "dependency", blockedBy[d], "status", blockedStatus[d])
+			complete(d, &TestResult{
+				Name:   stepKey(&steps[d]),
+				Status: "skipped",
+				Error:  fmt.Sprintf("skipped: %s (%s)", reason, blockedBy[d]),
+			})
+		}
+	}
+
+	for finished < len(steps) {
+		if err := e.context().Err(); err != nil && stopErr == nil {
+			stopErr = interruptError(err)
+		}
+		for stopErr == nil && len(ready) > 0 && running < maxParallel {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				step := steps[i]
+				result, err := run(i, &step)
+				done <- completion{index: i, result: result, err: err}
+			}(i)
+		}
+
+		if running == 0 {
+			// Nothing in flight and nothing can be started: either fail-fast stopped us or the graph is stuck
+			break
+		}
+
+		c := <-done
+		running--
+		if c.err != nil && stopErr == nil {
+			stopErr = c.err
+		}
+		if c.result == nil {
+			c.result = &TestResult{Name: stepKey(&steps[c.index]), Status: "failed"}
+			if c.err != nil {
+				c.result.Error = c.err.Error()
+			}
+		}
+		complete(c.index, c.result)
+	}
+
+	wg.Wait()
+
+	// Always steps that were never started still run once scheduling stopped
+	if stopErr != nil {
+		for i := range steps {
+			if results[i] != nil || !steps[i].Always {
+				continue
+			}
+			step := steps[i]
+			e.logger.Info("Running always step after stop", "step", stepKey(&step))
+			e.withCleanupContext(func() {
+				result, _ := run(i, &step)
+				if result == nil {
+					result = &TestResult{Name: stepKey(&step), Status: "failed"}
+				}
+				results[i] = result
+			})
+			finished++
+		}
+	}
+
+	var ordered []TestResult
+	for _, result := range results {
+		if result != nil {
+			ordered = append(ordered, *result)
+		}
+	}
+
+	if stopErr != nil {
+		return ordered, stopErr
+	}
+	if finished < len(steps) {
+		return ordered, fmt.Errorf("dependency graph could not be completed: %d of %d steps finished", finished, len(steps))
+	}
+	return ordered, nil
+}
@@ -0,0 +1,268 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+	"github.com/cjp2600/stepwise/internal/validation"
+)
+
+func writeTempWorkflow(t *testing.T, content string) string {
+	t.Helper()
+	tmpfile, err := os.CreateTemp("", "workflow-*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(tmpfile.Name()) })
+	if _, err := tmpfile.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	tmpfile.Close()
+	return tmpfile.Name()
+}
+
+func TestLoadRejectsDependencyCycle(t *testing.T) {
+	path := writeTempWorkflow(t, `name: "Cycle"
+steps:
+  - name: "a"
+    print: "a"
+    needs: ["c"]
+  - name: "b"
+    print: "b"
+    needs: ["a"]
+  - name: "c"
+    print: "c"
+    needs: ["b"]
+`)
+
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("Expected cycle error, got nil")
+	}
+	if !strings.Contains(err.Error(), "dependency cycle detected: a -> c -> b -> a") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestValidateStepDependencies(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []Step
+		wantErr string
+	}{
+		{
+			name:  "no needs",
+			steps: []Step{{Name: "a"}, {Name: "a"}},
+		},
+		{
+			name:  "valid graph",
+			steps: []Step{{Name: "a"}, {Name: "b", Needs: []string{"a"}}, {Name: "c", Needs: []string{"a", "b"}}},
+		},
+		{
+			name:    "unknown step",
+			steps:   []Step{{Name: "a"}, {Name: "b", Needs: []string{"missing"}}},
+			wantErr: `step "b" needs unknown step "missing"`,
+		},
+		{
+			name:    "self dependency",
+			steps:   []Step{{Name: "a", Needs: []string{"a"}}},
+			wantErr: `step "a" depends on itself`,
+		},
+		{
+			name:    "duplicate names",
+			steps:   []Step{{Name: "a"}, {Name: "a", Needs: []string{"a"}}},
+			wantErr: `duplicate step name "a"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStepDependencies(tt.steps)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestExecuteStepsDAG(t *testing.T) {
+	var mu sync.Mutex
+	inFlight := 0
+	maxInFlight := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ok := Request{Method: "GET", URL: server.URL + "/ok"}
+	status200 := []validation.ValidationRule{{Status: 200}}
+
+	wf := &Workflow{
+		Name: "DAG",
+		Steps: []Step{
+			{Name: "login", Request: ok, Validate: status200},
+			{Name: "users", Request: ok, Validate: status200},
+			{Name: "broken", Request: Request{Method: "GET", URL: server.URL + "/fail"}, Validate: status200},
+			{Name: "profile", Request: ok, Validate: status200, Needs: []string{"login", "users"}},
+			{Name: "orders", Request: ok, Validate: status200, Needs: []string{"broken"}},
+			{Name: "invoice", Request: ok, Validate: status200, Needs: []string{"orders"}},
+		},
+	}
+
+	cfg := &config.Config{Timeout: 5 * time.Second}
+	executor := NewExecutor(cfg, logger.New())
+
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results) != len(wf.Steps) {
+		t.Fatalf("Expected %d results, got %d", len(wf.Steps), len(results))
+	}
+
+	expected := []struct {
+		name   string
+		status string
+		err    string
+	}{
+		{"login", "passed", ""},
+		{"users", "passed", ""},
+		{"broken", "failed", ""},
+		{"profile", "passed", ""},
+		{"orders", "skipped", "skipped: dependency failed (broken)"},
+		{"invoice", "skipped", "skipped: dependency skipped (orders)"},
+	}
+	for i, exp := range expected {
+		if results[i].Name != exp.name {
+			t.Errorf("Result %d: expected name %s, got %s", i, exp.name, results[i].Name)
+		}
+		if results[i].Status != exp.status {
+			t.Errorf("Step %s: expected status %s, got %s", exp.name, exp.status, results[i].Status)
+		}
+		if exp.err != "" && results[i].Error != exp.err {
+			t.Errorf("Step %s: expected error %q, got %q", exp.name, exp.err, results[i].Error)
+		}
+	}
+
+	if maxInFlight < 2 {
+		t.Errorf("Expected independent steps to run concurrently, max in flight was %d", maxInFlight)
+	}
+}
+
+func TestExecuteStepsDAGMaxParallel(t *testing.T) {
+	var mu sync.Mutex
+	inFlight := 0
+	maxInFlight := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(30 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ok := Request{Method: "GET", URL: server.URL}
+	wf := &Workflow{
+		Name:        "Limited",
+		MaxParallel: 2,
+		Steps: []Step{
+			{Name: "a", Request: ok},
+			{Name: "b", Request: ok},
+			{Name: "c", Request: ok},
+			{Name: "d", Request: ok},
+			{Name: "e", Request: ok, Needs: []string{"a", "b", "c", "d"}},
+		},
+	}
+
+	cfg := &config.Config{Timeout: 5 * time.Second}
+	executor := NewExecutor(cfg, logger.New())
+
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, result := range results {
+		if result.Status != "passed" {
+			t.Errorf("Step %s: expected passed, got %s (%s)", result.Name, result.Status, result.Error)
+		}
+	}
+	if maxInFlight > 2 {
+		t.Errorf("Expected at most 2 steps in flight, got %d", maxInFlight)
+	}
+}
+
+func TestExecuteStepsDAGAlways(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	status200 := []validation.ValidationRule{{Status: 200}}
+	wf := &Workflow{
+		Name: "Always",
+		Steps: []Step{
+			{Name: "create", Request: Request{Method: "GET", URL: server.URL + "/fail"}, Validate: status200},
+			{Name: "use", Request: Request{Method: "GET", URL: server.URL}, Validate: status200, Needs: []string{"create"}},
+			{Name: "cleanup", Request: Request{Method: "GET", URL: server.URL}, Validate: status200, Needs: []string{"create"}, Always: true},
+		},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results[1].Status != "skipped" {
+		t.Errorf("Expected dependent step to be skipped, got %s", results[1].Status)
+	}
+	if results[2].Status != "passed" {
+		t.Errorf("Expected always step to run, got %s (%s)", results[2].Status, results[2].Error)
+	}
+}
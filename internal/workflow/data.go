@@ -0,0 +1,179 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/cjp2600/stepwise/internal/dataset"
+	"gopkg.in/yaml.v3"
+)
+
+// DataConfig represents an external data source. The workflow or group it belongs to
+// runs once per row, with the columns of the row available as variables.
+type DataConfig struct {
+	File   string `yaml:"file" json:"file"`                         // CSV, JSON, YAML or JSONL file, relative to the workflow file
+	Format string `yaml:"format,omitempty" json:"format,omitempty"` // csv, json, yaml or jsonl; detected from the extension by default
+}
+
+// UnmarshalYAML accepts the full form as well as the shorthand `data: users.csv`
+func (d *DataConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&d.File)
+	}
+	type plain DataConfig
+	return node.Decode((*plain)(d))
+}
+
+// validate checks that the data source names a file in a known format
+func (d *DataConfig) validate() error {
+	if d.File == "" {
+		return fmt.Errorf("file is required")
+	}
+	if d.Format != "" {
+		_, err := dataset.NormalizeFormat(d.Format)
+		return err
+	}
+	_, err := dataset.DetectFormat(d.File)
+	return err
+}
+
+// validateWorkflowData validates the data sections of a workflow and its groups
+func validateWorkflowData(wf *Workflow) error {
+	if wf.Data != nil {
+		if err := wf.Data.validate(); err != nil {
+			return err
+		}
+	}
+	return validateGroupData(wf.Groups)
+}
+
+// validateGroupData validates the data sections of groups, including nested groups
+func validateGroupData(groups []StepGroup) error {
+	for _, group := range groups {
+		if group.Data != nil {
+			if err := group.Data.validate(); err != nil {
+				return fmt.Errorf("group %s: %w", group.Name, err)
+			}
+		}
+		if err := validateGroupData(group.Groups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetDataFile sets a data file that replaces the data section of executed workflows
+func (e *Executor) SetDataFile(path string) {
+	e.dataFile = path
+}
+
+// workflowData returns the data source the whole workflow runs against, if any
+func (e *Executor) workflowData(wf *Workflow) *DataConfig {
+	if e.dataFile != "" {
+		return &DataConfig{File: e.dataFile}
+	}
+	return wf.Data
+}
+
+// loadDataRows loads the rows of a data source. Relative paths are resolved against
+// the directory of the workflow file.
+func (e *Executor) loadDataRows(config *DataConfig) ([]map[string]interface{}, error) {
+	if config.File == "" {
+		return nil, fmt.Errorf("data file is required")
+	}
+
+	path := config.File
+	if !filepath.IsAbs(path) && e.workflowDir != "" {
+		path = filepath.Join(e.workflowDir, path)
+	}
+
+	rows, err := dataset.Load(path, config.Format)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("data file %s has no rows", config.File)
+	}
+	return rows, nil
+}
+
+// rowBindings returns the variables visible while running a data row: every column
+// by name, the whole row as {{row}} with its fields as {{row.column}}, and {{row_index}}
+func rowBindings(index int, row map[string]interface{}) map[string]interface{} {
+	bindings := map[string]interface{}{
+		"row":       row,
+		"row_index": index,
+	}
+	flattenItem(bindings, "row", row)
+	for column, value := range row {
+		bindings[column] = value
+	}
+	return bindings
+}
+
+// rowLabel identifies a data row in results, e.g. `row 2 {"name":"bob"}`
+func rowLabel(index int, row map[string]interface{}) string {
+	return fmt.Sprintf("row %d %s", index+1, formatItem(row))
+}
+
+// executeDataRows runs the whole workflow once per data row. Every row starts from the
+// workflow variables with its own columns on top, and its results are labelled with the row.
+func (e *Executor) executeDataRows(ctx context.Context, wf *Workflow, config *DataConfig) ([]TestResult, error) {
+	rows, err := e.loadDataRows(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data: %w", err)
+	}
+
+	e.logger.Info("Executing workflow once per data row", "file", config.File, "rows", len(rows))
+
+	var allResults []TestResult
+	var firstErr error
+	for i, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return allResults, interruptError(err)
+		}
+
+		label := rowLabel(i, row)
+		e.logger.Info("Executing data row", "row", i+1, "total", len(rows))
+
+		results, err := e.fork("row", rowBindings(i, row)).executeRun(ctx, wf)
+		for j := range results {
+			results[j].Row = label
+		}
+		allResults = append(allResults, results...)
+
+		if err != nil {
+			// Fail-fast and interruption stop the remaining rows as well
+			if e.failFast || ctx.Err() != nil {
+				return allResults, err
+			}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", label, err)
+			}
+		}
+	}
+	return allResults, firstErr
+}
+
+// executeGroupData executes the steps of a group once per data row
+func (e *Executor) executeGroupData(group *StepGroup, groupResult *GroupResult) error {
+	rows, err := e.loadDataRows(group.Data)
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+
+	e.logger.Info("Executing group once per data row", "group", group.Name, "file", group.Data.File, "rows", len(rows))
+
+	items := make([]interface{}, len(rows))
+	for i, row := range rows {
+		items[i] = row
+	}
+
+	body := *group
+	body.Data = nil
+
+	return e.executeGroupIterations(&body, groupResult, items, false, "", func(i int, item interface{}) map[string]interface{} {
+		return rowBindings(i, rows[i])
+	})
+}
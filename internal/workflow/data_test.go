@@ -0,0 +1,193 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+	"github.com/cjp2600/stepwise/internal/validation"
+	"gopkg.in/yaml.v3"
+)
+
+// writeDataFile writes a data file next to the workflow files of a test
+func writeDataFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDataConfigUnmarshal(t *testing.T) {
+	var wf Workflow
+	if err := yaml.Unmarshal([]byte(`
+name: "short"
+data: users.csv
+`), &wf); err != nil {
+		t.Fatal(err)
+	}
+	if wf.Data == nil || wf.Data.File != "users.csv" {
+		t.Errorf("Expected shorthand file, got %+v", wf.Data)
+	}
+
+	if err := yaml.Unmarshal([]byte(`
+name: "full"
+data:
+  file: users.txt
+  format: jsonl
+`), &wf); err != nil {
+		t.Fatal(err)
+	}
+	if wf.Data.File != "users.txt" || wf.Data.Format != "jsonl" {
+		t.Errorf("Unexpected config: %+v", wf.Data)
+	}
+}
+
+func TestLoadRejectsInvalidData(t *testing.T) {
+	tests := map[string]string{
+		"unknown extension":  "name: wf\ndata: users.txt\nsteps: []\n",
+		"unknown format":     "name: wf\ndata:\n  file: users.csv\n  format: xml\nsteps: []\n",
+		"missing group file": "name: wf\ngroups:\n  - name: g\n    data:\n      format: csv\n    steps: []\n",
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(writeTempWorkflow(t, content)); err == nil || !strings.Contains(err.Error(), "invalid data section") {
+				t.Errorf("Expected invalid data section error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWorkflowDataRows(t *testing.T) {
+	recorder := &pathRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(recorder.handler))
+	defer server.Close()
+
+	dir := t.TempDir()
+	writeDataFile(t, dir, "users.csv", "name,role\nalice,admin\nbob,bad\n")
+
+	wf := &Workflow{
+		Name:       "Data",
+		Variables:  map[string]interface{}{"base_url": server.URL, "role": "default"},
+		Data:       &DataConfig{File: "users.csv"},
+		SourceFile: filepath.Join(dir, "workflow.yml"),
+		Steps: []Step{
+			{
+				Name:     "user",
+				Request:  Request{Method: "GET", URL: "{{base_url}}/{{row_index}}/{{row.name}}/{{role}}"},
+				Validate: []validation.ValidationRule{{Status: 200}},
+			},
+		},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected one result per row, got %d", len(results))
+	}
+	if results[0].Status != "passed" || results[1].Status != "failed" {
+		t.Errorf("Expected passed and failed, got %s and %s", results[0].Status, results[1].Status)
+	}
+	if results[0].Row != `row 1 {"name":"alice","role":"admin"}` || results[1].Row != `row 2 {"name":"bob","role":"bad"}` {
+		t.Errorf("Unexpected row labels: %q, %q", results[0].Row, results[1].Row)
+	}
+
+	want := []string{"/0/alice/admin", "/1/bob/bad"}
+	if got := recorder.sorted(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected paths %v, got %v", want, got)
+	}
+
+	// Row columns must not leak into the executor's own variables
+	if value, _ := executor.varManager.Get("role"); value != "default" {
+		t.Errorf("Expected workflow variable to be untouched, got %v", value)
+	}
+}
+
+func TestDataFileOverrideWithFailFast(t *testing.T) {
+	recorder := &pathRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(recorder.handler))
+	defer server.Close()
+
+	dataFile := writeDataFile(t, t.TempDir(), "ids.jsonl", "{\"id\": \"bad\"}\n{\"id\": \"ok\"}\n")
+
+	wf := &Workflow{
+		Name: "Override",
+		Data: &DataConfig{File: "ignored.csv"},
+		Steps: []Step{
+			{
+				Name:     "item",
+				Request:  Request{Method: "GET", URL: server.URL + "/items/{{id}}"},
+				Validate: []validation.ValidationRule{{Status: 200}},
+			},
+		},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	executor.SetDataFile(dataFile)
+	executor.SetFailFast(true)
+
+	results, err := executor.Execute(context.Background(), wf)
+	if err == nil {
+		t.Fatal("Expected fail-fast error")
+	}
+	if len(results) != 1 || results[0].Row == "" {
+		t.Fatalf("Expected only the first row to run, got %+v", results)
+	}
+	if got := recorder.sorted(); strings.Join(got, ",") != "/items/bad" {
+		t.Errorf("Expected remaining rows to be skipped, got %v", got)
+	}
+}
+
+func TestGroupData(t *testing.T) {
+	recorder := &pathRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(recorder.handler))
+	defer server.Close()
+
+	dir := t.TempDir()
+	writeDataFile(t, dir, "regions.yml", "- region: eu\n- region: us\n")
+
+	wf := &Workflow{
+		Name:       "Group data",
+		SourceFile: filepath.Join(dir, "workflow.yml"),
+		Groups: []StepGroup{
+			{
+				Name: "regions",
+				Data: &DataConfig{File: "regions.yml"},
+				Steps: []Step{
+					{Name: "health", Request: Request{Method: "GET", URL: server.URL + "/{{region}}/health"}},
+				},
+			},
+		},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results) != 1 || results[0].RepeatCount != 2 {
+		t.Fatalf("Expected one aggregated result with 2 rows, got %+v", results)
+	}
+	if results[0].RepeatResults[1].Item != `{"region":"us"}` {
+		t.Errorf("Unexpected row item: %s", results[0].RepeatResults[1].Item)
+	}
+
+	want := []string{"/eu/health", "/us/health"}
+	if got := recorder.sorted(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected paths %v, got %v", want, got)
+	}
+}
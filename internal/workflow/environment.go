@@ -0,0 +1,150 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// environmentDir is the directory next to the workflow file that holds env/<name>.yml files
+const environmentDir = "env"
+
+// SetEnvironment selects the environment whose variables are layered over the workflow variables
+func (e *Executor) SetEnvironment(name string) {
+	e.environment = name
+}
+
+// Environment returns the selected environment
+func (e *Executor) Environment() string {
+	return e.environment
+}
+
+// SetOverrides sets variables that take precedence over the workflow and environment
+// variables, such as the values of --var-file and --var
+func (e *Executor) SetOverrides(vars map[string]interface{}) {
+	e.overrides = vars
+}
+
+// resolveVariables returns the variables of a workflow run. Later layers win:
+// workflow variables, the environments block, env/<name>.yml, overrides.
+func (e *Executor) resolveVariables(wf *Workflow) (map[string]interface{}, error) {
+	vars := make(map[string]interface{}, len(wf.Variables))
+	for key, value := range wf.Variables {
+		vars[key] = value
+	}
+
+	if e.environment != "" {
+		envVars, err := environmentVariables(wf, e.environment)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range envVars {
+			vars[key] = value
+		}
+	}
+
+	for key, value := range e.overrides {
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// environmentVariables returns the variables of the named environment, taken from the
+// environments block and the env/<name>.yml file next to the workflow, the file winning.
+// A workflow that declares no environments at all accepts any name.
+func environmentVariables(wf *Workflow, name string) (map[string]interface{}, error) {
+	vars := make(map[string]interface{})
+	block, inBlock := wf.Environments[name]
+	for key, value := range block {
+		vars[key] = value
+	}
+
+	file := environmentFile(wf, name)
+	if file != "" {
+		fileVars, err := LoadVariablesFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("environment %s: %w", name, err)
+		}
+		for key, value := range fileVars {
+			vars[key] = value
+		}
+	}
+
+	if !inBlock && file == "" {
+		if known := environmentNames(wf); len(known) > 0 {
+			return nil, fmt.Errorf("unknown environment %q (available: %s)", name, strings.Join(known, ", "))
+		}
+	}
+	return vars, nil
+}
+
+// environmentFile returns the path of env/<name>.yml (or .yaml) next to the workflow, or "" if there is none
+func environmentFile(wf *Workflow, name string) string {
+	if wf.SourceFile == "" {
+		return ""
+	}
+	dir := filepath.Join(filepath.Dir(wf.SourceFile), environmentDir)
+	for _, ext := range []string{".yml", ".yaml"} {
+		path := filepath.Join(dir, name+ext)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// environmentNames returns the environments a workflow declares, in the block or as env files
+func environmentNames(wf *Workflow) []string {
+	seen := make(map[string]bool)
+	for name := range wf.Environments {
+		seen[name] = true
+	}
+	if wf.SourceFile != "" {
+		entries, _ := os.ReadDir(filepath.Join(filepath.Dir(wf.SourceFile), environmentDir))
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if !entry.IsDir() && (ext == ".yml" || ext == ".yaml") {
+				seen[strings.TrimSuffix(entry.Name(), ext)] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadVariablesFile reads a flat map of variables from a YAML or JSON file
+func LoadVariablesFile(path string) (map[string]interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variables file: %w", err)
+	}
+
+	vars := make(map[string]interface{})
+	if err := yaml.Unmarshal(content, &vars); err != nil {
+		return nil, fmt.Errorf("failed to parse variables file %s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// ParseVariableOverrides parses key=value pairs as given to --var. Values are kept as strings.
+func ParseVariableOverrides(pairs []string) (map[string]interface{}, error) {
+	vars := make(map[string]interface{}, len(pairs))
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid variable %q: expected key=value", pair)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
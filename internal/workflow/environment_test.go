@@ -0,0 +1,126 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+)
+
+// writeEnvironmentWorkflow writes a workflow with an environments block and an env/staging.yml file
+func writeEnvironmentWorkflow(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	content := `name: environments
+variables:
+  base_url: "http://localhost"
+  user: "local"
+  region: "none"
+  token: "dev"
+environments:
+  staging:
+    base_url: "https://staging.example.com"
+    user: "staging-user"
+  production:
+    base_url: "https://example.com"
+`
+	if err := os.WriteFile(filepath.Join(dir, "workflow.yml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "env"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "env", "staging.yml"), []byte("user: file-user\nregion: eu\nretries: 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "workflow.yml")
+}
+
+func TestEnvironmentPrecedence(t *testing.T) {
+	wf, err := Load(writeEnvironmentWorkflow(t))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	executor.SetEnvironment("staging")
+	executor.SetOverrides(map[string]interface{}{"region": "us"})
+	if _, err := executor.Execute(context.Background(), wf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"base_url": "https://staging.example.com", // environments block over variables
+		"user":     "file-user",                   // env file over environments block
+		"region":   "us",                          // overrides over everything
+		"token":    "dev",                         // untouched workflow variable
+		"retries":  3,                             // env file values keep their type
+	}
+	for name, expected := range want {
+		if value, _ := executor.varManager.Get(name); value != expected {
+			t.Errorf("Expected %s = %v, got %v", name, expected, value)
+		}
+	}
+}
+
+func TestEnvironmentWithoutFile(t *testing.T) {
+	wf, err := Load(writeEnvironmentWorkflow(t))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	executor.SetEnvironment("production")
+	if _, err := executor.Execute(context.Background(), wf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value, _ := executor.varManager.Get("base_url"); value != "https://example.com" {
+		t.Errorf("Expected production base_url, got %v", value)
+	}
+	if value, _ := executor.varManager.Get("user"); value != "local" {
+		t.Errorf("Expected workflow user, got %v", value)
+	}
+}
+
+func TestUnknownEnvironment(t *testing.T) {
+	wf, err := Load(writeEnvironmentWorkflow(t))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	executor.SetEnvironment("stagign")
+	_, err = executor.Execute(context.Background(), wf)
+	if err == nil || !strings.Contains(err.Error(), `unknown environment "stagign" (available: production, staging)`) {
+		t.Errorf("Expected unknown environment error, got %v", err)
+	}
+
+	// Workflows that declare no environments run with any environment selected
+	plain := &Workflow{Name: "plain", Variables: map[string]interface{}{"user": "local"}}
+	executor = NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	executor.SetEnvironment("staging")
+	if _, err := executor.Execute(context.Background(), plain); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestParseVariableOverrides(t *testing.T) {
+	vars, err := ParseVariableOverrides([]string{"user=alice", "query=a=b", "empty="})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if vars["user"] != "alice" || vars["query"] != "a=b" || vars["empty"] != "" {
+		t.Errorf("Unexpected overrides: %v", vars)
+	}
+
+	for _, pair := range []string{"novalue", "=value"} {
+		if _, err := ParseVariableOverrides([]string{pair}); err == nil {
+			t.Errorf("Expected an error for %q", pair)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package workflow
+
+import (
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/faker"
+)
+
+// SetSeed sets the seed of {{faker.*}} data, taking precedence over faker_seed
+func (e *Executor) SetSeed(seed int64) {
+	e.seed = &seed
+}
+
+// Seed returns the faker seed of the last run, so that the run can be repeated with
+// --seed. It is nil when no seed was given with --seed and the run used no {{faker.*}}.
+func (e *Executor) Seed() *int64 {
+	if e.faker == nil || e.seed == nil && !e.faker.Used() {
+		return nil
+	}
+	seed := e.faker.Seed()
+	return &seed
+}
+
+// validateWorkflowFaker checks the faker locale of a workflow
+func validateWorkflowFaker(wf *Workflow) error {
+	_, err := faker.New(0, wf.FakerLocale)
+	return err
+}
+
+// setupFaker creates the faker of a run. The seed is the one given with --seed, else
+// faker_seed, else a random one.
+func (e *Executor) setupFaker(wf *Workflow) error {
+	seed := time.Now().UnixNano()
+	switch {
+	case e.seed != nil:
+		seed = *e.seed
+	case wf.FakerSeed != nil:
+		seed = *wf.FakerSeed
+	}
+
+	f, err := faker.New(seed, wf.FakerLocale)
+	if err != nil {
+		return err
+	}
+	e.faker = f
+	e.varManager.SetFaker(f)
+	e.logger.Info("Faker seed", "seed", seed)
+	return nil
+}
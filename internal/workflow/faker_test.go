@@ -0,0 +1,78 @@
+package workflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+)
+
+func TestFakerSeed(t *testing.T) {
+	path := writeTempWorkflow(t, `name: faker
+faker_seed: 42
+faker_locale: de
+steps:
+  - name: print
+    print: "{{faker.name}} {{faker.iban}} {{faker.number(1, 1000000)}}"
+`)
+	run := func(seed *int64) (string, *int64) {
+		wf, err := Load(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+		if seed != nil {
+			executor.SetSeed(*seed)
+		}
+		results, err := executor.Execute(context.Background(), wf)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return results[0].PrintText, executor.Seed()
+	}
+
+	first, seed := run(nil)
+	second, _ := run(nil)
+	if seed == nil || *seed != 42 || first != second {
+		t.Errorf("Expected faker_seed to repeat the run, got %q and %q with seed %v", first, second, seed)
+	}
+	if !strings.Contains(first, " DE") {
+		t.Errorf("Expected a German IBAN, got %q", first)
+	}
+
+	override := int64(7)
+	third, seed := run(&override)
+	if seed == nil || *seed != 7 || third == first {
+		t.Errorf("Expected --seed to take precedence over faker_seed, got %q with seed %v", third, seed)
+	}
+}
+
+func TestFakerSeedOnlyWhenUsed(t *testing.T) {
+	path := writeTempWorkflow(t, "name: plain\nsteps:\n  - name: print\n    print: hello\n")
+	for _, given := range []bool{false, true} {
+		wf, err := Load(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+		if given {
+			executor.SetSeed(3)
+		}
+		if _, err := executor.Execute(context.Background(), wf); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if seed := executor.Seed(); (seed != nil) != given {
+			t.Errorf("Expected a seed only when given with --seed or when faker is used, got %v (given %v)", seed, given)
+		}
+	}
+}
+
+func TestLoadRejectsUnknownFakerLocale(t *testing.T) {
+	path := writeTempWorkflow(t, "name: faker\nfaker_locale: xx\nsteps: []\n")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "unknown faker locale") {
+		t.Errorf("Expected an unknown faker locale error, got %v", err)
+	}
+}
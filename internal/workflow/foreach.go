@@ -0,0 +1,357 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cjp2600/stepwise/internal/validation"
+	"gopkg.in/yaml.v3"
+)
+
+// maxItemLabelLength limits how much of an item is shown in result names and reports
+const maxItemLabelLength = 80
+
+// ForeachConfig represents configuration for running a step or group once per item of a list
+type ForeachConfig struct {
+	Items    interface{} `yaml:"items" json:"items"`                           // Inline list or "{{variable}}" holding a list
+	As       string      `yaml:"as,omitempty" json:"as,omitempty"`             // Variable bound to the current item (default: item)
+	IndexAs  string      `yaml:"index_as,omitempty" json:"index_as,omitempty"` // Variable bound to the zero-based index (default: index)
+	Parallel bool        `yaml:"parallel,omitempty" json:"parallel,omitempty"`
+	Delay    string      `yaml:"delay,omitempty" json:"delay,omitempty"` // Delay between sequential iterations
+}
+
+// UnmarshalYAML accepts the full form as well as the shorthand
+// `foreach: [a, b]` and `foreach: "{{ids}}"`
+func (f *ForeachConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		type plain ForeachConfig
+		return node.Decode((*plain)(f))
+	}
+
+	var items interface{}
+	if err := node.Decode(&items); err != nil {
+		return err
+	}
+	f.Items = items
+	return nil
+}
+
+// itemName returns the variable name the current item is bound to
+func (f *ForeachConfig) itemName() string {
+	if f.As != "" {
+		return f.As
+	}
+	return "item"
+}
+
+// indexName returns the variable name the current index is bound to
+func (f *ForeachConfig) indexName() string {
+	if f.IndexAs != "" {
+		return f.IndexAs
+	}
+	return "index"
+}
+
+// bindings returns the variables visible inside the iteration over the given item
+func (f *ForeachConfig) bindings(index int, item interface{}) map[string]interface{} {
+	bindings := map[string]interface{}{
+		f.itemName():  item,
+		f.indexName(): index,
+		"iteration":   index + 1,
+	}
+	flattenItem(bindings, f.itemName(), item)
+	return bindings
+}
+
+// flattenItem exposes fields of object items as dotted variables, e.g. {{item.id}}
+func flattenItem(bindings map[string]interface{}, prefix string, value interface{}) {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for key, field := range fields {
+		name := prefix + "." + key
+		bindings[name] = field
+		flattenItem(bindings, name, field)
+	}
+}
+
+// resolveForeachItems resolves the items of a foreach loop to a list
+func (e *Executor) resolveForeachItems(config *ForeachConfig) ([]interface{}, error) {
+	switch items := config.Items.(type) {
+	case nil:
+		return nil, fmt.Errorf("items are required")
+	case []interface{}:
+		return items, nil
+	case string:
+		// A reference to a single variable keeps the captured value as is
+		trimmed := strings.TrimSpace(items)
+		if strings.HasPrefix(trimmed, "{{") && strings.HasSuffix(trimmed, "}}") && strings.Count(trimmed, "{{") == 1 {
+			name := strings.TrimSpace(trimmed[2 : len(trimmed)-2])
+			if value, exists := e.varManager.Get(name); exists {
+				return toItemList(value)
+			}
+		}
+		substituted, err := e.varManager.Substitute(items)
+		if err != nil {
+			return nil, fmt.Errorf("failed to substitute items: %w", err)
+		}
+		return toItemList(substituted)
+	default:
+		return toItemList(items)
+	}
+}
+
+// toItemList converts a value to a list of foreach items
+func toItemList(value interface{}) ([]interface{}, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, nil
+	case string:
+		var list []interface{}
+		if err := json.Unmarshal([]byte(v), &list); err != nil {
+			return nil, fmt.Errorf("items must resolve to a list, got %q", v)
+		}
+		return list, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = rv.Index(i).Interface()
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("items must resolve to a list, got %T", value)
+}
+
+// formatItem renders an item for result names and reports
+func formatItem(item interface{}) string {
+	var label string
+	switch v := item.(type) {
+	case string:
+		label = v
+	case map[string]interface{}, []interface{}:
+		if data, err := json.Marshal(v); err == nil {
+			label = string(data)
+		} else {
+			label = fmt.Sprintf("%v", v)
+		}
+	default:
+		label = fmt.Sprintf("%v", v)
+	}
+
+	if utf8.RuneCountInString(label) > maxItemLabelLength {
+		runes := []rune(label)
+		label = string(runes[:maxItemLabelLength-3]) + "..."
+	}
+	return label
+}
+
+// fork returns a copy of the executor running in a new child variable scope. The child
+// sees the variables of e and the given bindings, while what it sets stays in its own
+// scope until promoted, so forks may run concurrently without sharing writes.
+func (e *Executor) fork(scope string, bindings map[string]interface{}) *Executor {
+	child := *e
+	child.varManager = e.varManager.NewScope(scope, bindings)
+	child.validator = validation.NewValidator(e.logger)
+	child.validator.SetVariableManager(child.varManager)
+	child.validator.SetUpdateSnapshots(e.updateSnapshots)
+	return &child
+}
+
+// runIterations calls run for every iteration, either concurrently or sequentially
+// with the given delay. Sequential loops stop when the run is cancelled.
+func (e *Executor) runIterations(parallel bool, delay string, count int, run func(i int)) {
+	if parallel {
+		var wg sync.WaitGroup
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				run(i)
+			}(i)
+		}
+		wg.Wait()
+		return
+	}
+
+	var wait time.Duration
+	if delay != "" {
+		wait = e.parseTimeout(delay)
+	}
+	for i := 0; i < count; i++ {
+		if e.context().Err() != nil {
+			return
+		}
+		if i > 0 && wait > 0 {
+			e.logger.Debug("Waiting between iterations", "delay", wait)
+			e.wait(wait)
+		}
+		run(i)
+	}
+}
+
+// executeStepWithForeach executes a step once per item and records every iteration in RepeatResults
+func (e *Executor) executeStepWithForeach(step *Step, result *TestResult) error {
+	startTime := time.Now()
+	config := step.Foreach
+
+	items, err := e.resolveForeachItems(config)
+	if err != nil {
+		return fmt.Errorf("foreach: %w", err)
+	}
+
+	e.logger.Info("Executing step with foreach",
+		"step", step.Name,
+		"items", len(items),
+		"parallel", config.Parallel)
+
+	result.RepeatCount = len(items)
+	result.RepeatResults = make([]TestResult, len(items))
+
+	e.runIterations(config.Parallel, config.Delay, len(items), func(i int) {
+		stepCopy := *step
+		// Clear foreach to avoid infinite recursion
+		stepCopy.Foreach = nil
+
+		iterationResult := &TestResult{
+			Name:         fmt.Sprintf("%s [%d]", step.Name, i),
+			Status:       "pending",
+			Item:         formatItem(items[i]),
+			CapturedData: make(map[string]interface{}),
+		}
+
+		child := e.fork("iteration", config.bindings(i, items[i]))
+		if err := child.executeStepWithRepeat(&stepCopy, iterationResult); err != nil {
+			iterationResult.Status = "failed"
+			iterationResult.Error = err.Error()
+		} else {
+			iterationResult.Status = "passed"
+		}
+		result.RepeatResults[i] = *iterationResult
+	})
+
+	result.Duration = time.Since(startTime)
+
+	failed := 0
+	for i := range result.RepeatResults {
+		if result.RepeatResults[i].Status == "" {
+			// Iteration never started because the run was cancelled
+			result.RepeatResults[i] = TestResult{
+				Name:   fmt.Sprintf("%s [%d]", step.Name, i),
+				Status: "skipped",
+				Item:   formatItem(items[i]),
+				Error:  "skipped: execution interrupted",
+			}
+		}
+		if result.RepeatResults[i].Status != "passed" {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d/%d iterations failed", failed, len(items))
+	}
+	return nil
+}
+
+// executeGroupForeach executes the steps of a group once per item
+func (e *Executor) executeGroupForeach(group *StepGroup, groupResult *GroupResult) error {
+	config := group.Foreach
+
+	items, err := e.resolveForeachItems(config)
+	if err != nil {
+		return fmt.Errorf("foreach: %w", err)
+	}
+
+	e.logger.Info("Executing group with foreach",
+		"group", group.Name,
+		"items", len(items),
+		"parallel", config.Parallel)
+
+	body := *group
+	body.Foreach = nil
+
+	return e.executeGroupIterations(&body, groupResult, items, config.Parallel, config.Delay, config.bindings)
+}
+
+// executeGroupIterations executes the body of a group once per item, each iteration in its
+// own variable scope. Results of the same step across iterations are combined into one result.
+func (e *Executor) executeGroupIterations(body *StepGroup, groupResult *GroupResult, items []interface{}, parallel bool, delay string, bindings func(int, interface{}) map[string]interface{}) error {
+	iterations := make([][]TestResult, len(items))
+	errs := make([]error, len(items))
+
+	e.runIterations(parallel, delay, len(items), func(i int) {
+		iterationResult := &GroupResult{Name: body.Name}
+		child := e.fork("iteration", bindings(i, items[i]))
+		errs[i] = child.executeGroupSteps(body, iterationResult)
+		iterations[i] = iterationResult.Results
+	})
+
+	groupResult.Results = append(groupResult.Results, aggregateForeachResults(items, iterations)...)
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// aggregateForeachResults combines per-iteration results of the same step into a single result
+func aggregateForeachResults(items []interface{}, iterations [][]TestResult) []TestResult {
+	var order []string
+	byName := make(map[string]*TestResult)
+
+	for i, results := range iterations {
+		for _, result := range results {
+			aggregated, exists := byName[result.Name]
+			if !exists {
+				aggregated = &TestResult{Name: result.Name, Phase: result.Phase}
+				byName[result.Name] = aggregated
+				order = append(order, result.Name)
+			}
+
+			iteration := result
+			iteration.Name = fmt.Sprintf("%s [%d]", result.Name, i)
+			iteration.Item = formatItem(items[i])
+			aggregated.RepeatResults = append(aggregated.RepeatResults, iteration)
+			aggregated.Duration += result.Duration
+		}
+	}
+
+	aggregatedResults := make([]TestResult, 0, len(order))
+	for _, name := range order {
+		aggregated := byName[name]
+		aggregated.RepeatCount = len(aggregated.RepeatResults)
+
+		passed, skipped := 0, 0
+		for _, iteration := range aggregated.RepeatResults {
+			switch iteration.Status {
+			case "passed":
+				passed++
+			case "skipped":
+				skipped++
+			}
+		}
+
+		switch {
+		case passed+skipped < aggregated.RepeatCount:
+			aggregated.Status = "failed"
+			aggregated.Error = fmt.Sprintf("%d/%d iterations failed", aggregated.RepeatCount-passed-skipped, aggregated.RepeatCount)
+		case passed == 0:
+			aggregated.Status = "skipped"
+		default:
+			aggregated.Status = "passed"
+		}
+		aggregatedResults = append(aggregatedResults, *aggregated)
+	}
+	return aggregatedResults
+}
@@ -0,0 +1,228 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+	"github.com/cjp2600/stepwise/internal/validation"
+	"gopkg.in/yaml.v3"
+)
+
+// pathRecorder is a test server that records requested paths
+type pathRecorder struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (p *pathRecorder) handler(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.paths = append(p.paths, r.URL.Path)
+	p.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/list":
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ids": [1, 2, 3]}`))
+	case strings.HasSuffix(r.URL.Path, "/bad"):
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (p *pathRecorder) sorted() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	paths := append([]string{}, p.paths...)
+	sort.Strings(paths)
+	return paths
+}
+
+func TestForeachConfigUnmarshal(t *testing.T) {
+	var step Step
+	if err := yaml.Unmarshal([]byte(`
+name: "short"
+foreach: [1, 2]
+`), &step); err != nil {
+		t.Fatal(err)
+	}
+	if items, ok := step.Foreach.Items.([]interface{}); !ok || len(items) != 2 {
+		t.Errorf("Expected shorthand list, got %#v", step.Foreach.Items)
+	}
+
+	if err := yaml.Unmarshal([]byte(`
+name: "ref"
+foreach: "{{ids}}"
+`), &step); err != nil {
+		t.Fatal(err)
+	}
+	if step.Foreach.Items != "{{ids}}" {
+		t.Errorf("Expected variable reference, got %#v", step.Foreach.Items)
+	}
+
+	if err := yaml.Unmarshal([]byte(`
+name: "full"
+foreach:
+  items: "{{users}}"
+  as: user
+  index_as: i
+  parallel: true
+`), &step); err != nil {
+		t.Fatal(err)
+	}
+	if step.Foreach.itemName() != "user" || step.Foreach.indexName() != "i" || !step.Foreach.Parallel {
+		t.Errorf("Unexpected config: %+v", step.Foreach)
+	}
+}
+
+func TestForeachOverCapturedArray(t *testing.T) {
+	recorder := &pathRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(recorder.handler))
+	defer server.Close()
+
+	wf := &Workflow{
+		Name: "Foreach",
+		Steps: []Step{
+			{
+				Name:    "list",
+				Request: Request{Method: "GET", URL: server.URL + "/list"},
+				Capture: map[string]string{"ids": "$.ids"},
+			},
+			{
+				Name:     "get",
+				Foreach:  &ForeachConfig{Items: "{{ids}}"},
+				Request:  Request{Method: "GET", URL: server.URL + "/items/{{item}}/{{index}}"},
+				Validate: []validation.ValidationRule{{Status: 200}},
+			},
+		},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	get := results[1]
+	if get.Status != "passed" {
+		t.Fatalf("Expected passed, got %s (%s)", get.Status, get.Error)
+	}
+	if get.RepeatCount != 3 || len(get.RepeatResults) != 3 {
+		t.Fatalf("Expected 3 iterations, got %d", len(get.RepeatResults))
+	}
+	for i, item := range []string{"1", "2", "3"} {
+		if get.RepeatResults[i].Item != item {
+			t.Errorf("Iteration %d: expected item %s, got %s", i, item, get.RepeatResults[i].Item)
+		}
+	}
+
+	want := []string{"/items/1/0", "/items/2/1", "/items/3/2", "/list"}
+	if got := recorder.sorted(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected paths %v, got %v", want, got)
+	}
+}
+
+func TestForeachParallelWithAlias(t *testing.T) {
+	recorder := &pathRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(recorder.handler))
+	defer server.Close()
+
+	var users []interface{}
+	for _, id := range []string{"alice", "bob", "carol", "bad"} {
+		users = append(users, map[string]interface{}{"id": id})
+	}
+
+	wf := &Workflow{
+		Name: "Parallel foreach",
+		Steps: []Step{
+			{
+				Name:     "user",
+				Foreach:  &ForeachConfig{Items: users, As: "user", Parallel: true},
+				Request:  Request{Method: "GET", URL: server.URL + "/users/{{user.id}}"},
+				Validate: []validation.ValidationRule{{Status: 200}},
+			},
+		},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result := results[0]
+	if result.Status != "failed" || result.Error != "1/4 iterations failed" {
+		t.Errorf("Expected one failed iteration, got %s (%s)", result.Status, result.Error)
+	}
+	if result.RepeatResults[3].Status != "failed" || result.RepeatResults[3].Item != `{"id":"bad"}` {
+		t.Errorf("Unexpected last iteration: %+v", result.RepeatResults[3])
+	}
+
+	want := []string{"/users/alice", "/users/bad", "/users/bob", "/users/carol"}
+	if got := recorder.sorted(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected each iteration to see its own item, got %v", got)
+	}
+}
+
+func TestGroupForeach(t *testing.T) {
+	recorder := &pathRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(recorder.handler))
+	defer server.Close()
+
+	wf := &Workflow{
+		Name: "Group foreach",
+		Groups: []StepGroup{
+			{
+				Name:    "regions",
+				Foreach: &ForeachConfig{Items: []interface{}{"eu", "us"}, As: "region"},
+				Steps: []Step{
+					{Name: "health", Request: Request{Method: "GET", URL: server.URL + "/{{region}}/health"}},
+					{Name: "status", Request: Request{Method: "GET", URL: server.URL + "/{{region}}/status"}},
+				},
+			},
+		},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 aggregated results, got %d", len(results))
+	}
+	if results[0].Name != "regions.health" || results[0].RepeatCount != 2 {
+		t.Errorf("Unexpected result: %s with %d iterations", results[0].Name, results[0].RepeatCount)
+	}
+	if results[1].RepeatResults[1].Item != "us" {
+		t.Errorf("Expected second iteration item us, got %s", results[1].RepeatResults[1].Item)
+	}
+	if got := len(recorder.sorted()); got != 4 {
+		t.Errorf("Expected 4 requests, got %d", got)
+	}
+}
+
+func TestForeachInvalidItems(t *testing.T) {
+	wf := &Workflow{
+		Name:      "Invalid",
+		Variables: map[string]interface{}{"name": "not a list"},
+		Steps: []Step{
+			{Name: "loop", Foreach: &ForeachConfig{Items: "{{name}}"}, Print: "{{item}}"},
+		},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: time.Second}, logger.New())
+	results, _ := executor.Execute(context.Background(), wf)
+	if results[0].Status != "failed" || !strings.Contains(results[0].Error, "items must resolve to a list") {
+		t.Errorf("Expected items error, got %s (%s)", results[0].Status, results[0].Error)
+	}
+}
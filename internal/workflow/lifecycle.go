@@ -0,0 +1,94 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// cleanupTimeout bounds teardown and always steps that run after the run was cancelled
+const cleanupTimeout = 30 * time.Second
+
+// Step phases reported in TestResult.Phase
+const (
+	phaseSetup    = "setup"
+	phaseTeardown = "teardown"
+)
+
+// executeSetupSteps runs setup steps in order and stops at the first failure
+func (e *Executor) executeSetupSteps(steps []Step) ([]TestResult, error) {
+	var results []TestResult
+	for i := range steps {
+		if err := e.context().Err(); err != nil {
+			return results, interruptError(err)
+		}
+		result := e.executePhaseStep(phaseSetup, &steps[i])
+		results = append(results, *result)
+		if result.Status == "failed" {
+			return results, fmt.Errorf("setup step %s failed: %s", result.Name, result.Error)
+		}
+	}
+	return results, nil
+}
+
+// executeTeardownSteps runs every teardown step, even if some of them fail
+// or the run has already been cancelled
+func (e *Executor) executeTeardownSteps(steps []Step) []TestResult {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	var results []TestResult
+	e.withCleanupContext(func() {
+		for i := range steps {
+			results = append(results, *e.executePhaseStep(phaseTeardown, &steps[i]))
+		}
+	})
+	return results
+}
+
+// executePhaseStep executes a single setup, teardown or always step
+func (e *Executor) executePhaseStep(phase string, step *Step) *TestResult {
+	result := &TestResult{
+		Name:         step.Name,
+		Status:       "pending",
+		Phase:        phase,
+		CapturedData: make(map[string]interface{}),
+	}
+
+	// Check condition if specified
+	if step.Condition != "" {
+		if !e.evaluateCondition(step.Condition) {
+			e.logger.Info("Skipping step due to condition", "step", step.Name, "phase", phase, "condition", step.Condition)
+			result.Status = "skipped"
+			return result
+		}
+	}
+
+	e.logger.Info("Executing step", "step", step.Name, "phase", phase)
+	if err := e.executeStepWithRepeat(step, result); err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+		e.logger.Error("Step execution failed", "step", step.Name, "phase", phase, "error", err)
+	} else {
+		result.Status = "passed"
+	}
+	return result
+}
+
+// withCleanupContext runs fn with a context that is not cancelled together with the run,
+// so cleanup requests can still be sent. If the run is already cancelled, cleanup is
+// bounded by cleanupTimeout.
+func (e *Executor) withCleanupContext(fn func()) {
+	parent := e.context()
+	ctx := context.WithoutCancel(parent)
+	if parent.Err() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cleanupTimeout)
+		defer cancel()
+	}
+
+	e.ctx = ctx
+	defer func() { e.ctx = parent }()
+	fn()
+}
@@ -0,0 +1,201 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+	"github.com/cjp2600/stepwise/internal/validation"
+)
+
+func newLifecycleServer(t *testing.T, teardownHits *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/teardown":
+			atomic.AddInt32(teardownHits, 1)
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func lifecycleStep(name, url string) Step {
+	return Step{
+		Name:     name,
+		Request:  Request{Method: "GET", URL: url},
+		Validate: []validation.ValidationRule{{Status: 200}},
+	}
+}
+
+func TestSetupTeardownWithFailFast(t *testing.T) {
+	var teardownHits int32
+	server := newLifecycleServer(t, &teardownHits)
+
+	always := lifecycleStep("cleanup-orders", server.URL+"/ok")
+	always.Always = true
+
+	wf := &Workflow{
+		Name:  "Lifecycle",
+		Setup: []Step{lifecycleStep("create-user", server.URL+"/ok")},
+		Steps: []Step{
+			lifecycleStep("broken", server.URL+"/fail"),
+			lifecycleStep("not-run", server.URL+"/ok"),
+			always,
+		},
+		Teardown: []Step{lifecycleStep("delete-user", server.URL+"/teardown")},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	executor.SetFailFast(true)
+
+	results, err := executor.Execute(context.Background(), wf)
+	if err == nil {
+		t.Fatal("Expected fail-fast error, got nil")
+	}
+
+	expected := []struct {
+		name   string
+		phase  string
+		status string
+	}{
+		{"create-user", "setup", "passed"},
+		{"broken", "", "failed"},
+		{"cleanup-orders", "", "passed"},
+		{"delete-user", "teardown", "passed"},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d: %+v", len(expected), len(results), results)
+	}
+	for i, exp := range expected {
+		if results[i].Name != exp.name || results[i].Phase != exp.phase || results[i].Status != exp.status {
+			t.Errorf("Result %d: expected %s/%s/%s, got %s/%s/%s", i,
+				exp.name, exp.phase, exp.status, results[i].Name, results[i].Phase, results[i].Status)
+		}
+	}
+	if teardownHits != 1 {
+		t.Errorf("Expected teardown to run once, got %d", teardownHits)
+	}
+}
+
+func TestSetupFailureSkipsSteps(t *testing.T) {
+	var teardownHits int32
+	server := newLifecycleServer(t, &teardownHits)
+
+	wf := &Workflow{
+		Name:     "Setup failure",
+		Setup:    []Step{lifecycleStep("create-user", server.URL+"/fail")},
+		Steps:    []Step{lifecycleStep("main", server.URL+"/ok")},
+		Teardown: []Step{lifecycleStep("delete-user", server.URL+"/teardown")},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err == nil || !strings.Contains(err.Error(), "setup step create-user failed") {
+		t.Fatalf("Expected setup error, got %v", err)
+	}
+	for _, result := range results {
+		if result.Name == "main" {
+			t.Error("Main step must not run after setup failure")
+		}
+	}
+	if teardownHits != 1 {
+		t.Errorf("Expected teardown to run once, got %d", teardownHits)
+	}
+}
+
+func TestTeardownRunsAfterCancellation(t *testing.T) {
+	var teardownHits int32
+	server := newLifecycleServer(t, &teardownHits)
+
+	wf := &Workflow{
+		Name:     "Cancelled",
+		Steps:    []Step{lifecycleStep("slow", server.URL+"/slow")},
+		Teardown: []Step{lifecycleStep("delete-user", server.URL+"/teardown")},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(ctx, wf)
+	if err == nil {
+		t.Fatal("Expected interrupt error, got nil")
+	}
+
+	last := results[len(results)-1]
+	if last.Phase != "teardown" || last.Status != "passed" {
+		t.Errorf("Expected passed teardown result, got %s/%s (%s)", last.Phase, last.Status, last.Error)
+	}
+	if teardownHits != 1 {
+		t.Errorf("Expected teardown request to be sent, got %d", teardownHits)
+	}
+}
+
+func TestGroupSetupTeardown(t *testing.T) {
+	var teardownHits int32
+	server := newLifecycleServer(t, &teardownHits)
+
+	wf := &Workflow{
+		Name: "Groups",
+		Groups: []StepGroup{
+			{
+				Name:     "orders",
+				Setup:    []Step{lifecycleStep("seed", server.URL+"/ok")},
+				Steps:    []Step{lifecycleStep("list", server.URL+"/ok")},
+				Teardown: []Step{lifecycleStep("purge", server.URL+"/teardown")},
+			},
+		},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, result.DisplayName())
+	}
+	got := strings.Join(names, ", ")
+	want := "[setup] orders.seed, orders.list, [teardown] orders.purge"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestMergeLifecycleSteps(t *testing.T) {
+	setup := []Step{{Name: "own-setup"}}
+	teardown := []Step{{Name: "own-teardown"}}
+	component := &Component{
+		Setup:    []Step{{Name: "component-setup"}},
+		Teardown: []Step{{Name: "component-teardown"}},
+	}
+
+	setup, teardown = mergeLifecycleSteps(setup, teardown, component)
+
+	if len(setup) != 2 || setup[1].Name != "component-setup" {
+		t.Errorf("Expected component setup after own setup, got %+v", setup)
+	}
+	if len(teardown) != 2 || teardown[0].Name != "component-teardown" {
+		t.Errorf("Expected component teardown before own teardown, got %+v", teardown)
+	}
+}
@@ -0,0 +1,63 @@
+package workflow
+
+import (
+	"fmt"
+	"path/filepath"
+
+	httpclient "github.com/cjp2600/stepwise/internal/http"
+	"github.com/cjp2600/stepwise/internal/openapi"
+	"github.com/cjp2600/stepwise/internal/validation"
+)
+
+// OpenAPICoverage returns the calls made to the operations of the OpenAPI description
+// of the last run, or nil when the workflow has none
+func (e *Executor) OpenAPICoverage() *openapi.Coverage {
+	return e.coverage
+}
+
+// setupOpenAPI loads the OpenAPI description of a workflow, relative to the workflow file
+func (e *Executor) setupOpenAPI(wf *Workflow) error {
+	e.openapi, e.coverage = nil, nil
+	if wf.OpenAPI == "" {
+		return nil
+	}
+	path := wf.OpenAPI
+	if !filepath.IsAbs(path) && wf.SourceFile != "" {
+		path = filepath.Join(filepath.Dir(wf.SourceFile), path)
+	}
+	spec, err := openapi.Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load OpenAPI description: %w", err)
+	}
+	e.openapi, e.coverage = spec, spec.NewCoverage()
+	return nil
+}
+
+// checkContract checks an HTTP exchange against the OpenAPI description of the
+// workflow, records it in the coverage and reports every problem as a failed validation
+func (e *Executor) checkContract(protocol string, response *httpclient.Response) []validation.ValidationResult {
+	if e.openapi == nil || response == nil || response.Request == nil || protocol != "http" {
+		return nil
+	}
+	result := e.openapi.Check(response)
+	e.coverage.Record(result)
+
+	expected := "documented endpoint"
+	if result.Operation != nil {
+		expected = result.Operation.Label()
+	}
+	if len(result.Problems) == 0 {
+		return []validation.ValidationResult{{Type: "openapi", Expected: expected, Actual: "conforms", Passed: true}}
+	}
+	results := make([]validation.ValidationResult, len(result.Problems))
+	for i, problem := range result.Problems {
+		results[i] = validation.ValidationResult{
+			Type:       "openapi",
+			Expected:   expected,
+			Actual:     problem.Kind,
+			Error:      "openapi: " + problem.Message,
+			Violations: problem.Violations,
+		}
+	}
+	return results
+}
@@ -0,0 +1,109 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+)
+
+func TestOpenAPIContract(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/users/1":
+			w.Write([]byte(`{"id": 1, "name": "Ann"}`))
+		case "/users/2":
+			w.Write([]byte(`{"id": "2"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "api.yaml"), []byte(`openapi: 3.0.3
+info: {title: Users, version: "1"}
+paths:
+  /users/{id}:
+    get:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: integer}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id, name]
+                properties:
+                  id: {type: integer}
+                  name: {type: string}
+    delete:
+      responses:
+        "204": {description: deleted}
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(dir, "workflow.yml")
+	if err := os.WriteFile(path, []byte(`name: contract
+openapi: api.yaml
+steps:
+  - name: conforming
+    request:
+      method: GET
+      url: "`+server.URL+`/users/1"
+  - name: drift
+    request:
+      method: GET
+      url: "`+server.URL+`/users/2"
+    validate:
+      - status: 200
+  - name: undocumented
+    request:
+      method: GET
+      url: "`+server.URL+`/orders"
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wf, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if results[0].Status != "passed" || len(results[0].Validations) != 1 || results[0].Validations[0].Type != "openapi" {
+		t.Errorf("Expected the conforming step to pass one openapi check, got %s: %+v", results[0].Status, results[0].Validations)
+	}
+	if results[1].Status != "failed" || !strings.Contains(results[1].Error, "openapi: response body: /: missing required property \"name\"; /id: expected integer, got string") {
+		t.Errorf("Expected the drift to fail the step, got %s: %s", results[1].Status, results[1].Error)
+	}
+	if results[2].Status != "failed" || !strings.Contains(results[2].Error, "GET /orders is not documented") {
+		t.Errorf("Expected the undocumented endpoint to fail the step, got %s: %s", results[2].Status, results[2].Error)
+	}
+
+	coverage := executor.OpenAPICoverage()
+	if coverage == nil {
+		t.Fatal("Expected OpenAPI coverage")
+	}
+	if coverage.Covered() != 1 || coverage.Undocumented != 1 {
+		t.Errorf("Expected 1 covered operation and 1 undocumented call, got %d and %d", coverage.Covered(), coverage.Undocumented)
+	}
+	get := coverage.Operations[0]
+	if get.Operation != "GET /users/{id}" || get.Calls != 2 || get.Failed != 1 {
+		t.Errorf("Unexpected coverage of GET /users/{id}: %+v", get)
+	}
+}
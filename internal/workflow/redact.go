@@ -0,0 +1,105 @@
+package workflow
+
+import (
+	dbclient "github.com/cjp2600/stepwise/internal/database"
+	grpcclient "github.com/cjp2600/stepwise/internal/grpc"
+	httpclient "github.com/cjp2600/stepwise/internal/http"
+	"github.com/cjp2600/stepwise/internal/jsondiff"
+	mcpclient "github.com/cjp2600/stepwise/internal/mcp"
+	"github.com/cjp2600/stepwise/internal/redact"
+	"github.com/cjp2600/stepwise/internal/validation"
+)
+
+// Redactor returns the redactor that masks sensitive values of the run. Values printed
+// outside the executor, such as the values of workflow captures, go through it.
+func (e *Executor) Redactor() *redact.Redactor {
+	return e.redactor
+}
+
+// validateWorkflowSensitive checks the sensitive paths of a workflow
+func validateWorkflowSensitive(wf *Workflow) error {
+	return redact.New().AddPaths(wf.Sensitive...)
+}
+
+// registerAuth registers the credentials of a substituted authentication config
+func (e *Executor) registerAuth(auth *httpclient.Auth) {
+	for _, value := range []string{auth.Password, auth.Token, auth.APIKey} {
+		e.redactor.AddValue(value)
+	}
+	if auth.OAuth != nil {
+		e.redactor.AddValue(auth.OAuth.ClientSecret)
+		e.redactor.AddValue(auth.OAuth.Password)
+	}
+	for _, value := range auth.Custom {
+		e.redactor.AddValue(value)
+	}
+}
+
+// registerResponseSecrets registers cookies set by a response and the values at sensitive paths of its body
+func (e *Executor) registerResponseSecrets(protocol string, httpResponse *httpclient.Response, grpcResponse *grpcclient.Response, dbResponse *dbclient.Response, mcpResponse *mcpclient.Response) {
+	switch {
+	case protocol == "grpc" && grpcResponse != nil:
+		e.redactor.AddValuesAt(grpcResponse.Data)
+	case protocol == "db" && dbResponse != nil:
+		e.redactor.AddValuesAt(dbResponse.Data)
+	case protocol == "mcp" && mcpResponse != nil:
+		e.redactor.AddValuesAt(mcpResponse.Result)
+	case httpResponse != nil:
+		for name, values := range httpResponse.Headers {
+			for _, value := range values {
+				e.redactor.AddHeader(name, value)
+			}
+		}
+		if data, err := httpResponse.GetJSONBody(); err == nil {
+			e.redactor.AddValuesAt(data)
+		}
+	}
+}
+
+// registerCapture registers a captured value when the name of the variable suggests a secret
+func (e *Executor) registerCapture(name string, value interface{}) {
+	if redact.SensitiveName(name) {
+		e.redactor.AddValueOf(value)
+	}
+}
+
+// redactResults masks sensitive values in results before they are printed, reported or sent
+func (e *Executor) redactResults(results []TestResult) {
+	for i := range results {
+		result := &results[i]
+		result.Error = e.redactor.String(result.Error)
+		result.PrintText = e.redactor.String(result.PrintText)
+		result.CapturedData = e.redactor.Map(result.CapturedData)
+		result.Validations = e.redactValidations(result.Validations)
+		for j, warning := range result.Warnings {
+			result.Warnings[j] = e.redactor.String(warning)
+		}
+		e.redactResults(result.RepeatResults)
+	}
+}
+
+// redactValidations returns a copy of validation results with sensitive values masked,
+// including those of the rules of combinators
+func (e *Executor) redactValidations(validations []validation.ValidationResult) []validation.ValidationResult {
+	if validations == nil {
+		return nil
+	}
+	redacted := make([]validation.ValidationResult, len(validations))
+	for i, v := range validations {
+		v.Expected = e.redactor.Value(v.Expected)
+		v.Actual = e.redactor.Value(v.Actual)
+		v.Error = e.redactor.String(v.Error)
+		if v.Diff != nil {
+			diff := make([]jsondiff.Difference, len(v.Diff))
+			for j, d := range v.Diff {
+				d.Expected = e.redactor.Value(d.Expected)
+				d.Actual = e.redactor.Value(d.Actual)
+				diff[j] = d
+			}
+			v.Diff = diff
+		}
+		v.Results = e.redactValidations(v.Results)
+		redacted[i] = v
+	}
+	return redacted
+}
@@ -0,0 +1,173 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+	"github.com/cjp2600/stepwise/internal/redact"
+)
+
+func TestSecretsAreRedacted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "cookie-abc123"})
+		fmt.Fprintf(w, `{"access_token": "server-token-456", "user": {"name": "alice", "password": "pw-789"}, "auth": %q}`, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"secrets.yml":             "api_token: file-token-123\n",
+		"__snapshots__/auth.json": `{"auth": "Bearer old-token"}`,
+		"workflow.yml": `name: redaction
+secrets:
+  - file: secrets.yml
+sensitive:
+  - "$.user.password"
+steps:
+  - name: login
+    request:
+      method: GET
+      url: "` + server.URL + `"
+      auth:
+        type: bearer
+        token: "{{secret.api_token}}"
+    capture:
+      access_token: "$.access_token"
+      user_name: "$.user.name"
+  - name: check
+    request:
+      method: GET
+      url: "` + server.URL + `"
+      auth:
+        type: bearer
+        token: "{{secret.api_token}}"
+    validate:
+      - json: "$.user.password"
+        equals: "wrong"
+      - json: "$.auth"
+        equals: "wrong"
+  - name: nested
+    request:
+      method: GET
+      url: "` + server.URL + `"
+      auth:
+        type: bearer
+        token: "{{secret.api_token}}"
+    validate:
+      - json: "$.auth"
+        equals: "wrong"
+        severity: warn
+      - any_of:
+          - json: "$.auth"
+            equals: "wrong"
+          - status: 201
+  - name: snapshot
+    request:
+      method: GET
+      url: "` + server.URL + `"
+      auth:
+        type: bearer
+        token: "{{secret.api_token}}"
+    validate:
+      - snapshot: auth.json
+        ignore_extra_fields: true
+      - json: "$"
+        equals:
+          auth: "Bearer old-token"
+        ignore_extra_fields: true
+  - name: print
+    print: "token={{secret.api_token}} access={{access_token}} user={{user_name}}"
+`,
+	}
+	if err := os.Mkdir(filepath.Join(dir, "__snapshots__"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wf, err := Load(filepath.Join(dir, "workflow.yml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	log := logger.New()
+	log.SetLevel("debug")
+	var output bytes.Buffer
+	log.SetOutput(&output)
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, log)
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	login := results[0]
+	if login.CapturedData["access_token"] != redact.Mask || login.CapturedData["user_name"] != "alice" {
+		t.Errorf("Unexpected captured data: %v", login.CapturedData)
+	}
+	if len(results[1].Validations) != 2 {
+		t.Fatalf("Expected 2 validations, got %d", len(results[1].Validations))
+	}
+	for _, v := range results[1].Validations {
+		if v.Actual != redact.Mask && v.Actual != "Bearer "+redact.Mask {
+			t.Errorf("Expected the actual value of %s to be masked, got %v", v.Type, v.Actual)
+		}
+	}
+	nested := results[2]
+	if len(nested.Warnings) != 1 || strings.Contains(nested.Warnings[0], "file-token-123") {
+		t.Errorf("Expected the warning to be masked, got %q", nested.Warnings)
+	}
+	if len(nested.Validations) != 2 || len(nested.Validations[1].Results) != 2 {
+		t.Fatalf("Expected the results of any_of, got %+v", nested.Validations)
+	}
+	if inner := nested.Validations[1].Results[0]; inner.Actual != "Bearer "+redact.Mask || strings.Contains(inner.Error, "file-token-123") {
+		t.Errorf("Expected the result inside any_of to be masked, got %+v", inner)
+	}
+	diffs := results[3].Validations
+	if len(diffs) != 2 {
+		t.Fatalf("Expected 2 validations, got %+v", diffs)
+	}
+	for _, v := range diffs {
+		if len(v.Diff) != 1 {
+			t.Fatalf("Expected a difference from %s, got %+v", v.Type, v)
+		}
+		if v.Diff[0].Actual != "Bearer "+redact.Mask || strings.Contains(v.Error, "file-token-123") {
+			t.Errorf("Expected the difference from %s to be masked, got %+v", v.Type, v)
+		}
+	}
+	if actual, ok := diffs[1].Actual.(map[string]interface{}); !ok || actual["auth"] != "Bearer "+redact.Mask {
+		t.Errorf("Expected the actual object to be masked, got %v", diffs[1].Actual)
+	}
+	if results[4].PrintText != "token=*** access=*** user=alice" {
+		t.Errorf("Unexpected print text: %q", results[4].PrintText)
+	}
+
+	redactor := executor.Redactor()
+	if got := redactor.String("cookie-abc123 pw-789"); got != "*** ***" {
+		t.Errorf("Expected the cookie and the sensitive field to be registered, got %q", got)
+	}
+	for _, secret := range []string{"file-token-123", "server-token-456", "pw-789", "cookie-abc123"} {
+		if strings.Contains(output.String(), secret) {
+			t.Errorf("Expected %s not to be logged", secret)
+		}
+	}
+}
+
+func TestLoadRejectsInvalidSensitivePaths(t *testing.T) {
+	path := writeTempWorkflow(t, "name: sensitive\nsensitive:\n  - token\nsteps: []\n")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "invalid sensitive paths") {
+		t.Errorf("Expected an invalid sensitive paths error, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package workflow
+
+import (
+	"path/filepath"
+
+	"github.com/cjp2600/stepwise/internal/validation"
+)
+
+// prepareRules returns the validation rules of a step ready to run: xml rules without
+// namespaces of their own get those of the step, and schema, script and snapshot files
+// are resolved against the directory of the workflow file. The rules of any_of, all_of, not,
+// each and some are prepared the same way.
+func (e *Executor) prepareRules(rules []validation.ValidationRule, namespaces map[string]string) []validation.ValidationRule {
+	result := make([]validation.ValidationRule, len(rules))
+	for i, rule := range rules {
+		if rule.XML != "" && rule.Namespaces == nil {
+			rule.Namespaces = namespaces
+		}
+		if rule.SchemaFile != "" && !filepath.IsAbs(rule.SchemaFile) && e.workflowDir != "" {
+			rule.SchemaFile = filepath.Join(e.workflowDir, rule.SchemaFile)
+		}
+		if rule.CustomFile != "" && !filepath.IsAbs(rule.CustomFile) && e.workflowDir != "" {
+			rule.CustomFile = filepath.Join(e.workflowDir, rule.CustomFile)
+		}
+		rule.ScriptDir = e.workflowDir
+		rule.SnapshotDir = filepath.Join(e.workflowDir, validation.SnapshotsDir)
+		if len(rule.AnyOf) > 0 {
+			rule.AnyOf = e.prepareRules(rule.AnyOf, namespaces)
+		}
+		if len(rule.AllOf) > 0 {
+			rule.AllOf = e.prepareRules(rule.AllOf, namespaces)
+		}
+		if len(rule.Each) > 0 {
+			rule.Each = e.prepareRules(rule.Each, namespaces)
+		}
+		if len(rule.Some) > 0 {
+			rule.Some = e.prepareRules(rule.Some, namespaces)
+		}
+		if rule.Not != nil {
+			not := e.prepareRules([]validation.ValidationRule{*rule.Not}, namespaces)[0]
+			rule.Not = &not
+		}
+		result[i] = rule
+	}
+	return result
+}
+
+// validationWarnings returns the errors of the failing rules of severity warn
+func validationWarnings(results []validation.ValidationResult) []string {
+	var warnings []string
+	for _, result := range results {
+		if result.Warning() {
+			warnings = append(warnings, result.Error)
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,83 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+)
+
+func TestSchemaValidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "email": "not-an-email", "tags": ["a", "a"]}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "schemas"), 0755); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "schemas", "user.yml"), []byte(`
+type: object
+required: [id, email]
+properties:
+  id: {type: integer}
+  email: {type: string, format: email}
+  tags: {type: array, uniqueItems: true}
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(dir, "workflow.yml")
+	if err := os.WriteFile(path, []byte(`name: schema
+steps:
+  - name: inline
+    request:
+      method: GET
+      url: "`+server.URL+`"
+    validate:
+      - json: "$.id"
+        schema:
+          type: integer
+          minimum: 1
+  - name: file
+    request:
+      method: GET
+      url: "`+server.URL+`"
+    validate:
+      - schema_file: schemas/user.yml
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wf, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if results[0].Status != "passed" {
+		t.Errorf("Expected the inline schema to pass, got %s: %s", results[0].Status, results[0].Error)
+	}
+	if results[1].Status != "failed" {
+		t.Fatalf("Expected the schema file to fail, got %s", results[1].Status)
+	}
+	violations := results[1].Validations[0].Violations
+	if len(violations) != 2 || violations[0].InstancePath != "/email" || violations[1].InstancePath != "/tags" {
+		t.Errorf("Expected violations at /email and /tags, got %+v", violations)
+	}
+	if !strings.Contains(results[1].Error, "/email: must be a valid email") {
+		t.Errorf("Expected the violations in the step error, got %q", results[1].Error)
+	}
+}
@@ -0,0 +1,138 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+	"github.com/cjp2600/stepwise/internal/validation"
+)
+
+// echoPathServer responds with the requested path as JSON and records it
+func echoPathServer(recorder *pathRecorder) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder.mu.Lock()
+		recorder.paths = append(recorder.paths, r.URL.Path)
+		recorder.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"path": r.URL.Path})
+	}))
+}
+
+func TestParallelGroupPromotesCaptures(t *testing.T) {
+	recorder := &pathRecorder{}
+	server := echoPathServer(recorder)
+	defer server.Close()
+
+	var parallelSteps []Step
+	for _, name := range []string{"a", "b", "c"} {
+		parallelSteps = append(parallelSteps, Step{
+			Name:     name,
+			Request:  Request{Method: "GET", URL: server.URL + "/" + name},
+			Capture:  map[string]string{name: "$.path", "last": "$.path"},
+			Validate: []validation.ValidationRule{{Status: 200}},
+		})
+	}
+
+	wf := &Workflow{
+		Name: "Parallel scopes",
+		Groups: []StepGroup{
+			{Name: "fetch", Parallel: true, Steps: parallelSteps},
+			{
+				Name: "use",
+				Steps: []Step{{
+					Name:     "combined",
+					Request:  Request{Method: "GET", URL: server.URL + "/combined{{a}}{{b}}{{c}}{{last}}"},
+					Validate: []validation.ValidationRule{{Status: 200}},
+				}},
+			},
+		},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, result := range results {
+		if result.Status != "passed" {
+			t.Errorf("Expected %s to pass, got %s (%s)", result.Name, result.Status, result.Error)
+		}
+	}
+
+	// Captures are promoted in step order, so the last step wins
+	want := "/combined/a/b/c/c"
+	if got := recorder.sorted(); got[len(got)-1] != want {
+		t.Errorf("Expected request to %s, got %v", want, got)
+	}
+	if value, _ := executor.varManager.Get("last"); value != "/c" {
+		t.Errorf("Expected last = /c in the workflow scope, got %v", value)
+	}
+}
+
+func TestParallelRepeatIterationScopes(t *testing.T) {
+	recorder := &pathRecorder{}
+	server := echoPathServer(recorder)
+	defer server.Close()
+
+	wf := &Workflow{
+		Name:      "Parallel repeat",
+		Variables: map[string]interface{}{"prefix": "r"},
+		Steps: []Step{
+			{
+				Name: "hit",
+				Repeat: &RepeatConfig{
+					Count:     8,
+					Parallel:  true,
+					Variables: map[string]interface{}{"slot": "slot-{{index}}"},
+				},
+				Request:  Request{Method: "GET", URL: server.URL + "/{{prefix}}/{{slot}}/{{iteration}}"},
+				Capture:  map[string]string{"last": "$.path"},
+				Validate: []validation.ValidationRule{{Status: 200}},
+			},
+			{
+				Name:     "after",
+				Request:  Request{Method: "GET", URL: server.URL + "/after{{last}}"},
+				Validate: []validation.ValidationRule{{Status: 200}},
+			},
+		},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results[0].Status != "passed" || results[1].Status != "passed" {
+		t.Fatalf("Expected both steps to pass, got %+v", results)
+	}
+
+	paths := recorder.sorted()
+	for i := 0; i < 8; i++ {
+		want := "/r/slot-" + string(rune('0'+i)) + "/" + string(rune('1'+i))
+		found := false
+		for _, path := range paths {
+			found = found || path == want
+		}
+		if !found {
+			t.Errorf("Expected iteration %d to request %s, got %v", i, want, paths)
+		}
+	}
+	if want := "/after/r/slot-7/8"; !strings.Contains(strings.Join(paths, ","), want) {
+		t.Errorf("Expected the last iteration's capture to be promoted (%s), got %v", want, paths)
+	}
+
+	// Iteration bindings stay in the iteration scope
+	for _, name := range []string{"slot", "index", "iteration"} {
+		if _, exists := executor.varManager.Get(name); exists {
+			t.Errorf("Expected %s not to leak into the workflow scope", name)
+		}
+	}
+}
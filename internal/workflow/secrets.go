@@ -0,0 +1,117 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cjp2600/stepwise/internal/dotenv"
+	"github.com/cjp2600/stepwise/internal/secrets"
+)
+
+// SecretProviderConfig configures a provider for {{secret.*}}. Exactly one field is set.
+// Relative paths are resolved against the directory of the workflow file.
+type SecretProviderConfig struct {
+	File     string `yaml:"file,omitempty" json:"file,omitempty"`         // YAML or JSON file, or a directory with one file per secret
+	Keystore string `yaml:"keystore,omitempty" json:"keystore,omitempty"` // Encrypted keystore managed with `stepwise secrets`
+	Command  string `yaml:"command,omitempty" json:"command,omitempty"`   // Command printing the secret, {name} is replaced by its name
+}
+
+// SetEnvFile sets a .env file loaded on top of the .env file next to the workflow
+func (e *Executor) SetEnvFile(path string) {
+	e.envFile = path
+}
+
+// validateWorkflowSecrets checks that every secret provider sets exactly one source
+func validateWorkflowSecrets(wf *Workflow) error {
+	for i, provider := range wf.Secrets {
+		sources := 0
+		for _, source := range []string{provider.File, provider.Keystore, provider.Command} {
+			if source != "" {
+				sources++
+			}
+		}
+		if sources != 1 {
+			return fmt.Errorf("provider %d: exactly one of file, keystore or command is required", i+1)
+		}
+	}
+	return nil
+}
+
+// loadEnvFiles reads the .env file next to the workflow, if there is one, and the
+// file given with --env-file. Their values are used by {{env.*}} after the process environment.
+func (e *Executor) loadEnvFiles(wf *Workflow) error {
+	values := make(map[string]string)
+
+	if wf.SourceFile != "" {
+		path := filepath.Join(filepath.Dir(wf.SourceFile), ".env")
+		if _, err := os.Stat(path); err == nil {
+			fileValues, err := dotenv.Load(path)
+			if err != nil {
+				return err
+			}
+			for key, value := range fileValues {
+				values[key] = value
+			}
+		}
+	}
+
+	if e.envFile != "" {
+		fileValues, err := dotenv.Load(e.envFile)
+		if err != nil {
+			return err
+		}
+		for key, value := range fileValues {
+			values[key] = value
+		}
+	}
+
+	if len(values) > 0 {
+		e.varManager.SetEnv(values)
+	}
+	return nil
+}
+
+// loadSecrets creates the secret providers of the workflow. The keystore passphrase is
+// read with the same lookup as {{env.*}}, so it may come from a .env file.
+func (e *Executor) loadSecrets(wf *Workflow) error {
+	if len(wf.Secrets) == 0 {
+		return nil
+	}
+
+	providers := make([]secrets.Provider, 0, len(wf.Secrets))
+	for _, config := range wf.Secrets {
+		provider, err := e.secretProvider(wf, config)
+		if err != nil {
+			return fmt.Errorf("failed to load secrets: %w", err)
+		}
+		providers = append(providers, provider)
+	}
+	e.varManager.SetSecrets(secrets.NewStore(providers...))
+	return nil
+}
+
+// secretProvider creates the provider for one entry of the secrets section
+func (e *Executor) secretProvider(wf *Workflow, config SecretProviderConfig) (secrets.Provider, error) {
+	resolve := func(path string) string {
+		if filepath.IsAbs(path) || wf.SourceFile == "" {
+			return path
+		}
+		return filepath.Join(filepath.Dir(wf.SourceFile), path)
+	}
+
+	switch {
+	case config.File != "":
+		return secrets.NewFileProvider(resolve(config.File))
+	case config.Keystore != "":
+		// OpenKeystore creates missing keystores, which is only wanted by `stepwise secrets set`
+		path := resolve(config.Keystore)
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("failed to open keystore: %w", err)
+		}
+		passphrase, _ := e.varManager.LookupEnv(secrets.PassphraseEnv)
+		return secrets.OpenKeystore(path, passphrase)
+	default:
+		return secrets.NewCommandProvider(config.Command)
+	}
+}
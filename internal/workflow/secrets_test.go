@@ -0,0 +1,179 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	httpclient "github.com/cjp2600/stepwise/internal/http"
+	"github.com/cjp2600/stepwise/internal/logger"
+	"github.com/cjp2600/stepwise/internal/secrets"
+	"github.com/cjp2600/stepwise/internal/validation"
+)
+
+// authServer responds 200 only to requests with the expected bearer token and X-User header
+func authServer(token, user string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token || r.Header.Get("X-User") != user {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"ok": true}`))
+	}))
+}
+
+func TestSecretsAndEnvFiles(t *testing.T) {
+	server := authServer("file-token", "dotenv-user")
+	defer server.Close()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		".env":        "STEPWISE_TEST_USER=dotenv-user\nSTEPWISE_TEST_REGION=eu\n",
+		"secrets.yml": "api_token: file-token\n",
+		"workflow.yml": `name: secrets
+secrets:
+  - file: secrets.yml
+steps:
+  - name: call
+    request:
+      method: GET
+      url: "` + server.URL + `/{{env.STEPWISE_TEST_REGION}}"
+      headers:
+        X-User: "{{env.STEPWISE_TEST_USER}}"
+      auth:
+        type: bearer
+        token: "{{secret.api_token}}"
+    validate:
+      - status: 200
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wf, err := Load(filepath.Join(dir, "workflow.yml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results[0].Status != "passed" {
+		t.Errorf("Expected the step to pass with the secret token, got %s (%s)", results[0].Status, results[0].Error)
+	}
+}
+
+func TestEnvFileOverridesWorkflowDotEnv(t *testing.T) {
+	dir := t.TempDir()
+	workflowFile := filepath.Join(dir, "workflow.yml")
+	if err := os.WriteFile(workflowFile, []byte("name: env\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("STEPWISE_TEST_A=dotenv\nSTEPWISE_TEST_B=dotenv\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	envFile := filepath.Join(t.TempDir(), "ci.env")
+	if err := os.WriteFile(envFile, []byte("STEPWISE_TEST_B=explicit\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wf, err := Load(workflowFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	executor.SetEnvFile(envFile)
+	if _, err := executor.Execute(context.Background(), wf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if value, _ := executor.varManager.LookupEnv("STEPWISE_TEST_A"); value != "dotenv" {
+		t.Errorf("Expected STEPWISE_TEST_A from .env, got %q", value)
+	}
+	if value, _ := executor.varManager.LookupEnv("STEPWISE_TEST_B"); value != "explicit" {
+		t.Errorf("Expected STEPWISE_TEST_B from --env-file, got %q", value)
+	}
+}
+
+func TestKeystoreSecrets(t *testing.T) {
+	dir := t.TempDir()
+	ks, err := secrets.OpenKeystore(filepath.Join(dir, "keystore.yml"), "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks.Set("db_password", "from-keystore")
+	if err := ks.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The passphrase may come from the .env file next to the workflow
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte(secrets.PassphraseEnv+"=passphrase\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	workflowFile := filepath.Join(dir, "workflow.yml")
+	if err := os.WriteFile(workflowFile, []byte("name: keystore\nsecrets:\n  - keystore: keystore.yml\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wf, err := Load(workflowFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	if _, err := executor.Execute(context.Background(), wf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value, _ := executor.varManager.Substitute("{{secret.db_password}}"); value != "from-keystore" {
+		t.Errorf("Expected the keystore secret, got %q", value)
+	}
+}
+
+func TestAuthIsSubstituted(t *testing.T) {
+	server := authServer("token-1", "alice")
+	defer server.Close()
+
+	wf := &Workflow{
+		Name:      "auth",
+		Variables: map[string]interface{}{"token": "token-1"},
+		Steps: []Step{{
+			Name: "call",
+			Request: Request{
+				Method:  "GET",
+				URL:     server.URL,
+				Headers: map[string]string{"X-User": "alice"},
+				Auth:    &httpclient.Auth{Type: "bearer", Token: "{{token}}"},
+			},
+			Validate: []validation.ValidationRule{{Status: 200}},
+		}},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results[0].Status != "passed" {
+		t.Errorf("Expected auth to be applied, got %s (%s)", results[0].Status, results[0].Error)
+	}
+	if wf.Steps[0].Request.Auth.Token != "{{token}}" {
+		t.Errorf("Expected the workflow auth to be left unchanged, got %q", wf.Steps[0].Request.Auth.Token)
+	}
+}
+
+func TestLoadRejectsInvalidSecrets(t *testing.T) {
+	content := "name: wf\nsecrets:\n  - file: a.yml\n    command: pass show {name}\n"
+	_, err := Load(writeTempWorkflow(t, content))
+	if err == nil || !strings.Contains(err.Error(), "provider 1: exactly one of file, keystore or command is required") {
+		t.Errorf("Expected invalid secrets error, got %v", err)
+	}
+}
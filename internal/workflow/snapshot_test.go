@@ -0,0 +1,75 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+)
+
+func TestSnapshots(t *testing.T) {
+	total := 10
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id": "%d", "total": %d}`, time.Now().UnixNano(), total)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "workflow.yml")
+	if err := os.WriteFile(path, []byte(`name: snapshots
+steps:
+  - name: order
+    request:
+      method: GET
+      url: "`+server.URL+`"
+    validate:
+      - snapshot: "order"
+        ignore: ["$.id"]
+`), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	run := func(update bool) TestResult {
+		wf, err := Load(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+		executor.SetUpdateSnapshots(update)
+		results, err := executor.Execute(context.Background(), wf)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return results[0]
+	}
+
+	if result := run(false); result.Status != "passed" {
+		t.Fatalf("Expected the snapshot to be written, got %s: %s", result.Status, result.Error)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "__snapshots__", "order.json")); err != nil {
+		t.Fatalf("Expected the snapshot next to the workflow: %v", err)
+	}
+	if result := run(false); result.Status != "passed" {
+		t.Errorf("Expected the snapshot to match despite the id, got %s: %s", result.Status, result.Error)
+	}
+
+	total = 12
+	if result := run(false); result.Status != "failed" || !strings.Contains(result.Error, "$.total: expected 10, got 12") {
+		t.Errorf("Expected the snapshot to differ, got %s: %s", result.Status, result.Error)
+	}
+	if result := run(true); result.Status != "passed" {
+		t.Errorf("Expected the snapshot to be updated, got %s: %s", result.Status, result.Error)
+	}
+	if result := run(false); result.Status != "passed" {
+		t.Errorf("Expected the updated snapshot to match, got %s: %s", result.Status, result.Error)
+	}
+}
@@ -0,0 +1,114 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+	"github.com/cjp2600/stepwise/internal/validation"
+)
+
+func TestExecuteWorkflowTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	wf := &Workflow{
+		Name:    "Timeout",
+		Timeout: "100ms",
+		Steps: []Step{
+			{
+				Name:     "slow",
+				Request:  Request{Method: "GET", URL: server.URL},
+				Validate: []validation.ValidationRule{{Status: 200}},
+			},
+			{
+				Name:  "never",
+				Print: "should not run",
+			},
+		},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 10 * time.Second}, logger.New())
+
+	start := time.Now()
+	results, err := executor.Execute(context.Background(), wf)
+	elapsed := time.Since(start)
+
+	if err == nil || !strings.Contains(err.Error(), "workflow timeout exceeded") {
+		t.Fatalf("Expected timeout error, got %v", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("Expected in-flight request to be cancelled, run took %v", elapsed)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 partial result, got %d", len(results))
+	}
+	if results[0].Status != "failed" {
+		t.Errorf("Expected interrupted step to fail, got %s", results[0].Status)
+	}
+}
+
+func TestExecuteCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	wf := &Workflow{
+		Name:  "Cancelled",
+		Steps: []Step{{Name: "print", Print: "hello"}},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: time.Second}, logger.New())
+	results, err := executor.Execute(ctx, wf)
+	if err == nil || !strings.Contains(err.Error(), "workflow interrupted") {
+		t.Fatalf("Expected interrupt error, got %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+}
+
+func TestWaitStepInterrupted(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	wf := &Workflow{
+		Name:  "Wait",
+		Steps: []Step{{Name: "wait", Wait: "5s"}},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: time.Second}, logger.New())
+
+	start := time.Now()
+	_, err := executor.Execute(ctx, wf)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected wait to be interrupted, run took %v", elapsed)
+	}
+	if err == nil {
+		t.Error("Expected error after deadline, got nil")
+	}
+}
+
+func TestLoadRejectsInvalidTimeout(t *testing.T) {
+	path := writeTempWorkflow(t, `name: "Bad timeout"
+timeout: "soon"
+steps:
+  - name: "a"
+    print: "a"
+`)
+
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "invalid workflow timeout") {
+		t.Errorf("Expected invalid timeout error, got %v", err)
+	}
+}
@@ -1,7 +1,9 @@
 package workflow
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -13,10 +15,13 @@ import (
 
 	"github.com/cjp2600/stepwise/internal/config"
 	dbclient "github.com/cjp2600/stepwise/internal/database"
+	"github.com/cjp2600/stepwise/internal/faker"
 	grpcclient "github.com/cjp2600/stepwise/internal/grpc"
 	httpclient "github.com/cjp2600/stepwise/internal/http"
 	"github.com/cjp2600/stepwise/internal/logger"
 	mcpclient "github.com/cjp2600/stepwise/internal/mcp"
+	"github.com/cjp2600/stepwise/internal/openapi"
+	"github.com/cjp2600/stepwise/internal/redact"
 	"github.com/cjp2600/stepwise/internal/validation"
 	"github.com/cjp2600/stepwise/internal/variables"
 	"gopkg.in/yaml.v3"
@@ -33,25 +38,40 @@ type Import struct {
 
 // Workflow represents a complete test workflow
 type Workflow struct {
-	Name        string                 `yaml:"name" json:"name"`
-	Version     string                 `yaml:"version" json:"version"`
-	Description string                 `yaml:"description" json:"description"`
-	Variables   map[string]interface{} `yaml:"variables" json:"variables"`
-	Imports     []Import               `yaml:"imports,omitempty" json:"imports,omitempty"`
-	Steps       []Step                 `yaml:"steps" json:"steps"`
-	Groups      []StepGroup            `yaml:"groups" json:"groups"`
-	Captures    map[string]string      `yaml:"captures,omitempty" json:"captures,omitempty"` // Global captures for the workflow
-	SourceFile  string                 `yaml:"-" json:"-"`                                   // путь к исходному workflow-файлу (не сериализуется)
+	Name         string                            `yaml:"name" json:"name"`
+	Version      string                            `yaml:"version" json:"version"`
+	Description  string                            `yaml:"description" json:"description"`
+	Variables    map[string]interface{}            `yaml:"variables" json:"variables"`
+	Environments map[string]map[string]interface{} `yaml:"environments,omitempty" json:"environments,omitempty"` // Variables layered over Variables for the selected environment
+	Imports      []Import                          `yaml:"imports,omitempty" json:"imports,omitempty"`
+	Data         *DataConfig                       `yaml:"data,omitempty" json:"data,omitempty"`   // Run the whole workflow once per row of a data file
+	Setup        []Step                            `yaml:"setup,omitempty" json:"setup,omitempty"` // Steps executed before the main steps
+	Steps        []Step                            `yaml:"steps" json:"steps"`
+	Groups       []StepGroup                       `yaml:"groups" json:"groups"`
+	Teardown     []Step                            `yaml:"teardown,omitempty" json:"teardown,omitempty"`         // Steps always executed at the end, even after failures
+	Captures     map[string]CaptureConfig          `yaml:"captures,omitempty" json:"captures,omitempty"`         // Captures evaluated after every step, reported at the end of the run
+	Secrets      []SecretProviderConfig            `yaml:"secrets,omitempty" json:"secrets,omitempty"`           // Providers for {{secret.*}}, asked in order
+	Sensitive    []string                          `yaml:"sensitive,omitempty" json:"sensitive,omitempty"`       // JSON paths of request and response fields masked in output
+	FakerSeed    *int64                            `yaml:"faker_seed,omitempty" json:"faker_seed,omitempty"`     // Seed of {{faker.*}} data, random when unset
+	FakerLocale  string                            `yaml:"faker_locale,omitempty" json:"faker_locale,omitempty"` // Locale of {{faker.*}} data, "en" by default
+	MaxParallel  int                               `yaml:"max_parallel,omitempty" json:"max_parallel,omitempty"` // Worker limit for steps scheduled by needs
+	Timeout      string                            `yaml:"timeout,omitempty" json:"timeout,omitempty"`           // Deadline for the whole workflow run, e.g. "5m"
+	OpenAPI      string                            `yaml:"openapi,omitempty" json:"openapi,omitempty"`           // OpenAPI description that HTTP steps are checked against
+	SourceFile   string                            `yaml:"-" json:"-"`                                           // путь к исходному workflow-файлу (не сериализуется)
 }
 
 // StepGroup represents a group of steps that can be executed together
 type StepGroup struct {
-	Name        string      `yaml:"name" json:"name"`
-	Description string      `yaml:"description" json:"description"`
-	Parallel    bool        `yaml:"parallel" json:"parallel"`
-	Condition   string      `yaml:"condition" json:"condition"`
-	Steps       []Step      `yaml:"steps" json:"steps"`
-	Groups      []StepGroup `yaml:"groups,omitempty" json:"groups,omitempty"`
+	Name        string         `yaml:"name" json:"name"`
+	Description string         `yaml:"description" json:"description"`
+	Parallel    bool           `yaml:"parallel" json:"parallel"`
+	Condition   string         `yaml:"condition" json:"condition"`
+	Foreach     *ForeachConfig `yaml:"foreach,omitempty" json:"foreach,omitempty"`
+	Data        *DataConfig    `yaml:"data,omitempty" json:"data,omitempty"` // Run the group once per row of a data file
+	Setup       []Step         `yaml:"setup,omitempty" json:"setup,omitempty"`
+	Steps       []Step         `yaml:"steps" json:"steps"`
+	Groups      []StepGroup    `yaml:"groups,omitempty" json:"groups,omitempty"`
+	Teardown    []Step         `yaml:"teardown,omitempty" json:"teardown,omitempty"`
 }
 
 // Branch represents a conditional branch with steps
@@ -70,15 +90,19 @@ type Step struct {
 	Request      Request                     `yaml:"request" json:"request"`
 	Validate     []validation.ValidationRule `yaml:"validate" json:"validate"`
 	Capture      map[string]string           `yaml:"capture" json:"capture"`
+	Namespaces   map[string]string           `yaml:"namespaces,omitempty" json:"namespaces,omitempty"` // XPath prefixes of xml validations and captures
 	Condition    string                      `yaml:"condition" json:"condition"`
 	Retry        int                         `yaml:"retry" json:"retry"`
 	RetryDelay   string                      `yaml:"retry_delay" json:"retry_delay"`
 	Timeout      string                      `yaml:"timeout" json:"timeout"`
 	Repeat       *RepeatConfig               `yaml:"repeat,omitempty" json:"repeat,omitempty"`
+	Foreach      *ForeachConfig              `yaml:"foreach,omitempty" json:"foreach,omitempty"`     // Run the step once per item of a list
 	Poll         *PollConfig                 `yaml:"poll,omitempty" json:"poll,omitempty"`           // Polling configuration
 	Wait         string                      `yaml:"wait,omitempty" json:"wait,omitempty"`           // Новое поле для задержки
 	Print        string                      `yaml:"print,omitempty" json:"print,omitempty"`         // Новое поле для вывода
 	Variables    map[string]interface{}      `yaml:"variables,omitempty" json:"variables,omitempty"` // Переменные для переопределения в use
+	Needs        []string                    `yaml:"needs,omitempty" json:"needs,omitempty"`         // Names of steps that must pass before this step runs
+	Always       bool                        `yaml:"always,omitempty" json:"always,omitempty"`       // Run even after fail-fast, interruption or failed needs
 	// Branching fields
 	If       string   `yaml:"if,omitempty" json:"if,omitempty"`             // Условие для if-then-else
 	Then     []Step   `yaml:"then,omitempty" json:"then,omitempty"`         // Шаги для then ветки
@@ -113,6 +137,8 @@ type Request struct {
 	Body    interface{}       `yaml:"body" json:"body"`
 	Query   interface{}       `yaml:"query" json:"query"` // map[string]string for HTTP, string for DB
 	Auth    *httpclient.Auth  `yaml:"auth" json:"auth"`
+	// Redirects are followed unless follow_redirects is false
+	FollowRedirects *bool `yaml:"follow_redirects,omitempty" json:"follow_redirects,omitempty"`
 
 	// gRPC fields
 	Service    string            `yaml:"service" json:"service"`
@@ -145,12 +171,24 @@ type TestResult struct {
 	Duration      time.Duration                 `json:"duration"`
 	Error         string                        `json:"error,omitempty"`
 	Validations   []validation.ValidationResult `json:"validations,omitempty"`
+	Warnings      []string                      `json:"warnings,omitempty"` // Errors of the failing rules of severity warn
 	CapturedData  map[string]interface{}        `json:"captured_data,omitempty"`
 	Retries       int                           `json:"retries,omitempty"`
 	RepeatResults []TestResult                  `json:"repeat_results,omitempty"`
 	RepeatCount   int                           `json:"repeat_count,omitempty"`
 	PrintText     string                        `json:"print_text,omitempty"`    // Текст print для отчёта
 	PollAttempts  int                           `json:"poll_attempts,omitempty"` // Количество попыток поллинга
+	Phase         string                        `json:"phase,omitempty"`         // "setup" or "teardown"; empty for regular steps
+	Item          string                        `json:"item,omitempty"`          // Item of a foreach iteration
+	Row           string                        `json:"row,omitempty"`           // Data row the result belongs to, for data-driven workflows
+}
+
+// DisplayName returns the step name prefixed with its phase for setup and teardown steps
+func (r TestResult) DisplayName() string {
+	if r.Phase == "" {
+		return r.Name
+	}
+	return fmt.Sprintf("[%s] %s", r.Phase, r.Name)
 }
 
 // GroupResult represents the result of a step group
@@ -177,10 +215,9 @@ type Executor struct {
 	config           *config.Config
 	logger           *logger.Logger
 	httpClient       *httpclient.Client
-	grpcClient       *grpcclient.Client
-	grpcServerAddr   string // Track current gRPC server address to detect changes
-	mcpClient        *mcpclient.Client
-	mcpClientKey     string // Track current MCP client key to detect changes
+	clients          *clientPool              // gRPC and MCP clients, shared with forked executors
+	captures         map[string]CaptureConfig // Workflow captures of the current run
+	captured         *captureStore            // Values of workflow captures, shared with forked executors
 	dbClient         *dbclient.Client
 	validator        *validation.Validator
 	varManager       *variables.Manager
@@ -188,6 +225,19 @@ type Executor struct {
 	failFast         bool
 	mcpMode          bool                    // MCP mode - disables verbose and show_response
 	componentMap     map[string]StepWithVars // Component map for use steps
+	maxParallel      int                     // Worker limit for steps scheduled by needs
+	ctx              context.Context         // Context of the current run, cancelled on timeout or interrupt
+	workflowDir      string                  // Directory of the workflow file, used to resolve data files
+	dataFile         string                  // Data file overriding the data section of the workflow
+	environment      string                  // Selected environment, see resolveVariables
+	overrides        map[string]interface{}  // Variables from --var-file and --var, applied last
+	envFile          string                  // .env file given with --env-file, on top of the .env next to the workflow
+	redactor         *redact.Redactor        // Masks secrets in output, shared with the logger
+	seed             *int64                  // Faker seed given with --seed
+	faker            *faker.Faker            // Generates {{faker.*}} data of the current run
+	openapi          *openapi.Spec           // OpenAPI description HTTP steps are checked against
+	coverage         *openapi.Coverage       // Calls of the OpenAPI operations, shared with forked executors
+	updateSnapshots  bool                    // Rewrite snapshots that differ instead of failing
 }
 
 // SetProgressCallback sets the progress callback function
@@ -200,6 +250,13 @@ func (e *Executor) SetFailFast(failFast bool) {
 	e.failFast = failFast
 }
 
+// SetUpdateSnapshots makes snapshot rules rewrite the snapshot files that differ
+// from the responses instead of failing
+func (e *Executor) SetUpdateSnapshots(update bool) {
+	e.updateSnapshots = update
+	e.validator.SetUpdateSnapshots(update)
+}
+
 // SetMCPMode sets the MCP mode (disables verbose and show_response)
 func (e *Executor) SetMCPMode(mcpMode bool) {
 	e.mcpMode = mcpMode
@@ -211,9 +268,11 @@ func NewExecutor(cfg *config.Config, log *logger.Logger) *Executor {
 		config:     cfg,
 		logger:     log,
 		httpClient: httpclient.NewClient(cfg.Timeout, log),
-		grpcClient: nil, // Will be initialized when needed
+		clients:    newClientPool(),
+		captured:   newCaptureStore(),
 		validator:  validation.NewValidator(log),
 		varManager: variables.NewManager(log),
+		redactor:   log.Redactor(),
 	}
 
 	// Set the variable manager in the validator
@@ -258,6 +317,33 @@ func LoadWithImports(filename string, searchPaths []string) (*Workflow, error) {
 	// Сохраняем путь к исходному workflow-файлу
 	workflow.SourceFile = filename
 
+	// Reject unknown or circular step dependencies before anything runs
+	if err := validateWorkflowDependencies(&workflow); err != nil {
+		return nil, fmt.Errorf("invalid step dependencies: %w", err)
+	}
+
+	if err := validateWorkflowData(&workflow); err != nil {
+		return nil, fmt.Errorf("invalid data section: %w", err)
+	}
+
+	if err := validateWorkflowSecrets(&workflow); err != nil {
+		return nil, fmt.Errorf("invalid secrets: %w", err)
+	}
+
+	if err := validateWorkflowSensitive(&workflow); err != nil {
+		return nil, fmt.Errorf("invalid sensitive paths: %w", err)
+	}
+
+	if err := validateWorkflowFaker(&workflow); err != nil {
+		return nil, err
+	}
+
+	if workflow.Timeout != "" {
+		if _, err := time.ParseDuration(workflow.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid workflow timeout %q: %w", workflow.Timeout, err)
+		}
+	}
+
 	// Resolve imports if any
 	if len(workflow.Imports) > 0 {
 		// Get the directory of the workflow file for relative imports
@@ -270,16 +356,69 @@ func LoadWithImports(filename string, searchPaths []string) (*Workflow, error) {
 		}
 	}
 
+	// Conditions are checked after imports so that steps from components are covered too
+	if err := validateWorkflowConditions(&workflow); err != nil {
+		return nil, err
+	}
+
+	// Captures may come from components and may name their steps
+	if err := validateWorkflowCaptures(&workflow); err != nil {
+		return nil, fmt.Errorf("invalid captures: %w", err)
+	}
+
 	return &workflow, nil
 }
 
 // Execute executes a workflow and returns the results
-func (e *Executor) Execute(wf *Workflow) ([]TestResult, error) {
+func (e *Executor) Execute(ctx context.Context, wf *Workflow) ([]TestResult, error) {
 	e.logger.Info("Starting workflow execution", "name", wf.Name)
 	startTime := time.Now()
 
-	// Initialize variables
-	e.initializeVariables(wf.Variables)
+	// Apply workflow-level deadline
+	if wf.Timeout != "" {
+		if timeout, err := time.ParseDuration(wf.Timeout); err == nil && timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+	}
+	e.ctx = ctx
+	defer e.clients.closeAll()
+
+	// Workflow variables and captures live in a workflow scope on top of the global one
+	e.varManager = e.varManager.NewScope("workflow", nil)
+	e.validator.SetVariableManager(e.varManager)
+
+	// Secrets and sensitive fields met during the run are masked in output
+	e.varManager.SetRedactor(e.redactor)
+	if err := e.redactor.AddPaths(wf.Sensitive...); err != nil {
+		return nil, fmt.Errorf("invalid sensitive paths: %w", err)
+	}
+
+	// Faker data is reproducible with the seed that is logged and reported
+	if err := e.setupFaker(wf); err != nil {
+		return nil, err
+	}
+	if err := e.setupOpenAPI(wf); err != nil {
+		return nil, err
+	}
+
+	// .env files and secret providers are resolved before the variables that may refer to them
+	if err := e.loadEnvFiles(wf); err != nil {
+		return nil, err
+	}
+	if err := e.loadSecrets(wf); err != nil {
+		return nil, err
+	}
+
+	// Initialize variables, layered by environment and overrides
+	vars, err := e.resolveVariables(wf)
+	if err != nil {
+		return nil, err
+	}
+	e.initializeVariables(vars)
+	e.maxParallel = e.resolveMaxParallel(wf)
+	e.captures = wf.Captures
 
 	// Собираем карту компонент по имени (только step-компоненты)
 	e.componentMap = make(map[string]StepWithVars)
@@ -288,6 +427,7 @@ func (e *Executor) Execute(wf *Workflow) ([]TestResult, error) {
 	if wf != nil && wf.SourceFile != "" {
 		workflowDir = filepath.Dir(wf.SourceFile)
 	}
+	e.workflowDir = workflowDir
 	searchPaths := []string{}
 	if workflowDir != "" {
 		searchPaths = append(searchPaths, workflowDir)
@@ -325,175 +465,94 @@ func (e *Executor) Execute(wf *Workflow) ([]TestResult, error) {
 	e.logger.Info("[DEBUG] Available componentMap keys", "keys", componentKeys)
 
 	var allResults []TestResult
-	totalSteps := len(wf.Steps)
+	var runErr error
+	if data := e.workflowData(wf); data != nil {
+		allResults, runErr = e.executeDataRows(ctx, wf, data)
+	} else {
+		allResults, runErr = e.executeRun(ctx, wf)
+	}
 
-	for stepIndex, step := range wf.Steps {
-		if step.Use != "" {
-			if comp, ok := e.componentMap[step.Use]; ok {
-				mergedStep := comp.Step
-				if step.Capture != nil {
-					mergedStep.Capture = step.Capture
-				}
-				if len(step.Validate) > 0 {
-					mergedStep.Validate = step.Validate
-				}
-				if step.Name != "" {
-					mergedStep.Name = step.Name
-				}
-				if step.Description != "" {
-					mergedStep.Description = step.Description
-				}
-				if step.Repeat != nil {
-					mergedStep.Repeat = step.Repeat
-				}
-				// Copy Poll configuration: step-level poll overrides component poll
-				// If component has poll but step doesn't, keep component's poll
-				// If step has poll, it overrides component's poll
-				if step.Poll != nil {
-					mergedStep.Poll = step.Poll
-					e.logger.Debug("[COMPONENT] Overriding poll configuration from step", "step", step.Name, "max_attempts", step.Poll.MaxAttempts, "interval", step.Poll.Interval)
-				} else if mergedStep.Poll != nil {
-					e.logger.Debug("[COMPONENT] Using poll configuration from component", "step", step.Name, "max_attempts", mergedStep.Poll.MaxAttempts, "interval", mergedStep.Poll.Interval)
-				}
-				// Note: If step.Poll is nil but component has poll, mergedStep already has it from comp.Step
-				// Copy ShowResponse: if either component or step has it set to true, show response
-				if step.ShowResponse {
-					mergedStep.ShowResponse = true
-				}
-				// Copy timeout: step-level timeout overrides component timeout
-				// If step has timeout in request, it overrides component's request timeout
-				if step.Request.Timeout != "" {
-					mergedStep.Request.Timeout = step.Request.Timeout
-					e.logger.Debug("[COMPONENT] Overriding timeout from step", "step", step.Name, "timeout", step.Request.Timeout)
-				} else if step.Timeout != "" {
-					// Also check step-level timeout field (for backward compatibility)
-					mergedStep.Request.Timeout = step.Timeout
-					e.logger.Debug("[COMPONENT] Overriding timeout from step-level field", "step", step.Name, "timeout", step.Timeout)
-				} else if mergedStep.Request.Timeout != "" {
-					e.logger.Debug("[COMPONENT] Using timeout from component", "step", step.Name, "timeout", mergedStep.Request.Timeout)
-				}
-				e.logger.Info("[COMPONENT] Executing use step", "use", step.Use, "step", mergedStep.Name)
-				// Initialize component variables first
-				e.initializeVariables(comp.Variables)
-				// Then apply step-level variable overrides
-				if len(step.Variables) > 0 {
-					e.logger.Debug("[COMPONENT] Applying step-level variable overrides", "variables", step.Variables)
-					e.initializeVariables(step.Variables)
-				}
-				result := &TestResult{
-					Name:         mergedStep.Name,
-					Status:       "pending",
-					CapturedData: make(map[string]interface{}),
-				}
+	totalDuration := time.Since(startTime)
+	e.logger.Info("Workflow execution completed", "duration", totalDuration, "total_steps", len(allResults))
+	e.redactResults(allResults)
 
-				// Notify progress callback that step is starting
-				if e.progressCallback != nil {
-					e.progressCallback(mergedStep.Name, stepIndex+1, totalSteps, "running", 0, 0, 0, nil)
-				}
+	if runErr != nil {
+		return allResults, runErr
+	}
+	// A step may have been cut short by the deadline without failing fast
+	if err := ctx.Err(); err != nil {
+		return allResults, interruptError(err)
+	}
 
-				if err := e.executeStepWithRepeat(&mergedStep, result); err != nil {
-					result.Status = "failed"
-					result.Error = err.Error()
-					e.logger.Error("Component step execution failed", "component", mergedStep.Name, "error", err)
-				} else {
-					result.Status = "passed"
-				}
+	return allResults, nil
+}
 
-				// Notify progress callback of completion
-				if e.progressCallback != nil {
-					validationsPassed := 0
-					validationsTotal := len(result.Validations)
-					for _, v := range result.Validations {
-						if v.Passed {
-							validationsPassed++
-						}
-					}
-					e.progressCallback(mergedStep.Name, stepIndex+1, totalSteps, result.Status, result.Duration, validationsPassed, validationsTotal, nil)
-				}
+// executeRun executes setup, the main steps and groups, and teardown of a workflow
+func (e *Executor) executeRun(ctx context.Context, wf *Workflow) ([]TestResult, error) {
+	// Setup runs first; if it fails the main steps are not executed
+	allResults, runErr := e.executeSetupSteps(wf.Setup)
+	if runErr == nil {
+		results, err := e.executeWorkflowBody(ctx, wf)
+		allResults = append(allResults, results...)
+		runErr = err
+	}
 
-				allResults = append(allResults, *result)
-				vars := e.varManager.GetAll()
-				e.logger.Info("[DEBUG] Variables after component step", "step", mergedStep.Name, "vars", vars)
+	// Teardown runs after failures, fail-fast aborts and cancellation alike
+	allResults = append(allResults, e.executeTeardownSteps(wf.Teardown)...)
+	return allResults, runErr
+}
 
-				// Check fail-fast mode for component steps
-				if e.failFast && result.Status == "failed" {
-					e.logger.Error("Fail-fast mode enabled: stopping execution due to component test failure", "step", mergedStep.Name)
-					return allResults, fmt.Errorf("execution stopped at first failure (component step: %s)", mergedStep.Name)
-				}
-				continue
-			} else {
-				e.logger.Error("Component not found for use step", "use", step.Use)
-				result := &TestResult{
-					Name:   step.Name,
-					Status: "failed",
-					Error:  fmt.Sprintf("Component not found for use: %s", step.Use),
-				}
-				allResults = append(allResults, *result)
+// executeWorkflowBody executes the main steps and groups of a workflow
+func (e *Executor) executeWorkflowBody(ctx context.Context, wf *Workflow) ([]TestResult, error) {
+	var allResults []TestResult
+	totalSteps := len(wf.Steps)
 
-				// Check fail-fast mode for missing component
-				if e.failFast {
-					e.logger.Error("Fail-fast mode enabled: stopping execution due to missing component", "use", step.Use)
-					return allResults, fmt.Errorf("execution stopped at first failure (component not found: %s)", step.Use)
-				}
-				continue
-			}
-		}
-		result := &TestResult{
-			Name:         step.Name,
-			Status:       "pending",
-			CapturedData: make(map[string]interface{}),
+	if hasStepDependencies(wf.Steps) {
+		// At least one step declares needs: schedule the steps as a dependency graph
+		results, err := e.executeStepsDAG(wf.Steps, e.maxParallel, func(stepIndex int, step *Step) (*TestResult, error) {
+			return e.executeWorkflowStep(step, stepIndex+1, totalSteps)
+		})
+		allResults = append(allResults, results...)
+		if err != nil {
+			return allResults, err
 		}
-		if step.Condition != "" {
-			if !e.evaluateCondition(step.Condition) {
-				e.logger.Info("Skipping step due to condition", "step", step.Name, "condition", step.Condition)
-				result.Status = "skipped"
-				allResults = append(allResults, *result)
-				continue
+	} else {
+		var stopErr error
+		for stepIndex := range wf.Steps {
+			step := wf.Steps[stepIndex]
+			if stopErr == nil {
+				if err := ctx.Err(); err != nil {
+					stopErr = interruptError(err)
+				}
 			}
-		}
-
-		// Notify progress callback that step is starting
-		if e.progressCallback != nil {
-			e.progressCallback(step.Name, stepIndex+1, totalSteps, "running", 0, 0, 0, nil)
-		}
-
-		if err := e.executeStepWithRepeat(&step, result); err != nil {
-			result.Status = "failed"
-			result.Error = err.Error()
-			e.logger.Error("Step execution failed", "step", step.Name, "error", err)
-		} else {
-			result.Status = "passed"
-		}
-
-		// Notify progress callback of completion
-		if e.progressCallback != nil {
-			validationsPassed := 0
-			validationsTotal := len(result.Validations)
-			for _, v := range result.Validations {
-				if v.Passed {
-					validationsPassed++
+			if stopErr != nil {
+				// Execution was stopped: only always steps still run
+				if !step.Always {
+					continue
 				}
+				e.logger.Info("Running always step after stop", "step", step.Name)
+				e.withCleanupContext(func() {
+					result, _ := e.executeWorkflowStep(&step, stepIndex+1, totalSteps)
+					allResults = append(allResults, *result)
+				})
+				continue
 			}
-			var resultErr error
-			if result.Status == "failed" && result.Error != "" {
-				resultErr = fmt.Errorf("%s", result.Error)
+			result, err := e.executeWorkflowStep(&step, stepIndex+1, totalSteps)
+			allResults = append(allResults, *result)
+			if err != nil {
+				stopErr = err
 			}
-			e.progressCallback(step.Name, stepIndex+1, totalSteps, result.Status, result.Duration, validationsPassed, validationsTotal, resultErr)
 		}
-
-		allResults = append(allResults, *result)
-		vars := e.varManager.GetAll()
-		e.logger.Info("[DEBUG] Variables after step", "step", step.Name, "vars", vars)
-
-		// Check fail-fast mode
-		if e.failFast && result.Status == "failed" {
-			e.logger.Error("Fail-fast mode enabled: stopping execution due to test failure", "step", step.Name)
-			return allResults, fmt.Errorf("execution stopped at first failure (step: %s)", step.Name)
+		if stopErr != nil {
+			return allResults, stopErr
 		}
 	}
 
 	// Execute step groups
 	for _, group := range wf.Groups {
+		if err := ctx.Err(); err != nil {
+			return allResults, interruptError(err)
+		}
 		groupResult := &GroupResult{
 			Name:     group.Name,
 			Status:   "pending",
@@ -533,25 +592,299 @@ func (e *Executor) Execute(wf *Workflow) ([]TestResult, error) {
 		}
 	}
 
-	totalDuration := time.Since(startTime)
-	e.logger.Info("Workflow execution completed", "duration", totalDuration, "total_steps", len(allResults))
-
 	return allResults, nil
 }
 
-// executeGroup executes a group of steps, either sequentially or in parallel
+// context returns the context of the current run
+func (e *Executor) context() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
+}
+
+// wait pauses for the given duration, returning early if the run is cancelled
+func (e *Executor) wait(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-e.context().Done():
+	}
+}
+
+// interruptError describes why the run context was cancelled
+func interruptError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("workflow timeout exceeded: %w", err)
+	}
+	return fmt.Errorf("workflow interrupted: %w", err)
+}
+
+// executeWorkflowStep executes a single top-level workflow step, resolving use components.
+// A non-nil error means that execution must stop (fail-fast mode).
+func (e *Executor) executeWorkflowStep(step *Step, stepIndex int, totalSteps int) (*TestResult, error) {
+	if step.Use != "" {
+		comp, ok := e.componentMap[step.Use]
+		if !ok {
+			e.logger.Error("Component not found for use step", "use", step.Use)
+			result := &TestResult{
+				Name:   step.Name,
+				Status: "failed",
+				Error:  fmt.Sprintf("Component not found for use: %s", step.Use),
+			}
+
+			// Check fail-fast mode for missing component
+			if e.failFast {
+				e.logger.Error("Fail-fast mode enabled: stopping execution due to missing component", "use", step.Use)
+				return result, fmt.Errorf("execution stopped at first failure (component not found: %s)", step.Use)
+			}
+			return result, nil
+		}
+
+		mergedStep := comp.Step
+		if step.Capture != nil {
+			mergedStep.Capture = step.Capture
+		}
+		if len(step.Validate) > 0 {
+			mergedStep.Validate = step.Validate
+		}
+		if step.Namespaces != nil {
+			mergedStep.Namespaces = step.Namespaces
+		}
+		if step.Name != "" {
+			mergedStep.Name = step.Name
+		}
+		if step.Description != "" {
+			mergedStep.Description = step.Description
+		}
+		if step.Repeat != nil {
+			mergedStep.Repeat = step.Repeat
+		}
+		if step.Foreach != nil {
+			mergedStep.Foreach = step.Foreach
+		}
+		// Copy Poll configuration: step-level poll overrides component poll
+		// If component has poll but step doesn't, keep component's poll
+		// If step has poll, it overrides component's poll
+		if step.Poll != nil {
+			mergedStep.Poll = step.Poll
+			e.logger.Debug("[COMPONENT] Overriding poll configuration from step", "step", step.Name, "max_attempts", step.Poll.MaxAttempts, "interval", step.Poll.Interval)
+		} else if mergedStep.Poll != nil {
+			e.logger.Debug("[COMPONENT] Using poll configuration from component", "step", step.Name, "max_attempts", mergedStep.Poll.MaxAttempts, "interval", mergedStep.Poll.Interval)
+		}
+		// Note: If step.Poll is nil but component has poll, mergedStep already has it from comp.Step
+		// Copy ShowResponse: if either component or step has it set to true, show response
+		if step.ShowResponse {
+			mergedStep.ShowResponse = true
+		}
+		// Copy timeout: step-level timeout overrides component timeout
+		// If step has timeout in request, it overrides component's request timeout
+		if step.Request.Timeout != "" {
+			mergedStep.Request.Timeout = step.Request.Timeout
+			e.logger.Debug("[COMPONENT] Overriding timeout from step", "step", step.Name, "timeout", step.Request.Timeout)
+		} else if step.Timeout != "" {
+			// Also check step-level timeout field (for backward compatibility)
+			mergedStep.Request.Timeout = step.Timeout
+			e.logger.Debug("[COMPONENT] Overriding timeout from step-level field", "step", step.Name, "timeout", step.Timeout)
+		} else if mergedStep.Request.Timeout != "" {
+			e.logger.Debug("[COMPONENT] Using timeout from component", "step", step.Name, "timeout", mergedStep.Request.Timeout)
+		}
+		e.logger.Info("[COMPONENT] Executing use step", "use", step.Use, "step", mergedStep.Name)
+		// Initialize component variables first
+		e.initializeVariables(comp.Variables)
+		// Then apply step-level variable overrides
+		if len(step.Variables) > 0 {
+			e.logger.Debug("[COMPONENT] Applying step-level variable overrides", "variables", step.Variables)
+			e.initializeVariables(step.Variables)
+		}
+		result := &TestResult{
+			Name:         mergedStep.Name,
+			Status:       "pending",
+			CapturedData: make(map[string]interface{}),
+		}
+
+		// Notify progress callback that step is starting
+		if e.progressCallback != nil {
+			e.progressCallback(mergedStep.Name, stepIndex, totalSteps, "running", 0, 0, 0, nil)
+		}
+
+		if err := e.executeStepWithRepeat(&mergedStep, result); err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+			e.logger.Error("Component step execution failed", "component", mergedStep.Name, "error", err)
+		} else {
+			result.Status = "passed"
+		}
+
+		// Notify progress callback of completion
+		if e.progressCallback != nil {
+			validationsPassed := 0
+			validationsTotal := len(result.Validations)
+			for _, v := range result.Validations {
+				if v.Passed {
+					validationsPassed++
+				}
+			}
+			e.progressCallback(mergedStep.Name, stepIndex, totalSteps, result.Status, result.Duration, validationsPassed, validationsTotal, nil)
+		}
+
+		vars := e.varManager.GetAll()
+		e.logger.Info("[DEBUG] Variables after component step", "step", mergedStep.Name, "vars", vars)
+
+		// Check fail-fast mode for component steps
+		if e.failFast && result.Status == "failed" {
+			e.logger.Error("Fail-fast mode enabled: stopping execution due to component test failure", "step", mergedStep.Name)
+			return result, fmt.Errorf("execution stopped at first failure (component step: %s)", mergedStep.Name)
+		}
+		return result, nil
+	}
+
+	result := &TestResult{
+		Name:         step.Name,
+		Status:       "pending",
+		CapturedData: make(map[string]interface{}),
+	}
+	if step.Condition != "" {
+		if !e.evaluateCondition(step.Condition) {
+			e.logger.Info("Skipping step due to condition", "step", step.Name, "condition", step.Condition)
+			result.Status = "skipped"
+			return result, nil
+		}
+	}
+
+	// Notify progress callback that step is starting
+	if e.progressCallback != nil {
+		e.progressCallback(step.Name, stepIndex, totalSteps, "running", 0, 0, 0, nil)
+	}
+
+	if err := e.executeStepWithRepeat(step, result); err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+		e.logger.Error("Step execution failed", "step", step.Name, "error", err)
+	} else {
+		result.Status = "passed"
+	}
+
+	// Notify progress callback of completion
+	if e.progressCallback != nil {
+		validationsPassed := 0
+		validationsTotal := len(result.Validations)
+		for _, v := range result.Validations {
+			if v.Passed {
+				validationsPassed++
+			}
+		}
+		var resultErr error
+		if result.Status == "failed" && result.Error != "" {
+			resultErr = fmt.Errorf("%s", e.redactor.String(result.Error))
+		}
+		e.progressCallback(step.Name, stepIndex, totalSteps, result.Status, result.Duration, validationsPassed, validationsTotal, resultErr)
+	}
+
+	vars := e.varManager.GetAll()
+	e.logger.Info("[DEBUG] Variables after step", "step", step.Name, "vars", vars)
+
+	// Check fail-fast mode
+	if e.failFast && result.Status == "failed" {
+		e.logger.Error("Fail-fast mode enabled: stopping execution due to test failure", "step", step.Name)
+		return result, fmt.Errorf("execution stopped at first failure (step: %s)", step.Name)
+	}
+	return result, nil
+}
+
+// executeGroup executes a group of steps, either sequentially or in parallel.
+// The group runs in its own scope; its variables are promoted to the workflow when it ends.
 func (e *Executor) executeGroup(group *StepGroup, groupResult *GroupResult) error {
 	e.logger.Info("Executing step group", "group", group.Name, "parallel", group.Parallel, "steps", len(group.Steps))
 
+	scoped := e.fork("group", nil)
+	defer scoped.varManager.Promote()
+	return scoped.executeGroupPhases(group, groupResult)
+}
+
+// executeGroupPhases executes setup, the main steps and teardown of a group
+func (e *Executor) executeGroupPhases(group *StepGroup, groupResult *GroupResult) error {
+
+	setupResults, err := e.executeSetupSteps(group.Setup)
+	groupResult.Results = append(groupResult.Results, setupResults...)
+	if err == nil {
+		err = e.executeGroupSteps(group, groupResult)
+	}
+	groupResult.Results = append(groupResult.Results, e.executeTeardownSteps(group.Teardown)...)
+	return err
+}
+
+// executeGroupSteps executes the main steps of a group
+func (e *Executor) executeGroupSteps(group *StepGroup, groupResult *GroupResult) error {
+	if group.Data != nil {
+		return e.executeGroupData(group, groupResult)
+	}
+	if group.Foreach != nil {
+		return e.executeGroupForeach(group, groupResult)
+	}
+	if hasStepDependencies(group.Steps) {
+		return e.executeGroupDAG(group, groupResult)
+	}
 	if group.Parallel {
 		return e.executeGroupParallel(group, groupResult)
 	}
 	return e.executeGroupSequential(group, groupResult)
 }
 
+// executeGroupDAG executes steps in a group according to their needs dependencies
+func (e *Executor) executeGroupDAG(group *StepGroup, groupResult *GroupResult) error {
+	results, err := e.executeStepsDAG(group.Steps, e.maxParallel, func(stepIndex int, step *Step) (*TestResult, error) {
+		result := &TestResult{
+			Name:         step.Name,
+			Status:       "pending",
+			CapturedData: make(map[string]interface{}),
+		}
+
+		// Check condition if specified
+		if step.Condition != "" {
+			if !e.evaluateCondition(step.Condition) {
+				e.logger.Info("Skipping step due to condition", "step", step.Name, "condition", step.Condition)
+				result.Status = "skipped"
+				return result, nil
+			}
+		}
+
+		if err := e.executeStepWithRepeat(step, result); err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+			e.logger.Error("Step execution failed", "step", step.Name, "error", err)
+		} else {
+			result.Status = "passed"
+		}
+		return result, nil
+	})
+	groupResult.Results = append(groupResult.Results, results...)
+	return err
+}
+
 // executeGroupSequential executes steps in a group sequentially
 func (e *Executor) executeGroupSequential(group *StepGroup, groupResult *GroupResult) error {
+	var stopErr error
 	for _, step := range group.Steps {
+		if stopErr == nil {
+			if err := e.context().Err(); err != nil {
+				stopErr = interruptError(err)
+			}
+		}
+		if stopErr != nil {
+			// Execution was stopped: only always steps still run
+			if step.Always {
+				e.withCleanupContext(func() {
+					groupResult.Results = append(groupResult.Results, *e.executePhaseStep("", &step))
+				})
+			}
+			continue
+		}
+
 		result := &TestResult{
 			Name:         step.Name,
 			Status:       "pending",
@@ -579,18 +912,21 @@ func (e *Executor) executeGroupSequential(group *StepGroup, groupResult *GroupRe
 		groupResult.Results = append(groupResult.Results, *result)
 	}
 
-	return nil
+	return stopErr
 }
 
-// executeGroupParallel executes steps in a group in parallel
+// executeGroupParallel executes steps in a group in parallel. Every step runs in its own
+// scope; once all steps are done their variables are promoted to the group in step order.
 func (e *Executor) executeGroupParallel(group *StepGroup, groupResult *GroupResult) error {
 	var wg sync.WaitGroup
 	results := make([]*TestResult, len(group.Steps))
+	scopes := make([]*Executor, len(group.Steps))
 	errors := make(chan error, len(group.Steps))
 
 	for i, step := range group.Steps {
+		scopes[i] = e.fork("step", nil)
 		wg.Add(1)
-		go func(stepIndex int, step Step) {
+		go func(stepIndex int, step Step, child *Executor) {
 			defer wg.Done()
 
 			result := &TestResult{
@@ -601,36 +937,32 @@ func (e *Executor) executeGroupParallel(group *StepGroup, groupResult *GroupResu
 
 			// Check condition if specified
 			if step.Condition != "" {
-				if !e.evaluateCondition(step.Condition) {
-					e.logger.Info("Skipping step due to condition", "step", step.Name, "condition", step.Condition)
+				if !child.evaluateCondition(step.Condition) {
+					child.logger.Info("Skipping step due to condition", "step", step.Name, "condition", step.Condition)
 					result.Status = "skipped"
 					results[stepIndex] = result
 					return
 				}
 			}
 
-			if err := e.executeStepWithRepeat(&step, result); err != nil {
+			if err := child.executeStepWithRepeat(&step, result); err != nil {
 				result.Status = "failed"
 				result.Error = err.Error()
-				e.logger.Error("Step execution failed", "step", step.Name, "error", err)
+				child.logger.Error("Step execution failed", "step", step.Name, "error", err)
 				errors <- err
 			} else {
 				result.Status = "passed"
 			}
 
 			results[stepIndex] = result
-		}(i, step)
+		}(i, step, scopes[i])
 	}
 
 	wg.Wait()
 	close(errors)
 
-	// Check for any errors
-	select {
-	case err := <-errors:
-		return err
-	default:
-		// No errors
+	for _, child := range scopes {
+		child.varManager.Promote()
 	}
 
 	// Add results to group result
@@ -640,228 +972,15 @@ func (e *Executor) executeGroupParallel(group *StepGroup, groupResult *GroupResu
 		}
 	}
 
-	return nil
-}
-
-// evaluateCondition evaluates a condition expression with support for operators
-func (e *Executor) evaluateCondition(condition string) bool {
-	if condition == "" {
-		return true
-	}
-
-	// Substitute variables first
-	substituted, err := e.varManager.Substitute(condition)
-	if err != nil {
-		e.logger.Warn("Failed to substitute variables in condition", "condition", condition, "error", err)
-		return false
-	}
-
-	// Trim whitespace
-	substituted = strings.TrimSpace(substituted)
-
-	// Handle simple variable check (backward compatibility)
-	if strings.HasPrefix(substituted, "{{") && strings.HasSuffix(substituted, "}}") {
-		varName := strings.TrimSpace(substituted[2 : len(substituted)-2])
-		value, exists := e.varManager.Get(varName)
-		if exists && value != nil {
-			switch v := value.(type) {
-			case bool:
-				return v
-			case string:
-				return v != "" && v != "false" && v != "0"
-			case int, int32, int64, float32, float64:
-				return v != 0
-			default:
-				return value != nil
-			}
-		}
-		return false
-	}
-
-	// Parse and evaluate expression with operators
-	return e.evaluateExpression(substituted)
-}
-
-// evaluateExpression evaluates a boolean expression with operators
-func (e *Executor) evaluateExpression(expr string) bool {
-	expr = strings.TrimSpace(expr)
-
-	// Handle logical operators (&&, ||)
-	if strings.Contains(expr, "&&") {
-		parts := strings.Split(expr, "&&")
-		for _, part := range parts {
-			if !e.evaluateExpression(strings.TrimSpace(part)) {
-				return false
-			}
-		}
-		return true
-	}
-
-	if strings.Contains(expr, "||") {
-		parts := strings.Split(expr, "||")
-		for _, part := range parts {
-			if e.evaluateExpression(strings.TrimSpace(part)) {
-				return true
-			}
-		}
-		return false
-	}
-
-	// Handle negation
-	if strings.HasPrefix(expr, "!") {
-		return !e.evaluateExpression(strings.TrimSpace(expr[1:]))
-	}
-
-	// Handle comparison operators
-	operators := []string{"==", "!=", ">=", "<=", ">", "<"}
-	for _, op := range operators {
-		if strings.Contains(expr, op) {
-			parts := strings.SplitN(expr, op, 2)
-			if len(parts) == 2 {
-				left := strings.TrimSpace(parts[0])
-				right := strings.TrimSpace(parts[1])
-				return e.compareValues(left, right, op)
-			}
-		}
-	}
-
-	// If no operator found, treat as boolean value
-	return e.isTruthy(expr)
-}
-
-// compareValues compares two values using the specified operator
-func (e *Executor) compareValues(left, right, operator string) bool {
-	// Get left value (could be variable or literal)
-	leftVal := e.getValue(left)
-	rightVal := e.getValue(right)
-
-	// Try numeric comparison first
-	leftNum, leftOk := e.toNumber(leftVal)
-	rightNum, rightOk := e.toNumber(rightVal)
-
-	if leftOk && rightOk {
-		switch operator {
-		case "==":
-			return leftNum == rightNum
-		case "!=":
-			return leftNum != rightNum
-		case ">":
-			return leftNum > rightNum
-		case "<":
-			return leftNum < rightNum
-		case ">=":
-			return leftNum >= rightNum
-		case "<=":
-			return leftNum <= rightNum
-		}
-	}
-
-	// String comparison
-	leftStr := fmt.Sprintf("%v", leftVal)
-	rightStr := fmt.Sprintf("%v", rightVal)
-
-	// Remove quotes if present
-	leftStr = strings.Trim(leftStr, "\"'")
-	rightStr = strings.Trim(rightStr, "\"'")
-
-	switch operator {
-	case "==":
-		return leftStr == rightStr
-	case "!=":
-		return leftStr != rightStr
-	case ">":
-		return leftStr > rightStr
-	case "<":
-		return leftStr < rightStr
-	case ">=":
-		return leftStr >= rightStr
-	case "<=":
-		return leftStr <= rightStr
-	}
-
-	return false
-}
-
-// getValue gets a value from variable or returns as literal
-func (e *Executor) getValue(expr string) interface{} {
-	expr = strings.TrimSpace(expr)
-
-	// Check if it's a variable reference
-	if strings.HasPrefix(expr, "{{") && strings.HasSuffix(expr, "}}") {
-		varName := strings.TrimSpace(expr[2 : len(expr)-2])
-		if value, exists := e.varManager.Get(varName); exists {
-			return value
-		}
-		return nil
-	}
-
-	// Try to parse as number
-	if num, err := strconv.ParseFloat(expr, 64); err == nil {
-		return num
-	}
-
-	// Return as string (remove quotes if present)
-	return strings.Trim(expr, "\"'")
-}
-
-// toNumber converts a value to float64
-func (e *Executor) toNumber(value interface{}) (float64, bool) {
-	switch v := value.(type) {
-	case int:
-		return float64(v), true
-	case int32:
-		return float64(v), true
-	case int64:
-		return float64(v), true
-	case float32:
-		return float64(v), true
-	case float64:
-		return v, true
-	case string:
-		if num, err := strconv.ParseFloat(v, 64); err == nil {
-			return num, true
-		}
-	}
-	return 0, false
-}
-
-// isTruthy checks if a value is truthy
-func (e *Executor) isTruthy(expr string) bool {
-	// Try to get as variable first
-	if strings.HasPrefix(expr, "{{") && strings.HasSuffix(expr, "}}") {
-		varName := strings.TrimSpace(expr[2 : len(expr)-2])
-		value, exists := e.varManager.Get(varName)
-		if exists {
-			switch v := value.(type) {
-			case bool:
-				return v
-			case string:
-				return v != "" && v != "false" && v != "0"
-			case int, int32, int64, float32, float64:
-				return v != 0
-			default:
-				return value != nil
-			}
-		}
-		return false
-	}
-
-	// Check string literals
-	expr = strings.Trim(expr, "\"'")
-	if expr == "true" || expr == "1" {
-		return true
-	}
-	if expr == "false" || expr == "0" || expr == "" {
-		return false
-	}
-
-	// Try as number
-	if num, err := strconv.ParseFloat(expr, 64); err == nil {
-		return num != 0
+	// Check for any errors
+	select {
+	case err := <-errors:
+		return err
+	default:
+		// No errors
 	}
 
-	// Non-empty string is truthy
-	return expr != ""
+	return nil
 }
 
 // executeStep executes a single step with retry logic
@@ -894,7 +1013,7 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 		duration := e.parseTimeout(step.Wait)
 		if duration > 0 {
 			e.logger.Info("Executing wait step", "step", step.Name, "wait", duration)
-			time.Sleep(duration)
+			e.wait(duration)
 		}
 		result.Duration = time.Since(startTime)
 		return nil
@@ -905,12 +1024,16 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 
 	var lastError error
 	for attempt := 0; attempt < maxRetries; attempt++ {
+		if err := e.context().Err(); err != nil {
+			result.Duration = time.Since(startTime)
+			return interruptError(err)
+		}
 		if attempt > 0 {
 			result.Retries = attempt
 			retryDelay := e.parseTimeout(step.RetryDelay)
 			if retryDelay > 0 {
 				e.logger.Info("Retrying step", "step", step.Name, "attempt", attempt+1, "delay", retryDelay)
-				time.Sleep(retryDelay)
+				e.wait(retryDelay)
 			}
 		}
 
@@ -943,22 +1066,11 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 		e.logger.Debug("Executing request", "protocol", substitutedReq.Protocol)
 
 		if substitutedReq.Protocol == "grpc" {
-			// Initialize gRPC client if not already done or if server address changed
-			// Need to create new client for each different server address
-			if e.grpcClient == nil || e.grpcServerAddr != substitutedReq.ServerAddr {
-				// Close old client if exists and server address changed
-				if e.grpcClient != nil && e.grpcServerAddr != substitutedReq.ServerAddr {
-					e.grpcClient.Close()
-					e.logger.Debug("Closing gRPC client for different server", "old_server", e.grpcServerAddr, "new_server", substitutedReq.ServerAddr)
-				}
-				grpcClient, err := grpcclient.NewClient(substitutedReq.ServerAddr, substitutedReq.Insecure, e.logger)
-				if err != nil {
-					lastError = fmt.Errorf("failed to create gRPC client: %w", err)
-					continue
-				}
-				e.grpcClient = grpcClient
-				e.grpcServerAddr = substitutedReq.ServerAddr
-				e.logger.Debug("Created new gRPC client", "server", substitutedReq.ServerAddr)
+			// gRPC clients are shared per server across steps, including parallel ones
+			grpcClient, err := e.clients.grpcClient(substitutedReq.ServerAddr, substitutedReq.Insecure, e.logger)
+			if err != nil {
+				lastError = fmt.Errorf("failed to create gRPC client: %w", err)
+				continue
 			}
 
 			// Execute gRPC request
@@ -971,7 +1083,7 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 				Insecure:   substitutedReq.Insecure,
 				Timeout:    e.parseTimeout(substitutedReq.Timeout),
 			}
-			grpcResponse, requestErr = e.grpcClient.Execute(grpcReq)
+			grpcResponse, requestErr = grpcClient.Execute(e.context(), grpcReq)
 		} else if substitutedReq.Protocol == "db" {
 			// Execute database request
 			if substitutedReq.DBConfig == nil {
@@ -1001,6 +1113,7 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 				if dbConfig.Password != "" {
 					if subPass, err := e.varManager.Substitute(dbConfig.Password); err == nil {
 						dbConfig.Password = subPass
+						e.redactor.AddValue(subPass)
 					}
 				}
 				if dbConfig.Database != "" {
@@ -1034,7 +1147,7 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 			}
 			defer dbClient.Close()
 
-			dbResponse, requestErr = dbClient.Execute(query)
+			dbResponse, requestErr = dbClient.Execute(e.context(), query)
 		} else if substitutedReq.Protocol == "mcp" {
 			// Execute MCP request
 			if substitutedReq.MCPMethod == "" {
@@ -1053,14 +1166,8 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 				continue
 			}
 
-			// Initialize MCP client if not already done or if client key changed
-			if e.mcpClient == nil || e.mcpClientKey != mcpClientKey {
-				// Close old client if exists and client key changed
-				if e.mcpClient != nil && e.mcpClientKey != mcpClientKey {
-					e.mcpClient.Close()
-					e.logger.Debug("Closing MCP client for different server", "old_key", e.mcpClientKey, "new_key", mcpClientKey)
-				}
-
+			// MCP clients are shared per server across steps, including parallel ones
+			mcpClient, err := e.clients.mcpClient(mcpClientKey, func() (*mcpclient.Client, error) {
 				// Build MCP request for client creation
 				mcpReq := &mcpclient.Request{
 					Transport:  substitutedReq.MCPTransport,
@@ -1083,14 +1190,11 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 					}
 				}
 
-				mcpClient, err := mcpclient.NewClient(mcpReq, e.logger)
-				if err != nil {
-					lastError = fmt.Errorf("failed to create MCP client: %w", err)
-					continue
-				}
-				e.mcpClient = mcpClient
-				e.mcpClientKey = mcpClientKey
-				e.logger.Debug("Created new MCP client", "key", mcpClientKey)
+				return mcpclient.NewClient(mcpReq, e.logger)
+			})
+			if err != nil {
+				lastError = fmt.Errorf("failed to create MCP client: %w", err)
+				continue
 			}
 
 			// Build MCP request for execution
@@ -1125,7 +1229,7 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 				mcpReq.Params = substitutedParams
 			}
 
-			mcpResponse, requestErr = e.mcpClient.Execute(mcpReq)
+			mcpResponse, requestErr = mcpClient.Execute(e.context(), mcpReq)
 		} else {
 			// Execute HTTP request (default)
 			queryMap := make(map[string]string)
@@ -1140,16 +1244,20 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 				Query:   queryMap,
 				Timeout: e.parseTimeout(substitutedReq.Timeout),
 				Auth:    substitutedReq.Auth,
+
+				NoRedirects: substitutedReq.FollowRedirects != nil && !*substitutedReq.FollowRedirects,
 			}
-			httpResponse, requestErr = e.httpClient.Execute(httpReq)
+			httpResponse, requestErr = e.httpClient.Execute(e.context(), httpReq)
 		}
 
+		e.registerResponseSecrets(substitutedReq.Protocol, httpResponse, grpcResponse, dbResponse, mcpResponse)
+
 		// Show response if requested (always show, even on errors)
 		// Skip in MCP mode - responses should be sent via MCP notifications
 		if step.ShowResponse && !e.mcpMode {
 			if substitutedReq.Protocol == "grpc" {
 				if grpcResponse != nil {
-					jsonData, err := json.MarshalIndent(grpcResponse.Data, "", "  ")
+					jsonData, err := json.MarshalIndent(e.redactor.Value(grpcResponse.Data), "", "  ")
 					if err == nil {
 						fmt.Println("================ RESPONSE (gRPC) ================")
 						fmt.Println(string(jsonData))
@@ -1158,12 +1266,12 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 				} else if requestErr != nil {
 					// Show error if response is nil but we have an error
 					fmt.Println("================ RESPONSE (gRPC) ================")
-					fmt.Printf("Error: %v\n", requestErr)
+					fmt.Printf("Error: %s\n", e.redactor.String(requestErr.Error()))
 					fmt.Println("================ END RESPONSE ================")
 				}
 			} else if substitutedReq.Protocol == "db" {
 				if dbResponse != nil {
-					jsonData, err := json.MarshalIndent(dbResponse.Data, "", "  ")
+					jsonData, err := json.MarshalIndent(e.redactor.Value(dbResponse.Data), "", "  ")
 					if err == nil {
 						fmt.Println("================ RESPONSE (DB) ================")
 						fmt.Println(string(jsonData))
@@ -1172,18 +1280,18 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 				} else if requestErr != nil {
 					// Show error if response is nil but we have an error
 					fmt.Println("================ RESPONSE (DB) ================")
-					fmt.Printf("Error: %v\n", requestErr)
+					fmt.Printf("Error: %s\n", e.redactor.String(requestErr.Error()))
 					fmt.Println("================ END RESPONSE ================")
 				}
 			} else if substitutedReq.Protocol == "mcp" {
 				if mcpResponse != nil {
-					jsonData, err := json.MarshalIndent(mcpResponse.Result, "", "  ")
+					jsonData, err := json.MarshalIndent(e.redactor.Value(mcpResponse.Result), "", "  ")
 					if err == nil {
 						fmt.Println("================ RESPONSE (MCP) ================")
 						fmt.Printf("Method: %s\n", mcpResponse.Method)
 						fmt.Printf("Duration: %v\n", mcpResponse.Duration)
 						if mcpResponse.Error != nil {
-							fmt.Printf("Error: Code %d - %s\n", mcpResponse.Error.Code, mcpResponse.Error.Message)
+							fmt.Printf("Error: Code %d - %s\n", mcpResponse.Error.Code, e.redactor.String(mcpResponse.Error.Message))
 						}
 						fmt.Println("Result:")
 						fmt.Println(string(jsonData))
@@ -1192,7 +1300,7 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 				} else if requestErr != nil {
 					// Show error if response is nil but we have an error
 					fmt.Println("================ RESPONSE (MCP) ================")
-					fmt.Printf("Error: %v\n", requestErr)
+					fmt.Printf("Error: %s\n", e.redactor.String(requestErr.Error()))
 					fmt.Println("================ END RESPONSE ================")
 				}
 			} else {
@@ -1201,7 +1309,7 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 					fmt.Printf("Status Code: %d\n", httpResponse.StatusCode)
 					if len(httpResponse.Body) > 0 {
 						fmt.Println("Body:")
-						fmt.Println(string(httpResponse.Body))
+						fmt.Println(string(e.redactor.Body(httpResponse.Body)))
 					} else {
 						fmt.Println("Body: (empty)")
 					}
@@ -1209,7 +1317,7 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 				} else if requestErr != nil {
 					// Show error if response is nil but we have an error
 					fmt.Println("================ RESPONSE ================")
-					fmt.Printf("Error: %v\n", requestErr)
+					fmt.Printf("Error: %s\n", e.redactor.String(requestErr.Error()))
 					fmt.Println("================ END RESPONSE ================")
 				}
 			}
@@ -1227,71 +1335,44 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 
 		// Run validations
 		var validationErrors []string
+		result.Validations = nil
+		result.Warnings = nil
 		if len(step.Validate) > 0 {
 			var validationResults []validation.ValidationResult
 			var validationErr error
 
-			if substitutedReq.Protocol == "grpc" {
-				// For gRPC, we'll need to create a mock HTTP response for validation
-				// This is a simplified approach - in production you'd want proper gRPC validation
-				jsonData, err := json.Marshal(grpcResponse.Data)
-				if err != nil {
-					validationErr = fmt.Errorf("failed to marshal gRPC response for validation: %w", err)
-				} else {
-					mockResponse := &httpclient.Response{
-						StatusCode: 200, // gRPC OK status
-						Body:       jsonData,
-						Duration:   grpcResponse.Duration,
-					}
-					validationResults, validationErr = e.validator.Validate(mockResponse, step.Validate)
-				}
-			} else if substitutedReq.Protocol == "db" {
-				// For database, create a mock HTTP response for validation
-				jsonData, err := json.Marshal(dbResponse.Data)
-				if err != nil {
-					validationErr = fmt.Errorf("failed to marshal database response for validation: %w", err)
-				} else {
-					mockResponse := &httpclient.Response{
-						StatusCode: 200, // Database OK status
-						Body:       jsonData,
-						Duration:   dbResponse.Duration,
-					}
-					validationResults, validationErr = e.validator.Validate(mockResponse, step.Validate)
-				}
-			} else if substitutedReq.Protocol == "mcp" {
-				// For MCP, create a mock HTTP response for validation
-				jsonData, err := json.Marshal(mcpResponse.Result)
-				if err != nil {
-					validationErr = fmt.Errorf("failed to marshal MCP response for validation: %w", err)
-				} else {
-					// Check for MCP error
-					statusCode := 200
-					if mcpResponse.Error != nil {
-						statusCode = mcpResponse.Error.Code
-					}
-					mockResponse := &httpclient.Response{
-						StatusCode: statusCode,
-						Body:       jsonData,
-						Duration:   mcpResponse.Duration,
-					}
-					validationResults, validationErr = e.validator.Validate(mockResponse, step.Validate)
-				}
+			// gRPC, database and MCP responses are validated in the JSON form they are captured from
+			validationResponse, err := protocolResponse(substitutedReq.Protocol, httpResponse, grpcResponse, dbResponse, mcpResponse)
+			if err != nil {
+				validationErr = fmt.Errorf("failed to prepare response for validation: %w", err)
 			} else {
-				validationResults, validationErr = e.validator.Validate(httpResponse, step.Validate)
+				validationResults, validationErr = e.validator.Validate(validationResponse, e.prepareRules(step.Validate, step.Namespaces))
 			}
 
 			// Always save validation results for CLI output
 			result.Validations = validationResults
+			result.Warnings = validationWarnings(validationResults)
 
 			if validationErr != nil {
 				validationErrors = append(validationErrors, validationErr.Error())
 			} else {
 				for _, validationResult := range validationResults {
-					if !validationResult.Passed {
+					if validationResult.Failed() {
 						validationErrors = append(validationErrors, validationResult.Error)
 					}
 				}
 			}
+			for _, warning := range result.Warnings {
+				e.logger.Warn("Validation warning", "step", step.Name, "warning", warning)
+			}
+		}
+
+		// HTTP exchanges are checked against the OpenAPI description of the workflow
+		for _, contractResult := range e.checkContract(substitutedReq.Protocol, httpResponse) {
+			result.Validations = append(result.Validations, contractResult)
+			if !contractResult.Passed {
+				validationErrors = append(validationErrors, contractResult.Error)
+			}
 		}
 
 		if len(validationErrors) > 0 {
@@ -1304,53 +1385,18 @@ func (e *Executor) executeStep(step *Step, result *TestResult) error {
 			continue
 		}
 
-		// Capture values if specified
-		if step.Capture != nil {
-			var captureErr error
-			if substitutedReq.Protocol == "grpc" {
-				// For gRPC, create a mock response for capture
-				jsonData, err := json.Marshal(grpcResponse.Data)
-				if err != nil {
-					captureErr = fmt.Errorf("failed to marshal gRPC response: %w", err)
-				} else {
-					mockResponse := &httpclient.Response{
-						StatusCode: 200,
-						Body:       jsonData,
-						Duration:   grpcResponse.Duration,
-					}
-					captureErr = e.captureValues(mockResponse, step.Capture, result)
-				}
-			} else if substitutedReq.Protocol == "db" {
-				// For database, create a mock response for capture
-				jsonData, err := json.Marshal(dbResponse.Data)
-				if err != nil {
-					captureErr = fmt.Errorf("failed to marshal database response: %w", err)
-				} else {
-					mockResponse := &httpclient.Response{
-						StatusCode: 200,
-						Body:       jsonData,
-						Duration:   dbResponse.Duration,
-					}
-					captureErr = e.captureValues(mockResponse, step.Capture, result)
-				}
-			} else if substitutedReq.Protocol == "mcp" {
-				// For MCP, create a mock response for capture
-				jsonData, err := json.Marshal(mcpResponse.Result)
-				if err != nil {
-					captureErr = fmt.Errorf("failed to marshal MCP response: %w", err)
-				} else {
-					mockResponse := &httpclient.Response{
-						StatusCode: 200,
-						Body:       jsonData,
-						Duration:   mcpResponse.Duration,
+		// Capture values if specified; workflow captures see the response of every step
+		if step.Capture != nil || len(e.captures) > 0 {
+			captureResponse, err := protocolResponse(substitutedReq.Protocol, httpResponse, grpcResponse, dbResponse, mcpResponse)
+			if err != nil {
+				e.logger.Warn("Failed to capture values", "step", step.Name, "error", err)
+			} else {
+				if step.Capture != nil {
+					if err := e.captureValues(captureResponse, step.Capture, step.Namespaces, result); err != nil {
+						e.logger.Warn("Failed to capture values", "step", step.Name, "error", err)
 					}
-					captureErr = e.captureValues(mockResponse, step.Capture, result)
 				}
-			} else {
-				captureErr = e.captureValues(httpResponse, step.Capture, result)
-			}
-			if captureErr != nil {
-				e.logger.Warn("Failed to capture values", "step", step.Name, "error", captureErr)
+				e.applyWorkflowCaptures(step.Name, captureResponse)
 			}
 		}
 
@@ -1389,6 +1435,11 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 	var lastSubstitutedReq *Request
 
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err := e.context().Err(); err != nil {
+			result.PollAttempts = attempt - 1
+			result.Duration = time.Since(startTime)
+			return fmt.Errorf("polling stopped after %d attempts: %w", attempt-1, interruptError(err))
+		}
 		e.logger.Debug("Polling attempt", "step", step.Name, "attempt", attempt, "max_attempts", maxAttempts)
 
 		// Substitute variables in request
@@ -1399,7 +1450,7 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 		if err != nil {
 			lastError = fmt.Errorf("variable substitution failed: %w", err)
 			if attempt < maxAttempts {
-				time.Sleep(interval)
+				e.wait(interval)
 			}
 			continue
 		}
@@ -1422,25 +1473,14 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 		var requestErr error
 
 		if substitutedReq.Protocol == "grpc" {
-			// Initialize gRPC client if not already done or if server address changed
-			// Need to create new client for each different server address
-			if e.grpcClient == nil || e.grpcServerAddr != substitutedReq.ServerAddr {
-				// Close old client if exists and server address changed
-				if e.grpcClient != nil && e.grpcServerAddr != substitutedReq.ServerAddr {
-					e.grpcClient.Close()
-					e.logger.Debug("Closing gRPC client for different server (polling)", "old_server", e.grpcServerAddr, "new_server", substitutedReq.ServerAddr)
-				}
-				grpcClient, err := grpcclient.NewClient(substitutedReq.ServerAddr, substitutedReq.Insecure, e.logger)
-				if err != nil {
-					lastError = fmt.Errorf("failed to create gRPC client: %w", err)
-					if attempt < maxAttempts {
-						time.Sleep(interval)
-					}
-					continue
+			// gRPC clients are shared per server across steps, including parallel ones
+			grpcClient, err := e.clients.grpcClient(substitutedReq.ServerAddr, substitutedReq.Insecure, e.logger)
+			if err != nil {
+				lastError = fmt.Errorf("failed to create gRPC client: %w", err)
+				if attempt < maxAttempts {
+					e.wait(interval)
 				}
-				e.grpcClient = grpcClient
-				e.grpcServerAddr = substitutedReq.ServerAddr
-				e.logger.Debug("Created new gRPC client (polling)", "server", substitutedReq.ServerAddr)
+				continue
 			}
 
 			// Execute gRPC request
@@ -1453,7 +1493,7 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 				Insecure:   substitutedReq.Insecure,
 				Timeout:    e.parseTimeout(substitutedReq.Timeout),
 			}
-			grpcResponse, requestErr = e.grpcClient.Execute(grpcReq)
+			grpcResponse, requestErr = grpcClient.Execute(e.context(), grpcReq)
 			if grpcResponse != nil {
 				lastGRPCResponse = grpcResponse
 			}
@@ -1462,7 +1502,7 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 			if substitutedReq.DBConfig == nil {
 				lastError = fmt.Errorf("database configuration is required for db protocol")
 				if attempt < maxAttempts {
-					time.Sleep(interval)
+					e.wait(interval)
 				}
 				continue
 			}
@@ -1489,6 +1529,7 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 				if dbConfig.Password != "" {
 					if subPass, err := e.varManager.Substitute(dbConfig.Password); err == nil {
 						dbConfig.Password = subPass
+						e.redactor.AddValue(subPass)
 					}
 				}
 				if dbConfig.Database != "" {
@@ -1506,7 +1547,7 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 			if query == "" {
 				lastError = fmt.Errorf("query is required for db protocol")
 				if attempt < maxAttempts {
-					time.Sleep(interval)
+					e.wait(interval)
 				}
 				continue
 			}
@@ -1521,13 +1562,13 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 			if err != nil {
 				lastError = fmt.Errorf("failed to create database client: %w", err)
 				if attempt < maxAttempts {
-					time.Sleep(interval)
+					e.wait(interval)
 				}
 				continue
 			}
 			defer dbClient.Close()
 
-			dbResponse, requestErr = dbClient.Execute(query)
+			dbResponse, requestErr = dbClient.Execute(e.context(), query)
 			if dbResponse != nil {
 				lastDBResponse = dbResponse
 			}
@@ -1536,7 +1577,7 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 			if substitutedReq.MCPMethod == "" {
 				lastError = fmt.Errorf("mcp_method is required for mcp protocol")
 				if attempt < maxAttempts {
-					time.Sleep(interval)
+					e.wait(interval)
 				}
 				continue
 			}
@@ -1550,19 +1591,13 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 			} else {
 				lastError = fmt.Errorf("unsupported mcp_transport: %s (supported: stdio, http, https)", substitutedReq.MCPTransport)
 				if attempt < maxAttempts {
-					time.Sleep(interval)
+					e.wait(interval)
 				}
 				continue
 			}
 
-			// Initialize MCP client if not already done or if client key changed
-			if e.mcpClient == nil || e.mcpClientKey != mcpClientKey {
-				// Close old client if exists and client key changed
-				if e.mcpClient != nil && e.mcpClientKey != mcpClientKey {
-					e.mcpClient.Close()
-					e.logger.Debug("Closing MCP client for different server (polling)", "old_key", e.mcpClientKey, "new_key", mcpClientKey)
-				}
-
+			// MCP clients are shared per server across steps, including parallel ones
+			mcpClient, err := e.clients.mcpClient(mcpClientKey, func() (*mcpclient.Client, error) {
 				// Build MCP request for client creation
 				mcpReq := &mcpclient.Request{
 					Transport:  substitutedReq.MCPTransport,
@@ -1585,17 +1620,14 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 					}
 				}
 
-				mcpClient, err := mcpclient.NewClient(mcpReq, e.logger)
-				if err != nil {
-					lastError = fmt.Errorf("failed to create MCP client: %w", err)
-					if attempt < maxAttempts {
-						time.Sleep(interval)
-					}
-					continue
+				return mcpclient.NewClient(mcpReq, e.logger)
+			})
+			if err != nil {
+				lastError = fmt.Errorf("failed to create MCP client: %w", err)
+				if attempt < maxAttempts {
+					e.wait(interval)
 				}
-				e.mcpClient = mcpClient
-				e.mcpClientKey = mcpClientKey
-				e.logger.Debug("Created new MCP client (polling)", "key", mcpClientKey)
+				continue
 			}
 
 			// Build MCP request for execution
@@ -1630,7 +1662,7 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 				mcpReq.Params = substitutedParams
 			}
 
-			mcpResponse, requestErr = e.mcpClient.Execute(mcpReq)
+			mcpResponse, requestErr = mcpClient.Execute(e.context(), mcpReq)
 			if mcpResponse != nil {
 				lastMCPResponse = mcpResponse
 			}
@@ -1648,19 +1680,23 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 				Query:   queryMap,
 				Timeout: e.parseTimeout(substitutedReq.Timeout),
 				Auth:    substitutedReq.Auth,
+
+				NoRedirects: substitutedReq.FollowRedirects != nil && !*substitutedReq.FollowRedirects,
 			}
-			httpResponse, requestErr = e.httpClient.Execute(httpReq)
+			httpResponse, requestErr = e.httpClient.Execute(e.context(), httpReq)
 			if httpResponse != nil {
 				lastHTTPResponse = httpResponse
 			}
 		}
 
+		e.registerResponseSecrets(substitutedReq.Protocol, httpResponse, grpcResponse, dbResponse, mcpResponse)
+
 		// Show response if requested (always show, even on errors)
 		// Skip in MCP mode - responses should be sent via MCP notifications
 		if step.ShowResponse && !e.mcpMode {
 			if substitutedReq.Protocol == "grpc" {
 				if grpcResponse != nil {
-					jsonData, err := json.MarshalIndent(grpcResponse.Data, "", "  ")
+					jsonData, err := json.MarshalIndent(e.redactor.Value(grpcResponse.Data), "", "  ")
 					if err == nil {
 						fmt.Println("================ RESPONSE (gRPC) ================")
 						fmt.Println(string(jsonData))
@@ -1669,12 +1705,12 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 				} else if requestErr != nil {
 					// Show error if response is nil but we have an error
 					fmt.Println("================ RESPONSE (gRPC) ================")
-					fmt.Printf("Error: %v\n", requestErr)
+					fmt.Printf("Error: %s\n", e.redactor.String(requestErr.Error()))
 					fmt.Println("================ END RESPONSE ================")
 				}
 			} else if substitutedReq.Protocol == "db" {
 				if dbResponse != nil {
-					jsonData, err := json.MarshalIndent(dbResponse.Data, "", "  ")
+					jsonData, err := json.MarshalIndent(e.redactor.Value(dbResponse.Data), "", "  ")
 					if err == nil {
 						fmt.Println("================ RESPONSE (DB) ================")
 						fmt.Println(string(jsonData))
@@ -1683,18 +1719,18 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 				} else if requestErr != nil {
 					// Show error if response is nil but we have an error
 					fmt.Println("================ RESPONSE (DB) ================")
-					fmt.Printf("Error: %v\n", requestErr)
+					fmt.Printf("Error: %s\n", e.redactor.String(requestErr.Error()))
 					fmt.Println("================ END RESPONSE ================")
 				}
 			} else if substitutedReq.Protocol == "mcp" {
 				if mcpResponse != nil {
-					jsonData, err := json.MarshalIndent(mcpResponse.Result, "", "  ")
+					jsonData, err := json.MarshalIndent(e.redactor.Value(mcpResponse.Result), "", "  ")
 					if err == nil {
 						fmt.Println("================ RESPONSE (MCP) ================")
 						fmt.Printf("Method: %s\n", mcpResponse.Method)
 						fmt.Printf("Duration: %v\n", mcpResponse.Duration)
 						if mcpResponse.Error != nil {
-							fmt.Printf("Error: Code %d - %s\n", mcpResponse.Error.Code, mcpResponse.Error.Message)
+							fmt.Printf("Error: Code %d - %s\n", mcpResponse.Error.Code, e.redactor.String(mcpResponse.Error.Message))
 						}
 						fmt.Println("Result:")
 						fmt.Println(string(jsonData))
@@ -1703,7 +1739,7 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 				} else if requestErr != nil {
 					// Show error if response is nil but we have an error
 					fmt.Println("================ RESPONSE (MCP) ================")
-					fmt.Printf("Error: %v\n", requestErr)
+					fmt.Printf("Error: %s\n", e.redactor.String(requestErr.Error()))
 					fmt.Println("================ END RESPONSE ================")
 				}
 			} else {
@@ -1712,7 +1748,7 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 					fmt.Printf("Status Code: %d\n", httpResponse.StatusCode)
 					if len(httpResponse.Body) > 0 {
 						fmt.Println("Body:")
-						fmt.Println(string(httpResponse.Body))
+						fmt.Println(string(e.redactor.Body(httpResponse.Body)))
 					} else {
 						fmt.Println("Body: (empty)")
 					}
@@ -1720,7 +1756,7 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 				} else if requestErr != nil {
 					// Show error if response is nil but we have an error
 					fmt.Println("================ RESPONSE ================")
-					fmt.Printf("Error: %v\n", requestErr)
+					fmt.Printf("Error: %s\n", e.redactor.String(requestErr.Error()))
 					fmt.Println("================ END RESPONSE ================")
 				}
 			}
@@ -1735,7 +1771,7 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 				e.logAPIResponseOnFailure(substitutedReq.Protocol, httpResponse, grpcResponse, mcpResponse, dbResponse, step.Name)
 			}
 			if attempt < maxAttempts {
-				time.Sleep(interval)
+				e.wait(interval)
 			}
 			continue
 		}
@@ -1743,73 +1779,29 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 		// Check polling conditions (poll.until)
 		var validationResults []validation.ValidationResult
 		var validationErr error
-		var responseForValidation *httpclient.Response
 
-		if substitutedReq.Protocol == "grpc" {
-			// For gRPC, create a mock HTTP response for validation
-			jsonData, err := json.Marshal(grpcResponse.Data)
-			if err != nil {
-				lastError = fmt.Errorf("failed to marshal gRPC response for validation: %w", err)
-				if attempt < maxAttempts {
-					time.Sleep(interval)
-				}
-				continue
-			}
-			responseForValidation = &httpclient.Response{
-				StatusCode: 200, // gRPC OK status
-				Body:       jsonData,
-				Duration:   grpcResponse.Duration,
-			}
-		} else if substitutedReq.Protocol == "db" {
-			// For database, create a mock HTTP response for validation
-			jsonData, err := json.Marshal(dbResponse.Data)
-			if err != nil {
-				lastError = fmt.Errorf("failed to marshal database response for validation: %w", err)
-				if attempt < maxAttempts {
-					time.Sleep(interval)
-				}
-				continue
-			}
-			responseForValidation = &httpclient.Response{
-				StatusCode: 200, // Database OK status
-				Body:       jsonData,
-				Duration:   dbResponse.Duration,
-			}
-		} else if substitutedReq.Protocol == "mcp" {
-			// For MCP, create a mock HTTP response for validation
-			jsonData, err := json.Marshal(mcpResponse.Result)
-			if err != nil {
-				lastError = fmt.Errorf("failed to marshal MCP response for validation: %w", err)
-				if attempt < maxAttempts {
-					time.Sleep(interval)
-				}
-				continue
-			}
-			// Check for MCP error
-			statusCode := 200
-			if mcpResponse.Error != nil {
-				statusCode = mcpResponse.Error.Code
-			}
-			responseForValidation = &httpclient.Response{
-				StatusCode: statusCode,
-				Body:       jsonData,
-				Duration:   mcpResponse.Duration,
+		// gRPC, database and MCP responses are validated in the JSON form they are captured from
+		responseForValidation, err := protocolResponse(substitutedReq.Protocol, httpResponse, grpcResponse, dbResponse, mcpResponse)
+		if err != nil {
+			lastError = fmt.Errorf("failed to prepare response for validation: %w", err)
+			if attempt < maxAttempts {
+				e.wait(interval)
 			}
-		} else {
-			responseForValidation = httpResponse
+			continue
 		}
 
 		// Validate against poll.until conditions
-		validationResults, validationErr = e.validator.Validate(responseForValidation, pollConfig.Until)
+		validationResults, validationErr = e.validator.Validate(responseForValidation, e.prepareRules(pollConfig.Until, step.Namespaces))
 
 		// Also run regular validations if specified (for reporting)
 		if len(step.Validate) > 0 {
-			regularResults, _ := e.validator.Validate(responseForValidation, step.Validate)
+			regularResults, _ := e.validator.Validate(responseForValidation, e.prepareRules(step.Validate, step.Namespaces))
 			result.Validations = regularResults
 		} else {
 			// Use polling validation results for reporting
 			result.Validations = validationResults
 		}
+		result.Warnings = validationWarnings(result.Validations)
 
 		// Check if all polling conditions are met
 		allConditionsMet := true
@@ -1817,7 +1809,7 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 			allConditionsMet = false
 		} else {
 			for _, validationResult := range validationResults {
-				if !validationResult.Passed {
+				if validationResult.Failed() {
 					allConditionsMet = false
 					break
 				}
@@ -1829,18 +1821,26 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 			result.PollAttempts = attempt
 			e.logger.Info("Polling condition met", "step", step.Name, "attempt", attempt, "total_attempts", attempt)
 
-			// Capture values if specified
-			if step.Capture != nil {
-				var captureErr error
-				if substitutedReq.Protocol == "grpc" || substitutedReq.Protocol == "db" || substitutedReq.Protocol == "mcp" {
-					captureErr = e.captureValues(responseForValidation, step.Capture, result)
-				} else {
-					captureErr = e.captureValues(httpResponse, step.Capture, result)
+			// Only the response that ends polling is checked against the OpenAPI description
+			var contractErrors []string
+			for _, contractResult := range e.checkContract(substitutedReq.Protocol, httpResponse) {
+				result.Validations = append(result.Validations, contractResult)
+				if !contractResult.Passed {
+					contractErrors = append(contractErrors, contractResult.Error)
 				}
-				if captureErr != nil {
-					e.logger.Warn("Failed to capture values", "step", step.Name, "error", captureErr)
+			}
+			if len(contractErrors) > 0 {
+				result.Duration = time.Since(startTime)
+				return fmt.Errorf("validation failed: %s", strings.Join(contractErrors, "; "))
+			}
+
+			// Capture values if specified; workflow captures see the response of every step
+			if step.Capture != nil {
+				if err := e.captureValues(responseForValidation, step.Capture, step.Namespaces, result); err != nil {
+					e.logger.Warn("Failed to capture values", "step", step.Name, "error", err)
 				}
 			}
+			e.applyWorkflowCaptures(step.Name, responseForValidation)
 
 			result.Duration = time.Since(startTime)
 			return nil
@@ -1854,7 +1854,7 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 
 		// If this is not the last attempt, wait before next poll
 		if attempt < maxAttempts {
-			time.Sleep(interval)
+			e.wait(interval)
 		}
 	}
 
@@ -1875,7 +1875,7 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 	// Build error message from failed validations
 	var failedValidations []string
 	for _, validationResult := range result.Validations {
-		if !validationResult.Passed {
+		if validationResult.Failed() {
 			failedValidations = append(failedValidations, validationResult.Error)
 		}
 	}
@@ -1889,6 +1889,11 @@ func (e *Executor) executeStepWithPoll(step *Step, result *TestResult, startTime
 
 // executeStepWithRepeat executes a step with repeat configuration
 func (e *Executor) executeStepWithRepeat(step *Step, result *TestResult) error {
+	// Foreach wraps everything else: each iteration may repeat, poll or branch
+	if step.Foreach != nil {
+		return e.executeStepWithForeach(step, result)
+	}
+
 	// Check for repeat first (repeat can contain branching)
 	if step.Repeat != nil {
 		return e.executeStepWithRepeatConfig(step, result)
@@ -1953,6 +1958,10 @@ func (e *Executor) executeStepRepeatSequential(step *Step, result *TestResult, r
 	delay := e.parseTimeout(repeatConfig.Delay)
 
 	for i := 0; i < repeatConfig.Count; i++ {
+		if e.context().Err() != nil {
+			// Run cancelled: remaining iterations are counted as failed
+			break
+		}
 		e.logger.Debug("Executing repeat iteration",
 			"step", step.Name,
 			"iteration", i+1,
@@ -1963,38 +1972,8 @@ func (e *Executor) executeStepRepeatSequential(step *Step, result *TestResult, r
 		// Clear repeat to avoid infinite recursion (repeat is already being handled)
 		stepCopy.Repeat = nil
 
-		// Apply repeat variables if specified
-		if repeatConfig.Variables != nil || true {
-			// Create a temporary variable manager for this iteration
-			tempVarManager := variables.NewManager(e.logger)
-
-			// Copy current variables
-			for key, value := range e.varManager.GetAll() {
-				tempVarManager.Set(key, value)
-			}
-
-			// Автоматически добавляем iteration и index
-			tempVarManager.Set("iteration", i+1)
-			tempVarManager.Set("index", i)
-
-			// Apply repeat-specific variables
-			if repeatConfig.Variables != nil {
-				for key, value := range repeatConfig.Variables {
-					if strValue, ok := value.(string); ok {
-						strValue = strings.ReplaceAll(strValue, "{{index}}", strconv.Itoa(i))
-						strValue = strings.ReplaceAll(strValue, "{{iteration}}", strconv.Itoa(i+1))
-						tempVarManager.Set(key, strValue)
-					} else {
-						tempVarManager.Set(key, value)
-					}
-				}
-			}
-
-			// Temporarily replace the variable manager
-			originalVarManager := e.varManager
-			e.varManager = tempVarManager
-			defer func() { e.varManager = originalVarManager }()
-		}
+		// The iteration runs in its own scope with iteration, index and the repeat variables bound
+		child := e.fork("iteration", repeatBindings(repeatConfig, i))
 
 		// Execute the step
 		iterationResult := &TestResult{
@@ -2002,20 +1981,22 @@ func (e *Executor) executeStepRepeatSequential(step *Step, result *TestResult, r
 			CapturedData: make(map[string]interface{}),
 		}
 
-		err := e.executeStepNormal(&stepCopy, iterationResult)
+		err := child.executeStepNormal(&stepCopy, iterationResult)
 		if err != nil {
 			iterationResult.Status = "failed"
 			iterationResult.Error = err.Error()
 		} else {
 			iterationResult.Status = "passed"
 		}
+		// Captures are visible to the following iterations and steps
+		child.varManager.Promote()
 
 		result.RepeatResults = append(result.RepeatResults, *iterationResult)
 
 		// Add delay between iterations (except for the last one)
 		if i < repeatConfig.Count-1 && delay > 0 {
 			e.logger.Debug("Waiting between iterations", "delay", delay)
-			time.Sleep(delay)
+			e.wait(delay)
 		}
 	}
 
@@ -2040,12 +2021,14 @@ func (e *Executor) executeStepRepeatSequential(step *Step, result *TestResult, r
 // executeStepRepeatParallel executes a step multiple times in parallel
 func (e *Executor) executeStepRepeatParallel(step *Step, result *TestResult, repeatConfig *RepeatConfig) error {
 	results := make([]TestResult, repeatConfig.Count)
+	scopes := make([]*Executor, repeatConfig.Count)
 	var wg sync.WaitGroup
-	var mu sync.Mutex
 
 	for i := 0; i < repeatConfig.Count; i++ {
+		// Each iteration runs in its own scope with iteration, index and the repeat variables bound
+		scopes[i] = e.fork("iteration", repeatBindings(repeatConfig, i))
 		wg.Add(1)
-		go func(iteration int) {
+		go func(iteration int, child *Executor) {
 			defer wg.Done()
 
 			e.logger.Debug("Executing parallel repeat iteration",
@@ -2058,49 +2041,13 @@ func (e *Executor) executeStepRepeatParallel(step *Step, result *TestResult, rep
 			// Clear repeat to avoid infinite recursion (repeat is already being handled)
 			stepCopy.Repeat = nil
 
-			// Apply repeat variables if specified
-			if repeatConfig.Variables != nil {
-				// Create a temporary variable manager for this iteration
-				tempVarManager := variables.NewManager(e.logger)
-
-				// Copy current variables
-				mu.Lock()
-				for key, value := range e.varManager.GetAll() {
-					tempVarManager.Set(key, value)
-				}
-				mu.Unlock()
-
-				// Apply repeat-specific variables
-				for key, value := range repeatConfig.Variables {
-					// Replace {{index}} with current iteration index
-					if strValue, ok := value.(string); ok {
-						strValue = strings.ReplaceAll(strValue, "{{index}}", strconv.Itoa(iteration))
-						strValue = strings.ReplaceAll(strValue, "{{iteration}}", strconv.Itoa(iteration+1))
-						tempVarManager.Set(key, strValue)
-					} else {
-						tempVarManager.Set(key, value)
-					}
-				}
-
-				// Temporarily replace the variable manager
-				mu.Lock()
-				originalVarManager := e.varManager
-				e.varManager = tempVarManager
-				mu.Unlock()
-				defer func() {
-					mu.Lock()
-					e.varManager = originalVarManager
-					mu.Unlock()
-				}()
-			}
-
 			// Execute the step (which may contain branching or poll)
 			iterationResult := &TestResult{
 				Name:         fmt.Sprintf("%s (iteration %d)", step.Name, iteration+1),
 				CapturedData: make(map[string]interface{}),
 			}
 
-			err := e.executeStepNormal(&stepCopy, iterationResult)
+			err := child.executeStepNormal(&stepCopy, iterationResult)
 			if err != nil {
 				iterationResult.Status = "failed"
 				iterationResult.Error = err.Error()
@@ -2108,14 +2055,17 @@ func (e *Executor) executeStepRepeatParallel(step *Step, result *TestResult, rep
 				iterationResult.Status = "passed"
 			}
 
-			mu.Lock()
 			results[iteration] = *iterationResult
-			mu.Unlock()
-		}(i)
+		}(i, scopes[i])
 	}
 
 	wg.Wait()
 
+	// Captures are promoted in iteration order, so the last iteration wins
+	for _, child := range scopes {
+		child.varManager.Promote()
+	}
+
 	// Collect results
 	result.RepeatResults = results
 
@@ -2137,6 +2087,25 @@ func (e *Executor) executeStepRepeatParallel(step *Step, result *TestResult, rep
 	return nil
 }
 
+// repeatBindings returns the variables bound in a repeat iteration: iteration, index and
+// the repeat variables with {{index}} and {{iteration}} replaced
+func repeatBindings(repeatConfig *RepeatConfig, i int) map[string]interface{} {
+	bindings := map[string]interface{}{
+		"iteration": i + 1,
+		"index":     i,
+	}
+	for key, value := range repeatConfig.Variables {
+		if strValue, ok := value.(string); ok {
+			strValue = strings.ReplaceAll(strValue, "{{index}}", strconv.Itoa(i))
+			strValue = strings.ReplaceAll(strValue, "{{iteration}}", strconv.Itoa(i+1))
+			bindings[key] = strValue
+		} else {
+			bindings[key] = value
+		}
+	}
+	return bindings
+}
+
 // executeBranchStepWithUse executes a step with use component support (for branches)
 func (e *Executor) executeBranchStepWithUse(step *Step, result *TestResult) error {
 	// Handle use component
@@ -2149,6 +2118,9 @@ func (e *Executor) executeBranchStepWithUse(step *Step, result *TestResult) erro
 			if len(step.Validate) > 0 {
 				mergedStep.Validate = step.Validate
 			}
+			if step.Namespaces != nil {
+				mergedStep.Namespaces = step.Namespaces
+			}
 			if step.Name != "" {
 				mergedStep.Name = step.Name
 			}
@@ -2191,7 +2163,7 @@ func (e *Executor) executeBranchStepWithUse(step *Step, result *TestResult) erro
 				substitutedVars := make(map[string]interface{})
 				for k, v := range step.Variables {
 					if strVal, ok := v.(string); ok {
-						substituted, err := e.varManager.Substitute(strVal)
+						substituted, err := e.varManager.SubstituteValue(strVal)
 						if err == nil {
 							substitutedVars[k] = substituted
 						} else {
@@ -2433,6 +2405,8 @@ func (e *Executor) substituteRequestVariables(req *Request) (*Request, error) {
 		MCPParams:     req.MCPParams,
 		MCPClientInfo: req.MCPClientInfo,
 		Timeout:       req.Timeout,
+
+		FollowRedirects: req.FollowRedirects,
 	}
 
 	// Substitute URL
@@ -2451,6 +2425,7 @@ func (e *Executor) substituteRequestVariables(req *Request) (*Request, error) {
 			return nil, fmt.Errorf("failed to substitute header %s: %w", key, err)
 		} else {
 			substituted.Headers[key] = substitutedValue
+			e.redactor.AddHeader(key, substitutedValue)
 			e.logger.Debug("Header substitution result", "key", key, "original", value, "substituted", substitutedValue)
 		}
 	}
@@ -2491,11 +2466,13 @@ func (e *Executor) substituteRequestVariables(req *Request) (*Request, error) {
 	if req.Body != nil {
 		switch body := req.Body.(type) {
 		case string:
-			if substitutedBody, err := e.varManager.Substitute(body); err != nil {
+			// A body that is exactly one {{variable}} reference keeps the type of the variable
+			if substitutedBody, err := e.varManager.SubstituteValue(body); err != nil {
 				e.logger.Error("Failed to substitute body", "body", body, "error", err)
 				return nil, fmt.Errorf("failed to substitute body: %w", err)
 			} else {
 				substituted.Body = substitutedBody
+				e.redactor.AddValuesAt(substitutedBody)
 				e.logger.Debug("Body substitution result", "original", body, "substituted", substitutedBody)
 			}
 		case map[string]interface{}:
@@ -2504,8 +2481,18 @@ func (e *Executor) substituteRequestVariables(req *Request) (*Request, error) {
 				return nil, fmt.Errorf("failed to substitute body: %w", err)
 			} else {
 				substituted.Body = substitutedBody
+				e.redactor.AddValuesAt(substitutedBody)
 				e.logger.Debug("Body map substitution result", "original", body, "substituted", substitutedBody)
 			}
+		case []interface{}:
+			if substitutedBody, err := e.varManager.SubstituteSlice(body); err != nil {
+				e.logger.Error("Failed to substitute body list", "body", body, "error", err)
+				return nil, fmt.Errorf("failed to substitute body: %w", err)
+			} else {
+				substituted.Body = substitutedBody
+				e.redactor.AddValuesAt(substitutedBody)
+				e.logger.Debug("Body list substitution result", "original", body, "substituted", substitutedBody)
+			}
 		default:
 			substituted.Body = req.Body
 		}
@@ -2527,6 +2514,7 @@ func (e *Executor) substituteRequestVariables(req *Request) (*Request, error) {
 			return nil, fmt.Errorf("failed to substitute metadata %s: %w", key, err)
 		} else {
 			substituted.Metadata[key] = substitutedValue
+			e.redactor.AddHeader(key, substitutedValue)
 			e.logger.Debug("Metadata substitution result", "key", key, "original", value, "substituted", substitutedValue)
 		}
 	}
@@ -2535,11 +2523,12 @@ func (e *Executor) substituteRequestVariables(req *Request) (*Request, error) {
 	if req.Data != nil {
 		switch data := req.Data.(type) {
 		case string:
-			if substitutedData, err := e.varManager.Substitute(data); err != nil {
+			if substitutedData, err := e.varManager.SubstituteValue(data); err != nil {
 				e.logger.Error("Failed to substitute data", "data", data, "error", err)
 				return nil, fmt.Errorf("failed to substitute data: %w", err)
 			} else {
 				substituted.Data = substitutedData
+				e.redactor.AddValuesAt(substitutedData)
 				e.logger.Debug("Data substitution result", "original", data, "substituted", substitutedData)
 			}
 		case map[string]interface{}:
@@ -2548,8 +2537,18 @@ func (e *Executor) substituteRequestVariables(req *Request) (*Request, error) {
 				return nil, fmt.Errorf("failed to substitute data: %w", err)
 			} else {
 				substituted.Data = substitutedData
+				e.redactor.AddValuesAt(substitutedData)
 				e.logger.Debug("Data map substitution result", "original", data, "substituted", substitutedData)
 			}
+		case []interface{}:
+			if substitutedData, err := e.varManager.SubstituteSlice(data); err != nil {
+				e.logger.Error("Failed to substitute data list", "data", data, "error", err)
+				return nil, fmt.Errorf("failed to substitute data: %w", err)
+			} else {
+				substituted.Data = substitutedData
+				e.redactor.AddValuesAt(substitutedData)
+				e.logger.Debug("Data list substitution result", "original", data, "substituted", substitutedData)
+			}
 		default:
 			substituted.Data = req.Data
 		}
@@ -2591,7 +2590,7 @@ func (e *Executor) substituteRequestVariables(req *Request) (*Request, error) {
 		substitutedParams := make(map[string]interface{})
 		for key, value := range req.MCPParams {
 			if strValue, ok := value.(string); ok {
-				if substitutedValue, err := e.varManager.Substitute(strValue); err != nil {
+				if substitutedValue, err := e.varManager.SubstituteValue(strValue); err != nil {
 					e.logger.Error("Failed to substitute mcp_param", "key", key, "value", strValue, "error", err)
 					return nil, fmt.Errorf("failed to substitute mcp_param %s: %w", key, err)
 				} else {
@@ -2606,6 +2605,14 @@ func (e *Executor) substituteRequestVariables(req *Request) (*Request, error) {
 					substitutedParams[key] = substitutedMap
 					e.logger.Debug("MCPParam map substitution result", "key", key, "original", mapValue, "substituted", substitutedMap)
 				}
+			} else if listValue, ok := value.([]interface{}); ok {
+				if substitutedList, err := e.varManager.SubstituteSlice(listValue); err != nil {
+					e.logger.Error("Failed to substitute mcp_param list", "key", key, "error", err)
+					return nil, fmt.Errorf("failed to substitute mcp_param %s: %w", key, err)
+				} else {
+					substitutedParams[key] = substitutedList
+					e.logger.Debug("MCPParam list substitution result", "key", key, "original", listValue, "substituted", substitutedList)
+				}
 			} else {
 				substitutedParams[key] = value
 			}
@@ -2624,25 +2631,114 @@ func (e *Executor) substituteRequestVariables(req *Request) (*Request, error) {
 		}
 	}
 
+	// Substitute authentication; values are not logged as they usually hold secrets
+	if req.Auth != nil {
+		auth, err := e.substituteAuth(req.Auth)
+		if err != nil {
+			return nil, err
+		}
+		substituted.Auth = auth
+	}
+
 	e.logger.Debug("Final substituted request", "url", substituted.URL, "method", substituted.Method, "timeout", substituted.Timeout, "mcp_method", substituted.MCPMethod)
 	return substituted, nil
 }
 
-// captureValues captures values from the response
-func (e *Executor) captureValues(response *httpclient.Response, captures map[string]string, result *TestResult) error {
-	jsonData, err := response.GetJSONBody()
+// substituteAuth returns a copy of auth with variables, environment variables and secrets substituted
+func (e *Executor) substituteAuth(auth *httpclient.Auth) (*httpclient.Auth, error) {
+	substituted := *auth
+	fields := []*string{&substituted.Username, &substituted.Password, &substituted.Token, &substituted.APIKey}
+	if auth.OAuth != nil {
+		oauth := *auth.OAuth
+		substituted.OAuth = &oauth
+		fields = append(fields, &oauth.ClientID, &oauth.ClientSecret, &oauth.TokenURL, &oauth.Scope, &oauth.Username, &oauth.Password)
+	}
+	for _, field := range fields {
+		value, err := e.varManager.Substitute(*field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to substitute auth: %w", err)
+		}
+		*field = value
+	}
+
+	if auth.Custom != nil {
+		substituted.Custom = make(map[string]string, len(auth.Custom))
+		for key, value := range auth.Custom {
+			substitutedValue, err := e.varManager.Substitute(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to substitute auth %s: %w", key, err)
+			}
+			substituted.Custom[key] = substitutedValue
+		}
+	}
+	e.registerAuth(&substituted)
+	return &substituted, nil
+}
+
+// protocolResponse converts the response of a gRPC, database or MCP step into an HTTP-like
+// JSON response, so captures work the same way for every protocol
+func protocolResponse(protocol string, httpResponse *httpclient.Response, grpcResponse *grpcclient.Response, dbResponse *dbclient.Response, mcpResponse *mcpclient.Response) (*httpclient.Response, error) {
+	var data interface{}
+	var duration time.Duration
+	statusCode := 200
+	switch protocol {
+	case "grpc":
+		data, duration = grpcResponse.Data, grpcResponse.Duration
+	case "db":
+		data, duration = dbResponse.Data, dbResponse.Duration
+	case "mcp":
+		data, duration = mcpResponse.Result, mcpResponse.Duration
+		// MCP errors are reported as the status code
+		if mcpResponse.Error != nil {
+			statusCode = mcpResponse.Error.Code
+		}
+	default:
+		return httpResponse, nil
+	}
+
+	jsonData, err := json.Marshal(data)
 	if err != nil {
-		return fmt.Errorf("failed to parse response as JSON: %w", err)
+		return nil, fmt.Errorf("failed to marshal %s response: %w", protocol, err)
 	}
+	return &httpclient.Response{
+		StatusCode: statusCode,
+		Body:       jsonData,
+		Duration:   duration,
+	}, nil
+}
 
-	for captureKey, jsonPath := range captures {
-		// Use validator's ExtractJSONValue which supports advanced JSONPath with filters
-		value, err := e.validator.ExtractJSONValue(jsonData, jsonPath)
+// captureValues captures values from the response. Paths starting with $ are JSONPath,
+// other paths are XPath over an XML response.
+func (e *Executor) captureValues(response *httpclient.Response, captures map[string]string, namespaces map[string]string, result *TestResult) error {
+	var jsonData interface{}
+	var jsonErr error
+	jsonParsed := false
+
+	for captureKey, path := range captures {
+		var value interface{}
+		var err error
+		if isXPath(path) {
+			value, err = e.validator.ExtractXMLValue(response.Body, path, namespaces)
+			if err == nil && value == nil {
+				err = fmt.Errorf("no node matched")
+			}
+		} else {
+			if !jsonParsed {
+				jsonData, jsonErr = response.GetJSONBody()
+				jsonParsed = true
+			}
+			if jsonErr != nil {
+				return fmt.Errorf("failed to parse response as JSON: %w", jsonErr)
+			}
+			// Use validator's ExtractJSONValue which supports advanced JSONPath with filters
+			value, err = e.validator.ExtractJSONValue(jsonData, path)
+		}
 		if err != nil {
-			e.logger.Warn("Failed to capture value", "key", captureKey, "path", jsonPath, "error", err)
+			e.logger.Warn("Failed to capture value", "key", captureKey, "path", path, "error", err)
 			continue
 		}
 
+		e.registerCapture(captureKey, value)
 		result.CapturedData[captureKey] = value
 		e.varManager.Set(captureKey, value)
 		e.logger.Debug("Captured value", "key", captureKey, "value", value)
@@ -2655,7 +2751,7 @@ func (e *Executor) captureValues(response *httpclient.Response, captures map[str
 func (e *Executor) logAPIResponseOnFailure(protocol string, httpResponse *httpclient.Response, grpcResponse *grpcclient.Response, mcpResponse *mcpclient.Response, dbResponse *dbclient.Response, stepName string) {
 	if protocol == "grpc" {
 		if grpcResponse != nil {
-			jsonData, err := json.MarshalIndent(grpcResponse.Data, "", "  ")
+			jsonData, err := json.MarshalIndent(e.redactor.Value(grpcResponse.Data), "", "  ")
 			if err == nil {
 				e.logger.Error("API Response (gRPC) on failure",
 					"step", stepName,
@@ -2668,7 +2764,7 @@ func (e *Executor) logAPIResponseOnFailure(protocol string, httpResponse *httpcl
 		}
 	} else if protocol == "db" {
 		if dbResponse != nil {
-			jsonData, err := json.MarshalIndent(dbResponse.Data, "", "  ")
+			jsonData, err := json.MarshalIndent(e.redactor.Value(dbResponse.Data), "", "  ")
 			if err == nil {
 				e.logger.Error("API Response (DB) on failure",
 					"step", stepName,
@@ -2682,7 +2778,7 @@ func (e *Executor) logAPIResponseOnFailure(protocol string, httpResponse *httpcl
 		}
 	} else if protocol == "mcp" {
 		if mcpResponse != nil {
-			jsonData, err := json.MarshalIndent(mcpResponse.Result, "", "  ")
+			jsonData, err := json.MarshalIndent(e.redactor.Value(mcpResponse.Result), "", "  ")
 			if err == nil {
 				e.logger.Error("API Response (MCP) on failure",
 					"step", stepName,
@@ -2703,7 +2799,7 @@ func (e *Executor) logAPIResponseOnFailure(protocol string, httpResponse *httpcl
 			var jsonData interface{}
 			if err := json.Unmarshal(httpResponse.Body, &jsonData); err == nil {
 				// Valid JSON, format it nicely
-				formatted, err := json.MarshalIndent(jsonData, "", "  ")
+				formatted, err := json.MarshalIndent(e.redactor.Value(jsonData), "", "  ")
 				if err == nil {
 					responseText = string(formatted)
 				} else {
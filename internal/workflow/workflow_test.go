@@ -1,7 +1,13 @@
 package workflow
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
@@ -120,7 +126,7 @@ func TestExecuteWorkflow(t *testing.T) {
 		},
 	}
 
-	results, err := executor.Execute(wf)
+	results, err := executor.Execute(context.Background(), wf)
 	if err != nil {
 		t.Fatalf("Failed to execute workflow: %v", err)
 	}
@@ -168,7 +174,7 @@ func TestStepWait(t *testing.T) {
 	}
 
 	start := time.Now()
-	results, err := executor.Execute(wf)
+	results, err := executor.Execute(context.Background(), wf)
 	elapsed := time.Since(start)
 	if err != nil {
 		t.Fatalf("Failed to execute workflow: %v", err)
@@ -220,3 +226,57 @@ steps:
 		t.Error("Expected error when loading invalid YAML")
 	}
 }
+
+func TestRequestBodyKeepsVariableTypes(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/orders/1" {
+			w.Write([]byte(`{"order": {"id": 1, "items": ["a", "b"]}, "paid": true}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	wf := &Workflow{
+		Name: "Typed body",
+		Steps: []Step{
+			{
+				Name:    "get order",
+				Request: Request{Method: "GET", URL: server.URL + "/orders/1"},
+				Capture: map[string]string{"order": "$.order", "order_id": "$.order.id", "paid": "$.paid"},
+			},
+			{
+				Name: "copy order",
+				Request: Request{
+					Method: "POST",
+					URL:    server.URL + "/orders",
+					Body: map[string]interface{}{
+						"source":    "{{order}}",
+						"source_id": "{{order_id}}",
+						"paid":      "{{paid}}",
+						"note":      "copy of {{order_id}}",
+					},
+				},
+			},
+		},
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	if _, err := executor.Execute(context.Background(), wf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"source":    map[string]interface{}{"id": float64(1), "items": []interface{}{"a", "b"}},
+		"source_id": float64(1),
+		"paid":      true,
+		"note":      "copy of 1",
+	}
+	if !reflect.DeepEqual(received, want) {
+		t.Errorf("Expected body %v, got %v", want, received)
+	}
+}
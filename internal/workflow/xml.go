@@ -0,0 +1,8 @@
+package workflow
+
+import "strings"
+
+// isXPath reports whether a capture path is XPath rather than JSONPath
+func isXPath(path string) bool {
+	return !strings.HasPrefix(strings.TrimSpace(path), "$")
+}
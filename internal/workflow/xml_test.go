@@ -0,0 +1,136 @@
+package workflow
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjp2600/stepwise/internal/config"
+	"github.com/cjp2600/stepwise/internal/logger"
+)
+
+func TestXMLValidationAndCapture(t *testing.T) {
+	var lastRequest string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lastRequest = string(body)
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		w.Write([]byte(`<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <ns1:CreateOrderResponse xmlns:ns1="urn:orders">
+      <ns1:OrderId>A-17</ns1:OrderId>
+      <ns1:Total currency="EUR">19.90</ns1:Total>
+      <ns1:Line>first</ns1:Line>
+      <ns1:Line>second</ns1:Line>
+    </ns1:CreateOrderResponse>
+  </soap:Body>
+</soap:Envelope>`))
+	}))
+	defer server.Close()
+
+	path := writeTempWorkflow(t, `name: soap
+variables:
+  base_url: "`+server.URL+`"
+captures:
+  total: "string(//Total)"
+steps:
+  - name: create order
+    request:
+      method: POST
+      url: "{{base_url}}/orders"
+      body: "<CreateOrder/>"
+    namespaces:
+      s: "http://schemas.xmlsoap.org/soap/envelope/"
+      o: "urn:orders"
+    validate:
+      - status: 200
+      - xml: "/s:Envelope/s:Body/o:CreateOrderResponse/o:OrderId"
+        equals: "A-17"
+      - xml: "//o:Total/@currency"
+        pattern: "^[A-Z]{3}$"
+      - xml: "//o:Total"
+        type: number
+      - xml: "//o:Line"
+        len: 2
+      - xml: "count(//o:Line) > 1"
+        equals: true
+      - xml: "//o:Missing"
+        empty: true
+    capture:
+      order_id: "//o:OrderId"
+  - name: get order
+    request:
+      method: POST
+      url: "{{base_url}}/orders"
+      body: "<GetOrder>{{order_id}}</GetOrder>"
+    validate:
+      - xml: "//*[local-name() = 'OrderId']"
+        contains: "A-"
+`)
+	wf, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, result := range results {
+		if result.Status != "passed" {
+			t.Errorf("Expected step %q to pass, got %s: %s", result.Name, result.Status, result.Error)
+		}
+	}
+	if !strings.Contains(lastRequest, "<GetOrder>A-17</GetOrder>") {
+		t.Errorf("Expected the captured order id in the next request, got %q", lastRequest)
+	}
+	if total := executor.Captured()["total"]; total != "19.90" {
+		t.Errorf("Expected the workflow capture to use XPath, got %v", total)
+	}
+}
+
+func TestXMLValidationFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<order><id>1</id></order>`))
+	}))
+	defer server.Close()
+
+	path := writeTempWorkflow(t, `name: soap
+steps:
+  - name: wrong value
+    request:
+      method: GET
+      url: "`+server.URL+`"
+    validate:
+      - xml: "/order/id"
+        equals: 2
+  - name: undeclared prefix
+    request:
+      method: GET
+      url: "`+server.URL+`"
+    validate:
+      - xml: "/x:order"
+`)
+	wf, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	executor := NewExecutor(&config.Config{Timeout: 5 * time.Second}, logger.New())
+	results, err := executor.Execute(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results[0].Status != "failed" || !strings.Contains(results[0].Error, "expected 2, got 1") {
+		t.Errorf("Expected a failed comparison, got %s: %s", results[0].Status, results[0].Error)
+	}
+	if results[1].Status != "failed" || !strings.Contains(results[1].Error, `undeclared namespace prefix "x"`) {
+		t.Errorf("Expected an undeclared prefix error, got %s: %s", results[1].Status, results[1].Error)
+	}
+}
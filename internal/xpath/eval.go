@@ -0,0 +1,447 @@
+package xpath
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// context is the evaluation context of an expression
+type context struct {
+	node       *Node
+	position   int
+	size       int
+	namespaces map[string]string
+}
+
+func (c *context) with(node *Node, position, size int) *context {
+	return &context{node: node, position: position, size: size, namespaces: c.namespaces}
+}
+
+// resolve returns the namespace URI of a prefix, declared in the step or else in the document
+func (c *context) resolve(prefix string) (string, error) {
+	if uri, ok := c.namespaces[prefix]; ok {
+		return uri, nil
+	}
+	if uri, ok := c.node.root().namespaces[prefix]; ok {
+		return uri, nil
+	}
+	return "", fmt.Errorf("undeclared namespace prefix %q", prefix)
+}
+
+func (e *literalExpr) eval(ctx *context) (interface{}, error) {
+	return e.value, nil
+}
+
+func (e *numberExpr) eval(ctx *context) (interface{}, error) {
+	return e.value, nil
+}
+
+func (e *negateExpr) eval(ctx *context) (interface{}, error) {
+	value, err := e.operand.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return -toNumber(value), nil
+}
+
+func (e *binaryExpr) eval(ctx *context) (interface{}, error) {
+	left, err := e.left.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// and/or stop at the left operand when it decides the result
+	switch e.op {
+	case "and":
+		if !toBool(left) {
+			return false, nil
+		}
+	case "or":
+		if toBool(left) {
+			return true, nil
+		}
+	}
+
+	right, err := e.right.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "and", "or":
+		return toBool(right), nil
+	case "|":
+		l, lok := left.([]*Node)
+		r, rok := right.([]*Node)
+		if !lok || !rok {
+			return nil, fmt.Errorf("the operands of | must be node-sets")
+		}
+		return documentOrder(append(append([]*Node{}, l...), r...)), nil
+	case "=", "!=", "<", "<=", ">", ">=":
+		return compare(e.op, left, right), nil
+	}
+
+	l, r := toNumber(left), toNumber(right)
+	switch e.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "div":
+		return l / r, nil
+	case "mod":
+		return math.Mod(l, r), nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", e.op)
+}
+
+func (e *filterExpr) eval(ctx *context) (interface{}, error) {
+	value, err := e.primary.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes, ok := value.([]*Node)
+	if !ok {
+		return nil, fmt.Errorf("predicates can only filter node-sets")
+	}
+	return filter(ctx, nodes, e.predicates)
+}
+
+func (e *pathExpr) eval(ctx *context) (interface{}, error) {
+	var nodes []*Node
+	switch {
+	case e.filter != nil:
+		value, err := e.filter.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if nodes, ok = value.([]*Node); !ok {
+			return nil, fmt.Errorf("a path can only continue from a node-set")
+		}
+	case e.absolute:
+		nodes = []*Node{ctx.node.root()}
+	default:
+		nodes = []*Node{ctx.node}
+	}
+
+	for _, s := range e.steps {
+		var selected []*Node
+		for _, node := range nodes {
+			var candidates []*Node
+			for _, candidate := range axis(node, s.axis) {
+				matched, err := s.test.matches(ctx, candidate, s.axis)
+				if err != nil {
+					return nil, err
+				}
+				if matched {
+					candidates = append(candidates, candidate)
+				}
+			}
+			// Positions in predicates count in the direction of the axis
+			candidates, err := filter(ctx, candidates, s.predicates)
+			if err != nil {
+				return nil, err
+			}
+			selected = append(selected, candidates...)
+		}
+		nodes = documentOrder(selected)
+	}
+	return nodes, nil
+}
+
+// filter keeps the nodes for which all predicates hold. A number as predicate
+// selects the node at that position.
+func filter(ctx *context, nodes []*Node, predicates []expr) ([]*Node, error) {
+	for _, predicate := range predicates {
+		var kept []*Node
+		for i, node := range nodes {
+			value, err := predicate.eval(ctx.with(node, i+1, len(nodes)))
+			if err != nil {
+				return nil, err
+			}
+			if n, ok := value.(float64); ok {
+				if n == float64(i+1) {
+					kept = append(kept, node)
+				}
+			} else if toBool(value) {
+				kept = append(kept, node)
+			}
+		}
+		nodes = kept
+	}
+	return nodes, nil
+}
+
+func (t nodeTest) matches(ctx *context, node *Node, axisName string) (bool, error) {
+	switch t.kind {
+	case testNode:
+		return true, nil
+	case testText:
+		return node.Type == TextNode, nil
+	case testComment:
+		return node.Type == CommentNode, nil
+	case testPI:
+		return node.Type == ProcessingInstructionNode && (t.local == "" || node.Local == t.local), nil
+	}
+
+	principal := ElementNode
+	if axisName == "attribute" {
+		principal = AttributeNode
+	}
+	if node.Type != principal {
+		return false, nil
+	}
+	if t.kind == testName && node.Local != t.local {
+		return false, nil
+	}
+	// Unprefixed names match in any namespace, so that elements in a default
+	// namespace can be selected without declaring a prefix
+	if t.prefix == "" {
+		return true, nil
+	}
+	uri, err := ctx.resolve(t.prefix)
+	if err != nil {
+		return false, err
+	}
+	return node.Space == uri, nil
+}
+
+// axis returns the nodes on an axis of a node, nearest first for reverse axes
+func axis(node *Node, name string) []*Node {
+	switch name {
+	case "self":
+		return []*Node{node}
+	case "child":
+		return node.Children
+	case "attribute":
+		return node.Attrs
+	case "descendant":
+		return descendants(node, nil)
+	case "descendant-or-self":
+		return descendants(node, []*Node{node})
+	case "parent":
+		if node.Parent == nil {
+			return nil
+		}
+		return []*Node{node.Parent}
+	case "ancestor", "ancestor-or-self":
+		var nodes []*Node
+		if name == "ancestor-or-self" {
+			nodes = append(nodes, node)
+		}
+		for n := node.Parent; n != nil; n = n.Parent {
+			nodes = append(nodes, n)
+		}
+		return nodes
+	case "following-sibling", "preceding-sibling":
+		if node.Parent == nil || node.Type == AttributeNode {
+			return nil
+		}
+		siblings := node.Parent.Children
+		index := indexOf(siblings, node)
+		if name == "following-sibling" {
+			return siblings[index+1:]
+		}
+		return reversed(siblings[:index])
+	case "following":
+		var nodes []*Node
+		if node.Type == AttributeNode {
+			node = node.Parent
+			nodes = descendants(node, nil)
+		}
+		for n := node; n.Parent != nil; n = n.Parent {
+			siblings := n.Parent.Children
+			for _, sibling := range siblings[indexOf(siblings, n)+1:] {
+				nodes = descendants(sibling, append(nodes, sibling))
+			}
+		}
+		return nodes
+	case "preceding":
+		var nodes []*Node
+		if node.Type == AttributeNode {
+			node = node.Parent
+		}
+		for n := node; n.Parent != nil; n = n.Parent {
+			siblings := n.Parent.Children
+			for i := indexOf(siblings, n) - 1; i >= 0; i-- {
+				nodes = append(nodes, reversed(descendants(siblings[i], []*Node{siblings[i]}))...)
+			}
+		}
+		return nodes
+	}
+	// The namespace axis is not modelled
+	return nil
+}
+
+// descendants appends the descendants of a node in document order
+func descendants(node *Node, nodes []*Node) []*Node {
+	for _, child := range node.Children {
+		nodes = append(nodes, child)
+		nodes = descendants(child, nodes)
+	}
+	return nodes
+}
+
+func indexOf(nodes []*Node, node *Node) int {
+	for i, n := range nodes {
+		if n == node {
+			return i
+		}
+	}
+	return -1
+}
+
+func reversed(nodes []*Node) []*Node {
+	result := make([]*Node, len(nodes))
+	for i, n := range nodes {
+		result[len(nodes)-1-i] = n
+	}
+	return result
+}
+
+// documentOrder sorts nodes in document order and removes duplicates
+func documentOrder(nodes []*Node) []*Node {
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].order < nodes[j].order })
+	result := nodes[:0]
+	for i, n := range nodes {
+		if i == 0 || n != nodes[i-1] {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// compare compares two values with the XPath 1.0 rules: a node-set matches when
+// any of its nodes does
+func compare(op string, left, right interface{}) bool {
+	if l, ok := left.([]*Node); ok {
+		if _, ok := right.(bool); ok {
+			return compareAtoms(op, toBool(l), right)
+		}
+		for _, n := range l {
+			if compare(op, n.StringValue(), right) {
+				return true
+			}
+		}
+		return false
+	}
+	if r, ok := right.([]*Node); ok {
+		if _, ok := left.(bool); ok {
+			return compareAtoms(op, left, toBool(r))
+		}
+		for _, n := range r {
+			if compareAtoms(op, left, n.StringValue()) {
+				return true
+			}
+		}
+		return false
+	}
+	return compareAtoms(op, left, right)
+}
+
+func compareAtoms(op string, left, right interface{}) bool {
+	if op == "=" || op == "!=" {
+		var equal bool
+		_, lbool := left.(bool)
+		_, rbool := right.(bool)
+		_, lnum := left.(float64)
+		_, rnum := right.(float64)
+		switch {
+		case lbool || rbool:
+			equal = toBool(left) == toBool(right)
+		case lnum || rnum:
+			equal = toNumber(left) == toNumber(right)
+		default:
+			equal = toString(left) == toString(right)
+		}
+		return equal == (op == "=")
+	}
+
+	l, r := toNumber(left), toNumber(right)
+	switch op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	default:
+		return l >= r
+	}
+}
+
+func toBool(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case string:
+		return v != ""
+	case []*Node:
+		return len(v) > 0
+	}
+	return false
+}
+
+var numberPattern = regexp.MustCompile(`^-?(\d+(\.\d*)?|\.\d+)$`)
+
+func toNumber(value interface{}) float64 {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case float64:
+		return v
+	case string:
+		s := strings.TrimSpace(v)
+		if !numberPattern.MatchString(s) {
+			return math.NaN()
+		}
+		n, _ := strconv.ParseFloat(s, 64)
+		return n
+	case []*Node:
+		return toNumber(toString(v))
+	}
+	return math.NaN()
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return formatNumber(v)
+	case string:
+		return v
+	case []*Node:
+		if len(v) == 0 {
+			return ""
+		}
+		return v[0].StringValue()
+	}
+	return ""
+}
+
+func formatNumber(n float64) string {
+	switch {
+	case math.IsNaN(n):
+		return "NaN"
+	case math.IsInf(n, 1):
+		return "Infinity"
+	case math.IsInf(n, -1):
+		return "-Infinity"
+	case n == math.Trunc(n) && math.Abs(n) < 1e15:
+		return strconv.FormatInt(int64(n), 10)
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
@@ -0,0 +1,197 @@
+package xpath
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+type arity struct {
+	min, max int // max is -1 for any number of arguments
+}
+
+// functions lists the XPath 1.0 core functions with their number of arguments
+var functions = map[string]arity{
+	"last":             {0, 0},
+	"position":         {0, 0},
+	"count":            {1, 1},
+	"local-name":       {0, 1},
+	"namespace-uri":    {0, 1},
+	"name":             {0, 1},
+	"string":           {0, 1},
+	"concat":           {2, -1},
+	"starts-with":      {2, 2},
+	"contains":         {2, 2},
+	"substring-before": {2, 2},
+	"substring-after":  {2, 2},
+	"substring":        {2, 3},
+	"string-length":    {0, 1},
+	"normalize-space":  {0, 1},
+	"translate":        {3, 3},
+	"boolean":          {1, 1},
+	"not":              {1, 1},
+	"true":             {0, 0},
+	"false":            {0, 0},
+	"number":           {0, 1},
+	"sum":              {1, 1},
+	"floor":            {1, 1},
+	"ceiling":          {1, 1},
+	"round":            {1, 1},
+}
+
+func (f *functionCall) eval(ctx *context) (interface{}, error) {
+	args := make([]interface{}, len(f.args))
+	for i, arg := range f.args {
+		value, err := arg.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	// Functions taking an optional argument default to the context node
+	if len(args) == 0 {
+		args = []interface{}{[]*Node{ctx.node}}
+	}
+
+	switch f.name {
+	case "last":
+		return float64(ctx.size), nil
+	case "position":
+		return float64(ctx.position), nil
+	case "count":
+		nodes, err := nodeSet(f.name, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return float64(len(nodes)), nil
+	case "local-name", "namespace-uri", "name":
+		nodes, err := nodeSet(f.name, args[0])
+		if err != nil {
+			return nil, err
+		}
+		if len(nodes) == 0 {
+			return "", nil
+		}
+		switch f.name {
+		case "local-name":
+			return nodes[0].Local, nil
+		case "namespace-uri":
+			return nodes[0].Space, nil
+		}
+		return nodes[0].Name(), nil
+	case "string":
+		return toString(args[0]), nil
+	case "concat":
+		var b strings.Builder
+		for _, arg := range args {
+			b.WriteString(toString(arg))
+		}
+		return b.String(), nil
+	case "starts-with":
+		return strings.HasPrefix(toString(args[0]), toString(args[1])), nil
+	case "contains":
+		return strings.Contains(toString(args[0]), toString(args[1])), nil
+	case "substring-before":
+		before, _, found := strings.Cut(toString(args[0]), toString(args[1]))
+		if !found {
+			return "", nil
+		}
+		return before, nil
+	case "substring-after":
+		_, after, _ := strings.Cut(toString(args[0]), toString(args[1]))
+		return after, nil
+	case "substring":
+		return substring(args), nil
+	case "string-length":
+		return float64(utf8.RuneCountInString(toString(args[0]))), nil
+	case "normalize-space":
+		return strings.Join(strings.Fields(toString(args[0])), " "), nil
+	case "translate":
+		return translate(toString(args[0]), toString(args[1]), toString(args[2])), nil
+	case "boolean":
+		return toBool(args[0]), nil
+	case "not":
+		return !toBool(args[0]), nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "number":
+		return toNumber(args[0]), nil
+	case "sum":
+		nodes, err := nodeSet(f.name, args[0])
+		if err != nil {
+			return nil, err
+		}
+		sum := 0.0
+		for _, n := range nodes {
+			sum += toNumber(n.StringValue())
+		}
+		return sum, nil
+	case "floor":
+		return math.Floor(toNumber(args[0])), nil
+	case "ceiling":
+		return math.Ceil(toNumber(args[0])), nil
+	case "round":
+		return round(toNumber(args[0])), nil
+	}
+	return nil, fmt.Errorf("unknown XPath function %q", f.name)
+}
+
+func nodeSet(function string, value interface{}) ([]*Node, error) {
+	nodes, ok := value.([]*Node)
+	if !ok {
+		return nil, fmt.Errorf("%s() expects a node-set", function)
+	}
+	return nodes, nil
+}
+
+// round rounds half up, as XPath does
+func round(n float64) float64 {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return n
+	}
+	return math.Floor(n + 0.5)
+}
+
+// substring returns the characters from a 1-based, rounded start position
+func substring(args []interface{}) string {
+	runes := []rune(toString(args[0]))
+	start := round(toNumber(args[1]))
+	end := math.Inf(1)
+	if len(args) == 3 {
+		end = start + round(toNumber(args[2]))
+	}
+	var b strings.Builder
+	for i, r := range runes {
+		if position := float64(i + 1); position >= start && position < end {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// translate replaces the characters of from with those at the same position in to,
+// removing the characters that have no counterpart
+func translate(s, from, to string) string {
+	mapping := make(map[rune]rune)
+	toRunes := []rune(to)
+	i := 0
+	for _, r := range from {
+		if _, exists := mapping[r]; !exists {
+			if i < len(toRunes) {
+				mapping[r] = toRunes[i]
+			} else {
+				mapping[r] = -1
+			}
+		}
+		i++
+	}
+	return strings.Map(func(r rune) rune {
+		if mapped, ok := mapping[r]; ok {
+			return mapped
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,171 @@
+package xpath
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenLiteral
+	tokenName     // NCName or QName, also prefix:*
+	tokenStar     // * as a name test
+	tokenOperator // and, or, div, mod, *, =, !=, <, <=, >, >=, +, -, |, /, //
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenDot
+	tokenDotDot
+	tokenAt
+	tokenComma
+	tokenColonColon
+	tokenVariable
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// tokenize splits an XPath expression into tokens. A * or a name such as "div" is an
+// operator when it follows a token that ends an operand, as the XPath 1.0 grammar says.
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	s := []rune(expr)
+	for i := 0; i < len(s); {
+		r := s[i]
+		if unicode.IsSpace(r) {
+			i++
+			continue
+		}
+
+		add := func(kind tokenKind, value string, length int) {
+			tokens = append(tokens, token{kind, value})
+			i += length
+		}
+		next := rune(0)
+		if i+1 < len(s) {
+			next = s[i+1]
+		}
+
+		switch {
+		case r == '(':
+			add(tokenLParen, "(", 1)
+		case r == ')':
+			add(tokenRParen, ")", 1)
+		case r == '[':
+			add(tokenLBracket, "[", 1)
+		case r == ']':
+			add(tokenRBracket, "]", 1)
+		case r == '@':
+			add(tokenAt, "@", 1)
+		case r == ',':
+			add(tokenComma, ",", 1)
+		case r == ':' && next == ':':
+			add(tokenColonColon, "::", 2)
+		case r == '.' && next == '.':
+			add(tokenDotDot, "..", 2)
+		case r == '.' && !isDigit(next):
+			add(tokenDot, ".", 1)
+		case r == '/' && next == '/':
+			add(tokenOperator, "//", 2)
+		case r == '!' && next == '=':
+			add(tokenOperator, "!=", 2)
+		case (r == '<' || r == '>') && next == '=':
+			add(tokenOperator, string(r)+"=", 2)
+		case strings.ContainsRune("/|+-=<>", r):
+			add(tokenOperator, string(r), 1)
+		case r == '*':
+			if operatorExpected(tokens) {
+				add(tokenOperator, "*", 1)
+			} else {
+				add(tokenStar, "*", 1)
+			}
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(s) && s[end] != r {
+				end++
+			}
+			if end == len(s) {
+				return nil, fmt.Errorf("unterminated string in %q", expr)
+			}
+			add(tokenLiteral, string(s[i+1:end]), end-i+1)
+		case isDigit(r) || r == '.':
+			end := i
+			for end < len(s) && isDigit(s[end]) {
+				end++
+			}
+			if end < len(s) && s[end] == '.' {
+				end++
+				for end < len(s) && isDigit(s[end]) {
+					end++
+				}
+			}
+			add(tokenNumber, string(s[i:end]), end-i)
+		case r == '$':
+			end := scanName(s, i+1)
+			if end == i+1 {
+				return nil, fmt.Errorf("expected a variable name after $ in %q", expr)
+			}
+			add(tokenVariable, string(s[i+1:end]), end-i)
+		case isNameStart(r):
+			end := scanName(s, i)
+			name := string(s[i:end])
+			if operatorExpected(tokens) {
+				if name != "and" && name != "or" && name != "div" && name != "mod" {
+					return nil, fmt.Errorf("unexpected %q in %q", name, expr)
+				}
+				add(tokenOperator, name, end-i)
+				continue
+			}
+			// A QName or prefix:*, but not an axis followed by ::
+			if end+1 < len(s) && s[end] == ':' && s[end+1] != ':' {
+				if s[end+1] == '*' {
+					name += ":*"
+					end += 2
+				} else if isNameStart(s[end+1]) {
+					local := scanName(s, end+1)
+					name += ":" + string(s[end+1:local])
+					end = local
+				}
+			}
+			add(tokenName, name, end-i)
+		default:
+			return nil, fmt.Errorf("unexpected character %q in %q", r, expr)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+// operatorExpected reports whether the previous token ends an operand, so that the
+// next * or name is an operator
+func operatorExpected(tokens []token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	switch tokens[len(tokens)-1].kind {
+	case tokenAt, tokenColonColon, tokenLParen, tokenLBracket, tokenComma, tokenOperator:
+		return false
+	}
+	return true
+}
+
+func scanName(s []rune, i int) int {
+	for i < len(s) && (isNameStart(s[i]) || isDigit(s[i]) || s[i] == '-' || s[i] == '.') {
+		i++
+	}
+	return i
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
@@ -0,0 +1,210 @@
+package xpath
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NodeType is the kind of an XML node
+type NodeType int
+
+const (
+	DocumentNode NodeType = iota
+	ElementNode
+	AttributeNode
+	TextNode
+	CommentNode
+	ProcessingInstructionNode
+)
+
+// Node is a node of a parsed XML document
+type Node struct {
+	Type     NodeType
+	Prefix   string // Prefix of an element or attribute name as written in the document
+	Local    string // Local name of an element or attribute, or the target of a processing instruction
+	Space    string // Namespace URI
+	Data     string // Text of text, comment and processing instruction nodes, value of attributes
+	Parent   *Node
+	Children []*Node
+	Attrs    []*Node
+
+	order      int               // Position in document order
+	namespaces map[string]string // Document nodes only: the first declaration of every prefix
+}
+
+// Parse parses an XML document, such as a SOAP envelope
+func Parse(data []byte) (*Node, error) {
+	doc := &Node{Type: DocumentNode, namespaces: make(map[string]string)}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charsetReader
+
+	order := 1
+	current := doc
+	// Namespace declarations in scope, innermost last, to recover the prefixes of names
+	var scopes []map[string]string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			declared := make(map[string]string)
+			for _, attr := range t.Attr {
+				switch {
+				case attr.Name.Space == "xmlns":
+					declared[attr.Name.Local] = attr.Value
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+					declared[""] = attr.Value
+				}
+			}
+			scopes = append(scopes, declared)
+			for prefix, uri := range declared {
+				if _, exists := doc.namespaces[prefix]; !exists && prefix != "" {
+					doc.namespaces[prefix] = uri
+				}
+			}
+
+			element := &Node{Type: ElementNode, Prefix: prefixOf(scopes, t.Name.Space), Local: t.Name.Local, Space: t.Name.Space, Parent: current, order: order}
+			order++
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+					continue
+				}
+				prefix := ""
+				if attr.Name.Space != "" {
+					prefix = prefixOf(scopes, attr.Name.Space)
+				}
+				element.Attrs = append(element.Attrs, &Node{Type: AttributeNode, Prefix: prefix, Local: attr.Name.Local, Space: attr.Name.Space, Data: attr.Value, Parent: element, order: order})
+				order++
+			}
+			current.Children = append(current.Children, element)
+			current = element
+		case xml.EndElement:
+			scopes = scopes[:len(scopes)-1]
+			current = current.Parent
+		case xml.CharData:
+			// Only whitespace may surround the root element
+			if current == doc {
+				continue
+			}
+			// CDATA sections and the text around them form one text node
+			if n := len(current.Children); n > 0 && current.Children[n-1].Type == TextNode {
+				current.Children[n-1].Data += string(t)
+				continue
+			}
+			current.Children = append(current.Children, &Node{Type: TextNode, Data: string(t), Parent: current, order: order})
+			order++
+		case xml.Comment:
+			current.Children = append(current.Children, &Node{Type: CommentNode, Data: string(t), Parent: current, order: order})
+			order++
+		case xml.ProcInst:
+			if t.Target == "xml" {
+				continue
+			}
+			current.Children = append(current.Children, &Node{Type: ProcessingInstructionNode, Local: t.Target, Data: string(t.Inst), Parent: current, order: order})
+			order++
+		}
+	}
+
+	if current != doc {
+		return nil, fmt.Errorf("invalid XML: unclosed element %s", current.Name())
+	}
+	if doc.documentElement() == nil {
+		return nil, fmt.Errorf("invalid XML: no root element")
+	}
+	return doc, nil
+}
+
+// prefixOf returns the innermost prefix declared for a namespace URI
+func prefixOf(scopes []map[string]string, uri string) string {
+	if uri == "" {
+		return ""
+	}
+	for i := len(scopes) - 1; i >= 0; i-- {
+		if scopes[i][""] == uri {
+			return ""
+		}
+		for prefix, declared := range scopes[i] {
+			if declared == uri && prefix != "" {
+				return prefix
+			}
+		}
+	}
+	return ""
+}
+
+// charsetReader accepts documents declared as Latin-1 next to UTF-8
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "latin1", "latin-1":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return strings.NewReader(string(runes)), nil
+	}
+	return nil, fmt.Errorf("unsupported charset %q", charset)
+}
+
+// Name returns the qualified name of an element or attribute as written in the document
+func (n *Node) Name() string {
+	if n.Prefix != "" {
+		return n.Prefix + ":" + n.Local
+	}
+	return n.Local
+}
+
+// StringValue returns the XPath string value of the node: the text of all descendant
+// text nodes for documents and elements, and the data of other nodes
+func (n *Node) StringValue() string {
+	switch n.Type {
+	case DocumentNode, ElementNode:
+		var b strings.Builder
+		n.writeText(&b)
+		return b.String()
+	}
+	return n.Data
+}
+
+func (n *Node) writeText(b *strings.Builder) {
+	for _, child := range n.Children {
+		switch child.Type {
+		case TextNode:
+			b.WriteString(child.Data)
+		case ElementNode:
+			child.writeText(b)
+		}
+	}
+}
+
+// root returns the document node the node belongs to
+func (n *Node) root() *Node {
+	for n.Parent != nil {
+		n = n.Parent
+	}
+	return n
+}
+
+// documentElement returns the root element of a document node
+func (n *Node) documentElement() *Node {
+	for _, child := range n.Children {
+		if child.Type == ElementNode {
+			return child
+		}
+	}
+	return nil
+}
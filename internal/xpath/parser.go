@@ -0,0 +1,423 @@
+package xpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// expr is a node of a compiled XPath expression
+type expr interface {
+	eval(ctx *context) (interface{}, error)
+}
+
+type binaryExpr struct {
+	op          string
+	left, right expr
+}
+
+type negateExpr struct {
+	operand expr
+}
+
+type literalExpr struct {
+	value string
+}
+
+type numberExpr struct {
+	value float64
+}
+
+type functionCall struct {
+	name string
+	args []expr
+}
+
+// filterExpr is a primary expression followed by predicates, such as (//item)[1]
+type filterExpr struct {
+	primary    expr
+	predicates []expr
+}
+
+// pathExpr is a location path, optionally starting from a filter expression
+type pathExpr struct {
+	filter   expr
+	absolute bool
+	steps    []*step
+}
+
+type step struct {
+	axis       string
+	test       nodeTest
+	predicates []expr
+}
+
+type testKind int
+
+const (
+	testName    testKind = iota // name or prefix:name
+	testAny                     // * or prefix:*
+	testNode                    // node()
+	testText                    // text()
+	testComment                 // comment()
+	testPI                      // processing-instruction()
+)
+
+type nodeTest struct {
+	kind   testKind
+	prefix string
+	local  string // Name for testName, target for testPI
+}
+
+var axes = map[string]bool{
+	"ancestor": true, "ancestor-or-self": true, "attribute": true, "child": true,
+	"descendant": true, "descendant-or-self": true, "following": true, "following-sibling": true,
+	"namespace": true, "parent": true, "preceding": true, "preceding-sibling": true, "self": true,
+}
+
+var nodeTypes = map[string]testKind{
+	"node": testNode, "text": testText, "comment": testComment, "processing-instruction": testPI,
+}
+
+type parser struct {
+	expr   string
+	tokens []token
+	pos    int
+}
+
+func parse(source string) (expr, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{expr: source, tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected()
+	}
+	return e, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return token{kind: tokenEOF}
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOperator(ops ...string) bool {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if t.value == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind, what string) error {
+	if p.peek().kind != kind {
+		return fmt.Errorf("expected %s in %q", what, p.expr)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of %q", p.expr)
+	}
+	return fmt.Errorf("unexpected %q in %q", t.value, p.expr)
+}
+
+// parseBinary parses a left-associative chain of operators of one precedence level
+func (p *parser) parseBinary(operand func() (expr, error), ops ...string) (expr, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator(ops...) {
+		op := p.next().value
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseOr() (expr, error) {
+	return p.parseBinary(p.parseAnd, "or")
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	return p.parseBinary(p.parseEquality, "and")
+}
+
+func (p *parser) parseEquality() (expr, error) {
+	return p.parseBinary(p.parseRelational, "=", "!=")
+}
+
+func (p *parser) parseRelational() (expr, error) {
+	return p.parseBinary(p.parseAdditive, "<", "<=", ">", ">=")
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	return p.parseBinary(p.parseUnary, "*", "div", "mod")
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.isOperator("-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negateExpr{operand: operand}, nil
+	}
+	return p.parseBinary(p.parsePath, "|")
+}
+
+func (p *parser) parsePath() (expr, error) {
+	t := p.peek()
+	isFilter := t.kind == tokenLParen || t.kind == tokenLiteral || t.kind == tokenNumber || t.kind == tokenVariable
+	if t.kind == tokenName && p.peekAt(1).kind == tokenLParen {
+		_, isNodeType := nodeTypes[t.value]
+		isFilter = !isNodeType
+	}
+	if !isFilter {
+		return p.parseLocationPath()
+	}
+
+	primary, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	predicates, err := p.parsePredicates()
+	if err != nil {
+		return nil, err
+	}
+	var filter expr = primary
+	if len(predicates) > 0 {
+		filter = &filterExpr{primary: primary, predicates: predicates}
+	}
+	if !p.isOperator("/", "//") {
+		return filter, nil
+	}
+
+	path := &pathExpr{filter: filter}
+	if err := p.parseRelativePath(path); err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+func (p *parser) parseLocationPath() (expr, error) {
+	path := &pathExpr{}
+	if p.isOperator("/") {
+		p.next()
+		path.absolute = true
+		if !p.startsStep() {
+			return path, nil
+		}
+	} else if p.isOperator("//") {
+		path.absolute = true
+	}
+	if !p.isOperator("//") && !p.startsStep() {
+		return nil, p.unexpected()
+	}
+	if !p.isOperator("//") {
+		s, err := p.parseStep()
+		if err != nil {
+			return nil, err
+		}
+		path.steps = append(path.steps, s)
+	}
+	if err := p.parseRelativePath(path); err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+// parseRelativePath parses the steps after a / or //, adding them to the path
+func (p *parser) parseRelativePath(path *pathExpr) error {
+	for p.isOperator("/", "//") {
+		if p.next().value == "//" {
+			path.steps = append(path.steps, &step{axis: "descendant-or-self", test: nodeTest{kind: testNode}})
+		}
+		s, err := p.parseStep()
+		if err != nil {
+			return err
+		}
+		path.steps = append(path.steps, s)
+	}
+	return nil
+}
+
+func (p *parser) startsStep() bool {
+	switch p.peek().kind {
+	case tokenName, tokenStar, tokenAt, tokenDot, tokenDotDot:
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseStep() (*step, error) {
+	switch p.peek().kind {
+	case tokenDot:
+		p.next()
+		return &step{axis: "self", test: nodeTest{kind: testNode}}, nil
+	case tokenDotDot:
+		p.next()
+		return &step{axis: "parent", test: nodeTest{kind: testNode}}, nil
+	}
+
+	s := &step{axis: "child"}
+	if p.peek().kind == tokenAt {
+		p.next()
+		s.axis = "attribute"
+	} else if p.peek().kind == tokenName && p.peekAt(1).kind == tokenColonColon {
+		name := p.next().value
+		if !axes[name] {
+			return nil, fmt.Errorf("unknown axis %q in %q", name, p.expr)
+		}
+		p.next()
+		s.axis = name
+	}
+
+	test, err := p.parseNodeTest()
+	if err != nil {
+		return nil, err
+	}
+	s.test = test
+	if s.predicates, err = p.parsePredicates(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (p *parser) parseNodeTest() (nodeTest, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenStar:
+		p.next()
+		return nodeTest{kind: testAny}, nil
+	case t.kind != tokenName:
+		return nodeTest{}, p.unexpected()
+	}
+	p.next()
+
+	if kind, ok := nodeTypes[t.value]; ok && p.peek().kind == tokenLParen {
+		p.next()
+		test := nodeTest{kind: kind}
+		if kind == testPI && p.peek().kind == tokenLiteral {
+			test.local = p.next().value
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nodeTest{}, err
+		}
+		return test, nil
+	}
+
+	if prefix, ok := strings.CutSuffix(t.value, ":*"); ok {
+		return nodeTest{kind: testAny, prefix: prefix}, nil
+	}
+	if prefix, local, ok := strings.Cut(t.value, ":"); ok {
+		return nodeTest{kind: testName, prefix: prefix, local: local}, nil
+	}
+	return nodeTest{kind: testName, local: t.value}, nil
+}
+
+func (p *parser) parsePredicates() ([]expr, error) {
+	var predicates []expr
+	for p.peek().kind == tokenLBracket {
+		p.next()
+		predicate, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRBracket, "]"); err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, predicate)
+	}
+	return predicates, nil
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenVariable:
+		return nil, fmt.Errorf("XPath variables are not supported, use {{%s}} instead", t.value)
+	case tokenLiteral:
+		return &literalExpr{value: t.value}, nil
+	case tokenNumber:
+		value, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q in %q", t.value, p.expr)
+		}
+		return &numberExpr{value: value}, nil
+	case tokenLParen:
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+
+	// Function call
+	name := t.value
+	arity, ok := functions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown XPath function %q", name)
+	}
+	p.next()
+	call := &functionCall{name: name}
+	if p.peek().kind != tokenRParen {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.expect(tokenRParen, ")"); err != nil {
+		return nil, err
+	}
+	if len(call.args) < arity.min || arity.max >= 0 && len(call.args) > arity.max {
+		return nil, fmt.Errorf("wrong number of arguments for %s(): %d", name, len(call.args))
+	}
+	return call, nil
+}
@@ -0,0 +1,44 @@
+package xpath
+
+import "fmt"
+
+// Expr is a compiled XPath 1.0 expression
+type Expr struct {
+	source string
+	root   expr
+}
+
+// Compile parses an XPath 1.0 expression
+func Compile(source string) (*Expr, error) {
+	root, err := parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid XPath: %w", err)
+	}
+	return &Expr{source: source, root: root}, nil
+}
+
+// String returns the source of the expression
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Evaluate evaluates the expression against a document. Prefixes in the expression are
+// resolved with namespaces first and with the declarations of the document second.
+// The result is a []*Node in document order, a string, a float64 or a bool.
+func (e *Expr) Evaluate(doc *Node, namespaces map[string]string) (interface{}, error) {
+	ctx := &context{node: doc, position: 1, size: 1, namespaces: namespaces}
+	value, err := e.root.eval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate XPath %q: %w", e.source, err)
+	}
+	return value, nil
+}
+
+// Evaluate compiles and evaluates an expression against a document
+func Evaluate(doc *Node, source string, namespaces map[string]string) (interface{}, error) {
+	e, err := Compile(source)
+	if err != nil {
+		return nil, err
+	}
+	return e.Evaluate(doc, namespaces)
+}
@@ -0,0 +1,184 @@
+package xpath
+
+import (
+	"math"
+	"testing"
+)
+
+const envelope = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <GetOrderResponse xmlns="urn:orders" xmlns:m="urn:money">
+      <Order id="42" status="shipped">
+        <Customer>Jane <![CDATA[& Co]]></Customer>
+        <Item sku="A"><Qty>1</Qty><m:Price currency="EUR">9.50</m:Price></Item>
+        <Item sku="B"><Qty>5</Qty><m:Price currency="EUR">2.00</m:Price></Item>
+        <Item sku="C"><Qty>2</Qty><m:Price currency="USD">4.25</m:Price></Item>
+        <!-- three items -->
+      </Order>
+    </GetOrderResponse>
+  </soap:Body>
+</soap:Envelope>`
+
+func parseEnvelope(t *testing.T) *Node {
+	t.Helper()
+	doc, err := Parse([]byte(envelope))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return doc
+}
+
+func TestEvaluate(t *testing.T) {
+	doc := parseEnvelope(t)
+	namespaces := map[string]string{"o": "urn:orders", "s": "http://schemas.xmlsoap.org/soap/envelope/"}
+
+	tests := []struct {
+		expr string
+		want interface{}
+	}{
+		// Paths, as strings of their first node
+		{"string(/soap:Envelope/soap:Body/GetOrderResponse/Order/@id)", "42"},
+		{"string(/s:Envelope/s:Body/o:GetOrderResponse/o:Order/@status)", "shipped"},
+		{"string(//Order/Customer)", "Jane & Co"},
+		{"string(//Item[2]/@sku)", "B"},
+		{"string(//Item[last()]/@sku)", "C"},
+		{"string(//Item[Qty > 1][1]/@sku)", "B"},
+		{"string(//Item[m:Price/@currency = 'USD']/@sku)", "C"},
+		{"string(//Item[@sku = 'B']/following-sibling::Item/@sku)", "C"},
+		{"string(//Item[@sku = 'B']/preceding-sibling::*[1]/@sku)", "A"},
+		{"string(//Qty[. = 5]/ancestor::Order/@id)", "42"},
+		{"string(//Item[3]/preceding::Qty[1])", "5"},
+		{"string((//Item)[position() = 2]/Qty)", "5"},
+		{"string(//Item/@sku[. = 'C']/..//m:Price)", "4.25"},
+		{"string(//comment())", " three items "},
+		{"local-name(//o:Order/*[1])", "Customer"},
+		{"name(//m:Price)", "m:Price"},
+		{"namespace-uri(//Order)", "urn:orders"},
+
+		// Numbers
+		{"count(//Item)", 3.0},
+		{"count(//Item | //Item[1] | //Qty)", 6.0},
+		{"sum(//Qty)", 8.0},
+		{"sum(//m:Price) * 2", 31.5},
+		{"count(//*[@currency = 'EUR']) div 2", 1.0},
+		{"7 mod 3 - -1", 2.0},
+		{"round(2.5) + floor(-1.5) + ceiling(1.2)", 3.0},
+		{"string-length(//Customer)", 9.0},
+
+		// Strings
+		{"concat(//Item[1]/@sku, '-', //Item[1]/Qty)", "A-1"},
+		{"substring('12345', 1.5, 2.6)", "234"},
+		{"substring-before('2024-01-02', '-')", "2024"},
+		{"substring-after('2024-01-02', '-')", "01-02"},
+		{"normalize-space('  a   b ')", "a b"},
+		{"translate('bar', 'abc', 'ABC')", "BAr"},
+		{"translate('--a--', '-', '')", "a"},
+		{"string(1 div 0)", "Infinity"},
+		{"string(0.5 * 3)", "1.5"},
+
+		// Booleans and comparisons
+		{"//Item/Qty = 5", true},
+		{"//Item/Qty = 6", false},
+		{"//Item/Qty != 1", true},
+		{"//Item/@sku = //Item[3]/@sku", true},
+		{"//Missing = ''", false},
+		{"//Order/@status = 'shipped' and count(//Item) = 3", true},
+		{"not(//Missing) or false()", true},
+		{"starts-with(//Customer, 'Jane')", true},
+		{"contains(//Customer, 'Co')", true},
+		{"boolean(//Item[4])", false},
+		{"//Item = true()", true},
+		{"number('abc') = number('abc')", false},
+	}
+	for _, tt := range tests {
+		got, err := Evaluate(doc, tt.expr, namespaces)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Evaluate(%q) = %#v, want %#v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateNodeSets(t *testing.T) {
+	doc := parseEnvelope(t)
+
+	got, err := Evaluate(doc, "//Item/@sku", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	nodes := got.([]*Node)
+	if len(nodes) != 3 || nodes[0].Data != "A" || nodes[2].Data != "C" {
+		t.Errorf("Expected the three sku attributes in document order, got %v", nodes)
+	}
+
+	got, err = Evaluate(doc, "//Item[Qty = 5]/ancestor-or-self::*", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var names []string
+	for _, n := range got.([]*Node) {
+		names = append(names, n.Name())
+	}
+	if len(names) != 5 || names[0] != "soap:Envelope" || names[4] != "Item" {
+		t.Errorf("Expected the ancestors in document order, got %v", names)
+	}
+
+	got, err = Evaluate(doc, "/", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if nodes := got.([]*Node); len(nodes) != 1 || nodes[0] != doc {
+		t.Errorf("Expected / to select the document, got %v", nodes)
+	}
+
+	got, err = Evaluate(doc, "number(//Missing)", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !math.IsNaN(got.(float64)) {
+		t.Errorf("Expected NaN for a missing node, got %v", got)
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	doc := parseEnvelope(t)
+
+	for _, expr := range []string{
+		"//Item[",
+		"//Item]",
+		"count(//Item",
+		"unknown(//Item)",
+		"count()",
+		"bogus::Item",
+		"//Item/$sku",
+		"'unterminated",
+		"//Item Qty",
+		"//x:Item",
+		"count(1)",
+		"1 | //Item",
+	} {
+		if _, err := Evaluate(doc, expr, nil); err == nil {
+			t.Errorf("Evaluate(%q): expected an error", expr)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, data := range []string{"", "not xml", "<a><b></a>", "<a>"} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q): expected an error", data)
+		}
+	}
+
+	doc, err := Parse([]byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><name>M\xfcller</name>"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := doc.StringValue(); got != "Müller" {
+		t.Errorf("Expected Latin-1 to be decoded, got %q", got)
+	}
+}